@@ -0,0 +1,26 @@
+// Package openapi содержит OpenAPI-описание HTTP API кошелька (openapi.json).
+package openapi
+
+import (
+	"context"
+	_ "embed"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Spec — документ OpenAPI, который сервер отдаёт по GET /api/v1/openapi.json.
+//
+//go:embed openapi.json
+var Spec []byte
+
+// Load разбирает и проверяет Spec.
+func Load() (*openapi3.T, error) {
+	doc, err := openapi3.NewLoader().LoadFromData(Spec)
+	if err != nil {
+		return nil, err
+	}
+	if err := doc.Validate(context.Background()); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -0,0 +1,135 @@
+// Команда apikey управляет API-ключами (AUTH_MODE=api_key):
+//
+//	apikey create -name billing   выпустить ключ; сам ключ печатается один раз, в БД хранится его хэш
+//	apikey create -name ops -scopes admin   ключ с правами (через запятую), admin — административные эндпоинты
+//	apikey list                   все ключи, включая отозванные
+//	apikey revoke -id <uuid>      отозвать ключ
+//
+// Результат печатается в stdout в JSON. Код выхода: 0 — успех, 1 — ключ не найден или имя
+// занято, 2 — ошибка.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+	"test_wallet/internal/auth"
+	"test_wallet/internal/config"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:]))
+}
+
+func run(args []string) int {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, "usage: apikey create -name NAME [-scopes admin] | list | revoke -id ID")
+		return 2
+	}
+	flags := flag.NewFlagSet("apikey "+args[0], flag.ContinueOnError)
+	name := flags.String("name", "", "имя ключа (create), например имя сервиса-клиента")
+	idFlag := flags.String("id", "", "id ключа (revoke)")
+	scopes := flags.String("scopes", "", "права ключа через запятую (create), например admin")
+	if err := flags.Parse(args[1:]); err != nil {
+		return 2
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Error("failed to load config", "err", err)
+		return 2
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute)
+	defer cancel()
+	pool, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		logger.Error("failed to connect to database", "err", err)
+		return 2
+	}
+	defer pool.Close()
+	repo := repository.NewWalletPGRepository(pool, logger)
+
+	var result any
+	switch args[0] {
+	case "create":
+		if *name == "" {
+			logger.Error("-name is required")
+			return 2
+		}
+		key, err := auth.GenerateAPIKey()
+		if err != nil {
+			logger.Error("failed to generate api key", "err", err)
+			return 2
+		}
+		created, err := repo.CreateAPIKey(ctx, models.APIKey{
+			ID:      uuid.New(),
+			Name:    *name,
+			KeyHash: auth.HashAPIKey(key),
+			Scopes:  parseScopes(*scopes),
+		})
+		if errors.Is(err, repository.ErrAPIKeyExists) {
+			logger.Error("api key with this name already exists", "name", *name)
+			return 1
+		}
+		if err != nil {
+			return 2
+		}
+		result = struct {
+			models.APIKey
+			Key string `json:"key"`
+		}{created, key}
+	case "list":
+		if result, err = repo.ListAPIKeys(ctx); err != nil {
+			return 2
+		}
+	case "revoke":
+		id, err := uuid.Parse(*idFlag)
+		if err != nil {
+			logger.Error("invalid api key id", "id", *idFlag, "err", err)
+			return 2
+		}
+		revoked, err := repo.RevokeAPIKey(ctx, id)
+		if errors.Is(err, repository.ErrAPIKeyNotFound) {
+			logger.Error("api key not found", "id", id)
+			return 1
+		}
+		if err != nil {
+			return 2
+		}
+		result = revoked
+	default:
+		logger.Error("unknown command", "command", args[0])
+		return 2
+	}
+
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		logger.Error("failed to write result", "err", err)
+		return 2
+	}
+	return 0
+}
+
+// parseScopes разбирает список прав через запятую, пропуская пустые элементы.
+func parseScopes(list string) []string {
+	scopes := []string{}
+	for _, scope := range strings.Split(list, ",") {
+		if scope = strings.TrimSpace(scope); scope != "" {
+			scopes = append(scopes, scope)
+		}
+	}
+	return scopes
+}
@@ -0,0 +1,56 @@
+// Команда reconcile сверяет балансы кошельков с журналом операций и главной книгой и печатает
+// JSON-отчёт в stdout. Код выхода: 0 — расхождений нет, 1 — найдены расхождения, 2 — сверка
+// не выполнена.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"os"
+	"test_wallet/internal/config"
+	"test_wallet/internal/repository"
+	"test_wallet/internal/service"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	// stdout занят отчётом, поэтому логи идут в stderr
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Error("failed to load config", "err", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Minute)
+	defer cancel()
+	pool, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		logger.Error("failed to connect to database", "err", err)
+		return 2
+	}
+	defer pool.Close()
+
+	svc := service.NewWalletService(repository.NewWalletPGRepository(pool, logger), logger)
+	report, err := svc.Reconcile(ctx)
+	if err != nil {
+		return 2
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(report); err != nil {
+		logger.Error("failed to write report", "err", err)
+		return 2
+	}
+	if !report.OK {
+		return 1
+	}
+	return 0
+}
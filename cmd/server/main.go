@@ -3,19 +3,28 @@ package main
 import (
 	"context"
 	"log"
+	"net"
 	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
+	"test_wallet/internal/auth"
 	"test_wallet/internal/config"
+	"test_wallet/internal/events"
 	"test_wallet/internal/handlers"
 	"test_wallet/internal/logging"
+	"test_wallet/internal/models"
+	"test_wallet/internal/rates"
 	"test_wallet/internal/repository"
+	"test_wallet/internal/scheduler"
 	"test_wallet/internal/service"
+	"test_wallet/internal/stream"
+	"test_wallet/internal/webhooks"
 	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/jackc/pgx/v5/pgxpool"
+	"google.golang.org/grpc"
 )
 
 func main() {
@@ -43,9 +52,108 @@ func main() {
 	}
 	defer pool.Close()
 
-	repo := repository.NewWalletPGRepository(pool, logger)
-	svc := service.NewWalletService(repo, logger)
-	hanlder := handlers.NewWalletHTTPHandler(svc)
+	repo := repository.NewWalletPGRepository(pool, logger,
+		repository.WithDefaultCurrency(cfg.DefaultCurrency),
+		repository.WithFrozenDeposits(cfg.FrozenWalletDeposits),
+		repository.WithImplicitCreation(cfg.ImplicitWalletCreation),
+	)
+	svcOpts := []service.Option{
+		service.WithQuoteTTL(cfg.QuoteTTL),
+		service.WithReversalPolicy(cfg.ReversalPolicy),
+	}
+	switch {
+	case cfg.RatesURL != "":
+		svcOpts = append(svcOpts, service.WithRateProvider(rates.NewHTTPProvider(cfg.RatesURL, nil)))
+	case cfg.RatesFile != "":
+		provider, err := rates.NewStaticProvider(cfg.RatesFile)
+		if err != nil {
+			logger.Error("failed to load rates file", "err", err)
+			os.Exit(1)
+		}
+		svcOpts = append(svcOpts, service.WithRateProvider(provider))
+	default:
+		logger.Warn("no exchange rate source configured, conversions are disabled")
+	}
+	svc := service.NewWalletService(repo, logger, svcOpts...)
+
+	bgCtx, stopBackground := context.WithCancel(context.Background())
+	defer stopBackground()
+	// Hub останавливается вместе с фоновыми задачами и закрывает SSE-потоки, иначе они
+	// держали бы srv.Shutdown до таймаута
+	hub := stream.NewHub(repo, logger)
+	go hub.Run(bgCtx)
+	handlerOpts := []handlers.Option{handlers.WithEventStream(hub)}
+	var grpcOpts []grpc.ServerOption
+	authenticator, err := newAuthenticator(cfg, repo)
+	if err != nil {
+		logger.Error("failed to configure authentication", "err", err)
+		os.Exit(1)
+	}
+	if authenticator != nil {
+		handlerOpts = append(handlerOpts, handlers.WithAuthenticator(authenticator))
+		grpcOpts = append(grpcOpts, grpc.UnaryInterceptor(handlers.UnaryAuthInterceptor(authenticator)))
+	} else {
+		logger.Warn("authentication is disabled (AUTH_MODE=none), the API is open to anyone who can reach it")
+	}
+	hanlder := handlers.NewWalletHTTPHandler(svc, handlerOpts...)
+	go func() {
+		ticker := time.NewTicker(time.Minute)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-bgCtx.Done():
+				return
+			case <-ticker.C:
+				svc.ExpireHolds(bgCtx)
+			}
+		}
+	}()
+	go func() {
+		ticker := time.NewTicker(time.Hour)
+		defer ticker.Stop()
+		svc.SnapshotBalances(bgCtx)
+		for {
+			select {
+			case <-bgCtx.Done():
+				return
+			case <-ticker.C:
+				svc.SnapshotBalances(bgCtx)
+			}
+		}
+	}()
+	if cfg.ReconcileInterval > 0 {
+		go func() {
+			ticker := time.NewTicker(cfg.ReconcileInterval)
+			defer ticker.Stop()
+			for {
+				select {
+				case <-bgCtx.Done():
+					return
+				case <-ticker.C:
+					_, _ = svc.Reconcile(bgCtx)
+				}
+			}
+		}()
+	}
+	go scheduler.New(repo, svc, logger, scheduler.WithInterval(cfg.SchedulerInterval)).Run(bgCtx)
+
+	publishers := []events.EventPublisher{webhooks.NewPublisher(repo)}
+	switch cfg.EventPublisher {
+	case "stdout":
+		publishers = append(publishers, events.NewStdoutPublisher())
+	case "file":
+		filePublisher, err := events.NewFilePublisher(cfg.EventFile)
+		if err != nil {
+			logger.Error("failed to open event file", "err", err)
+			os.Exit(1)
+		}
+		defer filePublisher.Close()
+		publishers = append(publishers, filePublisher)
+	case "http":
+		publishers = append(publishers, events.NewHTTPPublisher(cfg.EventURL, nil))
+	}
+	go events.NewRelay(repo, events.Multi(publishers...), logger).Run(bgCtx)
+	go webhooks.NewDispatcher(repo, logger).Run(bgCtx)
 
 	r := gin.Default()
 	hanlder.RegisterRoutes(r)
@@ -62,15 +170,80 @@ func main() {
 		}
 	}()
 
+	grpcServer := grpc.NewServer(grpcOpts...)
+	handlers.NewWalletGRPCServer(svc).Register(grpcServer)
+	grpcListener, err := net.Listen("tcp", ":"+cfg.GRPCPort)
+	if err != nil {
+		logger.Error("failed to listen gRPC port", "err", err)
+		os.Exit(1)
+	}
+	go func() {
+		logger.Info("Starting gRPC server", "port", cfg.GRPCPort)
+		if err := grpcServer.Serve(grpcListener); err != nil {
+			logger.Error("gRPC server failed", "err", err)
+		}
+	}()
+
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM)
 	<-quit
 	logger.Info("Shutting down server")
+	stopBackground()
 
 	ctxShutdown, cancelShutdown := context.WithTimeout(context.Background(), 10*time.Second)
 	defer cancelShutdown()
+	// HTTP и gRPC дожидаются текущих запросов параллельно и в пределах одного таймаута
+	grpcStopped := make(chan struct{})
+	go func() {
+		grpcServer.GracefulStop()
+		close(grpcStopped)
+	}()
 	if err := srv.Shutdown(ctxShutdown); err != nil {
 		logger.Error("Server forced to shutdown", "err", err)
 	}
+	select {
+	case <-grpcStopped:
+	case <-ctxShutdown.Done():
+		logger.Error("gRPC server forced to shutdown")
+		grpcServer.Stop()
+	}
 	logger.Info("Server exiting")
 }
+
+// newAuthenticator собирает аутентификаторы для cfg.AuthModes; nil — аутентификация отключена.
+func newAuthenticator(cfg *config.Config, store auth.APIKeyStore) (auth.Authenticator, error) {
+	var chain auth.Chain
+	for _, mode := range cfg.AuthModes {
+		switch mode {
+		case models.AuthMethodAPIKey:
+			chain = append(chain, auth.NewAPIKeyAuthenticator(store))
+		case models.AuthMethodJWT:
+			var keys auth.KeySet
+			if cfg.JWTSecret != "" {
+				keys = append(keys, auth.HMACKey([]byte(cfg.JWTSecret)))
+			}
+			if cfg.JWTPublicKeyFile != "" {
+				key, err := auth.LoadPublicKeyFile(cfg.JWTPublicKeyFile)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, key)
+			}
+			if cfg.JWTJWKSFile != "" {
+				set, err := auth.LoadJWKSFile(cfg.JWTJWKSFile)
+				if err != nil {
+					return nil, err
+				}
+				keys = append(keys, set...)
+			}
+			chain = append(chain, auth.NewJWTAuthenticator(keys,
+				auth.WithIssuer(cfg.JWTIssuer),
+				auth.WithAudience(cfg.JWTAudience),
+			))
+		}
+	}
+	if len(chain) == 0 {
+		return nil, nil
+	}
+	return chain, nil
+}
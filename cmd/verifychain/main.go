@@ -0,0 +1,71 @@
+// Команда verifychain проверяет хэш-цепочку журнала операций и печатает JSON-отчёт в stdout
+// с первым найденным разрывом. Флаг -wallet ограничивает проверку одним кошельком.
+// Код выхода: 0 — цепочка цела, 1 — найден разрыв, 2 — проверка не выполнена.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log/slog"
+	"os"
+	"test_wallet/internal/config"
+	"test_wallet/internal/repository"
+	"test_wallet/internal/service"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+func main() {
+	os.Exit(run())
+}
+
+func run() int {
+	logger := slog.New(slog.NewJSONHandler(os.Stderr, nil))
+	walletFlag := flag.String("wallet", "", "проверить только журнал указанного кошелька")
+	flag.Parse()
+
+	var walletID *uuid.UUID
+	if *walletFlag != "" {
+		id, err := uuid.Parse(*walletFlag)
+		if err != nil {
+			logger.Error("invalid wallet id", "wallet", *walletFlag, "err", err)
+			return 2
+		}
+		walletID = &id
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		logger.Error("failed to load config", "err", err)
+		return 2
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Minute)
+	defer cancel()
+	pool, err := pgxpool.New(ctx, cfg.DBURL)
+	if err != nil {
+		logger.Error("failed to connect to database", "err", err)
+		return 2
+	}
+	defer pool.Close()
+
+	svc := service.NewWalletService(repository.NewWalletPGRepository(pool, logger), logger)
+	result, err := svc.VerifyJournalChain(ctx, walletID)
+	if err != nil {
+		logger.Error("journal chain verification failed", "err", err)
+		return 2
+	}
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(result); err != nil {
+		logger.Error("failed to write report", "err", err)
+		return 2
+	}
+	if !result.OK {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,75 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+)
+
+//go:generate mockgen -source=apikey.go -destination=../../test/mock_auth.go -package=test
+
+// APIKeyHeader — заголовок со статическим API-ключом.
+const APIKeyHeader = "X-API-Key"
+
+// apiKeyPrefix отличает ключи сервиса от других секретов, например при поиске утечек в логах.
+const apiKeyPrefix = "wk_"
+
+type APIKeyStore interface {
+	// GetAPIKeyByHash возвращает действующий ключ или repository.ErrAPIKeyNotFound.
+	GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error)
+}
+
+// APIKeyAuthenticator принимает запросы с действующим ключом в заголовке X-API-Key.
+type APIKeyAuthenticator struct {
+	store APIKeyStore
+}
+
+func NewAPIKeyAuthenticator(store APIKeyStore) *APIKeyAuthenticator {
+	return &APIKeyAuthenticator{store: store}
+}
+
+func (a *APIKeyAuthenticator) Authenticate(ctx context.Context, header func(name string) string) (models.Principal, error) {
+	key := header(APIKeyHeader)
+	if key == "" {
+		return models.Principal{}, ErrNoCredentials
+	}
+	// Ключ без префикса не может быть в БД, запрос к ней не нужен
+	if !strings.HasPrefix(key, apiKeyPrefix) {
+		return models.Principal{}, ErrInvalidCredentials
+	}
+	stored, err := a.store.GetAPIKeyByHash(ctx, HashAPIKey(key))
+	if errors.Is(err, repository.ErrAPIKeyNotFound) {
+		return models.Principal{}, ErrInvalidCredentials
+	}
+	if err != nil {
+		return models.Principal{}, fmt.Errorf("look up api key: %w", err)
+	}
+	return models.Principal{
+		Subject:  stored.Name,
+		Method:   models.AuthMethodAPIKey,
+		APIKeyID: &stored.ID,
+		Scopes:   stored.Scopes,
+	}, nil
+}
+
+// GenerateAPIKey создаёт новый ключ: префикс wk_ и 32 случайных байта в base64url.
+func GenerateAPIKey() (string, error) {
+	buf := make([]byte, 32)
+	if _, err := rand.Read(buf); err != nil {
+		return "", err
+	}
+	return apiKeyPrefix + base64.RawURLEncoding.EncodeToString(buf), nil
+}
+
+// HashAPIKey — SHA-256 ключа в hex для хранения и поиска в БД.
+func HashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
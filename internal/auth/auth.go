@@ -0,0 +1,50 @@
+// Package auth проверяет учётные данные вызывающих API: статические API-ключи, хэши которых
+// хранятся в БД, и JWT, подписанные заданными ключами или ключами из JWKS-файла.
+package auth
+
+import (
+	"context"
+	"errors"
+	"test_wallet/internal/models"
+)
+
+var (
+	// ErrNoCredentials — в запросе нет учётных данных, которые проверяет аутентификатор.
+	ErrNoCredentials = errors.New("missing credentials")
+	// ErrInvalidCredentials — учётные данные есть, но неверны, просрочены или отозваны.
+	ErrInvalidCredentials = errors.New("invalid credentials")
+)
+
+// Authenticator проверяет учётные данные запроса; header возвращает заголовок по имени.
+type Authenticator interface {
+	Authenticate(ctx context.Context, header func(name string) string) (models.Principal, error)
+}
+
+// Chain принимает запрос, если его принял первый аутентификатор, нашедший в нём свои учётные
+// данные. Если учётных данных нет ни для одного, возвращает ErrNoCredentials.
+type Chain []Authenticator
+
+func (c Chain) Authenticate(ctx context.Context, header func(name string) string) (models.Principal, error) {
+	for _, a := range c {
+		principal, err := a.Authenticate(ctx, header)
+		if errors.Is(err, ErrNoCredentials) {
+			continue
+		}
+		return principal, err
+	}
+	return models.Principal{}, ErrNoCredentials
+}
+
+type principalKey struct{}
+
+// NewContext возвращает контекст с аутентифицированным вызывающим.
+func NewContext(ctx context.Context, principal models.Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext возвращает вызывающего, сохранённого NewContext; ok = false, если аутентификация
+// отключена.
+func FromContext(ctx context.Context) (principal models.Principal, ok bool) {
+	principal, ok = ctx.Value(principalKey{}).(models.Principal)
+	return principal, ok
+}
@@ -0,0 +1,115 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/golang-jwt/jwt/v5"
+)
+
+// jwtMethods — алгоритмы подписи, которые принимает JWTAuthenticator (none не принимается).
+var jwtMethods = []string{
+	"HS256", "HS384", "HS512",
+	"RS256", "RS384", "RS512",
+	"PS256", "PS384", "PS512",
+	"ES256", "ES384", "ES512",
+	"EdDSA",
+}
+
+// JWTAuthenticator проверяет заголовок Authorization: Bearer <JWT>; права берутся из claim scope.
+type JWTAuthenticator struct {
+	keys   KeySet
+	parser *jwt.Parser
+}
+
+type JWTOption func(*jwtConfig)
+
+type jwtConfig struct {
+	issuer   string
+	audience string
+	leeway   time.Duration
+}
+
+// WithIssuer требует claim iss, равный issuer.
+func WithIssuer(issuer string) JWTOption {
+	return func(c *jwtConfig) {
+		c.issuer = issuer
+	}
+}
+
+// WithAudience требует audience среди значений claim aud.
+func WithAudience(audience string) JWTOption {
+	return func(c *jwtConfig) {
+		c.audience = audience
+	}
+}
+
+// WithLeeway задаёт допустимое расхождение часов при проверке exp, nbf и iat (по умолчанию 30 секунд).
+func WithLeeway(leeway time.Duration) JWTOption {
+	return func(c *jwtConfig) {
+		c.leeway = leeway
+	}
+}
+
+func NewJWTAuthenticator(keys KeySet, opts ...JWTOption) *JWTAuthenticator {
+	cfg := jwtConfig{leeway: 30 * time.Second}
+	for _, opt := range opts {
+		opt(&cfg)
+	}
+	parserOpts := []jwt.ParserOption{
+		jwt.WithValidMethods(jwtMethods),
+		jwt.WithExpirationRequired(),
+		jwt.WithIssuedAt(),
+		jwt.WithLeeway(cfg.leeway),
+	}
+	if cfg.issuer != "" {
+		parserOpts = append(parserOpts, jwt.WithIssuer(cfg.issuer))
+	}
+	if cfg.audience != "" {
+		parserOpts = append(parserOpts, jwt.WithAudience(cfg.audience))
+	}
+	return &JWTAuthenticator{keys: keys, parser: jwt.NewParser(parserOpts...)}
+}
+
+func (a *JWTAuthenticator) Authenticate(_ context.Context, header func(name string) string) (models.Principal, error) {
+	scheme, token, ok := strings.Cut(header("Authorization"), " ")
+	if !ok || !strings.EqualFold(scheme, "Bearer") {
+		return models.Principal{}, ErrNoCredentials
+	}
+	var claims jwtClaims
+	if _, err := a.parser.ParseWithClaims(strings.TrimSpace(token), &claims, a.keyFunc); err != nil {
+		return models.Principal{}, fmt.Errorf("%w: %w", ErrInvalidCredentials, err)
+	}
+	if claims.Subject == "" {
+		return models.Principal{}, fmt.Errorf("%w: token has no sub claim", ErrInvalidCredentials)
+	}
+	principal := models.Principal{Subject: claims.Subject, Method: models.AuthMethodJWT}
+	if claims.Scope != "" {
+		principal.Scopes = strings.Fields(claims.Scope)
+	}
+	return principal, nil
+}
+
+type jwtClaims struct {
+	jwt.RegisteredClaims
+	Scope string `json:"scope"`
+}
+
+// keyFunc выбирает ключи проверки по kid заголовка токена; токен без kid проверяется всеми ключами.
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (any, error) {
+	kid, _ := token.Header["kid"].(string)
+	set := jwt.VerificationKeySet{}
+	for _, key := range a.keys {
+		if kid == "" || key.ID == kid {
+			set.Keys = append(set.Keys, key.Key)
+		}
+	}
+	if len(set.Keys) == 0 {
+		return nil, errors.New("unknown kid " + kid)
+	}
+	return set, nil
+}
@@ -3,7 +3,11 @@ package config
 import (
 	"fmt"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"test_wallet/internal/models"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -13,6 +17,40 @@ type Config struct {
 	DBURL      string
 	LogLevel   string
 	DBMaxConns int
+	// GRPCPort — порт gRPC API, отдельный от HTTP-порта Port
+	GRPCPort string
+	// DefaultCurrency — валюта кошельков, создаваемых без явного указания валюты
+	DefaultCurrency string
+	// RatesURL — адрес HTTP-сервиса курсов; если пуст, курсы читаются из RatesFile
+	RatesURL  string
+	RatesFile string
+	QuoteTTL  time.Duration
+	// ReversalPolicy — fail или allow_negative, см. models.ReversalPolicyFail
+	ReversalPolicy string
+	// FrozenWalletDeposits разрешает пополнять замороженные кошельки
+	FrozenWalletDeposits bool
+	// ImplicitWalletCreation разрешает создавать кошелёк первым пополнением
+	ImplicitWalletCreation bool
+	// SchedulerInterval — период опроса запланированных операций
+	SchedulerInterval time.Duration
+	// ReconcileInterval — период сверки главной книги в сервере; 0 отключает сверку
+	ReconcileInterval time.Duration
+	// EventPublisher — куда relay публикует события outbox: none, stdout, file (EventFile)
+	// или http (EventURL). Вебхуки работают при любом значении.
+	EventPublisher string
+	EventFile      string
+	EventURL       string
+	// AuthModes — способы аутентификации API (AUTH_MODE через запятую): api_key, jwt. Пустой
+	// список (AUTH_MODE=none) отключает аутентификацию.
+	AuthModes []string
+	// JWTSecret, JWTPublicKeyFile и JWTJWKSFile — ключи проверки подписи JWT; можно задать
+	// несколько, токен принимается, если его подпись проверяется любым из них.
+	JWTSecret        string
+	JWTPublicKeyFile string
+	JWTJWKSFile      string
+	// JWTIssuer и JWTAudience, если заданы, должны совпадать с claims iss и aud токена.
+	JWTIssuer   string
+	JWTAudience string
 }
 
 func LoadConfig() (*Config, error) {
@@ -24,8 +62,60 @@ func LoadConfig() (*Config, error) {
 			maxConns = v
 		}
 	}
+	currency := os.Getenv("DEFAULT_CURRENCY")
+	if currency == "" {
+		currency = models.DefaultCurrency
+	}
+	if !models.IsSupportedCurrency(currency) {
+		return nil, fmt.Errorf("DEFAULT_CURRENCY: %w: %s", models.ErrUnsupportedCurrency, currency)
+	}
+	quoteTTL := time.Minute
+	if v, err := strconv.Atoi(os.Getenv("QUOTE_TTL_SECONDS")); err == nil && v > 0 {
+		quoteTTL = time.Duration(v) * time.Second
+	}
+	schedulerInterval := 10 * time.Second
+	if v, err := strconv.Atoi(os.Getenv("SCHEDULER_INTERVAL_SECONDS")); err == nil && v > 0 {
+		schedulerInterval = time.Duration(v) * time.Second
+	}
+	reconcileInterval := time.Hour
+	if v, err := strconv.Atoi(os.Getenv("RECONCILE_INTERVAL_MINUTES")); err == nil && v >= 0 {
+		reconcileInterval = time.Duration(v) * time.Minute
+	}
+	reversalPolicy := os.Getenv("REVERSAL_POLICY")
+	if reversalPolicy == "" {
+		reversalPolicy = models.ReversalPolicyFail
+	}
+	if reversalPolicy != models.ReversalPolicyFail && reversalPolicy != models.ReversalPolicyAllowNegative {
+		return nil, fmt.Errorf("REVERSAL_POLICY: must be %q or %q, got %q",
+			models.ReversalPolicyFail, models.ReversalPolicyAllowNegative, reversalPolicy)
+	}
+	grpcPort := os.Getenv("GRPC_PORT")
+	if grpcPort == "" {
+		grpcPort = "9090"
+	}
+	eventPublisher := os.Getenv("EVENT_PUBLISHER")
+	if eventPublisher == "" {
+		eventPublisher = "none"
+	}
+	switch {
+	case eventPublisher == "file" && os.Getenv("EVENT_FILE") == "":
+		return nil, fmt.Errorf("EVENT_FILE is required for EVENT_PUBLISHER=file")
+	case eventPublisher == "http" && os.Getenv("EVENT_URL") == "":
+		return nil, fmt.Errorf("EVENT_URL is required for EVENT_PUBLISHER=http")
+	case eventPublisher != "none" && eventPublisher != "stdout" && eventPublisher != "file" && eventPublisher != "http":
+		return nil, fmt.Errorf("EVENT_PUBLISHER: must be none, stdout, file or http, got %q", eventPublisher)
+	}
+	authModes, err := parseAuthModes(os.Getenv("AUTH_MODE"))
+	if err != nil {
+		return nil, err
+	}
+	if slices.Contains(authModes, models.AuthMethodJWT) &&
+		os.Getenv("JWT_SECRET") == "" && os.Getenv("JWT_PUBLIC_KEY_FILE") == "" && os.Getenv("JWT_JWKS_FILE") == "" {
+		return nil, fmt.Errorf("AUTH_MODE=jwt requires JWT_SECRET, JWT_PUBLIC_KEY_FILE or JWT_JWKS_FILE")
+	}
 	return &Config{
 		Port:     os.Getenv("APP_PORT"),
+		GRPCPort: grpcPort,
 		LogLevel: os.Getenv("LOG_LEVEL"),
 		DBURL: fmt.Sprintf(
 			"postgres://%s:%s@%s:%s/%s",
@@ -35,6 +125,42 @@ func LoadConfig() (*Config, error) {
 			os.Getenv("DB_PORT"),
 			os.Getenv("DB_NAME"),
 		),
-		DBMaxConns: maxConns,
+		DBMaxConns:             maxConns,
+		DefaultCurrency:        currency,
+		RatesURL:               os.Getenv("RATES_URL"),
+		RatesFile:              os.Getenv("RATES_FILE"),
+		QuoteTTL:               quoteTTL,
+		ReversalPolicy:         reversalPolicy,
+		FrozenWalletDeposits:   os.Getenv("FROZEN_WALLET_DEPOSITS") == "true",
+		ImplicitWalletCreation: os.Getenv("IMPLICIT_WALLET_CREATION") != "false",
+		SchedulerInterval:      schedulerInterval,
+		ReconcileInterval:      reconcileInterval,
+		EventPublisher:         eventPublisher,
+		EventFile:              os.Getenv("EVENT_FILE"),
+		EventURL:               os.Getenv("EVENT_URL"),
+		AuthModes:              authModes,
+		JWTSecret:              os.Getenv("JWT_SECRET"),
+		JWTPublicKeyFile:       os.Getenv("JWT_PUBLIC_KEY_FILE"),
+		JWTJWKSFile:            os.Getenv("JWT_JWKS_FILE"),
+		JWTIssuer:              os.Getenv("JWT_ISSUER"),
+		JWTAudience:            os.Getenv("JWT_AUDIENCE"),
 	}, nil
 }
+
+// parseAuthModes разбирает AUTH_MODE: none (по умолчанию) или api_key и jwt через запятую.
+func parseAuthModes(value string) ([]string, error) {
+	var modes []string
+	for _, mode := range strings.Split(value, ",") {
+		mode = strings.TrimSpace(mode)
+		switch mode {
+		case "", "none":
+		case models.AuthMethodAPIKey, models.AuthMethodJWT:
+			if !slices.Contains(modes, mode) {
+				modes = append(modes, mode)
+			}
+		default:
+			return nil, fmt.Errorf("AUTH_MODE: must be none or a comma-separated list of api_key and jwt, got %q", value)
+		}
+	}
+	return modes, nil
+}
@@ -0,0 +1,56 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"test_wallet/internal/models"
+	"time"
+)
+
+const (
+	defaultHTTPTimeout = 10 * time.Second
+	// EventIDHeader дублирует eventId из тела, чтобы получатель мог отбросить повтор до разбора JSON.
+	EventIDHeader = "X-Event-Id"
+)
+
+// HTTPPublisher отправляет каждое событие POST-запросом с JSON-телом на url.
+// Любой ответ 2xx считается доставкой, остальные — ошибкой с повтором.
+type HTTPPublisher struct {
+	url    string
+	client *http.Client
+}
+
+// NewHTTPPublisher создаёт publisher; при client == nil используется клиент с таймаутом 10 секунд.
+func NewHTTPPublisher(url string, client *http.Client) *HTTPPublisher {
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return &HTTPPublisher{url: url, client: client}
+}
+
+func (p *HTTPPublisher) Publish(ctx context.Context, event models.Event) error {
+	body, err := json.Marshal(event)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventIDHeader, event.EventID.String())
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("publish event %s: %w", event.EventID, err)
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return fmt.Errorf("publish event %s: unexpected status %d", event.EventID, resp.StatusCode)
+	}
+	return nil
+}
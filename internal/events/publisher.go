@@ -0,0 +1,31 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"test_wallet/internal/models"
+)
+
+// EventPublisher доставляет событие внешним потребителям. Publish вызывается повторно, пока
+// не вернёт nil, поэтому доставка «хотя бы один раз»: потребители отбрасывают повторы по EventID.
+type EventPublisher interface {
+	Publish(ctx context.Context, event models.Event) error
+}
+
+type multiPublisher []EventPublisher
+
+// Multi публикует событие во все publishers. Если хотя бы один вернул ошибку, событие
+// будет опубликовано повторно во все — повтор безопасен при доставке «хотя бы один раз».
+func Multi(publishers ...EventPublisher) EventPublisher {
+	return multiPublisher(publishers)
+}
+
+func (m multiPublisher) Publish(ctx context.Context, event models.Event) error {
+	var errs []error
+	for _, p := range m {
+		if err := p.Publish(ctx, event); err != nil {
+			errs = append(errs, err)
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,130 @@
+package events
+
+import (
+	"context"
+	"log/slog"
+	"test_wallet/internal/models"
+	"test_wallet/internal/poller"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:generate mockgen -source=relay.go -destination=../../test/mock_events.go -package=test -mock_names=Store=MockOutboxStore
+
+// Store — таблица outbox_events.
+type Store interface {
+	LockOutboxRelay(ctx context.Context) (unlock func(), ok bool, err error)
+	PendingEvents(ctx context.Context, now time.Time, limit int) ([]models.Event, error)
+	MarkEventPublished(ctx context.Context, id int64) error
+	MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, reason string) error
+}
+
+const (
+	DefaultInterval   = time.Second
+	DefaultRetryDelay = time.Second
+	DefaultBatchSize  = 100
+	// maxRetryDelay невелик: пока событие ждёт повтора, события его кошелька стоят в очереди.
+	maxRetryDelay = 5 * time.Minute
+)
+
+// Relay публикует события из outbox после коммита породивших их транзакций.
+type Relay struct {
+	store      Store
+	publisher  EventPublisher
+	logger     *slog.Logger
+	interval   time.Duration
+	retryDelay time.Duration
+	batchSize  int
+	now        func() time.Time
+}
+
+type Option func(*Relay)
+
+// WithInterval задаёт период опроса outbox.
+func WithInterval(interval time.Duration) Option {
+	return func(r *Relay) {
+		r.interval = interval
+	}
+}
+
+// WithRetryDelay задаёт задержку повторной публикации после первой неудачи (см. poller.Backoff).
+func WithRetryDelay(delay time.Duration) Option {
+	return func(r *Relay) {
+		r.retryDelay = delay
+	}
+}
+
+// WithClock задаёт часы, от которых отсчитывается время следующей публикации; нужен тестам.
+func WithClock(now func() time.Time) Option {
+	return func(r *Relay) {
+		r.now = now
+	}
+}
+
+func NewRelay(store Store, publisher EventPublisher, logger *slog.Logger, opts ...Option) *Relay {
+	r := &Relay{
+		store:      store,
+		publisher:  publisher,
+		logger:     logger,
+		interval:   DefaultInterval,
+		retryDelay: DefaultRetryDelay,
+		batchSize:  DefaultBatchSize,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(r)
+	}
+	return r
+}
+
+// Run публикует события до отмены ctx.
+func (r *Relay) Run(ctx context.Context) {
+	poller.Run(ctx, r.interval, r.logger, "Outbox relay failed", r.RelayPending)
+}
+
+// RelayPending публикует накопившиеся события и возвращает число опубликованных.
+// Если блокировку relay держит другая реплика, ничего не делает.
+func (r *Relay) RelayPending(ctx context.Context) (int, error) {
+	unlock, ok, err := r.store.LockOutboxRelay(ctx)
+	if err != nil || !ok {
+		return 0, err
+	}
+	defer unlock()
+
+	pending, err := r.store.PendingEvents(ctx, r.now(), r.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	// Кошельки, событие которых в этом проходе не опубликовалось: их следующие события ждут
+	failed := make(map[uuid.UUID]bool)
+	n := 0
+	for _, event := range pending {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		if failed[event.WalletID] {
+			continue
+		}
+		if pubErr := r.publisher.Publish(ctx, event); pubErr != nil {
+			failed[event.WalletID] = true
+			next := r.now().Add(poller.Backoff(r.retryDelay, maxRetryDelay, event.Attempts+1))
+			r.logger.Warn("Failed to publish event, will retry",
+				slog.String("event_id", event.EventID.String()),
+				slog.String("wallet_id", event.WalletID.String()),
+				slog.Int("attempt", event.Attempts+1),
+				slog.Time("next_attempt_at", next),
+				slog.Any("err", pubErr),
+			)
+			if err := r.store.MarkEventFailed(ctx, event.ID, next, pubErr.Error()); err != nil {
+				return n, err
+			}
+			continue
+		}
+		if err := r.store.MarkEventPublished(ctx, event.ID); err != nil {
+			return n, err
+		}
+		n++
+	}
+	return n, nil
+}
@@ -0,0 +1,86 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"strings"
+
+	"test_wallet/internal/auth"
+
+	"github.com/gin-gonic/gin"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// WithAuthenticator требует аутентификации для всех маршрутов /api/v1, кроме спецификации
+// OpenAPI. Вызывающий доступен обработчикам через auth.FromContext(c.Request.Context()).
+func WithAuthenticator(authenticator auth.Authenticator) Option {
+	return func(h *WalletHTTPHandler) {
+		h.auth = authenticator
+	}
+}
+
+// authenticate отвечает 401, если учётных данных нет или они неверны, и 503, если их не
+// удалось проверить.
+func (h *WalletHTTPHandler) authenticate(c *gin.Context) {
+	if h.auth == nil {
+		c.Next()
+		return
+	}
+	principal, err := h.auth.Authenticate(c.Request.Context(), c.GetHeader)
+	switch {
+	case err == nil:
+		c.Request = c.Request.WithContext(auth.NewContext(c.Request.Context(), principal))
+		c.Next()
+	case errors.Is(err, auth.ErrNoCredentials), errors.Is(err, auth.ErrInvalidCredentials):
+		c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": unauthorizedMessage(err)})
+	default:
+		c.AbortWithStatusJSON(http.StatusServiceUnavailable, gin.H{"error": "authentication unavailable"})
+	}
+}
+
+// requireScope пропускает только вызывающих с правом scope, остальным отвечает 403. Без
+// аутентификации (WithAuthenticator не задан) права не проверяются.
+func (h *WalletHTTPHandler) requireScope(scope string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		if h.auth == nil {
+			c.Next()
+			return
+		}
+		principal, _ := auth.FromContext(c.Request.Context())
+		if !principal.HasScope(scope) {
+			c.AbortWithStatusJSON(http.StatusForbidden, gin.H{"error": "insufficient scope", "required": scope})
+			return
+		}
+		c.Next()
+	}
+}
+
+// unauthorizedMessage не раскрывает причину отказа (просрочен, отозван, неверная подпись):
+// по ней можно подбирать учётные данные.
+func unauthorizedMessage(err error) string {
+	if errors.Is(err, auth.ErrNoCredentials) {
+		return auth.ErrNoCredentials.Error()
+	}
+	return auth.ErrInvalidCredentials.Error()
+}
+
+// UnaryAuthInterceptor требует аутентификации для gRPC-вызовов.
+func UnaryAuthInterceptor(authenticator auth.Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		header := func(name string) string {
+			return metadataValue(ctx, strings.ToLower(name))
+		}
+		principal, err := authenticator.Authenticate(ctx, header)
+		switch {
+		case err == nil:
+			return handler(auth.NewContext(ctx, principal), req)
+		case errors.Is(err, auth.ErrNoCredentials), errors.Is(err, auth.ErrInvalidCredentials):
+			return nil, status.Error(codes.Unauthenticated, unauthorizedMessage(err))
+		default:
+			return nil, status.Error(codes.Unavailable, "authentication unavailable")
+		}
+	}
+}
@@ -0,0 +1,28 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// HandleVerifyJournalChain проверяет хэш-цепочку журнала кошелька из ?walletId= или всех
+// кошельков. Разрыв цепочки — не ошибка запроса: ответ 200 с ok=false и firstBreak.
+func (h *WalletHTTPHandler) HandleVerifyJournalChain(c *gin.Context) {
+	var walletID *uuid.UUID
+	if raw := c.Query("walletId"); raw != "" {
+		id, err := uuid.Parse(raw)
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid walletId"})
+			return
+		}
+		walletID = &id
+	}
+	result, err := h.admin.VerifyJournalChain(c.Request.Context(), walletID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
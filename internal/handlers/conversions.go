@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"net/http"
+	"test_wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+func (h *WalletHTTPHandler) HandleQuoteConversion(c *gin.Context) {
+	var req models.ConversionQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	if req.Amount.Cmp(decimal.Zero) <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be > 0"})
+		return
+	}
+	quote, err := h.conversions.QuoteConversion(c.Request.Context(), req.FromWalletID, req.ToWalletID, req.Amount)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, quote)
+}
+
+func (h *WalletHTTPHandler) HandleGetQuote(c *gin.Context) {
+	quoteID, err := uuid.Parse(c.Param("quote_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid quote_id"})
+		return
+	}
+	quote, err := h.conversions.GetQuote(c.Request.Context(), quoteID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+// HandleConversion исполняет котировку quoteId либо, если она не указана, конвертирует
+// amount между кошельками по текущему курсу.
+func (h *WalletHTTPHandler) HandleConversion(c *gin.Context) {
+	var req models.ConversionRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	if req.QuoteID == uuid.Nil {
+		if req.FromWalletID == uuid.Nil || req.ToWalletID == uuid.Nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "quoteId or fromWalletId and toWalletId are required"})
+			return
+		}
+		if req.Amount.Cmp(decimal.Zero) <= 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be > 0"})
+			return
+		}
+	}
+
+	idem, err := idempotencyFromRequest(c, req.RequestID, req.Hash(), func(result models.OperationResult) (int, any) {
+		return http.StatusOK, result.Conversion
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := operationOptions(idem, "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	var result models.ConversionResult
+	if req.QuoteID != uuid.Nil {
+		result, err = h.conversions.ExecuteConversion(c.Request.Context(), req.QuoteID, opts...)
+	} else {
+		result, err = h.conversions.Convert(c.Request.Context(), req.FromWalletID, req.ToWalletID, req.Amount, opts...)
+	}
+	if replayIdempotentResponse(c, idem) {
+		return
+	}
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
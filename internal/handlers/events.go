@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+// EventStream сообщает о появлении новых событий кошелька; см. stream.Hub. Канал подписки
+// закрывается при остановке источника.
+type EventStream interface {
+	Subscribe(walletID uuid.UUID) (<-chan struct{}, func())
+}
+
+const (
+	LastEventIDHeader = "Last-Event-ID"
+	// sseHeartbeat — период комментария-пульса, который не даёт прокси закрыть простаивающее
+	// соединение; заодно поток дочитывает события, сигнал о которых потерялся.
+	sseHeartbeat = 15 * time.Second
+	sseBatchSize = 100
+	// sseRetry — через сколько миллисекунд браузер переподключается после обрыва.
+	sseRetry = 3000
+)
+
+// HandleWalletEvents отдаёт поток событий кошелька в формате Server-Sent Events.
+func (h *WalletHTTPHandler) HandleWalletEvents(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	resume := c.GetHeader(LastEventIDHeader)
+	if resume == "" {
+		resume = c.Query("lastEventId")
+	}
+	var lastID int64
+	if resume != "" {
+		lastID, err = strconv.ParseInt(resume, 10, 64)
+		if err != nil || lastID < 0 {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid Last-Event-ID"})
+			return
+		}
+	}
+
+	ctx := c.Request.Context()
+	// Подписываемся до чтения событий, чтобы не пропустить сигнал между чтением и подпиской
+	var wake <-chan struct{}
+	if h.events != nil {
+		ch, unsubscribe := h.events.Subscribe(walletID)
+		defer unsubscribe()
+		wake = ch
+	}
+	snapshot, err := h.walletEvents.GetEventStreamSnapshot(ctx, walletID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+
+	c.Header("Content-Type", "text/event-stream")
+	c.Header("Cache-Control", "no-cache")
+	c.Header("Connection", "keep-alive")
+	c.Header("X-Accel-Buffering", "no")
+	c.Status(http.StatusOK)
+	fmt.Fprintf(c.Writer, "retry: %d\n\n", sseRetry)
+	if resume == "" {
+		lastID = snapshot.LastEventID
+		if err := writeSSE(c.Writer, lastID, "snapshot", snapshot); err != nil {
+			return
+		}
+	}
+
+	heartbeat := time.NewTicker(sseHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		for {
+			events, err := h.walletEvents.GetWalletEvents(ctx, walletID, lastID, sseBatchSize)
+			if err != nil {
+				// Заголовки уже отправлены: обрываем поток, клиент переподключится с Last-Event-ID
+				_ = c.Error(err)
+				return
+			}
+			for _, event := range events {
+				if err := writeSSE(c.Writer, event.ID, event.Type, event); err != nil {
+					return
+				}
+				lastID = event.ID
+			}
+			if len(events) < sseBatchSize {
+				break
+			}
+		}
+		c.Writer.Flush()
+
+		select {
+		case <-ctx.Done():
+			return
+		case _, ok := <-wake:
+			if !ok {
+				// Сервер останавливается: клиент переподключится к другой реплике
+				return
+			}
+		case <-heartbeat.C:
+			if _, err := io.WriteString(c.Writer, ": heartbeat\n\n"); err != nil {
+				return
+			}
+		}
+	}
+}
+
+func writeSSE(w io.Writer, id int64, event string, data any) error {
+	body, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", id, event, body)
+	return err
+}
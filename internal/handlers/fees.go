@@ -0,0 +1,46 @@
+package handlers
+
+import (
+	"net/http"
+	"test_wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+)
+
+// HandleQuoteFee считает комиссию за операцию без движения денег — для показа в интерфейсе.
+func (h *WalletHTTPHandler) HandleQuoteFee(c *gin.Context) {
+	var req models.FeeQuoteRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	quote, err := h.wallets.QuoteFee(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, quote)
+}
+
+func (h *WalletHTTPHandler) HandleSetFeeSchedule(c *gin.Context) {
+	var req models.FeeSchedule
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	schedule, err := h.admin.SetFeeSchedule(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, schedule)
+}
+
+func (h *WalletHTTPHandler) HandleListFeeSchedules(c *gin.Context) {
+	schedules, err := h.admin.ListFeeSchedules(c.Request.Context())
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"schedules": schedules})
+}
@@ -0,0 +1,291 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"strconv"
+	"time"
+
+	walletv1 "test_wallet/api/wallet/v1"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// Ключи метаданных gRPC — аналоги заголовков IdempotencyKeyHeader и IdempotentReplayedHeader.
+const (
+	IdempotencyKeyMetadata     = "idempotency-key"
+	IdempotentReplayedMetadata = "idempotent-replayed"
+)
+
+// errorDomain — домен google.rpc.ErrorInfo в ошибках gRPC.
+const errorDomain = "wallet"
+
+// WalletGRPCServer — gRPC-транспорт для операций WalletService (api/wallet/v1/wallet.proto).
+// Проверки запросов, идемпотентность и коды ошибок те же, что у HTTP-обработчиков.
+type WalletGRPCServer struct {
+	walletv1.UnimplementedWalletServiceServer
+	service WalletOperations
+}
+
+func NewWalletGRPCServer(service WalletOperations) *WalletGRPCServer {
+	return &WalletGRPCServer{service: service}
+}
+
+func (s *WalletGRPCServer) Register(registrar grpc.ServiceRegistrar) {
+	walletv1.RegisterWalletServiceServer(registrar, s)
+}
+
+func (s *WalletGRPCServer) Deposit(ctx context.Context, req *walletv1.DepositRequest) (*walletv1.DepositResponse, error) {
+	op, err := parseOperation(ctx, "DEPOSIT", req.GetWalletId(), req.GetAmount(), req.GetCurrency(), req.GetRequestId())
+	if err != nil {
+		return nil, err
+	}
+	balance, created, err := s.service.Deposit(ctx, op.walletID, op.amount, op.opts...)
+	if replayed, ok := replayedOperation(ctx, op.idem); ok {
+		return &walletv1.DepositResponse{Balance: replayed.Balance, Fee: replayed.Fee, Created: replayed.Created}, nil
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &walletv1.DepositResponse{Balance: balance.String(), Fee: feeString(op.fee), Created: created}, nil
+}
+
+func (s *WalletGRPCServer) Withdraw(ctx context.Context, req *walletv1.WithdrawRequest) (*walletv1.WithdrawResponse, error) {
+	op, err := parseOperation(ctx, "WITHDRAW", req.GetWalletId(), req.GetAmount(), req.GetCurrency(), req.GetRequestId())
+	if err != nil {
+		return nil, err
+	}
+	balance, err := s.service.Withdraw(ctx, op.walletID, op.amount, op.opts...)
+	if replayed, ok := replayedOperation(ctx, op.idem); ok {
+		return &walletv1.WithdrawResponse{Balance: replayed.Balance, Fee: replayed.Fee}, nil
+	}
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &walletv1.WithdrawResponse{Balance: balance.String(), Fee: feeString(op.fee)}, nil
+}
+
+// grpcOperation — разобранный запрос на пополнение или списание. fee получает удержанную комиссию.
+type grpcOperation struct {
+	walletID uuid.UUID
+	amount   decimal.Decimal
+	opts     []models.OperationOption
+	idem     *models.Idempotency
+	fee      decimal.Decimal
+}
+
+// parseOperation проверяет запрос на пополнение или списание так же, как HandleWalletOperation.
+func parseOperation(
+	ctx context.Context,
+	operationType, walletID, amount, currency, requestID string,
+) (*grpcOperation, error) {
+	op := &grpcOperation{}
+	var err error
+	if op.walletID, err = uuid.Parse(walletID); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid wallet_id")
+	}
+	if op.amount, err = decimal.NewFromString(amount); err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid amount")
+	}
+	if op.amount.Cmp(decimal.Zero) <= 0 {
+		return nil, status.Error(codes.InvalidArgument, "amount must be > 0")
+	}
+	req := models.WalletRequest{
+		WalletID:      op.walletID,
+		OperationType: operationType,
+		Amount:        op.amount,
+		Currency:      currency,
+		RequestID:     requestID,
+	}
+	op.idem, err = newIdempotency(metadataValue(ctx, IdempotencyKeyMetadata), requestID, req.Hash(),
+		func(result models.OperationResult) (int, any) {
+			return balanceResponse(result.Balance, result.Fee, result.Created)
+		})
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if op.opts, err = operationOptions(op.idem, currency); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	op.opts = append(op.opts, models.WithFeeReport(&op.fee))
+	return op, nil
+}
+
+type replayedBalance struct {
+	Balance string `json:"balance"`
+	Fee     string `json:"fee"`
+	Created bool   `json:"-"`
+}
+
+// replayedOperation разбирает сохранённый ответ повторённой операции (в формате
+// balanceResponse) и помечает ответ метаданными idempotent-replayed.
+func replayedOperation(ctx context.Context, idem *models.Idempotency) (replayedBalance, bool) {
+	if idem == nil || idem.Replayed == nil {
+		return replayedBalance{}, false
+	}
+	var replayed replayedBalance
+	_ = json.Unmarshal(idem.Replayed.Body, &replayed)
+	replayed.Created = idem.Replayed.StatusCode == http.StatusCreated
+	_ = grpc.SetHeader(ctx, metadata.Pairs(IdempotentReplayedMetadata, "true"))
+	return replayed, true
+}
+
+func (s *WalletGRPCServer) GetBalance(ctx context.Context, req *walletv1.GetBalanceRequest) (*walletv1.GetBalanceResponse, error) {
+	walletID, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid wallet_id")
+	}
+	balance, err := s.service.GetBalance(ctx, walletID)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	return &walletv1.GetBalanceResponse{Balance: balance.String()}, nil
+}
+
+func (s *WalletGRPCServer) ListTransactions(
+	ctx context.Context,
+	req *walletv1.ListTransactionsRequest,
+) (*walletv1.ListTransactionsResponse, error) {
+	walletID, err := uuid.Parse(req.GetWalletId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid wallet_id")
+	}
+	// Фильтр разбирается тем же кодом, что параметры GET .../transactions
+	params := map[string]string{
+		"type":      req.GetOperationType(),
+		"minAmount": req.GetMinAmount(),
+		"maxAmount": req.GetMaxAmount(),
+		"cursor":    req.GetPageToken(),
+	}
+	if req.GetFrom() != nil {
+		params["from"] = req.GetFrom().AsTime().Format(time.RFC3339Nano)
+	}
+	if req.GetTo() != nil {
+		params["to"] = req.GetTo().AsTime().Format(time.RFC3339Nano)
+	}
+	if req.GetPageSize() != 0 {
+		params["limit"] = strconv.Itoa(int(req.GetPageSize()))
+	}
+	filter, err := parseTransactionFilter(func(key string) string { return params[key] })
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, "invalid query: "+err.Error())
+	}
+	page, err := s.service.GetTransactions(ctx, walletID, filter)
+	if err != nil {
+		return nil, grpcError(err)
+	}
+	resp := &walletv1.ListTransactionsResponse{
+		Transactions:  make([]*walletv1.Transaction, 0, len(page.Transactions)),
+		NextPageToken: page.NextCursor,
+	}
+	for _, tx := range page.Transactions {
+		resp.Transactions = append(resp.Transactions, transactionMessage(tx))
+	}
+	return resp, nil
+}
+
+func transactionMessage(tx models.Transaction) *walletv1.Transaction {
+	msg := &walletv1.Transaction{
+		Id:             tx.ID,
+		WalletId:       tx.WalletID.String(),
+		OperationType:  tx.Type,
+		Amount:         tx.Amount.String(),
+		BalanceAfter:   tx.BalanceAfter.String(),
+		ReversedAmount: tx.ReversedAmount.String(),
+		CreatedAt:      timestamppb.New(tx.CreatedAt),
+		Hash:           tx.Hash,
+	}
+	if tx.EntryID != nil {
+		msg.EntryId = *tx.EntryID
+	}
+	if tx.TransferID != nil {
+		msg.TransferId = tx.TransferID.String()
+	}
+	if tx.HoldID != nil {
+		msg.HoldId = tx.HoldID.String()
+	}
+	if tx.ConversionID != nil {
+		msg.ConversionId = tx.ConversionID.String()
+	}
+	if tx.Rate != nil {
+		msg.Rate = tx.Rate.String()
+	}
+	if tx.ReversalOf != nil {
+		msg.ReversalOf = *tx.ReversalOf
+	}
+	return msg
+}
+
+// grpcError переводит ошибку сервиса в статус gRPC по HTTP-статусу из operationErrorStatus.
+func grpcError(err error) error {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return status.FromContextError(err).Err()
+	}
+	var code codes.Code
+	switch operationErrorStatus(err) {
+	case http.StatusBadRequest:
+		code = codes.InvalidArgument
+	case http.StatusForbidden:
+		code = codes.PermissionDenied
+	case http.StatusNotFound:
+		code = codes.NotFound
+	case http.StatusConflict, http.StatusGone, http.StatusLocked, http.StatusUnprocessableEntity:
+		code = codes.FailedPrecondition
+	case http.StatusServiceUnavailable:
+		code = codes.Unavailable
+	default:
+		code = codes.Internal
+	}
+	st := status.New(code, err.Error())
+
+	body := errorResponse(err)
+	delete(body, "error")
+	if len(body) == 0 {
+		return st.Err()
+	}
+	info := &errdetails.ErrorInfo{Reason: errorReason(err), Domain: errorDomain, Metadata: make(map[string]string, len(body))}
+	for key, value := range body {
+		info.Metadata[key] = fmt.Sprint(value)
+	}
+	if detailed, err := st.WithDetails(info); err == nil {
+		st = detailed
+	}
+	return st.Err()
+}
+
+func errorReason(err error) string {
+	switch {
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return "INSUFFICIENT_FUNDS"
+	case errors.Is(err, repository.ErrLimitExceeded):
+		return "LIMIT_EXCEEDED"
+	default:
+		return "UNKNOWN"
+	}
+}
+
+func metadataValue(ctx context.Context, key string) string {
+	if values := metadata.ValueFromIncomingContext(ctx, key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+func feeString(fee decimal.Decimal) string {
+	if fee.IsPositive() {
+		return fee.String()
+	}
+	return ""
+}
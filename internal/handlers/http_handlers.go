@@ -2,36 +2,205 @@ package handlers
 
 import (
 	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
 	"net/http"
+	"slices"
+	"strconv"
+	"strings"
+	"test_wallet/internal/auth"
 	"test_wallet/internal/models"
 	"test_wallet/internal/repository"
+	"time"
 
 	"github.com/gin-gonic/gin"
 	"github.com/google/uuid"
 	"github.com/shopspring/decimal"
 )
 
-//go:generate mockgen -source=http_handlers.go -destination=../../test/mock_wallet_service.go -package=test WalletService
+//go:generate mockgen -source=http_handlers.go -destination=../../test/mock_wallet_service.go -package=test
 
-type WalletService interface {
-	Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal) (decimal.Decimal, bool, error)
-	Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal) (decimal.Decimal, error)
+// WalletOperations — операции с балансом и кошельками; gRPC-сервер использует только их.
+type WalletOperations interface {
+	Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error)
+	Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, error)
+	Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.TransferResult, error)
 	GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error)
+	GetWallet(ctx context.Context, walletID uuid.UUID) (models.Wallet, error)
+	GetTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) (models.TransactionPage, error)
+	GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (models.BalanceAt, error)
+	ExportStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error
+	CreateWallet(ctx context.Context, req models.CreateWalletRequest) (models.Wallet, error)
+	ListWallets(ctx context.Context, filter models.WalletFilter) (models.WalletPage, error)
+	UpdateWallet(ctx context.Context, walletID uuid.UUID, update models.UpdateWalletRequest) (models.Wallet, error)
+	GetWalletLimits(ctx context.Context, walletID uuid.UUID) ([]models.SpendingLimit, error)
+	QuoteFee(ctx context.Context, req models.FeeQuoteRequest) (models.FeeQuote, error)
+}
+
+// HoldService — холды.
+type HoldService interface {
+	CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, ttl time.Duration, opts ...models.OperationOption) (models.HoldResult, error)
+	CaptureHold(ctx context.Context, holdID uuid.UUID, amount *decimal.Decimal) (models.HoldResult, error)
+	VoidHold(ctx context.Context, holdID uuid.UUID) (models.HoldResult, error)
+	GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error)
+}
+
+// ConversionService — котировки и конвертации.
+type ConversionService interface {
+	QuoteConversion(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal) (models.ConversionQuote, error)
+	GetQuote(ctx context.Context, quoteID uuid.UUID) (models.ConversionQuote, error)
+	ExecuteConversion(ctx context.Context, quoteID uuid.UUID, opts ...models.OperationOption) (models.ConversionResult, error)
+	Convert(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.ConversionResult, error)
+}
+
+// ScheduleService — запланированные операции.
+type ScheduleService interface {
+	CreateScheduledOperation(ctx context.Context, req models.ScheduleRequest) (models.ScheduledOperation, error)
+	GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error)
+	ListScheduledOperations(ctx context.Context, walletID uuid.UUID) ([]models.ScheduledOperation, error)
+	CancelScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error)
+	GetScheduledRuns(ctx context.Context, id uuid.UUID) ([]models.ScheduledRun, error)
 }
 
+// WebhookService — подписки на вебхуки и журнал доставок.
+type WebhookService interface {
+	CreateWebhook(ctx context.Context, req models.WebhookRequest) (models.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error)
+	RetryWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (models.WebhookDelivery, error)
+}
+
+// EventService — события кошелька для SSE.
+type EventService interface {
+	GetEventStreamSnapshot(ctx context.Context, walletID uuid.UUID) (models.StreamSnapshot, error)
+	GetWalletEvents(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.Event, error)
+}
+
+// AdminService — операции, требующие права admin.
+type AdminService interface {
+	ReverseTransaction(ctx context.Context, transactionID int64, amount *decimal.Decimal, opts ...models.OperationOption) (models.ReversalResult, error)
+	SetWalletStatus(ctx context.Context, walletID uuid.UUID, status, reason string) (models.Wallet, error)
+	SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit decimal.Decimal, reason string) (models.Wallet, error)
+	GetOverdraftLimitChanges(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftLimitChange, error)
+	SetLimitTier(ctx context.Context, tier, currency string, limit models.SpendingLimit) error
+	SetWalletLimit(ctx context.Context, walletID uuid.UUID, limit models.SpendingLimit) error
+	SetWalletTier(ctx context.Context, walletID uuid.UUID, tier string) (models.Wallet, error)
+	SetFeeSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error)
+	ListFeeSchedules(ctx context.Context) ([]models.FeeSchedule, error)
+	GetTrialBalance(ctx context.Context) (models.TrialBalance, error)
+	VerifyJournalChain(ctx context.Context, walletID *uuid.UUID) (models.ChainVerification, error)
+}
+
+// WalletService — все операции, которые обслуживают обработчики; реализуется service.WalletService.
+type WalletService interface {
+	WalletOperations
+	HoldService
+	ConversionService
+	ScheduleService
+	WebhookService
+	EventService
+	AdminService
+}
+
+const (
+	IdempotencyKeyHeader     = "Idempotency-Key"
+	IdempotentReplayedHeader = "Idempotent-Replayed"
+)
+
 type WalletHTTPHandler struct {
-	service WalletService
+	wallets      WalletOperations
+	holds        HoldService
+	conversions  ConversionService
+	schedules    ScheduleService
+	webhooks     WebhookService
+	walletEvents EventService
+	admin        AdminService
+	// events будит потоки GET /wallets/:wallet_id/events; без него потоки опрашивают БД
+	// с периодом heartbeat
+	events EventStream
+	// auth проверяет учётные данные запросов; nil — аутентификация отключена
+	auth auth.Authenticator
+}
+
+type Option func(*WalletHTTPHandler)
+
+// WithEventStream подключает источник сигналов о новых событиях кошельков для SSE.
+func WithEventStream(events EventStream) Option {
+	return func(h *WalletHTTPHandler) {
+		h.events = events
+	}
 }
 
-func NewWalletHTTPHandler(service WalletService) *WalletHTTPHandler {
-	return &WalletHTTPHandler{service: service}
+func NewWalletHTTPHandler(service WalletService, opts ...Option) *WalletHTTPHandler {
+	h := &WalletHTTPHandler{
+		wallets:      service,
+		holds:        service,
+		conversions:  service,
+		schedules:    service,
+		webhooks:     service,
+		walletEvents: service,
+		admin:        service,
+	}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
 }
 
 func (h *WalletHTTPHandler) RegisterRoutes(r *gin.Engine) {
-	v1 := r.Group("/api/v1")
+	// Спецификация доступна без аутентификации
+	r.GET(apiPrefix+"/openapi.json", h.HandleOpenAPI)
+	v1 := r.Group(apiPrefix, h.authenticate, h.validateRequest)
 	{
 		v1.POST("/wallet", h.HandleWalletOperation)
+		v1.POST("/transfers", h.HandleTransfer)
+		v1.POST("/wallets", h.HandleCreateWallet)
+		v1.GET("/wallets", h.HandleListWallets)
 		v1.GET("/wallets/:wallet_id", h.HandleGetBalance)
+		v1.PATCH("/wallets/:wallet_id", h.HandleUpdateWallet)
+		v1.GET("/wallets/:wallet_id/balance", h.HandleGetBalanceAt)
+		v1.GET("/wallets/:wallet_id/statement", h.HandleGetStatement)
+		v1.GET("/wallets/:wallet_id/events", h.HandleWalletEvents)
+		v1.GET("/wallets/:wallet_id/transactions", h.HandleGetTransactions)
+		v1.GET("/wallets/:wallet_id/limits", h.HandleGetWalletLimits)
+		v1.POST("/wallets/:wallet_id/holds", h.HandleCreateHold)
+		v1.GET("/holds/:hold_id", h.HandleGetHold)
+		v1.POST("/holds/:hold_id/capture", h.HandleCaptureHold)
+		v1.POST("/holds/:hold_id/void", h.HandleVoidHold)
+		v1.POST("/conversions/quotes", h.HandleQuoteConversion)
+		v1.GET("/conversions/quotes/:quote_id", h.HandleGetQuote)
+		v1.POST("/conversions", h.HandleConversion)
+		v1.POST("/transactions/:id/reverse", h.requireScope(models.ScopeAdmin), h.HandleReverseTransaction)
+		v1.POST("/fees/quote", h.HandleQuoteFee)
+		v1.POST("/scheduled-operations", h.HandleCreateScheduledOperation)
+		v1.GET("/scheduled-operations/:schedule_id", h.HandleGetScheduledOperation)
+		v1.GET("/scheduled-operations/:schedule_id/runs", h.HandleGetScheduledRuns)
+		v1.POST("/scheduled-operations/:schedule_id/cancel", h.HandleCancelScheduledOperation)
+		v1.GET("/wallets/:wallet_id/scheduled-operations", h.HandleListScheduledOperations)
+		v1.POST("/webhooks", h.HandleCreateWebhook)
+		v1.GET("/webhooks", h.HandleListWebhooks)
+		v1.GET("/webhooks/:webhook_id", h.HandleGetWebhook)
+		v1.DELETE("/webhooks/:webhook_id", h.HandleDeleteWebhook)
+		v1.GET("/webhooks/:webhook_id/deliveries", h.HandleListWebhookDeliveries)
+		v1.POST("/webhooks/:webhook_id/deliveries/:delivery_id/retry", h.HandleRetryWebhookDelivery)
+	}
+	admin := v1.Group("/admin", h.requireScope(models.ScopeAdmin))
+	{
+		admin.POST("/wallets/:wallet_id/freeze", h.handleSetWalletStatus(models.WalletFrozen))
+		admin.POST("/wallets/:wallet_id/unfreeze", h.handleSetWalletStatus(models.WalletActive))
+		admin.POST("/wallets/:wallet_id/close", h.handleSetWalletStatus(models.WalletClosed))
+		admin.PUT("/wallets/:wallet_id/overdraft", h.HandleSetOverdraftLimit)
+		admin.GET("/wallets/:wallet_id/overdraft/changes", h.HandleGetOverdraftLimitChanges)
+		admin.PUT("/wallets/:wallet_id/limits", h.HandleSetWalletLimit)
+		admin.PUT("/wallets/:wallet_id/tier", h.HandleSetWalletTier)
+		admin.PUT("/limit-tiers/:tier", h.HandleSetLimitTier)
+		admin.PUT("/fee-schedules", h.HandleSetFeeSchedule)
+		admin.GET("/fee-schedules", h.HandleListFeeSchedules)
+		admin.GET("/ledger/trial-balance", h.HandleGetTrialBalance)
+		admin.GET("/ledger/chain/verify", h.HandleVerifyJournalChain)
 	}
 }
 
@@ -44,42 +213,239 @@ func (h *WalletHTTPHandler) HandleWalletOperation(c *gin.Context) {
 
 	if req.Amount.Cmp(decimal.Zero) <= 0 {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be > 0"})
+		return
+	}
+
+	idem, err := idempotencyFromRequest(c, req.RequestID, req.Hash(), func(result models.OperationResult) (int, any) {
+		return balanceResponse(result.Balance, result.Fee, result.Created)
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := operationOptions(idem, req.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
 	}
+	var fee decimal.Decimal
+	opts = append(opts, models.WithFeeReport(&fee))
 
 	switch req.OperationType {
 	case "DEPOSIT":
-		balance, created, err := h.service.Deposit(c.Request.Context(), req.WalletID, req.Amount)
-		if err != nil {
-			status := http.StatusServiceUnavailable
-			if err == repository.ErrWalletNotFound {
-				status = http.StatusNotFound
-			} else if err == repository.ErrInsufficientFunds {
-				status = http.StatusConflict
-			}
-			c.JSON(status, gin.H{"error": err.Error(), "balance": balance.String()})
+		balance, created, err := h.wallets.Deposit(c.Request.Context(), req.WalletID, req.Amount, opts...)
+		if replayIdempotentResponse(c, idem) {
 			return
 		}
-		status := http.StatusOK
-		if created {
-			status = http.StatusCreated
+		if err != nil {
+			body := errorResponse(err)
+			body["balance"] = balance.String()
+			c.JSON(operationErrorStatus(err), body)
+			return
 		}
-		c.JSON(status, gin.H{"balance": balance.String()})
+		c.JSON(balanceResponse(balance, fee, created))
 	case "WITHDRAW":
-		balance, err := h.service.Withdraw(c.Request.Context(), req.WalletID, req.Amount)
+		balance, err := h.wallets.Withdraw(c.Request.Context(), req.WalletID, req.Amount, opts...)
+		if replayIdempotentResponse(c, idem) {
+			return
+		}
 		if err != nil {
-			status := http.StatusServiceUnavailable
-			if err == repository.ErrWalletNotFound {
-				status = http.StatusNotFound
-			} else if err == repository.ErrInsufficientFunds {
-				status = http.StatusConflict
-			}
-			c.JSON(status, gin.H{"error": err.Error(), "balance": balance.String()})
+			body := errorResponse(err)
+			body["balance"] = balance.String()
+			c.JSON(operationErrorStatus(err), body)
 			return
 		}
-		c.JSON(http.StatusOK, gin.H{"balance": balance.String()})
+		c.JSON(balanceResponse(balance, fee, false))
 	}
 }
 
+// balanceResponse формирует ответ на пополнение или списание; удержанная комиссия
+// попадает в ответ, только если она была.
+func balanceResponse(balance, fee decimal.Decimal, created bool) (int, gin.H) {
+	status := http.StatusOK
+	if created {
+		status = http.StatusCreated
+	}
+	body := gin.H{"balance": balance.String()}
+	if fee.IsPositive() {
+		body["fee"] = fee.String()
+	}
+	return status, body
+}
+
+// errorResponse формирует тело ответа с ошибкой.
+func errorResponse(err error) gin.H {
+	body := gin.H{"error": err.Error()}
+	var funds *repository.InsufficientFundsError
+	if errors.As(err, &funds) {
+		body["availableCredit"] = funds.AvailableCredit.String()
+	}
+	var limit *repository.LimitExceededError
+	if errors.As(err, &limit) {
+		body["limit"] = limit.Limit
+		body["max"] = limit.Max.String()
+		body["used"] = limit.Used.String()
+	}
+	return body
+}
+
+func operationErrorStatus(err error) int {
+	switch {
+	case errors.Is(err, repository.ErrWalletNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrWalletAccessDenied), errors.Is(err, models.ErrWebhookAccessDenied):
+		return http.StatusForbidden
+	case errors.Is(err, repository.ErrWalletFrozen):
+		return http.StatusLocked
+	case errors.Is(err, repository.ErrWalletClosed):
+		return http.StatusGone
+	case errors.Is(err, repository.ErrWalletNotEmpty), errors.Is(err, repository.ErrWalletAlreadyExist):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrInvalidLabels):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrInsufficientFunds):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrIdempotencyKeyUsed):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, repository.ErrInvalidAmount), errors.Is(err, repository.ErrSameWallet):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrHoldNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrHoldNotActive), errors.Is(err, repository.ErrHoldExpired):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrCaptureExceedHold),
+		errors.Is(err, repository.ErrCurrencyMismatch),
+		errors.Is(err, models.ErrInvalidAmountScale):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrUnsupportedCurrency), errors.Is(err, models.ErrSameCurrency):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrQuoteNotFound), errors.Is(err, repository.ErrTransactionNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, repository.ErrAlreadyReversed):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrNotReversible), errors.Is(err, repository.ErrReversalExceedsOriginal):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, repository.ErrQuoteExpired), errors.Is(err, repository.ErrQuoteExecuted):
+		return http.StatusConflict
+	case errors.Is(err, models.ErrRateNotFound), errors.Is(err, repository.ErrLimitExceeded):
+		return http.StatusUnprocessableEntity
+	case errors.Is(err, models.ErrInvalidLimit), errors.Is(err, models.ErrInvalidFeeSchedule):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrLimitTierNotFound), errors.Is(err, repository.ErrScheduledOperationNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidSchedule), errors.Is(err, models.ErrTimestampInFuture),
+		errors.Is(err, models.ErrInvalidStatementRange):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrScheduleNotActive):
+		return http.StatusConflict
+	case errors.Is(err, repository.ErrWebhookNotFound), errors.Is(err, repository.ErrWebhookDeliveryNotFound):
+		return http.StatusNotFound
+	case errors.Is(err, models.ErrInvalidWebhook):
+		return http.StatusBadRequest
+	case errors.Is(err, repository.ErrDeliveryNotRetryable):
+		return http.StatusConflict
+	default:
+		return http.StatusServiceUnavailable
+	}
+}
+
+// idempotencyFromRequest берёт ключ из заголовка Idempotency-Key или поля requestId.
+func idempotencyFromRequest(
+	c *gin.Context,
+	requestID, requestHash string,
+	render func(result models.OperationResult) (int, any),
+) (*models.Idempotency, error) {
+	return newIdempotency(c.GetHeader(IdempotencyKeyHeader), requestID, requestHash, render)
+}
+
+// newIdempotency — общая часть idempotencyFromRequest для HTTP и gRPC: key — ключ из
+// заголовка (метаданных), requestID — из тела запроса.
+func newIdempotency(
+	key, requestID, requestHash string,
+	render func(result models.OperationResult) (int, any),
+) (*models.Idempotency, error) {
+	if key != "" && requestID != "" && key != requestID {
+		return nil, errors.New("Idempotency-Key header and requestId must match")
+	}
+	if key == "" {
+		key = requestID
+	}
+	if key == "" {
+		return nil, nil
+	}
+	if len(key) > 255 {
+		return nil, errors.New("idempotency key must be at most 255 characters")
+	}
+	return &models.Idempotency{
+		Key:         key,
+		RequestHash: requestHash,
+		Response: func(result models.OperationResult) models.IdempotentResponse {
+			status, body := render(result)
+			raw, _ := json.Marshal(body)
+			return models.IdempotentResponse{StatusCode: status, Body: raw}
+		},
+	}, nil
+}
+
+// operationOptions собирает опции операции из ключа идемпотентности и ожидаемой валюты.
+func operationOptions(idem *models.Idempotency, currency string) ([]models.OperationOption, error) {
+	var opts []models.OperationOption
+	if idem != nil {
+		opts = append(opts, models.WithIdempotency(idem))
+	}
+	if currency != "" {
+		if !models.IsSupportedCurrency(currency) {
+			return nil, models.ErrUnsupportedCurrency
+		}
+		opts = append(opts, models.WithCurrency(currency))
+	}
+	return opts, nil
+}
+
+func replayIdempotentResponse(c *gin.Context, idem *models.Idempotency) bool {
+	if idem == nil || idem.Replayed == nil {
+		return false
+	}
+	c.Header(IdempotentReplayedHeader, "true")
+	c.Data(idem.Replayed.StatusCode, "application/json; charset=utf-8", idem.Replayed.Body)
+	return true
+}
+
+func (h *WalletHTTPHandler) HandleTransfer(c *gin.Context) {
+	var req models.TransferRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	if req.Amount.Cmp(decimal.Zero) <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be > 0"})
+		return
+	}
+
+	idem, err := idempotencyFromRequest(c, req.RequestID, req.Hash(), func(result models.OperationResult) (int, any) {
+		return http.StatusOK, result.Transfer
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := operationOptions(idem, req.Currency)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	transfer, err := h.wallets.Transfer(c.Request.Context(), req.FromWalletID, req.ToWalletID, req.Amount, opts...)
+	if replayIdempotentResponse(c, idem) {
+		return
+	}
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, transfer)
+}
+
 func (h *WalletHTTPHandler) HandleGetBalance(c *gin.Context) {
 	walletIDStr := c.Param("wallet_id")
 	walletID, err := uuid.Parse(walletIDStr)
@@ -87,14 +453,281 @@ func (h *WalletHTTPHandler) HandleGetBalance(c *gin.Context) {
 		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
 		return
 	}
-	balance, err := h.service.GetBalance(c.Request.Context(), walletID)
+	wallet, err := h.wallets.GetWallet(c.Request.Context(), walletID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{
+		"balance":   wallet.Balance.String(),
+		"held":      wallet.Held.String(),
+		"available": wallet.Available.String(),
+	})
+}
+
+// HandleGetBalanceAt возвращает баланс на момент ?at=<RFC3339>, по умолчанию — на текущий момент.
+func (h *WalletHTTPHandler) HandleGetBalanceAt(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	at := time.Now()
+	if v := c.Query("at"); v != "" {
+		if at, err = time.Parse(time.RFC3339, v); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid at, expected RFC3339"})
+			return
+		}
+	}
+	balance, err := h.wallets.GetBalanceAt(c.Request.Context(), walletID, at)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, balance)
+}
+
+func (h *WalletHTTPHandler) HandleGetTransactions(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	filter, err := parseTransactionFilter(c.Query)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query", "details": err.Error()})
+		return
+	}
+	page, err := h.wallets.GetTransactions(c.Request.Context(), walletID, filter)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+// parseTransactionFilter разбирает параметры истории операций; query возвращает значение
+// параметра по имени или пустую строку.
+func parseTransactionFilter(query func(key string) string) (models.TransactionFilter, error) {
+	var filter models.TransactionFilter
+	if v := query("type"); v != "" {
+		if !slices.Contains(models.JournalTypes, v) {
+			return filter, fmt.Errorf("type must be one of %s", strings.Join(models.JournalTypes, ", "))
+		}
+		filter.OperationType = v
+	}
+	if v := query("minAmount"); v != "" {
+		d, err := decimal.NewFromString(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid minAmount: %w", err)
+		}
+		filter.MinAmount = &d
+	}
+	if v := query("maxAmount"); v != "" {
+		d, err := decimal.NewFromString(v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid maxAmount: %w", err)
+		}
+		filter.MaxAmount = &d
+	}
+	if v := query("from"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid from: %w", err)
+		}
+		filter.From = &t
+	}
+	if v := query("to"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			return filter, fmt.Errorf("invalid to: %w", err)
+		}
+		filter.To = &t
+	}
+	if v := query("cursor"); v != "" {
+		cursor, err := models.DecodeTransactionCursor(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = cursor
+	}
+	if v := query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > models.MaxTransactionsLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", models.MaxTransactionsLimit)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+func (h *WalletHTTPHandler) HandleGetTrialBalance(c *gin.Context) {
+	tb, err := h.admin.GetTrialBalance(c.Request.Context())
+	if err != nil {
+		c.JSON(http.StatusServiceUnavailable, gin.H{"error": err.Error()})
+		return
+	}
+	c.JSON(http.StatusOK, tb)
+}
+
+// HandleReverseTransaction сторнирует пополнение или списание целиком или на сумму amount.
+func (h *WalletHTTPHandler) HandleReverseTransaction(c *gin.Context) {
+	transactionID, err := strconv.ParseInt(c.Param("id"), 10, 64)
+	if err != nil || transactionID <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid transaction id"})
+		return
+	}
+	var req models.ReversalRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+			return
+		}
+	}
+	if req.Amount != nil && req.Amount.Cmp(decimal.Zero) <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be > 0"})
+		return
+	}
+
+	idem, err := idempotencyFromRequest(c, req.RequestID, req.Hash(transactionID), func(result models.OperationResult) (int, any) {
+		return http.StatusCreated, result.Reversal
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := operationOptions(idem, "")
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	result, err := h.admin.ReverseTransaction(c.Request.Context(), transactionID, req.Amount, opts...)
+	if replayIdempotentResponse(c, idem) {
+		return
+	}
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+// handleSetWalletStatus возвращает обработчик административной смены статуса кошелька.
+func (h *WalletHTTPHandler) handleSetWalletStatus(status string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		walletID, err := uuid.Parse(c.Param("wallet_id"))
+		if err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+			return
+		}
+		var req models.WalletStatusRequest
+		if c.Request.ContentLength != 0 {
+			if err := c.ShouldBindJSON(&req); err != nil {
+				c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+				return
+			}
+		}
+		wallet, err := h.admin.SetWalletStatus(c.Request.Context(), walletID, status, req.Reason)
+		if err != nil {
+			c.JSON(operationErrorStatus(err), errorResponse(err))
+			return
+		}
+		c.JSON(http.StatusOK, wallet)
+	}
+}
+
+func (h *WalletHTTPHandler) HandleCreateHold(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	var req models.HoldRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	if req.Amount.Cmp(decimal.Zero) <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be > 0"})
+		return
+	}
+	idem, err := idempotencyFromRequest(c, req.RequestID, req.Hash(walletID), func(result models.OperationResult) (int, any) {
+		return http.StatusCreated, result.Hold
+	})
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	opts, err := operationOptions(idem, "")
 	if err != nil {
-		status := http.StatusServiceUnavailable
-		if err == repository.ErrWalletNotFound {
-			status = http.StatusNotFound
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+
+	ttl := time.Duration(req.TTLSeconds) * time.Second
+	result, err := h.holds.CreateHold(c.Request.Context(), walletID, req.Amount, ttl, opts...)
+	if replayIdempotentResponse(c, idem) {
+		return
+	}
+	if err != nil {
+		body := errorResponse(err)
+		body["wallet"] = result.Wallet
+		c.JSON(operationErrorStatus(err), body)
+		return
+	}
+	c.JSON(http.StatusCreated, result)
+}
+
+func (h *WalletHTTPHandler) HandleGetHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("hold_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold_id"})
+		return
+	}
+	hold, err := h.holds.GetHold(c.Request.Context(), holdID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, hold)
+}
+
+func (h *WalletHTTPHandler) HandleCaptureHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("hold_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold_id"})
+		return
+	}
+	var req models.CaptureRequest
+	if c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&req); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+			return
 		}
-		c.JSON(status, gin.H{"error": err.Error()})
+	}
+	if req.Amount != nil && req.Amount.Cmp(decimal.Zero) <= 0 {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "amount must be > 0"})
+		return
+	}
+	result, err := h.holds.CaptureHold(c.Request.Context(), holdID, req.Amount)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, result)
+}
+
+func (h *WalletHTTPHandler) HandleVoidHold(c *gin.Context) {
+	holdID, err := uuid.Parse(c.Param("hold_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid hold_id"})
+		return
+	}
+	result, err := h.holds.VoidHold(c.Request.Context(), holdID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
 		return
 	}
-	c.JSON(http.StatusOK, gin.H{"balance": balance.String()})
+	c.JSON(http.StatusOK, result)
 }
@@ -0,0 +1,75 @@
+package handlers
+
+import (
+	"net/http"
+	"test_wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHTTPHandler) HandleSetLimitTier(c *gin.Context) {
+	var req models.LimitTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	tier := c.Param("tier")
+	if err := h.admin.SetLimitTier(c.Request.Context(), tier, req.Currency, req.SpendingLimit); err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"tier": tier, "currency": req.Currency, "limit": req.SpendingLimit})
+}
+
+func (h *WalletHTTPHandler) HandleSetWalletLimit(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	var req models.SpendingLimit
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	if err := h.admin.SetWalletLimit(c.Request.Context(), walletID, req); err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	h.HandleGetWalletLimits(c)
+}
+
+func (h *WalletHTTPHandler) HandleSetWalletTier(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	var req models.WalletTierRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	wallet, err := h.admin.SetWalletTier(c.Request.Context(), walletID, req.Tier)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, wallet)
+}
+
+// HandleGetWalletLimits возвращает действующие лимиты кошелька с учётом тарифа.
+func (h *WalletHTTPHandler) HandleGetWalletLimits(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	limits, err := h.wallets.GetWalletLimits(c.Request.Context(), walletID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"walletId": walletID, "limits": limits})
+}
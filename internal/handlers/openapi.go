@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"errors"
+	"net/http"
+	"strings"
+
+	"test_wallet/api/openapi"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/gin-gonic/gin"
+)
+
+// apiPrefix — путь группы /api/v1, он же servers[0].url в openapi.json.
+const apiPrefix = "/api/v1"
+
+// apiSpec — разобранный openapi.Spec. Документ встроен в бинарник, поэтому ошибка в нём —
+// ошибка сборки, а не окружения.
+var apiSpec = mustLoadSpec()
+
+func mustLoadSpec() *openapi3.T {
+	doc, err := openapi.Load()
+	if err != nil {
+		panic("handlers: invalid openapi.json: " + err.Error())
+	}
+	return doc
+}
+
+// HandleOpenAPI отдаёт OpenAPI-описание API.
+func (h *WalletHTTPHandler) HandleOpenAPI(c *gin.Context) {
+	c.Data(http.StatusOK, "application/json; charset=utf-8", openapi.Spec)
+}
+
+// SpecRoute возвращает операцию OpenAPI для маршрута gin (c.FullPath(), например
+// /api/v1/wallets/:wallet_id) и метода; ok = false, если маршрут в спецификации не описан.
+func SpecRoute(fullPath, method string) (*routers.Route, bool) {
+	path, ok := strings.CutPrefix(fullPath, apiPrefix)
+	if !ok {
+		return nil, false
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		if name, ok := strings.CutPrefix(segment, ":"); ok {
+			segments[i] = "{" + name + "}"
+		}
+	}
+	path = strings.Join(segments, "/")
+	item := apiSpec.Paths.Value(path)
+	if item == nil {
+		return nil, false
+	}
+	operation := item.GetOperation(method)
+	if operation == nil {
+		return nil, false
+	}
+	return &routers.Route{Spec: apiSpec, Path: path, PathItem: item, Method: method, Operation: operation}, true
+}
+
+// validateRequest проверяет запросы к описанным в openapi.json операциям до обработчика.
+func (h *WalletHTTPHandler) validateRequest(c *gin.Context) {
+	route, ok := SpecRoute(c.FullPath(), c.Request.Method)
+	if !ok {
+		c.Next()
+		return
+	}
+	params := make(map[string]string, len(c.Params))
+	for _, p := range c.Params {
+		params[p.Key] = p.Value
+	}
+	err := openapi3filter.ValidateRequest(c.Request.Context(), &openapi3filter.RequestValidationInput{
+		Request:    c.Request,
+		PathParams: params,
+		Route:      route,
+		Options:    &openapi3filter.Options{AuthenticationFunc: openapi3filter.NoopAuthenticationFunc},
+	})
+	if err == nil {
+		c.Next()
+		return
+	}
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) && reqErr.Parameter != nil && reqErr.Parameter.In == openapi3.ParameterInPath {
+		c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid " + reqErr.Parameter.Name})
+		return
+	}
+	c.AbortWithStatusJSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+}
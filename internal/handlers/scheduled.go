@@ -0,0 +1,80 @@
+package handlers
+
+import (
+	"net/http"
+	"test_wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHTTPHandler) HandleCreateScheduledOperation(c *gin.Context) {
+	var req models.ScheduleRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	op, err := h.schedules.CreateScheduledOperation(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, op)
+}
+
+func (h *WalletHTTPHandler) HandleGetScheduledOperation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("schedule_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule_id"})
+		return
+	}
+	op, err := h.schedules.GetScheduledOperation(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, op)
+}
+
+func (h *WalletHTTPHandler) HandleListScheduledOperations(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	ops, err := h.schedules.ListScheduledOperations(c.Request.Context(), walletID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"walletId": walletID, "scheduledOperations": ops})
+}
+
+func (h *WalletHTTPHandler) HandleCancelScheduledOperation(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("schedule_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule_id"})
+		return
+	}
+	op, err := h.schedules.CancelScheduledOperation(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, op)
+}
+
+// HandleGetScheduledRuns возвращает все попытки исполнения, включая неудачные.
+func (h *WalletHTTPHandler) HandleGetScheduledRuns(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("schedule_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid schedule_id"})
+		return
+	}
+	runs, err := h.schedules.GetScheduledRuns(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"scheduledOperationId": id, "runs": runs})
+}
@@ -0,0 +1,69 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"test_wallet/internal/statement"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+const dateLayout = "2006-01-02"
+
+// HandleGetStatement отдаёт выписку за период [from, to) потоком в формате ?format=csv|ndjson|ofx.
+func (h *WalletHTTPHandler) HandleGetStatement(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	format, err := statement.Lookup(c.DefaultQuery("format", "csv"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": err.Error()})
+		return
+	}
+	from, err := parseStatementTime(c.Query("from"), false)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid from: %v", err)})
+		return
+	}
+	to := time.Now()
+	if v := c.Query("to"); v != "" {
+		if to, err = parseStatementTime(v, true); err != nil {
+			c.JSON(http.StatusBadRequest, gin.H{"error": fmt.Sprintf("invalid to: %v", err)})
+			return
+		}
+	}
+
+	c.Header("Content-Type", format.ContentType)
+	c.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="statement-%s-%s.%s"`,
+		walletID, from.UTC().Format(dateLayout), format.Extension))
+	err = h.wallets.ExportStatement(c.Request.Context(), walletID, from, to, format.NewWriter(c.Writer))
+	if err == nil {
+		return
+	}
+	if c.Writer.Written() {
+		// Статус уже отправлен. Выписка обрывается без записи closing, по отсутствию
+		// которой клиент отличает неполную выписку
+		_ = c.Error(err)
+		return
+	}
+	c.Writer.Header().Del("Content-Type")
+	c.Writer.Header().Del("Content-Disposition")
+	c.JSON(operationErrorStatus(err), errorResponse(err))
+}
+
+func parseStatementTime(v string, endOfDay bool) (time.Time, error) {
+	if v == "" {
+		return time.Time{}, fmt.Errorf("required")
+	}
+	if t, err := time.Parse(dateLayout, v); err == nil {
+		if endOfDay {
+			t = t.AddDate(0, 0, 1)
+		}
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, v)
+}
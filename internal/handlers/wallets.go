@@ -0,0 +1,136 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"test_wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHTTPHandler) HandleCreateWallet(c *gin.Context) {
+	var req models.CreateWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	wallet, err := h.wallets.CreateWallet(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, wallet)
+}
+
+func (h *WalletHTTPHandler) HandleListWallets(c *gin.Context) {
+	filter, err := parseWalletFilter(c)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid query", "details": err.Error()})
+		return
+	}
+	page, err := h.wallets.ListWallets(c.Request.Context(), filter)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, page)
+}
+
+func (h *WalletHTTPHandler) HandleUpdateWallet(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	var req models.UpdateWalletRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	wallet, err := h.wallets.UpdateWallet(c.Request.Context(), walletID, req)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, wallet)
+}
+
+// parseWalletFilter разбирает ?ownerId=&status=&label=key:value&cursor=&limit=.
+// Параметр label можно повторять.
+func parseWalletFilter(c *gin.Context) (models.WalletFilter, error) {
+	filter := models.WalletFilter{OwnerID: c.Query("ownerId")}
+	if v := c.Query("status"); v != "" {
+		switch v {
+		case models.WalletActive, models.WalletFrozen, models.WalletClosed:
+			filter.Status = v
+		default:
+			return filter, fmt.Errorf("status must be one of %s, %s, %s",
+				models.WalletActive, models.WalletFrozen, models.WalletClosed)
+		}
+	}
+	for _, label := range c.QueryArray("label") {
+		key, value, ok := strings.Cut(label, ":")
+		if !ok || key == "" {
+			return filter, fmt.Errorf("label must be key:value, got %q", label)
+		}
+		if filter.Labels == nil {
+			filter.Labels = make(map[string]string)
+		}
+		filter.Labels[key] = value
+	}
+	if v := c.Query("cursor"); v != "" {
+		cursor, err := models.DecodeWalletCursor(v)
+		if err != nil {
+			return filter, err
+		}
+		filter.Cursor = cursor
+	}
+	if v := c.Query("limit"); v != "" {
+		limit, err := strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > models.MaxWalletsLimit {
+			return filter, fmt.Errorf("limit must be between 1 and %d", models.MaxWalletsLimit)
+		}
+		filter.Limit = limit
+	}
+	return filter, nil
+}
+
+func (h *WalletHTTPHandler) HandleSetOverdraftLimit(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	var req models.OverdraftLimitRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	if req.Limit.IsNegative() {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "limit must be >= 0"})
+		return
+	}
+	wallet, err := h.admin.SetOverdraftLimit(c.Request.Context(), walletID, req.Limit, req.Reason)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, wallet)
+}
+
+func (h *WalletHTTPHandler) HandleGetOverdraftLimitChanges(c *gin.Context) {
+	walletID, err := uuid.Parse(c.Param("wallet_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid wallet_id"})
+		return
+	}
+	changes, err := h.admin.GetOverdraftLimitChanges(c.Request.Context(), walletID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"changes": changes})
+}
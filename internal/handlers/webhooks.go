@@ -0,0 +1,113 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"strconv"
+	"test_wallet/internal/auth"
+	"test_wallet/internal/models"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+)
+
+func (h *WalletHTTPHandler) HandleCreateWebhook(c *gin.Context) {
+	var req models.WebhookRequest
+	if err := c.ShouldBindJSON(&req); err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid request", "details": err.Error()})
+		return
+	}
+	sub, err := h.webhooks.CreateWebhook(c.Request.Context(), req)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusCreated, sub)
+}
+
+// HandleListWebhooks отдаёт подписки владельца ?ownerId=, по умолчанию — вызывающего.
+func (h *WalletHTTPHandler) HandleListWebhooks(c *gin.Context) {
+	ownerID := c.Query("ownerId")
+	subs, err := h.webhooks.ListWebhooks(c.Request.Context(), ownerID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	if principal, ok := auth.FromContext(c.Request.Context()); ok && ownerID == "" {
+		ownerID = principal.Subject
+	}
+	c.JSON(http.StatusOK, gin.H{"ownerId": ownerID, "webhooks": subs})
+}
+
+func (h *WalletHTTPHandler) HandleGetWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook_id"})
+		return
+	}
+	sub, err := h.webhooks.GetWebhook(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+func (h *WalletHTTPHandler) HandleDeleteWebhook(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook_id"})
+		return
+	}
+	sub, err := h.webhooks.DeleteWebhook(c.Request.Context(), id)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, sub)
+}
+
+// HandleListWebhookDeliveries отдаёт журнал доставок подписки от новых к старым;
+// ?status= фильтрует по статусу (PENDING, DELIVERED, DEAD), ?limit= ограничивает выборку.
+func (h *WalletHTTPHandler) HandleListWebhookDeliveries(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook_id"})
+		return
+	}
+	limit := 0
+	if v := c.Query("limit"); v != "" {
+		limit, err = strconv.Atoi(v)
+		if err != nil || limit <= 0 || limit > models.MaxWebhookDeliveriesLimit {
+			c.JSON(http.StatusBadRequest, gin.H{
+				"error": fmt.Sprintf("limit must be between 1 and %d", models.MaxWebhookDeliveriesLimit),
+			})
+			return
+		}
+	}
+	deliveries, err := h.webhooks.ListWebhookDeliveries(c.Request.Context(), id, c.Query("status"), limit)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, gin.H{"webhookId": id, "deliveries": deliveries})
+}
+
+func (h *WalletHTTPHandler) HandleRetryWebhookDelivery(c *gin.Context) {
+	id, err := uuid.Parse(c.Param("webhook_id"))
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid webhook_id"})
+		return
+	}
+	deliveryID, err := strconv.ParseInt(c.Param("delivery_id"), 10, 64)
+	if err != nil {
+		c.JSON(http.StatusBadRequest, gin.H{"error": "invalid delivery_id"})
+		return
+	}
+	delivery, err := h.webhooks.RetryWebhookDelivery(c.Request.Context(), id, deliveryID)
+	if err != nil {
+		c.JSON(operationErrorStatus(err), errorResponse(err))
+		return
+	}
+	c.JSON(http.StatusOK, delivery)
+}
@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Способы аутентификации вызывающего, Principal.Method.
+const (
+	AuthMethodAPIKey = "api_key"
+	AuthMethodJWT    = "jwt"
+)
+
+// ScopeAdmin разрешает административные операции: /admin/* и сторнирование транзакций.
+// Вызывающему с этим правом доступны кошельки всех владельцев.
+const ScopeAdmin = "admin"
+
+// ErrWalletAccessDenied — кошелёк принадлежит другому владельцу.
+var ErrWalletAccessDenied = errors.New("wallet belongs to another owner")
+
+// Principal — аутентифицированный вызывающий. Subject — имя API-ключа или claim sub JWT.
+type Principal struct {
+	Subject string
+	Method  string
+	// APIKeyID — ключ, которым выполнен вход (только для AuthMethodAPIKey).
+	APIKeyID *uuid.UUID
+	// Scopes — права вызывающего: scopes API-ключа или claim scope JWT.
+	Scopes []string
+}
+
+// HasScope сообщает, выдано ли вызывающему право scope.
+func (p Principal) HasScope(scope string) bool {
+	return slices.Contains(p.Scopes, scope)
+}
+
+// APIKey — статический ключ доступа к API. В БД хранится только SHA-256 ключа, сам ключ
+// показывается один раз при выпуске.
+type APIKey struct {
+	ID        uuid.UUID  `db:"id" json:"id"`
+	Name      string     `db:"name" json:"name"`
+	KeyHash   string     `db:"key_hash" json:"-"`
+	Scopes    []string   `db:"scopes" json:"scopes"`
+	CreatedAt time.Time  `db:"created_at" json:"createdAt"`
+	RevokedAt *time.Time `db:"revoked_at" json:"revokedAt,omitempty"`
+}
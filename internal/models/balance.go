@@ -0,0 +1,19 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var ErrTimestampInFuture = errors.New("timestamp is in the future")
+
+// BalanceAt — баланс кошелька на момент At, восстановленный по журналу операций.
+type BalanceAt struct {
+	WalletID uuid.UUID       `json:"walletId"`
+	Currency string          `json:"currency"`
+	Balance  decimal.Decimal `json:"balance"`
+	At       time.Time       `json:"at"`
+}
@@ -0,0 +1,38 @@
+package models
+
+import "github.com/google/uuid"
+
+// Причины разрыва хэш-цепочки журнала.
+const (
+	// ChainHashMismatch — содержимое строки не соответствует её хэшу: строку изменили.
+	ChainHashMismatch = "hash_mismatch"
+	// ChainBrokenLink — prev_hash строки не равен хэшу предыдущей строки: строку удалили,
+	// вставили или пересчитали цепочку до неё.
+	ChainBrokenLink = "broken_link"
+	// ChainHeadMismatch — последняя строка журнала не совпадает с wallets.journal_hash:
+	// удалены последние строки.
+	ChainHeadMismatch = "head_mismatch"
+	// ChainReversedMismatch — reversed_amount строки не равен сумме её сторно: поле вне
+	// хэша изменили в обход приложения.
+	ChainReversedMismatch = "reversed_amount_mismatch"
+)
+
+// ChainBreak — первое найденное нарушение цепочки. TransactionID пуст, если у кошелька
+// не осталось строк журнала.
+type ChainBreak struct {
+	WalletID      uuid.UUID `json:"walletId"`
+	TransactionID *int64    `json:"transactionId,omitempty"`
+	Reason        string    `json:"reason"`
+	Expected      string    `json:"expected"`
+	Actual        string    `json:"actual"`
+}
+
+// ChainVerification — результат проверки хэш-цепочки журнала.
+type ChainVerification struct {
+	WalletID       *uuid.UUID  `json:"walletId,omitempty"`
+	WalletsChecked int64       `json:"walletsChecked"`
+	RowsChecked    int64       `json:"rowsChecked"`
+	OK             bool        `json:"ok"`
+	FirstBreak     *ChainBreak `json:"firstBreak,omitempty"`
+	Head           *string     `json:"head,omitempty"`
+}
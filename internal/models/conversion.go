@@ -0,0 +1,56 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrRateNotFound     = errors.New("exchange rate not found")
+	ErrRatesUnavailable = errors.New("exchange rates are not configured")
+	ErrSameCurrency     = errors.New("wallets have the same currency, use a transfer")
+)
+
+// Rate — курс обмена: одна единица From стоит Value единиц To на момент Timestamp.
+type Rate struct {
+	From      string          `json:"from"`
+	To        string          `json:"to"`
+	Value     decimal.Decimal `json:"rate"`
+	Timestamp time.Time       `json:"timestamp"`
+}
+
+// Convert пересчитывает amount по курсу. Результат округляется вниз до минимальной единицы
+// валюты To, так что округление никогда не создаёт деньги.
+func (r Rate) Convert(amount decimal.Decimal) (decimal.Decimal, error) {
+	units, err := MinorUnits(r.To)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	return amount.Mul(r.Value).RoundDown(units), nil
+}
+
+// ConversionQuote — котировка конвертации с зафиксированным курсом, действующая до ExpiresAt.
+type ConversionQuote struct {
+	ID            uuid.UUID       `db:"id" json:"quoteId"`
+	FromWalletID  uuid.UUID       `db:"from_wallet_id" json:"fromWalletId"`
+	ToWalletID    uuid.UUID       `db:"to_wallet_id" json:"toWalletId"`
+	FromCurrency  string          `db:"from_currency" json:"fromCurrency"`
+	ToCurrency    string          `db:"to_currency" json:"toCurrency"`
+	FromAmount    decimal.Decimal `db:"from_amount" json:"fromAmount"`
+	ToAmount      decimal.Decimal `db:"to_amount" json:"toAmount"`
+	Rate          decimal.Decimal `db:"rate" json:"rate"`
+	RateTimestamp time.Time       `db:"rate_timestamp" json:"rateTimestamp"`
+	ExpiresAt     time.Time       `db:"expires_at" json:"expiresAt"`
+	ExecutedAt    *time.Time      `db:"executed_at" json:"executedAt,omitempty"`
+	EntryID       *int64          `db:"entry_id" json:"entryId,omitempty"`
+	CreatedAt     time.Time       `db:"created_at" json:"createdAt"`
+}
+
+type ConversionResult struct {
+	Quote       ConversionQuote `json:"quote"`
+	FromBalance decimal.Decimal `json:"fromBalance"`
+	ToBalance   decimal.Decimal `json:"toBalance"`
+}
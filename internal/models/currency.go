@@ -0,0 +1,48 @@
+package models
+
+import (
+	"errors"
+
+	"github.com/shopspring/decimal"
+)
+
+const DefaultCurrency = "RUB"
+
+var (
+	ErrUnsupportedCurrency = errors.New("unsupported currency")
+	ErrInvalidAmountScale  = errors.New("amount has more decimal places than the currency allows")
+)
+
+// currencyMinorUnits — число знаков после запятой для поддерживаемых валют ISO 4217.
+var currencyMinorUnits = map[string]int32{
+	"AED": 2, "AMD": 2, "AZN": 2, "BHD": 3, "BYN": 2, "CHF": 2, "CNY": 2,
+	"EUR": 2, "GBP": 2, "GEL": 2, "IQD": 3, "JOD": 3, "JPY": 0, "KGS": 2,
+	"KRW": 0, "KWD": 3, "KZT": 2, "LYD": 3, "OMR": 3, "RUB": 2, "TND": 3,
+	"TRY": 2, "UAH": 2, "USD": 2, "UZS": 2, "VND": 0,
+}
+
+func IsSupportedCurrency(code string) bool {
+	_, ok := currencyMinorUnits[code]
+	return ok
+}
+
+// MinorUnits возвращает число знаков после запятой для валюты.
+func MinorUnits(code string) (int32, error) {
+	units, ok := currencyMinorUnits[code]
+	if !ok {
+		return 0, ErrUnsupportedCurrency
+	}
+	return units, nil
+}
+
+// ValidateScale проверяет, что сумма выражается целым числом минимальных единиц валюты.
+func ValidateScale(amount decimal.Decimal, currency string) error {
+	units, err := MinorUnits(currency)
+	if err != nil {
+		return err
+	}
+	if !amount.Equal(amount.Truncate(units)) {
+		return ErrInvalidAmountScale
+	}
+	return nil
+}
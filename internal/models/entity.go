@@ -7,15 +7,111 @@ import (
 	"github.com/shopspring/decimal"
 )
 
+// Wallet — кошелёк; Available = Balance - Held + OverdraftLimit.
 type Wallet struct {
-	ID      uuid.UUID       `db:"id" json:"walletId"`
-	Balance decimal.Decimal `db:"balance" json:"balance"`
+	ID              uuid.UUID         `db:"id" json:"walletId"`
+	Currency        string            `db:"currency" json:"currency"`
+	Status          string            `db:"status" json:"status"`
+	StatusReason    *string           `db:"status_reason" json:"statusReason,omitempty"`
+	StatusChangedAt *time.Time        `db:"status_changed_at" json:"statusChangedAt,omitempty"`
+	OwnerID         string            `db:"owner_id" json:"ownerId,omitempty"`
+	Name            string            `db:"name" json:"name,omitempty"`
+	Labels          map[string]string `db:"labels" json:"labels"`
+	Balance         decimal.Decimal   `db:"balance" json:"balance"`
+	Held            decimal.Decimal   `db:"held" json:"held"`
+	OverdraftLimit  decimal.Decimal   `db:"overdraft_limit" json:"overdraftLimit"`
+	LimitTier       *string           `db:"limit_tier" json:"limitTier,omitempty"`
+	Available       decimal.Decimal   `db:"available" json:"available"`
+	CreatedAt       time.Time         `db:"created_at" json:"createdAt"`
+	UpdatedAt       time.Time         `db:"updated_at" json:"updatedAt"`
 }
 
+// OverdraftLimitChange — запись журнала изменений лимита овердрафта кошелька.
+type OverdraftLimitChange struct {
+	ID        int64           `db:"id" json:"id"`
+	WalletID  uuid.UUID       `db:"wallet_id" json:"walletId"`
+	OldLimit  decimal.Decimal `db:"old_limit" json:"oldLimit"`
+	NewLimit  decimal.Decimal `db:"new_limit" json:"newLimit"`
+	Reason    *string         `db:"reason" json:"reason,omitempty"`
+	CreatedAt time.Time       `db:"created_at" json:"createdAt"`
+}
+
+// Статусы кошелька. Замороженный кошелёк не участвует в списаниях и зачислениях
+// (кроме пополнений, если они разрешены настройкой), закрытый — ни в каких операциях.
+const (
+	WalletActive = "ACTIVE"
+	WalletFrozen = "FROZEN"
+	WalletClosed = "CLOSED"
+)
+
 type Transaction struct {
-	ID        int64           `db:"id"`
-	WalletID  uuid.UUID       `db:"wallet_id"`
-	Type      string          `db:"type"`
-	Amount    decimal.Decimal `db:"amount"`
-	CreatedAt time.Time       `db:"created_at"`
+	ID           int64           `db:"id" json:"id"`
+	WalletID     uuid.UUID       `db:"wallet_id" json:"walletId"`
+	Type         string          `db:"type" json:"operationType"`
+	Amount       decimal.Decimal `db:"amount" json:"amount"`
+	BalanceAfter decimal.Decimal `db:"balance_after" json:"balanceAfter"`
+	EntryID      *int64          `db:"entry_id" json:"entryId,omitempty"`
+	TransferID   *uuid.UUID      `db:"transfer_id" json:"transferId,omitempty"`
+	HoldID       *uuid.UUID      `db:"hold_id" json:"holdId,omitempty"`
+	ConversionID *uuid.UUID      `db:"conversion_id" json:"conversionId,omitempty"`
+	// Rate и RateTimestamp — курс конвертации и время его публикации провайдером.
+	Rate          *decimal.Decimal `db:"rate" json:"rate,omitempty"`
+	RateTimestamp *time.Time       `db:"rate_timestamp" json:"rateTimestamp,omitempty"`
+	// ReversalOf — исходная операция для строки сторно; ReversedAmount — уже сторнированная часть операции.
+	ReversalOf     *int64          `db:"reversal_of" json:"reversalOf,omitempty"`
+	ReversedAmount decimal.Decimal `db:"reversed_amount" json:"reversedAmount"`
+	CreatedAt      time.Time       `db:"created_at" json:"createdAt"`
+	// PrevHash и Hash — звенья хэш-цепочки журнала кошелька, см. models.ChainVerification.
+	PrevHash *string `db:"prev_hash" json:"prevHash,omitempty"`
+	Hash     string  `db:"hash" json:"hash"`
+}
+
+type TransferResult struct {
+	ID           uuid.UUID       `json:"transferId"`
+	FromWalletID uuid.UUID       `json:"fromWalletId"`
+	ToWalletID   uuid.UUID       `json:"toWalletId"`
+	Amount       decimal.Decimal `json:"amount"`
+	Currency     string          `json:"currency"`
+	FromBalance  decimal.Decimal `json:"fromBalance"`
+	ToBalance    decimal.Decimal `json:"toBalance"`
+}
+
+const (
+	// ReversalPolicyFail — сторно пополнения отклоняется, если средства уже потрачены.
+	ReversalPolicyFail = "fail"
+	// ReversalPolicyAllowNegative — сторно проводится, даже если баланс станет отрицательным.
+	ReversalPolicyAllowNegative = "allow_negative"
+)
+
+// ReversalResult — строка сторно, исходная операция после сторно и новый баланс кошелька.
+// FeeRefund — строка возврата комиссии исходной операции, если она была.
+type ReversalResult struct {
+	Reversal  Transaction     `json:"reversal"`
+	FeeRefund *Transaction    `json:"feeRefund,omitempty"`
+	Original  Transaction     `json:"original"`
+	Balance   decimal.Decimal `json:"balance"`
+}
+
+const (
+	HoldActive   = "ACTIVE"
+	HoldCaptured = "CAPTURED"
+	HoldVoided   = "VOIDED"
+	HoldExpired  = "EXPIRED"
+)
+
+// Hold — резервирование средств кошелька до списания (capture) или отмены (void).
+type Hold struct {
+	ID             uuid.UUID       `db:"id" json:"holdId"`
+	WalletID       uuid.UUID       `db:"wallet_id" json:"walletId"`
+	Amount         decimal.Decimal `db:"amount" json:"amount"`
+	CapturedAmount decimal.Decimal `db:"captured_amount" json:"capturedAmount"`
+	Status         string          `db:"status" json:"status"`
+	ExpiresAt      time.Time       `db:"expires_at" json:"expiresAt"`
+	CreatedAt      time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updatedAt"`
+}
+
+type HoldResult struct {
+	Hold   Hold   `json:"hold"`
+	Wallet Wallet `json:"wallet"`
 }
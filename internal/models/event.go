@@ -0,0 +1,56 @@
+package models
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Типы событий outbox.
+const (
+	EventBalanceChanged = "wallet.balance_changed"
+	EventStatusChanged  = "wallet.status_changed"
+)
+
+// Event — событие об изменении кошелька из таблицы outbox_events. Data — тело события:
+// BalanceChanged или StatusChanged в зависимости от Type.
+type Event struct {
+	ID        int64           `db:"id" json:"-"`
+	EventID   uuid.UUID       `db:"event_id" json:"eventId"`
+	Type      string          `db:"type" json:"type"`
+	WalletID  uuid.UUID       `db:"wallet_id" json:"walletId"`
+	Data      json.RawMessage `db:"payload" json:"data"`
+	CreatedAt time.Time       `db:"created_at" json:"occurredAt"`
+	Attempts  int             `db:"attempts" json:"-"`
+}
+
+// BalanceChanged — тело события wallet.balance_changed. Amount — сумма операции со знаком,
+// Fee — удержанная комиссия; BalanceAfter = BalanceBefore + Amount - Fee.
+type BalanceChanged struct {
+	Operation     string          `json:"operation"`
+	Amount        decimal.Decimal `json:"amount"`
+	Fee           decimal.Decimal `json:"fee"`
+	Currency      string          `json:"currency"`
+	BalanceBefore decimal.Decimal `json:"balanceBefore"`
+	BalanceAfter  decimal.Decimal `json:"balanceAfter"`
+	TransactionID int64           `json:"transactionId"`
+	EntryID       int64           `json:"entryId"`
+}
+
+// StatusChanged — тело события wallet.status_changed.
+type StatusChanged struct {
+	OldStatus string  `json:"oldStatus"`
+	Status    string  `json:"status"`
+	Reason    *string `json:"reason,omitempty"`
+}
+
+// StreamSnapshot — текущий баланс кошелька, с которого начинается поток событий без
+// Last-Event-ID. LastEventID — id последнего события кошелька на момент снимка.
+type StreamSnapshot struct {
+	WalletID    uuid.UUID       `json:"walletId"`
+	Currency    string          `json:"currency"`
+	Balance     decimal.Decimal `json:"balance"`
+	LastEventID int64           `json:"-"`
+}
@@ -0,0 +1,133 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+var ErrInvalidFeeSchedule = errors.New("invalid fee schedule")
+
+// FeeTier — ступень тарифа: действует для сумм не больше UpTo; ступень без UpTo — для всех
+// сумм больше предыдущей ступени.
+type FeeTier struct {
+	UpTo    *decimal.Decimal `json:"upTo,omitempty"`
+	Flat    decimal.Decimal  `json:"flat"`
+	Percent decimal.Decimal  `json:"percent"`
+}
+
+// FeeSchedule — тариф комиссий для операции Operation в валюте Currency.
+// Percent задаётся в процентах: 1.5 означает 1,5% суммы операции.
+type FeeSchedule struct {
+	Operation string           `db:"operation" json:"operation" binding:"required,oneof=DEPOSIT WITHDRAW"`
+	Currency  string           `db:"currency" json:"currency" binding:"required,len=3"`
+	Flat      decimal.Decimal  `db:"flat" json:"flat"`
+	Percent   decimal.Decimal  `db:"percent" json:"percent"`
+	MinFee    *decimal.Decimal `db:"min_fee" json:"minFee,omitempty"`
+	MaxFee    *decimal.Decimal `db:"max_fee" json:"maxFee,omitempty"`
+	Tiers     []FeeTier        `db:"tiers" json:"tiers"`
+	UpdatedAt time.Time        `db:"updated_at" json:"updatedAt"`
+}
+
+var hundred = decimal.NewFromInt(100)
+
+// Compute считает комиссию с суммы amount, округлённую до минимальной единицы валюты тарифа.
+func (s FeeSchedule) Compute(amount decimal.Decimal) (decimal.Decimal, error) {
+	units, err := MinorUnits(s.Currency)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	flat, percent := s.Flat, s.Percent
+	for _, tier := range s.Tiers {
+		if tier.UpTo == nil || amount.LessThanOrEqual(*tier.UpTo) {
+			flat, percent = tier.Flat, tier.Percent
+			break
+		}
+	}
+	fee := flat.Add(amount.Mul(percent).Div(hundred))
+	if s.MinFee != nil && fee.LessThan(*s.MinFee) {
+		fee = *s.MinFee
+	}
+	if s.MaxFee != nil && fee.GreaterThan(*s.MaxFee) {
+		fee = *s.MaxFee
+	}
+	return fee.Round(units), nil
+}
+
+// Validate проверяет валюту, неотрицательность и точность сумм, диапазон процентов
+// и порядок ступеней: UpTo строго возрастают, ступень без UpTo может быть только последней.
+func (s FeeSchedule) Validate() error {
+	if !IsSupportedCurrency(s.Currency) {
+		return ErrUnsupportedCurrency
+	}
+	if err := s.validateRate(s.Flat, s.Percent); err != nil {
+		return err
+	}
+	for _, fee := range []*decimal.Decimal{s.MinFee, s.MaxFee} {
+		if fee == nil {
+			continue
+		}
+		if fee.IsNegative() {
+			return fmt.Errorf("%w: minFee and maxFee must be >= 0", ErrInvalidFeeSchedule)
+		}
+		if err := ValidateScale(*fee, s.Currency); err != nil {
+			return err
+		}
+	}
+	if s.MinFee != nil && s.MaxFee != nil && s.MinFee.GreaterThan(*s.MaxFee) {
+		return fmt.Errorf("%w: minFee must not exceed maxFee", ErrInvalidFeeSchedule)
+	}
+	for i, tier := range s.Tiers {
+		if err := s.validateRate(tier.Flat, tier.Percent); err != nil {
+			return err
+		}
+		if tier.UpTo == nil {
+			if i != len(s.Tiers)-1 {
+				return fmt.Errorf("%w: only the last tier may omit upTo", ErrInvalidFeeSchedule)
+			}
+			continue
+		}
+		if !tier.UpTo.IsPositive() {
+			return fmt.Errorf("%w: upTo must be positive", ErrInvalidFeeSchedule)
+		}
+		if i > 0 && !tier.UpTo.GreaterThan(*s.Tiers[i-1].UpTo) {
+			return fmt.Errorf("%w: tiers must be ordered by upTo", ErrInvalidFeeSchedule)
+		}
+	}
+	return nil
+}
+
+func (s FeeSchedule) validateRate(flat, percent decimal.Decimal) error {
+	if flat.IsNegative() {
+		return fmt.Errorf("%w: flat must be >= 0", ErrInvalidFeeSchedule)
+	}
+	if err := ValidateScale(flat, s.Currency); err != nil {
+		return err
+	}
+	if percent.IsNegative() || percent.GreaterThan(hundred) {
+		return fmt.Errorf("%w: percent must be between 0 and 100", ErrInvalidFeeSchedule)
+	}
+	return nil
+}
+
+// FeeQuoteRequest — запрос предварительного расчёта комиссии. Валюта берётся из кошелька,
+// если он указан, иначе из Currency.
+type FeeQuoteRequest struct {
+	WalletID      *uuid.UUID      `json:"walletId"`
+	OperationType string          `json:"operationType" binding:"required,oneof=DEPOSIT WITHDRAW"`
+	Amount        decimal.Decimal `json:"amount" binding:"required"`
+	Currency      string          `json:"currency,omitempty" binding:"omitempty,len=3"`
+}
+
+// FeeQuote — результат предварительного расчёта: BalanceChange — на сколько изменится
+// баланс кошелька с учётом комиссии.
+type FeeQuote struct {
+	OperationType string          `json:"operationType"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency"`
+	Fee           decimal.Decimal `json:"fee"`
+	BalanceChange decimal.Decimal `json:"balanceChange"`
+}
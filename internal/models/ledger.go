@@ -0,0 +1,107 @@
+package models
+
+import (
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Системные счета главной книги ведутся отдельно по каждой валюте (см. SystemAccountID).
+// Их балансы не кэшируются и считаются по проводкам.
+const (
+	SystemExternalCashIn  = "external_cash_in"
+	SystemExternalCashOut = "external_cash_out"
+	SystemFees            = "fees"
+	// SystemFXPosition — валютная позиция сервиса: через неё проходят конвертации.
+	SystemFXPosition = "fx_position"
+)
+
+const (
+	walletAccountPrefix = "wallet:"
+	systemAccountPrefix = "system:"
+)
+
+// SystemAccountID возвращает идентификатор системного счёта в валюте currency.
+func SystemAccountID(name, currency string) string {
+	return systemAccountPrefix + name + ":" + currency
+}
+
+// WalletAccountID возвращает идентификатор счёта главной книги, закреплённого за кошельком.
+func WalletAccountID(walletID uuid.UUID) string {
+	return walletAccountPrefix + walletID.String()
+}
+
+// ParseWalletAccountID возвращает кошелёк счёта или false, если счёт системный.
+func ParseWalletAccountID(accountID string) (uuid.UUID, bool) {
+	raw, ok := strings.CutPrefix(accountID, walletAccountPrefix)
+	if !ok {
+		return uuid.Nil, false
+	}
+	id, err := uuid.Parse(raw)
+	if err != nil {
+		return uuid.Nil, false
+	}
+	return id, true
+}
+
+// Posting — проводка по одному счёту. Положительная сумма — кредит счёта
+// (увеличивает баланс кошелька), отрицательная — дебет.
+type Posting struct {
+	ID        int64           `db:"id" json:"id"`
+	EntryID   int64           `db:"entry_id" json:"entryId"`
+	AccountID string          `db:"account_id" json:"accountId"`
+	Amount    decimal.Decimal `db:"amount" json:"amount"`
+	Currency  string          `db:"currency" json:"currency"`
+	CreatedAt time.Time       `db:"created_at" json:"createdAt"`
+	// JournalType переопределяет тип строки журнала для проводки по кошельку
+	// (например, FEE для комиссии внутри пополнения).
+	JournalType string `db:"-" json:"-"`
+	// ReversalOf — строка журнала, которую сторнирует проводка, если это не исходная операция
+	// сторно (возврат комиссии ссылается на строку FEE).
+	ReversalOf *int64 `db:"-" json:"-"`
+}
+
+// LedgerEntry — запись двойной бухгалтерии. Сумма её проводок в каждой валюте равна нулю.
+type LedgerEntry struct {
+	ID        int64     `json:"id"`
+	Type      string    `json:"type"`
+	Postings  []Posting `json:"postings"`
+	CreatedAt time.Time `json:"createdAt"`
+}
+
+// IsBalanced сообщает, что проводок не меньше двух, они ненулевые и в каждой валюте дают ноль.
+func (e LedgerEntry) IsBalanced() bool {
+	if len(e.Postings) < 2 {
+		return false
+	}
+	sums := make(map[string]decimal.Decimal)
+	for _, p := range e.Postings {
+		if p.Amount.IsZero() || p.Currency == "" {
+			return false
+		}
+		sums[p.Currency] = sums[p.Currency].Add(p.Amount)
+	}
+	for _, sum := range sums {
+		if !sum.IsZero() {
+			return false
+		}
+	}
+	return true
+}
+
+type AccountBalance struct {
+	AccountID string          `db:"account_id" json:"accountId"`
+	Currency  string          `db:"currency" json:"currency"`
+	Balance   decimal.Decimal `db:"balance" json:"balance"`
+}
+
+// TrialBalance — оборотно-сальдовая ведомость по всем счетам. Книги сходятся,
+// если итог по каждой валюте равен нулю и нет несбалансированных записей.
+type TrialBalance struct {
+	Accounts          []AccountBalance           `json:"accounts"`
+	Totals            map[string]decimal.Decimal `json:"totals"`
+	UnbalancedEntries []int64                    `json:"unbalancedEntries"`
+	Balanced          bool                       `json:"balanced"`
+}
@@ -0,0 +1,62 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/shopspring/decimal"
+)
+
+// DefaultLimitTier — тариф лимитов для кошельков, которым тариф не назначен.
+const DefaultLimitTier = "default"
+
+// Виды лимитов, которые может нарушить операция.
+const (
+	LimitMaxSingleAmount = "max_single_amount"
+	LimitDailyCap        = "daily_cap"
+	LimitWeeklyCap       = "weekly_cap"
+	LimitMonthlyCap      = "monthly_cap"
+	LimitMaxOperations   = "max_operations"
+)
+
+var ErrInvalidLimit = errors.New("invalid spending limit")
+
+// SpendingLimit — лимиты одного типа операций; пустое поле означает отсутствие ограничения.
+type SpendingLimit struct {
+	Operation               string           `db:"operation" json:"operation" binding:"required,oneof=DEPOSIT WITHDRAW"`
+	MaxSingleAmount         *decimal.Decimal `db:"max_single_amount" json:"maxSingleAmount,omitempty"`
+	DailyCap                *decimal.Decimal `db:"daily_cap" json:"dailyCap,omitempty"`
+	WeeklyCap               *decimal.Decimal `db:"weekly_cap" json:"weeklyCap,omitempty"`
+	MonthlyCap              *decimal.Decimal `db:"monthly_cap" json:"monthlyCap,omitempty"`
+	MaxOperations           *int             `db:"max_operations" json:"maxOperations,omitempty"`
+	OperationsWindowSeconds *int             `db:"operations_window_seconds" json:"operationsWindowSeconds,omitempty"`
+}
+
+// Validate проверяет, что суммы положительные, а лимит числа операций задан вместе с окном.
+func (l SpendingLimit) Validate() error {
+	if l.Operation != "DEPOSIT" && l.Operation != "WITHDRAW" {
+		return fmt.Errorf("%w: operation must be DEPOSIT or WITHDRAW", ErrInvalidLimit)
+	}
+	for _, amount := range []*decimal.Decimal{l.MaxSingleAmount, l.DailyCap, l.WeeklyCap, l.MonthlyCap} {
+		if amount != nil && !amount.IsPositive() {
+			return fmt.Errorf("%w: amounts must be positive", ErrInvalidLimit)
+		}
+	}
+	if (l.MaxOperations == nil) != (l.OperationsWindowSeconds == nil) {
+		return fmt.Errorf("%w: maxOperations and operationsWindowSeconds must be set together", ErrInvalidLimit)
+	}
+	if l.MaxOperations != nil && (*l.MaxOperations <= 0 || *l.OperationsWindowSeconds <= 0) {
+		return fmt.Errorf("%w: maxOperations and operationsWindowSeconds must be positive", ErrInvalidLimit)
+	}
+	return nil
+}
+
+// LimitTierRequest — лимиты тарифа для одной валюты.
+type LimitTierRequest struct {
+	Currency string `json:"currency" binding:"required,len=3"`
+	SpendingLimit
+}
+
+type WalletTierRequest struct {
+	Tier string `json:"tier" binding:"max=63"`
+}
@@ -0,0 +1,117 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// IdempotentResponse — сохранённый ответ на операцию, который возвращается при повторе запроса.
+type IdempotentResponse struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Idempotency связывает операцию с ключом идемпотентности клиента.
+type Idempotency struct {
+	Key         string
+	RequestHash string
+	// Response строит ответ, который сохраняется в одной транзакции с изменением баланса.
+	Response func(result OperationResult) IdempotentResponse
+	// Replayed заполняется, если операция с этим ключом уже была выполнена ранее.
+	Replayed *IdempotentResponse
+}
+
+// OperationResult — итог успешной операции, из которого строится сохраняемый ответ.
+type OperationResult struct {
+	Balance    decimal.Decimal
+	Created    bool
+	Fee        decimal.Decimal
+	Transfer   *TransferResult
+	Conversion *ConversionResult
+	Reversal   *ReversalResult
+	Hold       *HoldResult
+}
+
+// OperationOptions — необязательные параметры операции над балансом.
+type OperationOptions struct {
+	Idempotency *Idempotency
+	// Currency — ожидаемая валюта кошелька; пустая строка означает «любая».
+	// Для создаваемого пополнением кошелька задаёт его валюту.
+	Currency string
+	// Fee, если задан, получает комиссию, списанную операцией (как Idempotency.Replayed —
+	// ответ при повторе).
+	Fee *decimal.Decimal
+	// Owner — владелец кошелька, создаваемого пополнением.
+	Owner string
+}
+
+type OperationOption func(*OperationOptions)
+
+func WithIdempotency(idem *Idempotency) OperationOption {
+	return func(o *OperationOptions) {
+		o.Idempotency = idem
+	}
+}
+
+func WithCurrency(currency string) OperationOption {
+	return func(o *OperationOptions) {
+		o.Currency = currency
+	}
+}
+
+// WithFeeReport просит сообщить в fee комиссию, удержанную операцией.
+func WithFeeReport(fee *decimal.Decimal) OperationOption {
+	return func(o *OperationOptions) {
+		o.Fee = fee
+	}
+}
+
+// WithOwner задаёт владельца кошелька, если пополнение его создаёт.
+func WithOwner(owner string) OperationOption {
+	return func(o *OperationOptions) {
+		o.Owner = owner
+	}
+}
+
+func NewOperationOptions(opts ...OperationOption) OperationOptions {
+	var o OperationOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+// Hash возвращает отпечаток запроса, по которому повтор отличается от нового запроса с тем же ключом.
+func (r WalletRequest) Hash() string {
+	sum := sha256.Sum256([]byte(r.WalletID.String() + "|" + r.OperationType + "|" + r.Amount.String() + "|" + r.Currency))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r TransferRequest) Hash() string {
+	sum := sha256.Sum256([]byte("TRANSFER|" + r.FromWalletID.String() + "|" + r.ToWalletID.String() + "|" + r.Amount.String() + "|" + r.Currency))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r ConversionRequest) Hash() string {
+	sum := sha256.Sum256([]byte("CONVERSION|" + r.QuoteID.String() + "|" + r.FromWalletID.String() + "|" +
+		r.ToWalletID.String() + "|" + r.Amount.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r HoldRequest) Hash(walletID uuid.UUID) string {
+	sum := sha256.Sum256([]byte("HOLD|" + walletID.String() + "|" + r.Amount.String() + "|" + strconv.Itoa(r.TTLSeconds)))
+	return hex.EncodeToString(sum[:])
+}
+
+func (r ReversalRequest) Hash(transactionID int64) string {
+	amount := "FULL"
+	if r.Amount != nil {
+		amount = r.Amount.String()
+	}
+	sum := sha256.Sum256([]byte("REVERSAL|" + strconv.FormatInt(transactionID, 10) + "|" + amount))
+	return hex.EncodeToString(sum[:])
+}
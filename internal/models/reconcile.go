@@ -0,0 +1,44 @@
+package models
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Проверки сверки главной книги.
+const (
+	// CheckBalanceDrift — wallets.balance не равен сумме операций журнала transactions.
+	CheckBalanceDrift = "balance_drift"
+	// CheckLedgerDrift — wallets.balance не равен сумме проводок по счёту кошелька.
+	CheckLedgerDrift = "ledger_drift"
+	// CheckNegativeBalance — баланс ниже минус лимита овердрафта.
+	CheckNegativeBalance = "negative_balance"
+	// CheckOrphanedTransaction — строка журнала ссылается на запись книги без проводки по кошельку.
+	CheckOrphanedTransaction = "orphaned_transaction"
+	// CheckUnbalancedTransfer — ноги перевода не в сумме ноль или их не две.
+	CheckUnbalancedTransfer = "unbalanced_transfer"
+)
+
+// ReconciliationIssue — нарушение инварианта. Expected и Actual заполняются для расхождений
+// сумм: ожидаемое значение по журналу и фактическое.
+type ReconciliationIssue struct {
+	Check         string           `db:"-" json:"check"`
+	WalletID      *uuid.UUID       `db:"wallet_id" json:"walletId,omitempty"`
+	TransactionID *int64           `db:"transaction_id" json:"transactionId,omitempty"`
+	TransferID    *uuid.UUID       `db:"transfer_id" json:"transferId,omitempty"`
+	Expected      *decimal.Decimal `db:"expected" json:"expected,omitempty"`
+	Actual        *decimal.Decimal `db:"actual" json:"actual,omitempty"`
+	Details       string           `db:"details" json:"details,omitempty"`
+}
+
+// ReconciliationReport — результат сверки по согласованному снимку БД.
+type ReconciliationReport struct {
+	StartedAt           time.Time             `json:"startedAt"`
+	FinishedAt          time.Time             `json:"finishedAt"`
+	WalletsChecked      int64                 `json:"walletsChecked"`
+	TransactionsChecked int64                 `json:"transactionsChecked"`
+	OK                  bool                  `json:"ok"`
+	Issues              []ReconciliationIssue `json:"issues"`
+}
@@ -9,4 +9,74 @@ type WalletRequest struct {
 	WalletID      uuid.UUID       `json:"walletId" binding:"required"`
 	OperationType string          `json:"operationType" binding:"required,oneof=DEPOSIT WITHDRAW"`
 	Amount        decimal.Decimal `json:"amount" binding:"required"`
+	Currency      string          `json:"currency,omitempty" binding:"omitempty,len=3"`
+	RequestID     string          `json:"requestId,omitempty" binding:"max=255"`
+}
+
+type TransferRequest struct {
+	FromWalletID uuid.UUID       `json:"fromWalletId" binding:"required"`
+	ToWalletID   uuid.UUID       `json:"toWalletId" binding:"required"`
+	Amount       decimal.Decimal `json:"amount" binding:"required"`
+	Currency     string          `json:"currency,omitempty" binding:"omitempty,len=3"`
+	RequestID    string          `json:"requestId,omitempty" binding:"max=255"`
+}
+
+type ConversionQuoteRequest struct {
+	FromWalletID uuid.UUID       `json:"fromWalletId" binding:"required"`
+	ToWalletID   uuid.UUID       `json:"toWalletId" binding:"required"`
+	Amount       decimal.Decimal `json:"amount" binding:"required"`
+}
+
+// ConversionRequest исполняет ранее полученную котировку (QuoteID) либо конвертирует
+// Amount по текущему курсу, если котировка не указана.
+type ConversionRequest struct {
+	QuoteID      uuid.UUID       `json:"quoteId"`
+	FromWalletID uuid.UUID       `json:"fromWalletId"`
+	ToWalletID   uuid.UUID       `json:"toWalletId"`
+	Amount       decimal.Decimal `json:"amount"`
+	RequestID    string          `json:"requestId,omitempty" binding:"max=255"`
+}
+
+type ReversalRequest struct {
+	// Amount — сумма сторно; если не указана, сторнируется весь несторнированный остаток.
+	Amount    *decimal.Decimal `json:"amount"`
+	RequestID string           `json:"requestId,omitempty" binding:"max=255"`
+}
+
+// CreateWalletRequest — явное создание кошелька. Если WalletID не указан, он генерируется,
+// если не указана Currency — используется валюта по умолчанию.
+type CreateWalletRequest struct {
+	WalletID *uuid.UUID        `json:"walletId"`
+	Currency string            `json:"currency,omitempty" binding:"omitempty,len=3"`
+	OwnerID  string            `json:"ownerId" binding:"max=255"`
+	Name     string            `json:"name" binding:"max=255"`
+	Labels   map[string]string `json:"labels"`
+}
+
+// UpdateWalletRequest — частичное обновление метаданных: nil-поля не меняются,
+// Labels заменяются целиком.
+type UpdateWalletRequest struct {
+	OwnerID *string            `json:"ownerId" binding:"omitempty,max=255"`
+	Name    *string            `json:"name" binding:"omitempty,max=255"`
+	Labels  *map[string]string `json:"labels"`
+}
+
+type OverdraftLimitRequest struct {
+	Limit  decimal.Decimal `json:"limit" binding:"required"`
+	Reason string          `json:"reason" binding:"max=255"`
+}
+
+type WalletStatusRequest struct {
+	Reason string `json:"reason" binding:"max=255"`
+}
+
+type HoldRequest struct {
+	Amount     decimal.Decimal `json:"amount" binding:"required"`
+	TTLSeconds int             `json:"ttlSeconds" binding:"min=0"`
+	RequestID  string          `json:"requestId,omitempty" binding:"max=255"`
+}
+
+type CaptureRequest struct {
+	// Amount — сумма списания; если не указана, списывается весь холд.
+	Amount *decimal.Decimal `json:"amount"`
 }
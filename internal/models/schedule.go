@@ -0,0 +1,88 @@
+package models
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strconv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// Статусы запланированной операции. ACTIVE — ждёт очередного срабатывания, COMPLETED —
+// разовая операция исполнена, FAILED — разовая операция исчерпала попытки.
+const (
+	ScheduleActive    = "ACTIVE"
+	ScheduleCompleted = "COMPLETED"
+	ScheduleCancelled = "CANCELLED"
+	ScheduleFailed    = "FAILED"
+)
+
+const (
+	RunSucceeded = "SUCCEEDED"
+	RunFailed    = "FAILED"
+)
+
+const DefaultScheduleMaxAttempts = 3
+
+var ErrInvalidSchedule = errors.New("invalid schedule")
+
+// ScheduledOperation — отложенное (RunAt) или регулярное (Cron) пополнение, списание или перевод.
+type ScheduledOperation struct {
+	ID           uuid.UUID       `db:"id" json:"id"`
+	Operation    string          `db:"operation" json:"operation"`
+	WalletID     uuid.UUID       `db:"wallet_id" json:"walletId"`
+	ToWalletID   *uuid.UUID      `db:"to_wallet_id" json:"toWalletId,omitempty"`
+	Amount       decimal.Decimal `db:"amount" json:"amount"`
+	Currency     *string         `db:"currency" json:"currency,omitempty"`
+	Cron         *string         `db:"cron" json:"cron,omitempty"`
+	Status       string          `db:"status" json:"status"`
+	OccurrenceAt time.Time       `db:"occurrence_at" json:"occurrenceAt"`
+	NextRunAt    time.Time       `db:"next_run_at" json:"nextRunAt"`
+	Attempts     int             `db:"attempts" json:"attempts"`
+	MaxAttempts  int             `db:"max_attempts" json:"maxAttempts"`
+	LastError    *string         `db:"last_error" json:"lastError,omitempty"`
+	CreatedAt    time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt    time.Time       `db:"updated_at" json:"updatedAt"`
+}
+
+// IdempotencyKey — ключ идемпотентности срабатывания occurrence: повторное исполнение того же
+// срабатывания (после сбоя или на другой реплике) не проводит операцию второй раз.
+func (o ScheduledOperation) IdempotencyKey(occurrence time.Time) string {
+	return "scheduled:" + o.ID.String() + ":" + strconv.FormatInt(occurrence.Unix(), 10)
+}
+
+// RequestHash — отпечаток операции для ключа идемпотентности срабатывания.
+func (o ScheduledOperation) RequestHash() string {
+	to := ""
+	if o.ToWalletID != nil {
+		to = o.ToWalletID.String()
+	}
+	sum := sha256.Sum256([]byte("SCHEDULED|" + o.Operation + "|" + o.WalletID.String() + "|" + to + "|" + o.Amount.String()))
+	return hex.EncodeToString(sum[:])
+}
+
+// ScheduledRun — попытка исполнения срабатывания запланированной операции.
+type ScheduledRun struct {
+	ID                   int64     `db:"id" json:"id"`
+	ScheduledOperationID uuid.UUID `db:"scheduled_operation_id" json:"scheduledOperationId"`
+	OccurrenceAt         time.Time `db:"occurrence_at" json:"occurrenceAt"`
+	Attempt              int       `db:"attempt" json:"attempt"`
+	Status               string    `db:"status" json:"status"`
+	Error                *string   `db:"error" json:"error,omitempty"`
+	CreatedAt            time.Time `db:"created_at" json:"createdAt"`
+}
+
+// ScheduleRequest — создание запланированной операции: разовой (RunAt) или регулярной (Cron).
+type ScheduleRequest struct {
+	Operation   string          `json:"operation" binding:"required,oneof=DEPOSIT WITHDRAW TRANSFER"`
+	WalletID    uuid.UUID       `json:"walletId" binding:"required"`
+	ToWalletID  *uuid.UUID      `json:"toWalletId"`
+	Amount      decimal.Decimal `json:"amount" binding:"required"`
+	Currency    string          `json:"currency,omitempty" binding:"omitempty,len=3"`
+	RunAt       *time.Time      `json:"runAt"`
+	Cron        string          `json:"cron,omitempty" binding:"max=255"`
+	MaxAttempts int             `json:"maxAttempts,omitempty" binding:"omitempty,min=1,max=20"`
+}
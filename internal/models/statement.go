@@ -0,0 +1,41 @@
+package models
+
+import (
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	StatementCSV    = "csv"
+	StatementNDJSON = "ndjson"
+	StatementOFX    = "ofx"
+)
+
+var ErrInvalidStatementRange = errors.New("statement range is invalid")
+
+// Statement — выписка по кошельку за период [From, To): остаток на начало и на конец периода.
+// ClosingBalance известен только после всех строк и заполняется для WriteFooter.
+type Statement struct {
+	WalletID       uuid.UUID       `json:"walletId"`
+	Currency       string          `json:"currency"`
+	From           time.Time       `json:"from"`
+	To             time.Time       `json:"to"`
+	OpeningBalance decimal.Decimal `json:"openingBalance"`
+	ClosingBalance decimal.Decimal `json:"closingBalance"`
+}
+
+// StatementLine — операция выписки с нарастающим остатком после неё.
+type StatementLine struct {
+	Transaction
+	RunningBalance decimal.Decimal `json:"runningBalance"`
+}
+
+// StatementWriter выводит выписку по мере чтения операций из журнала.
+type StatementWriter interface {
+	WriteHeader(s Statement) error
+	WriteLine(line StatementLine) error
+	WriteFooter(s Statement) error
+}
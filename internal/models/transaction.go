@@ -0,0 +1,69 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+const (
+	DefaultTransactionsLimit = 50
+	MaxTransactionsLimit     = 200
+)
+
+var ErrInvalidCursor = errors.New("invalid cursor")
+
+// JournalTypes — типы строк журнала transactions.
+var JournalTypes = []string{"OPENING_BALANCE", "DEPOSIT", "WITHDRAW", "TRANSFER_IN", "TRANSFER_OUT", "CAPTURE", "CONVERSION_IN", "CONVERSION_OUT", "REVERSAL", "FEE"}
+
+// TransactionCursor — позиция в истории операций для keyset-пагинации по (created_at, id).
+type TransactionCursor struct {
+	CreatedAt time.Time
+	ID        int64
+}
+
+func (c TransactionCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + strconv.FormatInt(c.ID, 10)
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeTransactionCursor(s string) (*TransactionCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	txID, err := strconv.ParseInt(id, 10, 64)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &TransactionCursor{CreatedAt: createdAt, ID: txID}, nil
+}
+
+// TransactionFilter описывает выборку истории операций. Операции отдаются от новых к старым,
+// MinAmount/MaxAmount сравниваются с модулем суммы, From включительно, To — не включительно.
+type TransactionFilter struct {
+	OperationType string
+	MinAmount     *decimal.Decimal
+	MaxAmount     *decimal.Decimal
+	From          *time.Time
+	To            *time.Time
+	Cursor        *TransactionCursor
+	Limit         int
+}
+
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
+}
@@ -0,0 +1,84 @@
+package models
+
+import (
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+const (
+	DefaultWalletsLimit = 50
+	MaxWalletsLimit     = 200
+
+	MaxWalletLabels     = 20
+	MaxLabelKeyLength   = 63
+	MaxLabelValueLength = 255
+)
+
+var ErrInvalidLabels = errors.New("invalid labels")
+
+// WalletCursor — позиция в списке кошельков для keyset-пагинации по (created_at, id).
+type WalletCursor struct {
+	CreatedAt time.Time
+	ID        uuid.UUID
+}
+
+func (c WalletCursor) Encode() string {
+	raw := c.CreatedAt.UTC().Format(time.RFC3339Nano) + "|" + c.ID.String()
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func DecodeWalletCursor(s string) (*WalletCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(s)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	ts, id, ok := strings.Cut(string(raw), "|")
+	if !ok {
+		return nil, ErrInvalidCursor
+	}
+	createdAt, err := time.Parse(time.RFC3339Nano, ts)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	walletID, err := uuid.Parse(id)
+	if err != nil {
+		return nil, ErrInvalidCursor
+	}
+	return &WalletCursor{CreatedAt: createdAt, ID: walletID}, nil
+}
+
+// WalletFilter описывает выборку кошельков. Кошельки отдаются от новых к старым;
+// Labels отбирает кошельки, у которых есть все перечисленные метки с такими значениями.
+type WalletFilter struct {
+	OwnerID string
+	Status  string
+	Labels  map[string]string
+	Cursor  *WalletCursor
+	Limit   int
+}
+
+type WalletPage struct {
+	Wallets    []Wallet `json:"wallets"`
+	NextCursor string   `json:"nextCursor,omitempty"`
+}
+
+// ValidateLabels проверяет число меток и длину ключей и значений.
+func ValidateLabels(labels map[string]string) error {
+	if len(labels) > MaxWalletLabels {
+		return fmt.Errorf("%w: at most %d labels allowed", ErrInvalidLabels, MaxWalletLabels)
+	}
+	for k, v := range labels {
+		if k == "" || len(k) > MaxLabelKeyLength {
+			return fmt.Errorf("%w: key %q must be 1-%d characters", ErrInvalidLabels, k, MaxLabelKeyLength)
+		}
+		if len(v) > MaxLabelValueLength {
+			return fmt.Errorf("%w: value of %q must be at most %d characters", ErrInvalidLabels, k, MaxLabelValueLength)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,98 @@
+package models
+
+import (
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// События, на которые можно подписать вебхук.
+const (
+	WebhookDeposit      = "deposit"
+	WebhookWithdraw     = "withdraw"
+	WebhookLowBalance   = "low_balance"
+	WebhookWalletFrozen = "wallet_frozen"
+)
+
+// Статусы доставки вебхука. DEAD — попытки исчерпаны, доставку можно перезапустить через API.
+const (
+	DeliveryPending   = "PENDING"
+	DeliveryDelivered = "DELIVERED"
+	DeliveryDead      = "DEAD"
+)
+
+var (
+	ErrInvalidWebhook      = errors.New("invalid webhook")
+	ErrWebhookAccessDenied = errors.New("webhook belongs to another owner")
+)
+
+// WebhookSubscription — подписка владельца кошельков на события.
+type WebhookSubscription struct {
+	ID                  uuid.UUID        `db:"id" json:"id"`
+	OwnerID             string           `db:"owner_id" json:"ownerId"`
+	WalletID            *uuid.UUID       `db:"wallet_id" json:"walletId,omitempty"`
+	URL                 string           `db:"url" json:"url"`
+	Secret              string           `db:"-" json:"secret,omitempty"`
+	Events              []string         `db:"events" json:"events"`
+	LowBalanceThreshold *decimal.Decimal `db:"low_balance_threshold" json:"lowBalanceThreshold,omitempty"`
+	Active              bool             `db:"active" json:"active"`
+	CreatedAt           time.Time        `db:"created_at" json:"createdAt"`
+	UpdatedAt           time.Time        `db:"updated_at" json:"updatedAt"`
+}
+
+// Subscribed сообщает, подписан ли вебхук на событие event.
+func (s WebhookSubscription) Subscribed(event string) bool {
+	for _, e := range s.Events {
+		if e == event {
+			return true
+		}
+	}
+	return false
+}
+
+// WebhookRequest — создание подписки. low_balance требует LowBalanceThreshold: событие
+// приходит, когда операция опускает баланс ниже порога. OwnerID по умолчанию — вызывающий.
+type WebhookRequest struct {
+	OwnerID             string           `json:"ownerId" binding:"max=255"`
+	WalletID            *uuid.UUID       `json:"walletId"`
+	URL                 string           `json:"url" binding:"required,url,max=2048"`
+	Events              []string         `json:"events" binding:"required,min=1,dive,oneof=deposit withdraw low_balance wallet_frozen"`
+	LowBalanceThreshold *decimal.Decimal `json:"lowBalanceThreshold"`
+}
+
+// WebhookDelivery — доставка события подписке. URL и Secret подставляются из подписки
+// для отправки и наружу не отдаются.
+type WebhookDelivery struct {
+	ID             int64           `db:"id" json:"id"`
+	SubscriptionID uuid.UUID       `db:"subscription_id" json:"subscriptionId"`
+	EventID        uuid.UUID       `db:"event_id" json:"eventId"`
+	Event          string          `db:"event" json:"event"`
+	Payload        json.RawMessage `db:"payload" json:"payload"`
+	Status         string          `db:"status" json:"status"`
+	Attempts       int             `db:"attempts" json:"attempts"`
+	NextAttemptAt  time.Time       `db:"next_attempt_at" json:"nextAttemptAt"`
+	LastStatusCode *int            `db:"last_status_code" json:"lastStatusCode,omitempty"`
+	LastError      *string         `db:"last_error" json:"lastError,omitempty"`
+	CreatedAt      time.Time       `db:"created_at" json:"createdAt"`
+	UpdatedAt      time.Time       `db:"updated_at" json:"updatedAt"`
+	DeliveredAt    *time.Time      `db:"delivered_at" json:"deliveredAt,omitempty"`
+	URL            string          `db:"-" json:"-"`
+	Secret         string          `db:"-" json:"-"`
+}
+
+// WebhookPayload — тело POST-запроса вебхука.
+type WebhookPayload struct {
+	EventID    uuid.UUID       `json:"eventId"`
+	Event      string          `json:"event"`
+	WalletID   uuid.UUID       `json:"walletId"`
+	OccurredAt time.Time       `json:"occurredAt"`
+	Data       json.RawMessage `json:"data"`
+}
+
+const (
+	DefaultWebhookDeliveriesLimit = 50
+	MaxWebhookDeliveriesLimit     = 200
+)
@@ -0,0 +1,35 @@
+// Package poller — общий каркас фоновых обработчиков (планировщик, outbox relay, диспетчер
+// вебхуков): периодический опрос и экспоненциальная задержка повторов.
+package poller
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// Run вызывает tick каждые interval до отмены ctx. Ошибка прохода логируется с сообщением msg
+// и не прерывает цикл: следующий проход повторит необработанное.
+func Run(ctx context.Context, interval time.Duration, logger *slog.Logger, msg string, tick func(context.Context) (int, error)) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := tick(ctx); err != nil {
+				logger.Error(msg, slog.Any("err", err))
+			}
+		}
+	}
+}
+
+// Backoff возвращает задержку перед попыткой attempt+1: base, 2·base, 4·base..., но не больше maxDelay.
+func Backoff(base, maxDelay time.Duration, attempt int) time.Duration {
+	delay := base
+	for i := 1; i < attempt && delay < maxDelay; i++ {
+		delay *= 2
+	}
+	return min(delay, maxDelay)
+}
@@ -0,0 +1,71 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"test_wallet/internal/models"
+	"time"
+)
+
+const defaultHTTPTimeout = 5 * time.Second
+
+// HTTPProvider запрашивает курс у внешнего сервиса: GET <baseURL>?from=USD&to=RUB.
+type HTTPProvider struct {
+	baseURL string
+	client  *http.Client
+}
+
+// NewHTTPProvider создаёт провайдер; при client == nil используется клиент с таймаутом 5 секунд.
+func NewHTTPProvider(baseURL string, client *http.Client) *HTTPProvider {
+	if client == nil {
+		client = &http.Client{Timeout: defaultHTTPTimeout}
+	}
+	return &HTTPProvider{baseURL: baseURL, client: client}
+}
+
+func (p *HTTPProvider) Rate(ctx context.Context, from, to string) (models.Rate, error) {
+	u, err := url.Parse(p.baseURL)
+	if err != nil {
+		return models.Rate{}, err
+	}
+	q := u.Query()
+	q.Set("from", from)
+	q.Set("to", to)
+	u.RawQuery = q.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u.String(), nil)
+	if err != nil {
+		return models.Rate{}, err
+	}
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return models.Rate{}, fmt.Errorf("fetch rate %s/%s: %w", from, to, err)
+	}
+	defer resp.Body.Close()
+
+	switch resp.StatusCode {
+	case http.StatusOK:
+	case http.StatusNotFound:
+		return models.Rate{}, fmt.Errorf("%w: %s/%s", models.ErrRateNotFound, from, to)
+	default:
+		return models.Rate{}, fmt.Errorf("fetch rate %s/%s: unexpected status %d", from, to, resp.StatusCode)
+	}
+
+	var rate models.Rate
+	if err := json.NewDecoder(resp.Body).Decode(&rate); err != nil {
+		return models.Rate{}, fmt.Errorf("decode rate %s/%s: %w", from, to, err)
+	}
+	if rate.From != from || rate.To != to {
+		return models.Rate{}, fmt.Errorf("rate provider returned %s/%s for %s/%s", rate.From, rate.To, from, to)
+	}
+	if !rate.Value.IsPositive() {
+		return models.Rate{}, fmt.Errorf("rate provider returned non-positive rate %s for %s/%s", rate.Value, from, to)
+	}
+	if rate.Timestamp.IsZero() {
+		return models.Rate{}, fmt.Errorf("rate provider returned no timestamp for %s/%s", from, to)
+	}
+	return rate, nil
+}
@@ -0,0 +1,63 @@
+package rates
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/shopspring/decimal"
+)
+
+// inverseRatePrecision — число знаков обратного курса, вычисленного как 1/rate.
+const inverseRatePrecision = 12
+
+// StaticProvider отдаёт курсы из JSON-файла, загруженного при старте:
+//
+//	{
+//	  "timestamp": "2026-10-01T00:00:00Z",
+//	  "rates": {"USD": {"RUB": "92.50", "EUR": "0.92"}}
+//	}
+//
+// Если прямого курса нет, используется обратный.
+type StaticProvider struct {
+	timestamp time.Time
+	rates     map[string]map[string]decimal.Decimal
+}
+
+type staticFile struct {
+	Timestamp time.Time                             `json:"timestamp"`
+	Rates     map[string]map[string]decimal.Decimal `json:"rates"`
+}
+
+func NewStaticProvider(path string) (*StaticProvider, error) {
+	raw, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var file staticFile
+	if err := json.Unmarshal(raw, &file); err != nil {
+		return nil, fmt.Errorf("parse rates file %s: %w", path, err)
+	}
+	for from, quotes := range file.Rates {
+		for to, rate := range quotes {
+			if !rate.IsPositive() {
+				return nil, fmt.Errorf("rates file %s: rate %s/%s must be positive", path, from, to)
+			}
+		}
+	}
+	return &StaticProvider{timestamp: file.Timestamp, rates: file.Rates}, nil
+}
+
+func (p *StaticProvider) Rate(_ context.Context, from, to string) (models.Rate, error) {
+	if rate, ok := p.rates[from][to]; ok {
+		return models.Rate{From: from, To: to, Value: rate, Timestamp: p.timestamp}, nil
+	}
+	if rate, ok := p.rates[to][from]; ok {
+		inverse := decimal.NewFromInt(1).DivRound(rate, inverseRatePrecision)
+		return models.Rate{From: from, To: to, Value: inverse, Timestamp: p.timestamp}, nil
+	}
+	return models.Rate{}, fmt.Errorf("%w: %s/%s", models.ErrRateNotFound, from, to)
+}
@@ -0,0 +1,32 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+)
+
+// tryAdvisoryLock берёт сессионную advisory-блокировку по ключу без ожидания.
+func (r *WalletPGRepository) tryAdvisoryLock(ctx context.Context, key string) (unlock func(), ok bool, err error) {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return nil, false, err
+	}
+	if err := conn.QueryRow(ctx, "SELECT pg_try_advisory_lock(hashtextextended($1, 0))", key).Scan(&ok); err != nil {
+		conn.Release()
+		r.logger.Error("Failed to take advisory lock", slog.String("key", key), slog.Any("err", err))
+		return nil, false, err
+	}
+	if !ok {
+		conn.Release()
+		return nil, false, nil
+	}
+	return func() {
+		// Контекст вызова мог уже завершиться, а блокировку нужно снять в любом случае
+		if _, err := conn.Exec(context.Background(), "SELECT pg_advisory_unlock(hashtextextended($1, 0))", key); err != nil {
+			r.logger.Error("Failed to release advisory lock", slog.String("key", key), slog.Any("err", err))
+			// Соединение с висящей блокировкой нельзя возвращать в пул
+			_ = conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}, true, nil
+}
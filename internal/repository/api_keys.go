@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrAPIKeyExists   = errors.New("api key with this name already exists")
+)
+
+const apiKeyColumns = `id, name, key_hash, scopes, created_at, revoked_at`
+
+// CreateAPIKey сохраняет ключ; key.KeyHash — SHA-256 ключа, см. auth.HashAPIKey.
+func (r *WalletPGRepository) CreateAPIKey(ctx context.Context, key models.APIKey) (models.APIKey, error) {
+	if key.Scopes == nil {
+		key.Scopes = []string{}
+	}
+	rows, err := r.pool.Query(ctx, `
+		INSERT INTO api_keys (id, name, key_hash, scopes) VALUES ($1, $2, $3, $4)
+		RETURNING `+apiKeyColumns,
+		key.ID, key.Name, key.KeyHash, key.Scopes)
+	if err != nil {
+		r.logger.Error("Failed to insert api key", slog.String("name", key.Name), slog.Any("err", err))
+		return models.APIKey{}, err
+	}
+	created, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.APIKey])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return models.APIKey{}, ErrAPIKeyExists
+		}
+		r.logger.Error("Failed to insert api key", slog.String("name", key.Name), slog.Any("err", err))
+		return models.APIKey{}, err
+	}
+	return created, nil
+}
+
+// GetAPIKeyByHash возвращает действующий (не отозванный) ключ по хэшу.
+func (r *WalletPGRepository) GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error) {
+	rows, err := r.pool.Query(ctx,
+		"SELECT "+apiKeyColumns+" FROM api_keys WHERE key_hash = $1 AND revoked_at IS NULL", hash)
+	if err != nil {
+		r.logger.Error("Failed to query api key", slog.Any("err", err))
+		return models.APIKey{}, err
+	}
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.APIKey])
+	if err == pgx.ErrNoRows {
+		return key, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan api key", slog.Any("err", err))
+	}
+	return key, err
+}
+
+// ListAPIKeys возвращает все ключи, включая отозванные, от новых к старым.
+func (r *WalletPGRepository) ListAPIKeys(ctx context.Context) ([]models.APIKey, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+apiKeyColumns+" FROM api_keys ORDER BY created_at DESC, id")
+	if err != nil {
+		r.logger.Error("Failed to query api keys", slog.Any("err", err))
+		return nil, err
+	}
+	keys, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.APIKey])
+	if err != nil {
+		r.logger.Error("Failed to scan api keys", slog.Any("err", err))
+		return nil, err
+	}
+	if keys == nil {
+		keys = []models.APIKey{}
+	}
+	return keys, nil
+}
+
+// RevokeAPIKey отзывает ключ; повторный отзыв не меняет revoked_at.
+func (r *WalletPGRepository) RevokeAPIKey(ctx context.Context, id uuid.UUID) (models.APIKey, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE api_keys SET revoked_at = COALESCE(revoked_at, NOW())
+		WHERE id = $1
+		RETURNING `+apiKeyColumns, id)
+	if err != nil {
+		r.logger.Error("Failed to revoke api key", slog.String("api_key_id", id.String()), slog.Any("err", err))
+		return models.APIKey{}, err
+	}
+	key, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.APIKey])
+	if err == pgx.ErrNoRows {
+		return key, ErrAPIKeyNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan api key", slog.String("api_key_id", id.String()), slog.Any("err", err))
+	}
+	return key, err
+}
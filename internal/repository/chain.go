@@ -0,0 +1,124 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// VerifyJournalChain проходит хэш-цепочку журнала кошелька (или всех кошельков) до первого разрыва.
+func (r *WalletPGRepository) VerifyJournalChain(ctx context.Context, walletID *uuid.UUID) (models.ChainVerification, error) {
+	result := models.ChainVerification{WalletID: walletID}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	if walletID != nil {
+		err := tx.QueryRow(ctx, "SELECT journal_hash FROM wallets WHERE id = $1", *walletID).Scan(&result.Head)
+		if err == pgx.ErrNoRows {
+			return result, ErrWalletNotFound
+		}
+		if err != nil {
+			r.logger.Error("Failed to select wallet",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+			return result, err
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT t.id, t.wallet_id, t.prev_hash, t.hash,
+			journal_row_hash(t.id, t.wallet_id, t.type, t.amount, t.balance_after, t.entry_id,
+				t.transfer_id, t.hold_id, t.conversion_id, t.rate, t.rate_timestamp, t.reversal_of,
+				t.created_at, t.prev_hash) AS computed,
+			t.reversed_amount,
+			(SELECT COALESCE(SUM(ABS(r.amount)), 0) FROM transactions r WHERE r.reversal_of = t.id) AS reversals,
+			LAG(t.hash) OVER w AS expected_prev,
+			LEAD(t.id) OVER w IS NULL AS is_last,
+			wl.journal_hash AS head
+		FROM transactions t
+		JOIN wallets wl ON wl.id = t.wallet_id
+		WHERE $1::uuid IS NULL OR t.wallet_id = $1
+		WINDOW w AS (PARTITION BY t.wallet_id ORDER BY t.id)
+		ORDER BY t.wallet_id, t.id`, walletID)
+	if err != nil {
+		r.logger.Error("Failed to query journal chain", slog.Any("err", err))
+		return result, err
+	}
+	defer rows.Close()
+
+	var (
+		id                   int64
+		rowWallet, current   uuid.UUID
+		prevHash, expectPrev *string
+		hash, computed       string
+		isLast               bool
+		head                 *string
+		reversedAmount       decimal.Decimal
+		reversals            decimal.Decimal
+	)
+	for rows.Next() {
+		if err := rows.Scan(&id, &rowWallet, &prevHash, &hash, &computed, &reversedAmount, &reversals, &expectPrev, &isLast, &head); err != nil {
+			r.logger.Error("Failed to scan journal chain", slog.Any("err", err))
+			return result, err
+		}
+		if rowWallet != current {
+			current = rowWallet
+			result.WalletsChecked++
+		}
+		result.RowsChecked++
+		brk := models.ChainBreak{WalletID: rowWallet, TransactionID: &id}
+		switch {
+		case hash != computed:
+			brk.Reason, brk.Expected, brk.Actual = models.ChainHashMismatch, computed, hash
+		case deref(prevHash) != deref(expectPrev):
+			brk.Reason, brk.Expected, brk.Actual = models.ChainBrokenLink, deref(expectPrev), deref(prevHash)
+		case !reversedAmount.Equal(reversals):
+			brk.Reason, brk.Expected, brk.Actual = models.ChainReversedMismatch, reversals.String(), reversedAmount.String()
+		case isLast && deref(head) != hash:
+			brk.Reason, brk.Expected, brk.Actual = models.ChainHeadMismatch, deref(head), hash
+		default:
+			continue
+		}
+		result.FirstBreak = &brk
+		return result, nil
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to read journal chain", slog.Any("err", err))
+		return result, err
+	}
+
+	// Кошелёк, у которого удалили все строки журнала, виден только по wallets.journal_hash
+	var emptied uuid.UUID
+	err = tx.QueryRow(ctx, `
+		SELECT w.id, w.journal_hash FROM wallets w
+		WHERE w.journal_hash IS NOT NULL
+			AND ($1::uuid IS NULL OR w.id = $1)
+			AND NOT EXISTS (SELECT 1 FROM transactions t WHERE t.wallet_id = w.id)
+		ORDER BY w.id
+		LIMIT 1`, walletID).Scan(&emptied, &head)
+	if err != nil && err != pgx.ErrNoRows {
+		r.logger.Error("Failed to check journal chain heads", slog.Any("err", err))
+		return result, err
+	}
+	if err == nil {
+		result.FirstBreak = &models.ChainBreak{WalletID: emptied, Reason: models.ChainHeadMismatch, Expected: deref(head)}
+		return result, nil
+	}
+	result.OK = true
+	return result, nil
+}
+
+func deref(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
@@ -0,0 +1,191 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrQuoteNotFound = errors.New("conversion quote not found")
+	ErrQuoteExpired  = errors.New("conversion quote has expired")
+	ErrQuoteExecuted = errors.New("conversion quote has already been executed")
+)
+
+const quoteColumns = `id, from_wallet_id, to_wallet_id, from_currency, to_currency, from_amount, to_amount,
+	rate, rate_timestamp, expires_at, executed_at, entry_id, created_at`
+
+// CreateQuote сохраняет котировку конвертации. Курс и суммы уже посчитаны сервисом.
+func (r *WalletPGRepository) CreateQuote(ctx context.Context, quote models.ConversionQuote) (models.ConversionQuote, error) {
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO fx_quotes (
+			id, from_wallet_id, to_wallet_id, from_currency, to_currency,
+			from_amount, to_amount, rate, rate_timestamp, expires_at
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10)
+		RETURNING created_at`,
+		quote.ID, quote.FromWalletID, quote.ToWalletID, quote.FromCurrency, quote.ToCurrency,
+		quote.FromAmount, quote.ToAmount, quote.Rate, quote.RateTimestamp, quote.ExpiresAt,
+	).Scan(&quote.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to create conversion quote",
+			slog.String("from_wallet_id", quote.FromWalletID.String()),
+			slog.String("to_wallet_id", quote.ToWalletID.String()),
+			slog.Any("err", err),
+		)
+	}
+	return quote, err
+}
+
+func (r *WalletPGRepository) GetQuote(ctx context.Context, quoteID uuid.UUID) (models.ConversionQuote, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+quoteColumns+" FROM fx_quotes WHERE id = $1", quoteID)
+	if err != nil {
+		r.logger.Error("Failed to get conversion quote",
+			slog.String("quote_id", quoteID.String()),
+			slog.Any("err", err),
+		)
+		return models.ConversionQuote{}, err
+	}
+	quote, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.ConversionQuote])
+	if err == pgx.ErrNoRows {
+		return quote, ErrQuoteNotFound
+	}
+	return quote, err
+}
+
+// ExecuteConversion исполняет котировку не более одного раза и только до истечения её срока.
+func (r *WalletPGRepository) ExecuteConversion(
+	ctx context.Context,
+	quoteID uuid.UUID,
+	opts ...models.OperationOption,
+) (models.ConversionResult, error) {
+	options := models.NewOperationOptions(opts...)
+	var result models.ConversionResult
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.String("quote_id", quoteID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("quote_id", quoteID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	if idem := options.Idempotency; idem != nil {
+		replayed, err := r.claimIdempotencyKey(ctx, tx, idem)
+		if err != nil {
+			return result, err
+		}
+		if replayed {
+			return result, nil
+		}
+	}
+
+	quote, expired, err := r.lockQuote(ctx, tx, quoteID)
+	if err != nil {
+		return result, err
+	}
+	result.Quote = quote
+	if quote.ExecutedAt != nil {
+		return result, ErrQuoteExecuted
+	}
+	if expired {
+		return result, ErrQuoteExpired
+	}
+	if err := r.lockWallets(ctx, tx, quote.FromWalletID, quote.ToWalletID); err != nil {
+		return result, err
+	}
+	if err := r.checkLimits(ctx, tx, quote.FromWalletID, "WITHDRAW", quote.FromAmount); err != nil {
+		return result, err
+	}
+	if err := r.checkLimits(ctx, tx, quote.ToWalletID, "DEPOSIT", quote.ToAmount); err != nil {
+		return result, err
+	}
+
+	entry := models.LedgerEntry{
+		Type: "CONVERSION",
+		Postings: []models.Posting{
+			{AccountID: models.WalletAccountID(quote.FromWalletID), Amount: quote.FromAmount.Neg(), Currency: quote.FromCurrency},
+			{AccountID: models.SystemAccountID(models.SystemFXPosition, quote.FromCurrency), Amount: quote.FromAmount, Currency: quote.FromCurrency},
+			{AccountID: models.SystemAccountID(models.SystemFXPosition, quote.ToCurrency), Amount: quote.ToAmount.Neg(), Currency: quote.ToCurrency},
+			{AccountID: models.WalletAccountID(quote.ToWalletID), Amount: quote.ToAmount, Currency: quote.ToCurrency},
+		},
+	}
+	balances, err := r.postEntry(ctx, tx, &entry, journalMeta{
+		conversionID:  &quote.ID,
+		rate:          &quote.Rate,
+		rateTimestamp: &quote.RateTimestamp,
+	})
+	if err != nil {
+		result.FromBalance = balances[quote.FromWalletID]
+		result.ToBalance = balances[quote.ToWalletID]
+		return result, err
+	}
+
+	err = tx.QueryRow(ctx, `
+		UPDATE fx_quotes SET executed_at = NOW(), entry_id = $1
+		WHERE id = $2
+		RETURNING executed_at, entry_id`, entry.ID, quote.ID).Scan(&quote.ExecutedAt, &quote.EntryID)
+	if err != nil {
+		r.logger.Error("Failed to mark conversion quote executed",
+			slog.String("quote_id", quoteID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+
+	conversion := models.ConversionResult{
+		Quote:       quote,
+		FromBalance: balances[quote.FromWalletID],
+		ToBalance:   balances[quote.ToWalletID],
+	}
+	opResult := models.OperationResult{Balance: conversion.FromBalance, Conversion: &conversion}
+	if err := r.storeIdempotentResponse(ctx, tx, options.Idempotency, opResult); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.String("quote_id", quoteID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	return conversion, nil
+}
+
+func (r *WalletPGRepository) lockQuote(ctx context.Context, tx pgx.Tx, quoteID uuid.UUID) (models.ConversionQuote, bool, error) {
+	var (
+		quote   models.ConversionQuote
+		expired bool
+	)
+	err := tx.QueryRow(ctx, `
+		SELECT `+quoteColumns+`, expires_at <= NOW()
+		FROM fx_quotes WHERE id = $1 FOR UPDATE`, quoteID).Scan(
+		&quote.ID, &quote.FromWalletID, &quote.ToWalletID, &quote.FromCurrency, &quote.ToCurrency,
+		&quote.FromAmount, &quote.ToAmount, &quote.Rate, &quote.RateTimestamp, &quote.ExpiresAt,
+		&quote.ExecutedAt, &quote.EntryID, &quote.CreatedAt, &expired,
+	)
+	if err == pgx.ErrNoRows {
+		return quote, false, ErrQuoteNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to select conversion quote for update",
+			slog.String("quote_id", quoteID.String()),
+			slog.Any("err", err),
+		)
+	}
+	return quote, expired, err
+}
@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+const feeScheduleColumns = "operation, currency, flat, percent, min_fee, max_fee, tiers, updated_at"
+
+// computeFee считает комиссию за операцию opType на сумму amount по тарифу валюты currency.
+// Если тарифа нет, комиссия нулевая.
+func (r *WalletPGRepository) computeFee(
+	ctx context.Context,
+	tx pgx.Tx,
+	opType, currency string,
+	amount decimal.Decimal,
+) (decimal.Decimal, error) {
+	rows, err := tx.Query(ctx, "SELECT "+feeScheduleColumns+" FROM fee_schedules WHERE operation = $1 AND currency = $2",
+		opType, currency)
+	if err != nil {
+		r.logger.Error("Failed to query fee schedule",
+			slog.String("operation", opType),
+			slog.String("currency", currency),
+			slog.Any("err", err),
+		)
+		return decimal.Zero, err
+	}
+	schedule, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.FeeSchedule])
+	if err == pgx.ErrNoRows {
+		return decimal.Zero, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan fee schedule",
+			slog.String("operation", opType),
+			slog.String("currency", currency),
+			slog.Any("err", err),
+		)
+		return decimal.Zero, err
+	}
+	return schedule.Compute(amount.Abs())
+}
+
+// QuoteFee считает комиссию, не проводя операцию.
+func (r *WalletPGRepository) QuoteFee(ctx context.Context, opType, currency string, amount decimal.Decimal) (decimal.Decimal, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return decimal.Zero, err
+	}
+	defer tx.Rollback(ctx)
+	return r.computeFee(ctx, tx, opType, currency, amount)
+}
+
+// SetFeeSchedule создаёт или заменяет тариф комиссий для пары операция–валюта.
+func (r *WalletPGRepository) SetFeeSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error) {
+	if schedule.Tiers == nil {
+		schedule.Tiers = []models.FeeTier{}
+	}
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO fee_schedules (operation, currency, flat, percent, min_fee, max_fee, tiers)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		ON CONFLICT (operation, currency) DO UPDATE SET
+			flat = EXCLUDED.flat,
+			percent = EXCLUDED.percent,
+			min_fee = EXCLUDED.min_fee,
+			max_fee = EXCLUDED.max_fee,
+			tiers = EXCLUDED.tiers,
+			updated_at = NOW()
+		RETURNING updated_at`,
+		schedule.Operation, schedule.Currency, schedule.Flat, schedule.Percent,
+		schedule.MinFee, schedule.MaxFee, schedule.Tiers,
+	).Scan(&schedule.UpdatedAt)
+	if err != nil {
+		r.logger.Error("Failed to set fee schedule",
+			slog.String("operation", schedule.Operation),
+			slog.String("currency", schedule.Currency),
+			slog.Any("err", err),
+		)
+	}
+	return schedule, err
+}
+
+func (r *WalletPGRepository) ListFeeSchedules(ctx context.Context) ([]models.FeeSchedule, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+feeScheduleColumns+" FROM fee_schedules ORDER BY currency, operation")
+	if err != nil {
+		r.logger.Error("Failed to query fee schedules", slog.Any("err", err))
+		return nil, err
+	}
+	schedules, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.FeeSchedule])
+	if err != nil {
+		r.logger.Error("Failed to scan fee schedules", slog.Any("err", err))
+		return nil, err
+	}
+	if schedules == nil {
+		schedules = []models.FeeSchedule{}
+	}
+	return schedules, nil
+}
@@ -0,0 +1,319 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrHoldNotFound      = errors.New("hold not found")
+	ErrHoldNotActive     = errors.New("hold is not active")
+	ErrHoldExpired       = errors.New("hold has expired")
+	ErrCaptureExceedHold = errors.New("capture amount exceeds held amount")
+)
+
+const holdColumns = "id, wallet_id, amount, captured_amount, status, expires_at, created_at, updated_at"
+
+// activeHolds возвращает сумму действующих холдов кошелька.
+func (r *WalletPGRepository) activeHolds(ctx context.Context, tx pgx.Tx, walletID uuid.UUID) (decimal.Decimal, error) {
+	var held decimal.Decimal
+	err := tx.QueryRow(ctx, `
+		SELECT COALESCE(SUM(amount), 0) FROM holds
+		WHERE wallet_id = $1 AND status = 'ACTIVE' AND expires_at > NOW()`, walletID).Scan(&held)
+	if err != nil {
+		r.logger.Error("Failed to sum active holds",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+	}
+	return held, err
+}
+
+// CreateHold резервирует amount на кошельке до expiresAt в пределах доступного баланса.
+func (r *WalletPGRepository) CreateHold(
+	ctx context.Context,
+	walletID uuid.UUID,
+	amount decimal.Decimal,
+	expiresAt time.Time,
+	opts ...models.OperationOption,
+) (models.HoldResult, error) {
+	options := models.NewOperationOptions(opts...)
+	var result models.HoldResult
+	if !amount.IsPositive() {
+		return result, ErrInvalidAmount
+	}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	if idem := options.Idempotency; idem != nil {
+		replayed, err := r.claimIdempotencyKey(ctx, tx, idem)
+		if err != nil {
+			return result, err
+		}
+		if replayed {
+			return result, nil
+		}
+	}
+
+	var (
+		balance, overdraft decimal.Decimal
+		currency, status   string
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT balance, currency, status, overdraft_limit
+		FROM wallets WHERE id = $1 FOR UPDATE`, walletID).
+		Scan(&balance, &currency, &status, &overdraft)
+	if err == pgx.ErrNoRows {
+		return result, ErrWalletNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to select wallet for update",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	if err := r.checkWalletStatus(status, "HOLD"); err != nil {
+		return result, err
+	}
+	if err := models.ValidateScale(amount, currency); err != nil {
+		return result, err
+	}
+	held, err := r.activeHolds(ctx, tx, walletID)
+	if err != nil {
+		return result, err
+	}
+	result.Wallet = models.Wallet{
+		ID:             walletID,
+		Currency:       currency,
+		Status:         status,
+		Balance:        balance,
+		Held:           held,
+		OverdraftLimit: overdraft,
+		Available:      balance.Sub(held).Add(overdraft),
+	}
+	if result.Wallet.Available.LessThan(amount) {
+		return result, &InsufficientFundsError{
+			WalletID:        walletID,
+			AvailableCredit: availableCredit(balance, held, overdraft),
+		}
+	}
+
+	rows, err := tx.Query(ctx, `
+		INSERT INTO holds (id, wallet_id, amount, expires_at)
+		VALUES ($1, $2, $3, $4)
+		RETURNING `+holdColumns, uuid.New(), walletID, amount, expiresAt)
+	if err == nil {
+		result.Hold, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.Hold])
+	}
+	if err != nil {
+		r.logger.Error("Failed to insert hold",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	result.Wallet.Held = held.Add(amount)
+	result.Wallet.Available = balance.Sub(result.Wallet.Held).Add(overdraft)
+	if err := r.storeIdempotentResponse(ctx, tx, options.Idempotency, models.OperationResult{Hold: &result}); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	return result, nil
+}
+
+// CaptureHold списывает с кошелька amount (или весь холд, если amount == nil) и закрывает холд.
+// Незахваченный остаток частичного списания освобождается.
+func (r *WalletPGRepository) CaptureHold(ctx context.Context, holdID uuid.UUID, amount *decimal.Decimal) (models.HoldResult, error) {
+	return r.closeHold(ctx, holdID, func(tx pgx.Tx, hold *models.Hold) error {
+		captured := hold.Amount
+		if amount != nil {
+			captured = *amount
+		}
+		if !captured.IsPositive() {
+			return ErrInvalidAmount
+		}
+		if captured.GreaterThan(hold.Amount) {
+			return ErrCaptureExceedHold
+		}
+		if err := r.checkLimits(ctx, tx, hold.WalletID, "WITHDRAW", captured); err != nil {
+			return err
+		}
+		err := tx.QueryRow(ctx, `
+			UPDATE holds SET status = 'CAPTURED', captured_amount = $1, updated_at = NOW()
+			WHERE id = $2
+			RETURNING status, captured_amount, updated_at`, captured, hold.ID).
+			Scan(&hold.Status, &hold.CapturedAmount, &hold.UpdatedAt)
+		if err != nil {
+			r.logger.Error("Failed to capture hold",
+				slog.String("hold_id", hold.ID.String()),
+				slog.Any("err", err),
+			)
+			return err
+		}
+		currency, err := r.walletCurrency(ctx, tx, hold.WalletID)
+		if err != nil {
+			return err
+		}
+		// Холд уже закрыт, поэтому при проверке средств он не учитывается
+		entry := models.LedgerEntry{
+			Type: "CAPTURE",
+			Postings: []models.Posting{
+				{AccountID: models.WalletAccountID(hold.WalletID), Amount: captured.Neg(), Currency: currency},
+				{AccountID: models.SystemAccountID(models.SystemExternalCashOut, currency), Amount: captured, Currency: currency},
+			},
+		}
+		_, err = r.postEntry(ctx, tx, &entry, journalMeta{holdID: &hold.ID})
+		return err
+	})
+}
+
+// VoidHold отменяет холд и освобождает зарезервированные средства.
+func (r *WalletPGRepository) VoidHold(ctx context.Context, holdID uuid.UUID) (models.HoldResult, error) {
+	return r.closeHold(ctx, holdID, func(tx pgx.Tx, hold *models.Hold) error {
+		err := tx.QueryRow(ctx, `
+			UPDATE holds SET status = 'VOIDED', updated_at = NOW()
+			WHERE id = $1
+			RETURNING status, updated_at`, hold.ID).Scan(&hold.Status, &hold.UpdatedAt)
+		if err != nil {
+			r.logger.Error("Failed to void hold",
+				slog.String("hold_id", hold.ID.String()),
+				slog.Any("err", err),
+			)
+		}
+		return err
+	})
+}
+
+// closeHold блокирует активный холд и выполняет над ним fn в одной транзакции.
+func (r *WalletPGRepository) closeHold(
+	ctx context.Context,
+	holdID uuid.UUID,
+	fn func(tx pgx.Tx, hold *models.Hold) error,
+) (models.HoldResult, error) {
+	var result models.HoldResult
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.String("hold_id", holdID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("hold_id", holdID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	hold, expired, err := r.lockHold(ctx, tx, holdID)
+	if err != nil {
+		return result, err
+	}
+	result.Hold = hold
+	if hold.Status != models.HoldActive {
+		return result, ErrHoldNotActive
+	}
+	if expired {
+		return result, ErrHoldExpired
+	}
+	if err := fn(tx, &result.Hold); err != nil {
+		return result, err
+	}
+	result.Wallet, err = r.walletTx(ctx, tx, hold.WalletID)
+	if err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.String("hold_id", holdID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	return result, nil
+}
+
+func (r *WalletPGRepository) lockHold(ctx context.Context, tx pgx.Tx, holdID uuid.UUID) (models.Hold, bool, error) {
+	var (
+		hold    models.Hold
+		expired bool
+	)
+	err := tx.QueryRow(ctx, `
+		SELECT `+holdColumns+`, expires_at <= NOW()
+		FROM holds WHERE id = $1 FOR UPDATE`, holdID).Scan(
+		&hold.ID, &hold.WalletID, &hold.Amount, &hold.CapturedAmount, &hold.Status,
+		&hold.ExpiresAt, &hold.CreatedAt, &hold.UpdatedAt, &expired,
+	)
+	if err == pgx.ErrNoRows {
+		return hold, false, ErrHoldNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to select hold for update",
+			slog.String("hold_id", holdID.String()),
+			slog.Any("err", err),
+		)
+	}
+	return hold, expired, err
+}
+
+func (r *WalletPGRepository) GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+holdColumns+" FROM holds WHERE id = $1", holdID)
+	if err != nil {
+		r.logger.Error("Failed to get hold",
+			slog.String("hold_id", holdID.String()),
+			slog.Any("err", err),
+		)
+		return models.Hold{}, err
+	}
+	hold, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.Hold])
+	if err == pgx.ErrNoRows {
+		return hold, ErrHoldNotFound
+	}
+	return hold, err
+}
+
+// ExpireHolds переводит просроченные активные холды в статус EXPIRED. На доступный баланс
+// это не влияет — просроченные холды и так не учитываются, — но делает статус наглядным.
+func (r *WalletPGRepository) ExpireHolds(ctx context.Context) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE holds SET status = 'EXPIRED', updated_at = NOW()
+		WHERE status = 'ACTIVE' AND expires_at <= NOW()`)
+	if err != nil {
+		r.logger.Error("Failed to expire holds", slog.Any("err", err))
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
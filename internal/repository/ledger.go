@@ -0,0 +1,412 @@
+package repository
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"maps"
+	"slices"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// journalMeta — дополнительные поля строк журнала transactions, которые порождает проводка.
+type journalMeta struct {
+	transferID    *uuid.UUID
+	holdID        *uuid.UUID
+	conversionID  *uuid.UUID
+	rate          *decimal.Decimal
+	rateTimestamp *time.Time
+	reversalOf    *int64
+	// allowNegative отключает проверку достаточности средств: баланс может уйти в минус.
+	allowNegative bool
+}
+
+// lockWallets блокирует строки кошельков в порядке возрастания UUID, как postEntry.
+func (r *WalletPGRepository) lockWallets(ctx context.Context, tx pgx.Tx, walletIDs ...uuid.UUID) error {
+	walletIDs = slices.Clone(walletIDs)
+	slices.SortFunc(walletIDs, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+	for _, walletID := range walletIDs {
+		if _, err := tx.Exec(ctx, "SELECT 1 FROM wallets WHERE id = $1 FOR UPDATE", walletID); err != nil {
+			r.logger.Error("Failed to select wallet for update",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+			return err
+		}
+	}
+	return nil
+}
+
+// postEntry проводит запись двойной бухгалтерии в рамках tx и возвращает новые балансы кошельков.
+func (r *WalletPGRepository) postEntry(
+	ctx context.Context,
+	tx pgx.Tx,
+	entry *models.LedgerEntry,
+	meta journalMeta,
+) (map[uuid.UUID]decimal.Decimal, error) {
+	if !entry.IsBalanced() {
+		return nil, ErrUnbalancedEntry
+	}
+
+	deltas := make(map[uuid.UUID]decimal.Decimal)
+	for _, p := range entry.Postings {
+		if walletID, ok := models.ParseWalletAccountID(p.AccountID); ok {
+			deltas[walletID] = deltas[walletID].Add(p.Amount)
+		}
+	}
+	lockOrder := make([]uuid.UUID, 0, len(deltas))
+	for walletID := range deltas {
+		lockOrder = append(lockOrder, walletID)
+	}
+	slices.SortFunc(lockOrder, func(a, b uuid.UUID) int { return bytes.Compare(a[:], b[:]) })
+
+	for _, p := range entry.Postings {
+		if err := models.ValidateScale(p.Amount, p.Currency); err != nil {
+			return nil, err
+		}
+	}
+
+	balances := make(map[uuid.UUID]decimal.Decimal, len(deltas))
+	currencies := make(map[uuid.UUID]string, len(deltas))
+	overdrafts := make(map[uuid.UUID]decimal.Decimal, len(deltas))
+	for _, walletID := range lockOrder {
+		var (
+			balance, overdraft decimal.Decimal
+			currency, status   string
+		)
+		err := tx.QueryRow(ctx, `
+			SELECT balance, currency, status, overdraft_limit
+			FROM wallets WHERE id = $1 FOR UPDATE`, walletID).
+			Scan(&balance, &currency, &status, &overdraft)
+		if err == pgx.ErrNoRows {
+			return balances, ErrWalletNotFound
+		}
+		if err != nil {
+			r.logger.Error("Failed to select wallet for update",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+			return balances, err
+		}
+		balances[walletID] = balance
+		currencies[walletID] = currency
+		overdrafts[walletID] = overdraft
+		if err := r.checkWalletStatus(status, entry.Type); err != nil {
+			return balances, err
+		}
+	}
+	for _, p := range entry.Postings {
+		if walletID, ok := models.ParseWalletAccountID(p.AccountID); ok && currencies[walletID] != p.Currency {
+			return balances, ErrCurrencyMismatch
+		}
+	}
+
+	newBalances := make(map[uuid.UUID]decimal.Decimal, len(deltas))
+	for walletID, delta := range deltas {
+		newBalance := balances[walletID].Add(delta)
+		if delta.IsNegative() && !meta.allowNegative {
+			held, err := r.activeHolds(ctx, tx, walletID)
+			if err != nil {
+				return balances, err
+			}
+			if newBalance.Sub(held).Add(overdrafts[walletID]).IsNegative() {
+				return balances, &InsufficientFundsError{
+					WalletID:        walletID,
+					AvailableCredit: availableCredit(balances[walletID], held, overdrafts[walletID]),
+				}
+			}
+		}
+		newBalances[walletID] = newBalance
+	}
+
+	for _, walletID := range lockOrder {
+		_, err := tx.Exec(ctx, "UPDATE wallets SET balance = $1 WHERE id = $2", newBalances[walletID], walletID)
+		if err != nil {
+			r.logger.Error("Failed to update wallet balance",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+			return balances, err
+		}
+	}
+
+	err := tx.QueryRow(ctx, "INSERT INTO ledger_entries (type) VALUES ($1) RETURNING id, created_at",
+		entry.Type).Scan(&entry.ID, &entry.CreatedAt)
+	if err != nil {
+		r.logger.Error("Failed to insert ledger entry",
+			slog.String("type", entry.Type),
+			slog.Any("err", err),
+		)
+		return balances, err
+	}
+	for i := range entry.Postings {
+		p := &entry.Postings[i]
+		if _, ok := models.ParseWalletAccountID(p.AccountID); !ok {
+			if err := r.ensureSystemAccount(ctx, tx, p.AccountID, p.Currency); err != nil {
+				return balances, err
+			}
+		}
+		err := tx.QueryRow(ctx, `
+			INSERT INTO ledger_postings (entry_id, account_id, amount, currency, created_at)
+			VALUES ($1, $2, $3, $4, $5)
+			RETURNING id`, entry.ID, p.AccountID, p.Amount, p.Currency, entry.CreatedAt).Scan(&p.ID)
+		if err != nil {
+			r.logger.Error("Failed to insert ledger posting",
+				slog.Int64("entry_id", entry.ID),
+				slog.String("account_id", p.AccountID),
+				slog.Any("err", err),
+			)
+			return balances, err
+		}
+		p.EntryID = entry.ID
+		p.CreatedAt = entry.CreatedAt
+	}
+
+	// Если у кошелька несколько проводок (операция и комиссия), balance_after каждой строки
+	// журнала — баланс после этой проводки.
+	running := maps.Clone(balances)
+	events := make(map[uuid.UUID]*models.BalanceChanged, len(deltas))
+	for _, p := range entry.Postings {
+		walletID, ok := models.ParseWalletAccountID(p.AccountID)
+		if !ok {
+			continue
+		}
+		running[walletID] = running[walletID].Add(p.Amount)
+		opType := entry.Type
+		if entry.Type == "TRANSFER" || entry.Type == "CONVERSION" {
+			opType = entry.Type + "_IN"
+			if p.Amount.IsNegative() {
+				opType = entry.Type + "_OUT"
+			}
+		}
+		if p.JournalType != "" {
+			opType = p.JournalType
+		}
+		rowMeta := meta
+		if p.ReversalOf != nil {
+			rowMeta.reversalOf = p.ReversalOf
+		}
+		transactionID, err := r.insertTransaction(ctx, tx, walletID, opType, p.Amount, running[walletID], entry.ID, rowMeta)
+		if err != nil {
+			return balances, err
+		}
+
+		event := events[walletID]
+		if event == nil {
+			event = &models.BalanceChanged{
+				Currency:      currencies[walletID],
+				BalanceBefore: balances[walletID],
+				BalanceAfter:  newBalances[walletID],
+				EntryID:       entry.ID,
+			}
+			events[walletID] = event
+		}
+		// Возврат комиссии при сторно уменьшает удержанную комиссию
+		if p.JournalType == "FEE" || p.ReversalOf != nil {
+			event.Fee = event.Fee.Sub(p.Amount)
+		} else if event.Operation == "" {
+			event.Operation = opType
+			event.Amount = p.Amount
+			event.TransactionID = transactionID
+		}
+	}
+
+	// Событие об изменении баланса пишется для каждого затронутого кошелька в той же транзакции
+	for _, walletID := range lockOrder {
+		if err := r.enqueueEvent(ctx, tx, models.EventBalanceChanged, walletID, events[walletID]); err != nil {
+			return balances, err
+		}
+	}
+
+	return newBalances, nil
+}
+
+// availableCredit — сколько ещё можно списать с кошелька; не бывает отрицательным,
+// даже если баланс ушёл ниже лимита овердрафта.
+func availableCredit(balance, held, overdraft decimal.Decimal) decimal.Decimal {
+	return decimal.Max(balance.Sub(held).Add(overdraft), decimal.Zero)
+}
+
+// checkWalletStatus проверяет, что кошелёк в статусе status может участвовать в записи типа entryType.
+func (r *WalletPGRepository) checkWalletStatus(status, entryType string) error {
+	switch status {
+	case models.WalletClosed:
+		return ErrWalletClosed
+	case models.WalletFrozen:
+		if entryType == "DEPOSIT" && r.frozenDeposits {
+			return nil
+		}
+		return ErrWalletFrozen
+	}
+	return nil
+}
+
+func (r *WalletPGRepository) insertTransaction(
+	ctx context.Context,
+	tx pgx.Tx,
+	walletID uuid.UUID,
+	opType string,
+	amount, balanceAfter decimal.Decimal,
+	entryID int64,
+	meta journalMeta,
+) (int64, error) {
+	var id int64
+	err := tx.QueryRow(ctx, `
+		INSERT INTO transactions (
+			wallet_id, type, amount, balance_after, entry_id, transfer_id, hold_id,
+			conversion_id, rate, rate_timestamp, reversal_of
+		)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11)
+		RETURNING id`,
+		walletID, opType, amount, balanceAfter, entryID, meta.transferID, meta.holdID,
+		meta.conversionID, meta.rate, meta.rateTimestamp, meta.reversalOf).Scan(&id)
+	if err != nil {
+		r.logger.Error("Failed to insert transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.String("operation", opType),
+			slog.Any("amount", amount),
+			slog.Any("err", err),
+		)
+	}
+	return id, err
+}
+
+// ensureSystemAccount заводит системный счёт при первой проводке в новой валюте.
+func (r *WalletPGRepository) ensureSystemAccount(ctx context.Context, tx pgx.Tx, accountID, currency string) error {
+	_, err := tx.Exec(ctx, `
+		INSERT INTO ledger_accounts (id, kind, currency) VALUES ($1, 'SYSTEM', $2)
+		ON CONFLICT (id) DO NOTHING`, accountID, currency)
+	if err != nil {
+		r.logger.Error("Failed to ensure system account",
+			slog.String("account_id", accountID),
+			slog.Any("err", err),
+		)
+	}
+	return err
+}
+
+// walletCurrency возвращает валюту кошелька. Валюта не меняется, поэтому блокировка не нужна.
+func (r *WalletPGRepository) walletCurrency(ctx context.Context, tx pgx.Tx, walletID uuid.UUID) (string, error) {
+	var currency string
+	err := tx.QueryRow(ctx, "SELECT currency FROM wallets WHERE id = $1", walletID).Scan(&currency)
+	if err == pgx.ErrNoRows {
+		return "", ErrWalletNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to get wallet currency",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+	}
+	return currency, err
+}
+
+// createWalletIfNotExists создаёт кошелёк владельца owner вместе с его счётом в главной книге.
+// Возвращает true, если кошелёк был создан этой транзакцией.
+func (r *WalletPGRepository) createWalletIfNotExists(
+	ctx context.Context,
+	tx pgx.Tx,
+	walletID uuid.UUID,
+	currency, owner string,
+) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		WITH w AS (
+			INSERT INTO wallets (id, balance, currency, owner_id) VALUES ($1, 0, $2, $3)
+			ON CONFLICT (id) DO NOTHING
+			RETURNING id, currency
+		)
+		INSERT INTO ledger_accounts (id, kind, wallet_id, currency)
+		SELECT 'wallet:' || id, 'WALLET', id, currency FROM w`, walletID, currency, owner)
+	if err != nil {
+		r.logger.Error("Failed to upsert wallet",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return false, err
+	}
+	return tag.RowsAffected() == 1, nil
+}
+
+// GetPostings возвращает проводки по счёту главной книги в порядке проведения.
+func (r *WalletPGRepository) GetPostings(ctx context.Context, accountID string) ([]models.Posting, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, entry_id, account_id, amount, currency, created_at
+		FROM ledger_postings
+		WHERE account_id = $1
+		ORDER BY id`, accountID)
+	if err != nil {
+		r.logger.Error("Failed to query postings",
+			slog.String("account_id", accountID),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	postings, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Posting])
+	if err != nil {
+		r.logger.Error("Failed to scan postings",
+			slog.String("account_id", accountID),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	return postings, nil
+}
+
+// GetTrialBalance считает сальдо всех счетов по проводкам и ищет несбалансированные записи.
+func (r *WalletPGRepository) GetTrialBalance(ctx context.Context) (models.TrialBalance, error) {
+	var tb models.TrialBalance
+	rows, err := r.pool.Query(ctx, `
+		SELECT account_id, currency, SUM(amount) AS balance
+		FROM ledger_postings
+		GROUP BY account_id, currency
+		ORDER BY account_id, currency`)
+	if err != nil {
+		r.logger.Error("Failed to query trial balance", slog.Any("err", err))
+		return tb, err
+	}
+	tb.Accounts, err = pgx.CollectRows(rows, pgx.RowToStructByName[models.AccountBalance])
+	if err != nil {
+		r.logger.Error("Failed to scan trial balance", slog.Any("err", err))
+		return tb, err
+	}
+
+	rows, err = r.pool.Query(ctx, `
+		SELECT DISTINCT entry_id FROM (
+			SELECT entry_id FROM ledger_postings
+			GROUP BY entry_id, currency
+			HAVING SUM(amount) <> 0
+		) unbalanced
+		ORDER BY entry_id`)
+	if err != nil {
+		r.logger.Error("Failed to query unbalanced entries", slog.Any("err", err))
+		return tb, err
+	}
+	tb.UnbalancedEntries, err = pgx.CollectRows(rows, pgx.RowTo[int64])
+	if err != nil {
+		r.logger.Error("Failed to scan unbalanced entries", slog.Any("err", err))
+		return tb, err
+	}
+
+	tb.Totals = make(map[string]decimal.Decimal)
+	for _, a := range tb.Accounts {
+		tb.Totals[a.Currency] = tb.Totals[a.Currency].Add(a.Balance)
+	}
+	if tb.Accounts == nil {
+		tb.Accounts = []models.AccountBalance{}
+	}
+	if tb.UnbalancedEntries == nil {
+		tb.UnbalancedEntries = []int64{}
+	}
+	tb.Balanced = len(tb.UnbalancedEntries) == 0
+	for _, total := range tb.Totals {
+		if !total.IsZero() {
+			tb.Balanced = false
+		}
+	}
+	return tb, nil
+}
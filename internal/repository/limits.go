@@ -0,0 +1,300 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrLimitExceeded     = errors.New("spending limit exceeded")
+	ErrLimitTierNotFound = errors.New("limit tier not found")
+)
+
+// LimitExceededError уточняет ErrLimitExceeded: Limit — нарушенный лимит (models.LimitDailyCap и т.п.),
+// Max — его значение, Used — сколько уже израсходовано без учёта отклонённой операции.
+type LimitExceededError struct {
+	Limit string
+	Max   decimal.Decimal
+	Used  decimal.Decimal
+}
+
+func (e *LimitExceededError) Error() string {
+	return ErrLimitExceeded.Error() + ": " + e.Limit
+}
+
+func (e *LimitExceededError) Unwrap() error {
+	return ErrLimitExceeded
+}
+
+// limitJournalTypes — типы строк журнала, которые расходуют лимиты операции.
+var limitJournalTypes = map[string][]string{
+	"DEPOSIT":  {"DEPOSIT", "TRANSFER_IN", "CONVERSION_IN"},
+	"WITHDRAW": {"WITHDRAW", "CAPTURE", "TRANSFER_OUT", "CONVERSION_OUT"},
+}
+
+const limitColumns = `max_single_amount, daily_cap, weekly_cap, monthly_cap,
+	max_operations, operations_window_seconds`
+
+// effectiveLimit собирает лимиты кошелька на операцию opType: поля лимита кошелька
+// переопределяют поля его тарифа, кошелёк без тарифа получает тариф models.DefaultLimitTier.
+func (r *WalletPGRepository) effectiveLimit(
+	ctx context.Context,
+	tx pgx.Tx,
+	walletID uuid.UUID,
+	opType string,
+) (models.SpendingLimit, error) {
+	rows, err := tx.Query(ctx, `
+		SELECT $2::text AS operation,
+			COALESCE(wl.max_single_amount, lt.max_single_amount) AS max_single_amount,
+			COALESCE(wl.daily_cap, lt.daily_cap) AS daily_cap,
+			COALESCE(wl.weekly_cap, lt.weekly_cap) AS weekly_cap,
+			COALESCE(wl.monthly_cap, lt.monthly_cap) AS monthly_cap,
+			COALESCE(wl.max_operations, lt.max_operations) AS max_operations,
+			COALESCE(wl.operations_window_seconds, lt.operations_window_seconds) AS operations_window_seconds
+		FROM wallets w
+		LEFT JOIN limit_tiers lt
+			ON lt.tier = COALESCE(w.limit_tier, $3) AND lt.currency = w.currency AND lt.operation = $2
+		LEFT JOIN wallet_limits wl ON wl.wallet_id = w.id AND wl.operation = $2
+		WHERE w.id = $1`, walletID, opType, models.DefaultLimitTier)
+	if err != nil {
+		r.logger.Error("Failed to query spending limits",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.SpendingLimit{}, err
+	}
+	limit, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.SpendingLimit])
+	if err == pgx.ErrNoRows {
+		return limit, ErrWalletNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan spending limits",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+	}
+	return limit, err
+}
+
+// checkLimits проверяет, что операция opType на сумму amount укладывается в лимиты кошелька.
+func (r *WalletPGRepository) checkLimits(
+	ctx context.Context,
+	tx pgx.Tx,
+	walletID uuid.UUID,
+	opType string,
+	amount decimal.Decimal,
+) error {
+	if _, err := tx.Exec(ctx, "SELECT 1 FROM wallets WHERE id = $1 FOR UPDATE", walletID); err != nil {
+		r.logger.Error("Failed to select wallet for update",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	limit, err := r.effectiveLimit(ctx, tx, walletID, opType)
+	if err != nil {
+		return err
+	}
+	amount = amount.Abs()
+	if limit.MaxSingleAmount != nil && amount.GreaterThan(*limit.MaxSingleAmount) {
+		return &LimitExceededError{Limit: models.LimitMaxSingleAmount, Max: *limit.MaxSingleAmount, Used: decimal.Zero}
+	}
+	if limit.DailyCap == nil && limit.WeeklyCap == nil && limit.MonthlyCap == nil && limit.MaxOperations == nil {
+		return nil
+	}
+
+	window := 0
+	if limit.OperationsWindowSeconds != nil {
+		window = *limit.OperationsWindowSeconds
+	}
+	var (
+		daily, weekly, monthly decimal.Decimal
+		operations             int64
+	)
+	err = tx.QueryRow(ctx, `
+		WITH bounds AS (
+			SELECT date_trunc('day', NOW(), 'UTC') AS day,
+				date_trunc('week', NOW(), 'UTC') AS week,
+				date_trunc('month', NOW(), 'UTC') AS month,
+				NOW() - make_interval(secs => $3::int) AS window_start
+		)
+		SELECT
+			COALESCE(SUM(ABS(t.amount) - t.reversed_amount) FILTER (WHERE t.created_at >= b.day), 0),
+			COALESCE(SUM(ABS(t.amount) - t.reversed_amount) FILTER (WHERE t.created_at >= b.week), 0),
+			COALESCE(SUM(ABS(t.amount) - t.reversed_amount) FILTER (WHERE t.created_at >= b.month), 0),
+			COUNT(*) FILTER (WHERE t.created_at > b.window_start)
+		FROM bounds b
+		LEFT JOIN transactions t
+			ON t.wallet_id = $1 AND t.type = ANY($2)
+			AND t.created_at >= LEAST(b.week, b.month, b.window_start)`,
+		walletID, limitJournalTypes[opType], window).Scan(&daily, &weekly, &monthly, &operations)
+	if err != nil {
+		r.logger.Error("Failed to compute limit usage",
+			slog.String("wallet_id", walletID.String()),
+			slog.String("operation", opType),
+			slog.Any("err", err),
+		)
+		return err
+	}
+
+	caps := []struct {
+		name string
+		max  *decimal.Decimal
+		used decimal.Decimal
+	}{
+		{models.LimitDailyCap, limit.DailyCap, daily},
+		{models.LimitWeeklyCap, limit.WeeklyCap, weekly},
+		{models.LimitMonthlyCap, limit.MonthlyCap, monthly},
+	}
+	for _, c := range caps {
+		if c.max != nil && c.used.Add(amount).GreaterThan(*c.max) {
+			return &LimitExceededError{Limit: c.name, Max: *c.max, Used: c.used}
+		}
+	}
+	if limit.MaxOperations != nil && operations >= int64(*limit.MaxOperations) {
+		return &LimitExceededError{
+			Limit: models.LimitMaxOperations,
+			Max:   decimal.NewFromInt(int64(*limit.MaxOperations)),
+			Used:  decimal.NewFromInt(operations),
+		}
+	}
+	return nil
+}
+
+// SetLimitTier задаёт лимиты тарифа tier для кошельков в валюте currency.
+func (r *WalletPGRepository) SetLimitTier(ctx context.Context, tier, currency string, limit models.SpendingLimit) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO limit_tiers (tier, currency, operation, `+limitColumns+`)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)
+		ON CONFLICT (tier, currency, operation) DO UPDATE SET
+			max_single_amount = EXCLUDED.max_single_amount,
+			daily_cap = EXCLUDED.daily_cap,
+			weekly_cap = EXCLUDED.weekly_cap,
+			monthly_cap = EXCLUDED.monthly_cap,
+			max_operations = EXCLUDED.max_operations,
+			operations_window_seconds = EXCLUDED.operations_window_seconds,
+			updated_at = NOW()`,
+		tier, currency, limit.Operation, limit.MaxSingleAmount, limit.DailyCap, limit.WeeklyCap,
+		limit.MonthlyCap, limit.MaxOperations, limit.OperationsWindowSeconds)
+	if err != nil {
+		r.logger.Error("Failed to set limit tier",
+			slog.String("tier", tier),
+			slog.String("currency", currency),
+			slog.Any("err", err),
+		)
+	}
+	return err
+}
+
+// SetWalletLimit задаёт лимиты конкретного кошелька; заданные поля переопределяют тариф.
+func (r *WalletPGRepository) SetWalletLimit(ctx context.Context, walletID uuid.UUID, limit models.SpendingLimit) error {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO wallet_limits (wallet_id, operation, `+limitColumns+`)
+		SELECT id, $2, $3, $4, $5, $6, $7, $8 FROM wallets WHERE id = $1
+		ON CONFLICT (wallet_id, operation) DO UPDATE SET
+			max_single_amount = EXCLUDED.max_single_amount,
+			daily_cap = EXCLUDED.daily_cap,
+			weekly_cap = EXCLUDED.weekly_cap,
+			monthly_cap = EXCLUDED.monthly_cap,
+			max_operations = EXCLUDED.max_operations,
+			operations_window_seconds = EXCLUDED.operations_window_seconds,
+			updated_at = NOW()`,
+		walletID, limit.Operation, limit.MaxSingleAmount, limit.DailyCap, limit.WeeklyCap,
+		limit.MonthlyCap, limit.MaxOperations, limit.OperationsWindowSeconds)
+	if err != nil {
+		r.logger.Error("Failed to set wallet limit",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrWalletNotFound
+	}
+	return nil
+}
+
+// SetWalletTier назначает кошельку тариф лимитов; пустой tier возвращает кошелёк к тарифу по умолчанию.
+func (r *WalletPGRepository) SetWalletTier(ctx context.Context, walletID uuid.UUID, tier string) (models.Wallet, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.Wallet{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	var tierArg *string
+	if tier != "" {
+		var exists bool
+		err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM limit_tiers WHERE tier = $1)", tier).Scan(&exists)
+		if err != nil {
+			r.logger.Error("Failed to check limit tier",
+				slog.String("tier", tier),
+				slog.Any("err", err),
+			)
+			return models.Wallet{}, err
+		}
+		if !exists {
+			return models.Wallet{}, ErrLimitTierNotFound
+		}
+		tierArg = &tier
+	}
+	tag, err := tx.Exec(ctx, "UPDATE wallets SET limit_tier = $1, updated_at = NOW() WHERE id = $2", tierArg, walletID)
+	if err != nil {
+		r.logger.Error("Failed to set wallet tier",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.Wallet{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.Wallet{}, ErrWalletNotFound
+	}
+	wallet, err := r.walletTx(ctx, tx, walletID)
+	if err != nil {
+		return wallet, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return wallet, err
+	}
+	return wallet, nil
+}
+
+// GetWalletLimits возвращает действующие лимиты кошелька на пополнения и списания.
+func (r *WalletPGRepository) GetWalletLimits(ctx context.Context, walletID uuid.UUID) ([]models.SpendingLimit, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	limits := make([]models.SpendingLimit, 0, 2)
+	for _, opType := range []string{"DEPOSIT", "WITHDRAW"} {
+		limit, err := r.effectiveLimit(ctx, tx, walletID, opType)
+		if err != nil {
+			return nil, err
+		}
+		limits = append(limits, limit)
+	}
+	return limits, nil
+}
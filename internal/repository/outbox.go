@@ -0,0 +1,88 @@
+package repository
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+const eventColumns = "id, event_id, type, wallet_id, payload, created_at, attempts"
+
+// enqueueEvent пишет событие в outbox в транзакции tx: событие становится видно relay
+// только вместе с изменением, которое оно описывает.
+func (r *WalletPGRepository) enqueueEvent(ctx context.Context, tx pgx.Tx, eventType string, walletID uuid.UUID, data any) error {
+	payload, err := json.Marshal(data)
+	if err != nil {
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO outbox_events (event_id, type, wallet_id, payload)
+		VALUES ($1, $2, $3, $4)`, uuid.New(), eventType, walletID, payload)
+	if err != nil {
+		r.logger.Error("Failed to insert outbox event",
+			slog.String("wallet_id", walletID.String()),
+			slog.String("type", eventType),
+			slog.Any("err", err),
+		)
+	}
+	return err
+}
+
+// LockOutboxRelay берёт advisory-блокировку relay: публикует события только одна реплика,
+// иначе порядок событий кошелька не гарантирован.
+func (r *WalletPGRepository) LockOutboxRelay(ctx context.Context) (unlock func(), ok bool, err error) {
+	return r.tryAdvisoryLock(ctx, "outbox_relay")
+}
+
+// PendingEvents возвращает неопубликованные события в порядке записи, по порядку внутри кошелька.
+func (r *WalletPGRepository) PendingEvents(ctx context.Context, now time.Time, limit int) ([]models.Event, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+eventColumns+`
+		FROM outbox_events e
+		WHERE e.published_at IS NULL
+			AND e.next_attempt_at <= $1
+			AND NOT EXISTS (
+				SELECT 1 FROM outbox_events p
+				WHERE p.wallet_id = e.wallet_id AND p.published_at IS NULL
+					AND p.id < e.id AND p.next_attempt_at > $1
+			)
+		ORDER BY e.id
+		LIMIT $2`, now, limit)
+	if err != nil {
+		r.logger.Error("Failed to query outbox events", slog.Any("err", err))
+		return nil, err
+	}
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Event])
+	if err != nil {
+		r.logger.Error("Failed to scan outbox events", slog.Any("err", err))
+		return nil, err
+	}
+	return events, nil
+}
+
+// MarkEventPublished отмечает событие опубликованным.
+func (r *WalletPGRepository) MarkEventPublished(ctx context.Context, id int64) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE outbox_events SET published_at = NOW(), attempts = attempts + 1, last_error = NULL
+		WHERE id = $1`, id)
+	if err != nil {
+		r.logger.Error("Failed to mark outbox event published", slog.Int64("event_id", id), slog.Any("err", err))
+	}
+	return err
+}
+
+// MarkEventFailed записывает неудачную попытку публикации и время следующей.
+func (r *WalletPGRepository) MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, reason string) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE outbox_events SET attempts = attempts + 1, next_attempt_at = $2, last_error = $3
+		WHERE id = $1`, id, nextAttemptAt, reason)
+	if err != nil {
+		r.logger.Error("Failed to mark outbox event failed", slog.Int64("event_id", id), slog.Any("err", err))
+	}
+	return err
+}
@@ -0,0 +1,161 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// InsufficientFundsError уточняет ErrInsufficientFunds: AvailableCredit — сколько ещё
+// можно списать с кошелька с учётом холдов и лимита овердрафта.
+type InsufficientFundsError struct {
+	WalletID        uuid.UUID
+	AvailableCredit decimal.Decimal
+}
+
+func (e *InsufficientFundsError) Error() string {
+	return ErrInsufficientFunds.Error()
+}
+
+func (e *InsufficientFundsError) Unwrap() error {
+	return ErrInsufficientFunds
+}
+
+// SetOverdraftLimit устанавливает лимит овердрафта кошелька и записывает изменение в журнал.
+func (r *WalletPGRepository) SetOverdraftLimit(
+	ctx context.Context,
+	walletID uuid.UUID,
+	limit decimal.Decimal,
+	reason string,
+) (models.Wallet, error) {
+	if limit.IsNegative() {
+		return models.Wallet{}, ErrInvalidAmount
+	}
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.Wallet{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	var (
+		current          decimal.Decimal
+		currency, status string
+	)
+	err = tx.QueryRow(ctx, "SELECT overdraft_limit, currency, status FROM wallets WHERE id = $1 FOR UPDATE", walletID).
+		Scan(&current, &currency, &status)
+	if err == pgx.ErrNoRows {
+		return models.Wallet{}, ErrWalletNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to select wallet for update",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.Wallet{}, err
+	}
+	if status == models.WalletClosed {
+		return models.Wallet{}, ErrWalletClosed
+	}
+	if err := models.ValidateScale(limit, currency); err != nil {
+		return models.Wallet{}, err
+	}
+
+	if !current.Equal(limit) {
+		var reasonArg *string
+		if reason != "" {
+			reasonArg = &reason
+		}
+		_, err = tx.Exec(ctx, `
+			WITH w AS (
+				UPDATE wallets SET overdraft_limit = $1, updated_at = NOW()
+				WHERE id = $2
+			)
+			INSERT INTO overdraft_limit_changes (wallet_id, old_limit, new_limit, reason)
+			VALUES ($2, $3, $1, $4)`, limit, walletID, current, reasonArg)
+		if err != nil {
+			r.logger.Error("Failed to update overdraft limit",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("limit", limit),
+				slog.Any("err", err),
+			)
+			return models.Wallet{}, err
+		}
+	}
+
+	wallet, err := r.walletTx(ctx, tx, walletID)
+	if err != nil {
+		return wallet, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return wallet, err
+	}
+	return wallet, nil
+}
+
+// GetOverdraftLimitChanges возвращает журнал изменений лимита овердрафта в порядке изменений.
+func (r *WalletPGRepository) GetOverdraftLimitChanges(
+	ctx context.Context,
+	walletID uuid.UUID,
+) ([]models.OverdraftLimitChange, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM wallets WHERE id = $1)", walletID).Scan(&exists); err != nil {
+		r.logger.Error("Failed to check wallet",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrWalletNotFound
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT id, wallet_id, old_limit, new_limit, reason, created_at
+		FROM overdraft_limit_changes
+		WHERE wallet_id = $1
+		ORDER BY id`, walletID)
+	if err != nil {
+		r.logger.Error("Failed to query overdraft limit changes",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	changes, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.OverdraftLimitChange])
+	if err != nil {
+		r.logger.Error("Failed to scan overdraft limit changes",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	if changes == nil {
+		changes = []models.OverdraftLimitChange{}
+	}
+	return changes, nil
+}
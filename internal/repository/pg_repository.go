@@ -3,10 +3,11 @@ package repository
 import (
 	"context"
 	"errors"
+	"fmt"
 	"log/slog"
+	"test_wallet/internal/models"
 
 	"github.com/google/uuid"
-	"github.com/jackc/pgconn"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
@@ -14,21 +15,67 @@ import (
 
 var (
 	ErrWalletNotFound     = errors.New("wallet not found")
+	ErrWalletFrozen       = errors.New("wallet is frozen")
+	ErrWalletClosed       = errors.New("wallet is closed")
+	ErrWalletNotEmpty     = errors.New("wallet has a non-zero balance or active holds")
 	ErrInsufficientFunds  = errors.New("insufficient funds")
 	ErrWalletAlreadyExist = errors.New("wallet already exists")
 	ErrInvalidAmount      = errors.New("amount must not be zero")
+	ErrIdempotencyKeyUsed = errors.New("idempotency key already used with a different request")
+	ErrSameWallet         = errors.New("source and destination wallets must differ")
+	ErrUnbalancedEntry    = errors.New("ledger entry postings must sum to zero")
+	ErrCurrencyMismatch   = errors.New("currency does not match wallet currency")
 )
 
+const transactionColumns = `id, wallet_id, type, amount, balance_after, entry_id, transfer_id, hold_id,
+	conversion_id, rate, rate_timestamp, reversal_of, reversed_amount, created_at, prev_hash, hash`
+
 type WalletPGRepository struct {
-	pool   *pgxpool.Pool
-	logger *slog.Logger
+	pool            *pgxpool.Pool
+	logger          *slog.Logger
+	defaultCurrency string
+	// frozenDeposits разрешает пополнять замороженные кошельки.
+	frozenDeposits bool
+	// implicitCreation разрешает создавать кошелёк первым пополнением.
+	implicitCreation bool
+}
+
+type Option func(*WalletPGRepository)
+
+// WithDefaultCurrency задаёт валюту кошельков, создаваемых без явного указания валюты.
+func WithDefaultCurrency(currency string) Option {
+	return func(r *WalletPGRepository) {
+		r.defaultCurrency = currency
+	}
+}
+
+// WithFrozenDeposits разрешает пополнения (DEPOSIT) замороженных кошельков.
+func WithFrozenDeposits(allow bool) Option {
+	return func(r *WalletPGRepository) {
+		r.frozenDeposits = allow
+	}
+}
+
+// WithImplicitCreation включает или отключает создание кошелька первым пополнением.
+// Если оно отключено, пополнение несуществующего кошелька завершается ErrWalletNotFound.
+func WithImplicitCreation(enabled bool) Option {
+	return func(r *WalletPGRepository) {
+		r.implicitCreation = enabled
+	}
 }
 
-func NewWalletPGRepository(pool *pgxpool.Pool, logger *slog.Logger) *WalletPGRepository {
-	return &WalletPGRepository{
-		pool:   pool,
-		logger: logger,
+func NewWalletPGRepository(pool *pgxpool.Pool, logger *slog.Logger, opts ...Option) *WalletPGRepository {
+	r := &WalletPGRepository{
+		pool:            pool,
+		logger:          logger,
+		defaultCurrency: models.DefaultCurrency,
+
+		implicitCreation: true,
+	}
+	for _, opt := range opts {
+		opt(r)
 	}
+	return r
 }
 
 func (r *WalletPGRepository) UpdateBalance(
@@ -36,7 +83,9 @@ func (r *WalletPGRepository) UpdateBalance(
 	walletID uuid.UUID,
 	amount decimal.Decimal,
 	opType string,
+	opts ...models.OperationOption,
 ) (decimal.Decimal, bool, error) {
+	options := models.NewOperationOptions(opts...)
 	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
 		r.logger.Error("Failed to begin transaction",
@@ -54,85 +103,290 @@ func (r *WalletPGRepository) UpdateBalance(
 		}
 	}()
 
-	var currentBalance decimal.Decimal
-	err = tx.QueryRow(ctx, "SELECT balance FROM wallets WHERE id = $1 FOR UPDATE", walletID).Scan(&currentBalance)
+	if idem := options.Idempotency; idem != nil {
+		replayed, err := r.claimIdempotencyKey(ctx, tx, idem)
+		if err != nil {
+			return decimal.Zero, false, err
+		}
+		if replayed {
+			return decimal.Zero, false, nil
+		}
+	}
 
 	if amount.IsZero() {
-		return currentBalance, false, ErrInvalidAmount
+		return decimal.Zero, false, ErrInvalidAmount
 	}
 
 	created := false
-	if err == pgx.ErrNoRows {
-		if opType != "DEPOSIT" {
-			return decimal.Zero, false, ErrWalletNotFound
-		}
-
-		var insertedID uuid.UUID
-		err := tx.QueryRow(ctx, `
-            INSERT INTO wallets (id, balance) VALUES ($1, 0)
-            ON CONFLICT (id) DO NOTHING
-            RETURNING id`, walletID).Scan(&insertedID)
-		if err != nil && err != pgx.ErrNoRows {
-			r.logger.Error("Failed to upsert wallet",
-				slog.String("wallet_id", walletID.String()),
-				slog.Any("err", err),
-			)
-			return decimal.Zero, false, err
+	if opType == "DEPOSIT" && r.implicitCreation {
+		currency := options.Currency
+		if currency == "" {
+			currency = r.defaultCurrency
 		}
-		if err == nil {
-			created = true
-		}
-
-		err = tx.QueryRow(ctx, "SELECT balance FROM wallets WHERE id = $1 FOR UPDATE", walletID).Scan(&currentBalance)
+		created, err = r.createWalletIfNotExists(ctx, tx, walletID, currency, options.Owner)
 		if err != nil {
-			r.logger.Error("Failed to select wallet after upsert",
-				slog.String("wallet_id", walletID.String()),
-				slog.Any("err", err),
-			)
 			return decimal.Zero, false, err
 		}
-	} else if err != nil {
-		r.logger.Error("Failed to select wallet for update",
+	}
+	currency, err := r.walletCurrency(ctx, tx, walletID)
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	if options.Currency != "" && options.Currency != currency {
+		return decimal.Zero, false, ErrCurrencyMismatch
+	}
+	if err := r.checkLimits(ctx, tx, walletID, opType, amount); err != nil {
+		return decimal.Zero, false, err
+	}
+
+	counterparty := models.SystemExternalCashIn
+	if opType == "WITHDRAW" {
+		counterparty = models.SystemExternalCashOut
+	}
+	entry := models.LedgerEntry{
+		Type: opType,
+		Postings: []models.Posting{
+			{AccountID: models.WalletAccountID(walletID), Amount: amount, Currency: currency},
+			{AccountID: models.SystemAccountID(counterparty, currency), Amount: amount.Neg(), Currency: currency},
+		},
+	}
+	// Комиссия удерживается в той же записи главной книги и зачисляется на системный счёт комиссий
+	fee, err := r.computeFee(ctx, tx, opType, currency, amount)
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	if fee.IsPositive() {
+		entry.Postings = append(entry.Postings,
+			models.Posting{AccountID: models.WalletAccountID(walletID), Amount: fee.Neg(), Currency: currency, JournalType: "FEE"},
+			models.Posting{AccountID: models.SystemAccountID(models.SystemFees, currency), Amount: fee, Currency: currency},
+		)
+	}
+	balances, err := r.postEntry(ctx, tx, &entry, journalMeta{})
+	if err != nil {
+		return balances[walletID], false, err
+	}
+	newBalance := balances[walletID]
+
+	result := models.OperationResult{Balance: newBalance, Created: created, Fee: fee}
+	if err := r.storeIdempotentResponse(ctx, tx, options.Idempotency, result); err != nil {
+		return decimal.Zero, false, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
 			slog.String("wallet_id", walletID.String()),
 			slog.Any("err", err),
 		)
 		return decimal.Zero, false, err
 	}
 
-	newBalance := currentBalance.Add(amount)
-	if newBalance.IsNegative() {
-		return currentBalance, false, ErrInsufficientFunds
+	if options.Fee != nil {
+		*options.Fee = fee
+	}
+	return newBalance, created, nil
+}
+
+// Transfer атомарно списывает amount с кошелька fromID и зачисляет на toID.
+func (r *WalletPGRepository) Transfer(
+	ctx context.Context,
+	fromID, toID uuid.UUID,
+	amount decimal.Decimal,
+	opts ...models.OperationOption,
+) (models.TransferResult, error) {
+	options := models.NewOperationOptions(opts...)
+	result := models.TransferResult{FromWalletID: fromID, ToWalletID: toID, Amount: amount}
+	if !amount.IsPositive() {
+		return result, ErrInvalidAmount
+	}
+	if fromID == toID {
+		return result, ErrSameWallet
 	}
 
-	_, err = tx.Exec(ctx, "UPDATE wallets SET balance = $1 WHERE id = $2", newBalance, walletID)
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
 	if err != nil {
-		r.logger.Error("Failed to update wallet balance",
-			slog.String("wallet_id", walletID.String()),
+		r.logger.Error("Failed to begin transaction",
+			slog.String("from_wallet_id", fromID.String()),
+			slog.String("to_wallet_id", toID.String()),
 			slog.Any("err", err),
 		)
-		return currentBalance, false, err
+		return result, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("from_wallet_id", fromID.String()),
+				slog.String("to_wallet_id", toID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	if idem := options.Idempotency; idem != nil {
+		replayed, err := r.claimIdempotencyKey(ctx, tx, idem)
+		if err != nil {
+			return result, err
+		}
+		if replayed {
+			return result, nil
+		}
 	}
 
-	/* _, err = tx.Exec(ctx, "INSERT INTO transactions (wallet_id, type, amount) VALUES ($1, $2, $3)", walletID, opType, amount)
-	 if err != nil {
-	 	r.logger.Error("Failed to insert transaction",
-	 		slog.String("wallet_id", walletID.String()),
-	 		slog.String("operation", opType),
-	 		slog.Any("amount", amount),
-	 		slog.Any("err", err),
-	 	)
-	 	return currentBalance, false, err
-	}*/
+	for _, id := range []uuid.UUID{fromID, toID} {
+		currency, err := r.walletCurrency(ctx, tx, id)
+		if err != nil {
+			return result, err
+		}
+		if result.Currency == "" {
+			result.Currency = currency
+		}
+		if currency != result.Currency || (options.Currency != "" && options.Currency != currency) {
+			return result, ErrCurrencyMismatch
+		}
+	}
+
+	if err := r.lockWallets(ctx, tx, fromID, toID); err != nil {
+		return result, err
+	}
+	if err := r.checkLimits(ctx, tx, fromID, "WITHDRAW", amount); err != nil {
+		return result, err
+	}
+	if err := r.checkLimits(ctx, tx, toID, "DEPOSIT", amount); err != nil {
+		return result, err
+	}
+
+	transferID := uuid.New()
+	entry := models.LedgerEntry{
+		Type: "TRANSFER",
+		Postings: []models.Posting{
+			{AccountID: models.WalletAccountID(fromID), Amount: amount.Neg(), Currency: result.Currency},
+			{AccountID: models.WalletAccountID(toID), Amount: amount, Currency: result.Currency},
+		},
+	}
+	balances, err := r.postEntry(ctx, tx, &entry, journalMeta{transferID: &transferID})
+	if err != nil {
+		result.FromBalance = balances[fromID]
+		result.ToBalance = balances[toID]
+		return result, err
+	}
+	newFrom, newTo := balances[fromID], balances[toID]
+
+	transfer := models.TransferResult{
+		ID:           transferID,
+		FromWalletID: fromID,
+		ToWalletID:   toID,
+		Amount:       amount,
+		Currency:     result.Currency,
+		FromBalance:  newFrom,
+		ToBalance:    newTo,
+	}
+	opResult := models.OperationResult{Balance: newFrom, Transfer: &transfer}
+	if err := r.storeIdempotentResponse(ctx, tx, options.Idempotency, opResult); err != nil {
+		return result, err
+	}
 
 	if err := tx.Commit(ctx); err != nil {
 		r.logger.Error("Failed to commit transaction",
-			slog.String("wallet_id", walletID.String()),
+			slog.String("from_wallet_id", fromID.String()),
+			slog.String("to_wallet_id", toID.String()),
 			slog.Any("err", err),
 		)
-		return currentBalance, false, err
+		return result, err
 	}
+	return transfer, nil
+}
 
-	return newBalance, created, nil
+func (r *WalletPGRepository) storeIdempotentResponse(
+	ctx context.Context,
+	tx pgx.Tx,
+	idem *models.Idempotency,
+	result models.OperationResult,
+) error {
+	if idem == nil || idem.Response == nil {
+		return nil
+	}
+	resp := idem.Response(result)
+	_, err := tx.Exec(ctx, `
+		UPDATE idempotency_keys SET status_code = $1, response_body = $2 WHERE key = $3`,
+		resp.StatusCode, resp.Body, idem.Key)
+	if err != nil {
+		r.logger.Error("Failed to store idempotent response",
+			slog.String("idempotency_key", idem.Key),
+			slog.Any("err", err),
+		)
+	}
+	return err
+}
+
+// claimIdempotencyKey резервирует ключ в текущей транзакции; для использованного ключа возвращает true.
+func (r *WalletPGRepository) claimIdempotencyKey(ctx context.Context, tx pgx.Tx, idem *models.Idempotency) (bool, error) {
+	tag, err := tx.Exec(ctx, `
+		INSERT INTO idempotency_keys (key, request_hash) VALUES ($1, $2)
+		ON CONFLICT (key) DO NOTHING`, idem.Key, idem.RequestHash)
+	if err != nil {
+		r.logger.Error("Failed to claim idempotency key",
+			slog.String("idempotency_key", idem.Key),
+			slog.Any("err", err),
+		)
+		return false, err
+	}
+	if tag.RowsAffected() == 1 {
+		return false, nil
+	}
+
+	var (
+		requestHash string
+		statusCode  *int
+		body        []byte
+	)
+	err = tx.QueryRow(ctx, `
+		SELECT request_hash, status_code, response_body FROM idempotency_keys WHERE key = $1`,
+		idem.Key).Scan(&requestHash, &statusCode, &body)
+	if err != nil {
+		r.logger.Error("Failed to load idempotency key",
+			slog.String("idempotency_key", idem.Key),
+			slog.Any("err", err),
+		)
+		return false, err
+	}
+	if requestHash != idem.RequestHash {
+		return false, ErrIdempotencyKeyUsed
+	}
+	resp := models.IdempotentResponse{Body: body}
+	if statusCode != nil {
+		resp.StatusCode = *statusCode
+	}
+	idem.Replayed = &resp
+	return true, nil
+}
+
+// LookupIdempotentResponse ищет сохранённый ответ по ключу, не резервируя ключ.
+func (r *WalletPGRepository) LookupIdempotentResponse(ctx context.Context, idem *models.Idempotency) (bool, error) {
+	var (
+		requestHash string
+		statusCode  *int
+		body        []byte
+	)
+	err := r.pool.QueryRow(ctx, `
+		SELECT request_hash, status_code, response_body FROM idempotency_keys WHERE key = $1`,
+		idem.Key).Scan(&requestHash, &statusCode, &body)
+	if err == pgx.ErrNoRows {
+		return false, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to load idempotency key",
+			slog.String("idempotency_key", idem.Key),
+			slog.Any("err", err),
+		)
+		return false, err
+	}
+	if requestHash != idem.RequestHash {
+		return false, ErrIdempotencyKeyUsed
+	}
+	if statusCode == nil {
+		return false, nil
+	}
+	idem.Replayed = &models.IdempotentResponse{StatusCode: *statusCode, Body: body}
+	return true, nil
 }
 
 func (r *WalletPGRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
@@ -151,19 +405,97 @@ func (r *WalletPGRepository) GetBalance(ctx context.Context, walletID uuid.UUID)
 	return balance, nil
 }
 
-// Для тестов
-func (r *WalletPGRepository) CreateWallet(ctx context.Context, walletID uuid.UUID) error {
-	_, err := r.pool.Exec(ctx, "INSERT INTO wallets (id, balance) VALUES ($1, 0)", walletID)
+// GetTransactions возвращает журнал операций кошелька в хронологическом порядке.
+func (r *WalletPGRepository) GetTransactions(ctx context.Context, walletID uuid.UUID) ([]models.Transaction, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE wallet_id = $1
+		ORDER BY created_at, id`, walletID)
 	if err != nil {
-		var pgErr *pgconn.PgError
-		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
-			return ErrWalletAlreadyExist
-		}
-		r.logger.Error("Failed to create wallet",
+		r.logger.Error("Failed to query transactions",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	transactions, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Transaction])
+	if err != nil {
+		r.logger.Error("Failed to scan transactions",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	return transactions, nil
+}
+
+// ListTransactions возвращает страницу истории операций кошелька от новых к старым.
+// Возвращается не более filter.Limit записей, начиная строго после filter.Cursor.
+func (r *WalletPGRepository) ListTransactions(
+	ctx context.Context,
+	walletID uuid.UUID,
+	filter models.TransactionFilter,
+) ([]models.Transaction, error) {
+	var exists bool
+	err := r.pool.QueryRow(ctx, "SELECT EXISTS (SELECT 1 FROM wallets WHERE id = $1)", walletID).Scan(&exists)
+	if err != nil {
+		r.logger.Error("Failed to check wallet existence",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrWalletNotFound
+	}
+
+	query := `
+		SELECT ` + transactionColumns + `
+		FROM transactions
+		WHERE wallet_id = $1`
+	args := []any{walletID}
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+cond, len(args))
+	}
+	if filter.OperationType != "" {
+		addCond("type = $%d", filter.OperationType)
+	}
+	if filter.MinAmount != nil {
+		addCond("ABS(amount) >= $%d", *filter.MinAmount)
+	}
+	if filter.MaxAmount != nil {
+		addCond("ABS(amount) <= $%d", *filter.MaxAmount)
+	}
+	if filter.From != nil {
+		addCond("created_at >= $%d", *filter.From)
+	}
+	if filter.To != nil {
+		addCond("created_at < $%d", *filter.To)
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		query += fmt.Sprintf(" AND (created_at, id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY created_at DESC, id DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query transactions",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	transactions, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Transaction])
+	if err != nil {
+		r.logger.Error("Failed to scan transactions",
 			slog.String("wallet_id", walletID.String()),
 			slog.Any("err", err),
 		)
-		return err
+		return nil, err
 	}
-	return nil
+	return transactions, nil
 }
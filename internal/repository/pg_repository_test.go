@@ -2,15 +2,23 @@ package repository_test
 
 import (
 	"context"
+	"encoding/json"
 	"io"
 	"log/slog"
+	"strings"
 	"sync"
 	"testing"
+	"time"
 
+	"test_wallet/internal/models"
 	"test_wallet/internal/repository"
+	"test_wallet/internal/scheduler"
+	"test_wallet/internal/service"
 	"test_wallet/internal/testutil"
+	"test_wallet/internal/webhooks"
 
 	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5/pgxpool"
 	"github.com/shopspring/decimal"
 	"github.com/stretchr/testify/assert"
 )
@@ -36,6 +44,15 @@ func TestUpdateBalance_EdgeCases(t *testing.T) {
 	// Снятие больше, чем есть
 	_, _, err = repo.UpdateBalance(context.Background(), walletID, decimal.NewFromFloat(-200), "WITHDRAW")
 	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+
+	// Кошелёк, созданный пополнением, получает владельца из опций
+	ownedID := uuid.New()
+	_, created, err = repo.UpdateBalance(context.Background(), ownedID, decimal.NewFromInt(5), "DEPOSIT", models.WithOwner("user-1"))
+	assert.NoError(t, err)
+	assert.True(t, created)
+	wallet, err := repo.GetWallet(context.Background(), ownedID)
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", wallet.OwnerID)
 }
 
 func TestUpdateBalance_ConcurrentDeposits(t *testing.T) {
@@ -43,7 +60,7 @@ func TestUpdateBalance_ConcurrentDeposits(t *testing.T) {
 	defer teardown()
 	repo := repository.NewWalletPGRepository(pool, testLogger)
 	walletID := uuid.New()
-	_ = repo.CreateWallet(context.Background(), walletID)
+	_, _ = repo.CreateWallet(context.Background(), models.Wallet{ID: walletID})
 
 	var wg sync.WaitGroup
 	for i := 0; i < 2000; i++ {
@@ -102,3 +119,1561 @@ func TestGetBalance_NotFound(t *testing.T) {
 	assert.Error(t, err)
 	assert.True(t, balance.Equal(decimal.Zero))
 }
+
+func TestUpdateBalance_WritesJournal(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	walletID := uuid.New()
+
+	_, _, err := repo.UpdateBalance(context.Background(), walletID, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(context.Background(), walletID, decimal.NewFromInt(-30), "WITHDRAW")
+	assert.NoError(t, err)
+	// Неуспешная операция не должна попадать в журнал
+	_, _, err = repo.UpdateBalance(context.Background(), walletID, decimal.NewFromInt(-500), "WITHDRAW")
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+
+	transactions, err := repo.GetTransactions(context.Background(), walletID)
+	assert.NoError(t, err)
+	if assert.Len(t, transactions, 2) {
+		assert.Equal(t, "DEPOSIT", transactions[0].Type)
+		assert.True(t, transactions[0].Amount.Equal(decimal.NewFromInt(100)))
+		assert.True(t, transactions[0].BalanceAfter.Equal(decimal.NewFromInt(100)))
+		assert.Equal(t, "WITHDRAW", transactions[1].Type)
+		assert.True(t, transactions[1].Amount.Equal(decimal.NewFromInt(-30)))
+		assert.True(t, transactions[1].BalanceAfter.Equal(decimal.NewFromInt(70)))
+	}
+}
+
+func TestMigrations_OpeningBalance(t *testing.T) {
+	legacyID, emptyID := uuid.New(), uuid.New()
+	// Кошельки, созданные до появления журнала: баланс есть, строк transactions нет
+	pool, teardown := testutil.SetupTestDBWithSeed(t, "002_transactions_journal.sql", func(ctx context.Context, pool *pgxpool.Pool) {
+		_, err := pool.Exec(ctx, "INSERT INTO wallets (id, balance) VALUES ($1, 150.50), ($2, 0)", legacyID, emptyID)
+		assert.NoError(t, err)
+	})
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+
+	journal, err := repo.GetTransactions(ctx, legacyID)
+	assert.NoError(t, err)
+	if assert.Len(t, journal, 1) {
+		assert.Equal(t, "OPENING_BALANCE", journal[0].Type)
+		assert.True(t, journal[0].Amount.Equal(decimal.RequireFromString("150.50")))
+		assert.True(t, journal[0].BalanceAfter.Equal(decimal.RequireFromString("150.50")))
+		assert.NotNil(t, journal[0].EntryID)
+	}
+	journal, err = repo.GetTransactions(ctx, emptyID)
+	assert.NoError(t, err)
+	assert.Empty(t, journal)
+	balance, err := repo.GetBalanceAt(ctx, legacyID, time.Now())
+	assert.NoError(t, err)
+	assert.True(t, balance.Balance.Equal(decimal.RequireFromString("150.50")))
+
+	// Новые операции продолжают журнал от перенесённого остатка
+	newBalance, _, err := repo.UpdateBalance(ctx, legacyID, decimal.NewFromInt(-50), "WITHDRAW")
+	assert.NoError(t, err)
+	assert.True(t, newBalance.Equal(decimal.RequireFromString("100.50")))
+	report, err := repo.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.True(t, report.OK, "%+v", report.Issues)
+	chain, err := repo.VerifyJournalChain(ctx, nil)
+	assert.NoError(t, err)
+	assert.True(t, chain.OK)
+}
+
+func TestListTransactions_CursorAndFilters(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	for i := 1; i <= 5; i++ {
+		_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(int64(i*10)), "DEPOSIT")
+		assert.NoError(t, err)
+	}
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-5), "WITHDRAW")
+	assert.NoError(t, err)
+
+	// Первая страница — самые новые операции
+	page, err := repo.ListTransactions(ctx, walletID, models.TransactionFilter{Limit: 2})
+	assert.NoError(t, err)
+	if assert.Len(t, page, 2) {
+		assert.Equal(t, "WITHDRAW", page[0].Type)
+		assert.True(t, page[1].Amount.Equal(decimal.NewFromInt(50)))
+	}
+
+	// Следующая страница по курсору
+	last := page[len(page)-1]
+	page, err = repo.ListTransactions(ctx, walletID, models.TransactionFilter{
+		Limit:  10,
+		Cursor: &models.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID},
+	})
+	assert.NoError(t, err)
+	assert.Len(t, page, 4)
+
+	minAmount, maxAmount := decimal.NewFromInt(20), decimal.NewFromInt(40)
+	page, err = repo.ListTransactions(ctx, walletID, models.TransactionFilter{
+		Limit:         10,
+		OperationType: "DEPOSIT",
+		MinAmount:     &minAmount,
+		MaxAmount:     &maxAmount,
+	})
+	assert.NoError(t, err)
+	assert.Len(t, page, 3)
+
+	_, err = repo.ListTransactions(ctx, uuid.New(), models.TransactionFilter{Limit: 10})
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+func TestUpdateBalance_Idempotency(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	newIdem := func(hash string) *models.Idempotency {
+		return &models.Idempotency{
+			Key:         "retry-key",
+			RequestHash: hash,
+			Response: func(result models.OperationResult) models.IdempotentResponse {
+				return models.IdempotentResponse{StatusCode: 201, Body: []byte(`{"balance":"` + result.Balance.String() + `"}`)}
+			},
+		}
+	}
+
+	first := newIdem("hash-1")
+	balance, created, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT", models.WithIdempotency(first))
+	assert.NoError(t, err)
+	assert.True(t, created)
+	assert.True(t, balance.Equal(decimal.NewFromInt(100)))
+	assert.Nil(t, first.Replayed)
+
+	// Повтор с тем же ключом не меняет баланс и возвращает сохранённый ответ
+	retry := newIdem("hash-1")
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT", models.WithIdempotency(retry))
+	assert.NoError(t, err)
+	if assert.NotNil(t, retry.Replayed) {
+		assert.Equal(t, 201, retry.Replayed.StatusCode)
+		assert.JSONEq(t, `{"balance":"100"}`, string(retry.Replayed.Body))
+	}
+
+	// Тот же ключ с другим запросом отклоняется
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(50), "DEPOSIT", models.WithIdempotency(newIdem("hash-2")))
+	assert.ErrorIs(t, err, repository.ErrIdempotencyKeyUsed)
+
+	// Сохранённый ответ можно найти, не резервируя ключ
+	lookup := newIdem("hash-1")
+	replayed, err := repo.LookupIdempotentResponse(ctx, lookup)
+	assert.NoError(t, err)
+	assert.True(t, replayed)
+	assert.JSONEq(t, `{"balance":"100"}`, string(lookup.Replayed.Body))
+	_, err = repo.LookupIdempotentResponse(ctx, newIdem("hash-2"))
+	assert.ErrorIs(t, err, repository.ErrIdempotencyKeyUsed)
+	unknown := newIdem("hash-1")
+	unknown.Key = "unknown-key"
+	replayed, err = repo.LookupIdempotentResponse(ctx, unknown)
+	assert.NoError(t, err)
+	assert.False(t, replayed)
+
+	balance, err = repo.GetBalance(ctx, walletID)
+	assert.NoError(t, err)
+	assert.True(t, balance.Equal(decimal.NewFromInt(100)))
+}
+
+func TestTransfer_ConcurrentOppositeDirections(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletA, walletB := uuid.New(), uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, walletA, decimal.NewFromInt(1000), "DEPOSIT")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletB, decimal.NewFromInt(1000), "DEPOSIT")
+	assert.NoError(t, err)
+
+	// Встречные переводы блокируют строки в одном порядке и не должны упираться в deadlock
+	var wg sync.WaitGroup
+	for i := 0; i < 200; i++ {
+		wg.Add(2)
+		go func() {
+			defer wg.Done()
+			_, err := repo.Transfer(ctx, walletA, walletB, decimal.NewFromInt(1))
+			assert.NoError(t, err)
+		}()
+		go func() {
+			defer wg.Done()
+			_, err := repo.Transfer(ctx, walletB, walletA, decimal.NewFromInt(1))
+			assert.NoError(t, err)
+		}()
+	}
+	wg.Wait()
+
+	balanceA, err := repo.GetBalance(ctx, walletA)
+	assert.NoError(t, err)
+	balanceB, err := repo.GetBalance(ctx, walletB)
+	assert.NoError(t, err)
+	assert.True(t, balanceA.Add(balanceB).Equal(decimal.NewFromInt(2000)))
+}
+
+func TestTransfer_InsufficientFundsAndJournal(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletA, walletB := uuid.New(), uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, walletA, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+	_, err = repo.CreateWallet(ctx, models.Wallet{ID: walletB})
+	assert.NoError(t, err)
+
+	_, err = repo.Transfer(ctx, walletA, walletB, decimal.NewFromInt(101))
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+	_, err = repo.Transfer(ctx, walletA, uuid.New(), decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+
+	result, err := repo.Transfer(ctx, walletA, walletB, decimal.NewFromInt(40))
+	assert.NoError(t, err)
+	assert.True(t, result.FromBalance.Equal(decimal.NewFromInt(60)))
+	assert.True(t, result.ToBalance.Equal(decimal.NewFromInt(40)))
+
+	in, err := repo.GetTransactions(ctx, walletB)
+	assert.NoError(t, err)
+	if assert.Len(t, in, 1) {
+		assert.Equal(t, "TRANSFER_IN", in[0].Type)
+		assert.Equal(t, result.ID, *in[0].TransferID)
+	}
+}
+
+func TestLedger_PostingsSumToZero(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletA, walletB := uuid.New(), uuid.New()
+
+	_, _, err := repo.UpdateBalance(ctx, walletA, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletB, decimal.NewFromInt(10), "DEPOSIT")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletA, decimal.NewFromInt(-30), "WITHDRAW")
+	assert.NoError(t, err)
+	_, err = repo.Transfer(ctx, walletA, walletB, decimal.NewFromInt(20))
+	assert.NoError(t, err)
+
+	postings, err := repo.GetPostings(ctx, models.WalletAccountID(walletA))
+	assert.NoError(t, err)
+	assert.Len(t, postings, 3)
+	sum := decimal.Zero
+	for _, p := range postings {
+		sum = sum.Add(p.Amount)
+	}
+	balance, err := repo.GetBalance(ctx, walletA)
+	assert.NoError(t, err)
+	assert.True(t, sum.Equal(balance))
+
+	tb, err := repo.GetTrialBalance(ctx)
+	assert.NoError(t, err)
+	assert.True(t, tb.Balanced)
+	assert.True(t, tb.Totals["RUB"].IsZero())
+	assert.Empty(t, tb.UnbalancedEntries)
+	accounts := make(map[string]decimal.Decimal)
+	for _, a := range tb.Accounts {
+		accounts[a.AccountID] = a.Balance
+	}
+	cashIn := models.SystemAccountID(models.SystemExternalCashIn, "RUB")
+	cashOut := models.SystemAccountID(models.SystemExternalCashOut, "RUB")
+	assert.True(t, accounts[cashIn].Equal(decimal.NewFromInt(-110)))
+	assert.True(t, accounts[cashOut].Equal(decimal.NewFromInt(30)))
+	assert.True(t, accounts[models.WalletAccountID(walletB)].Equal(decimal.NewFromInt(30)))
+}
+
+func TestCurrencies_ScaleAndMismatch(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	jpy, bhd, rub := uuid.New(), uuid.New(), uuid.New()
+
+	_, _, err := repo.UpdateBalance(ctx, jpy, decimal.RequireFromString("100.5"), "DEPOSIT", models.WithCurrency("JPY"))
+	assert.ErrorIs(t, err, models.ErrInvalidAmountScale)
+	_, _, err = repo.UpdateBalance(ctx, jpy, decimal.NewFromInt(100), "DEPOSIT", models.WithCurrency("JPY"))
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, bhd, decimal.RequireFromString("1.234"), "DEPOSIT", models.WithCurrency("BHD"))
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, rub, decimal.NewFromInt(10), "DEPOSIT")
+	assert.NoError(t, err)
+
+	wallet, err := repo.GetWallet(ctx, bhd)
+	assert.NoError(t, err)
+	assert.Equal(t, "BHD", wallet.Currency)
+	assert.True(t, wallet.Balance.Equal(decimal.RequireFromString("1.234")))
+
+	_, _, err = repo.UpdateBalance(ctx, jpy, decimal.NewFromInt(1), "DEPOSIT", models.WithCurrency("USD"))
+	assert.ErrorIs(t, err, repository.ErrCurrencyMismatch)
+	_, err = repo.Transfer(ctx, jpy, rub, decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, repository.ErrCurrencyMismatch)
+
+	tb, err := repo.GetTrialBalance(ctx)
+	assert.NoError(t, err)
+	assert.True(t, tb.Balanced)
+	for _, currency := range []string{"JPY", "BHD", "RUB"} {
+		total, ok := tb.Totals[currency]
+		assert.True(t, ok, currency)
+		assert.True(t, total.IsZero(), currency)
+	}
+}
+
+func TestConversion_ExecuteQuote(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	usd, rub := uuid.New(), uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, usd, decimal.NewFromInt(100), "DEPOSIT", models.WithCurrency("USD"))
+	assert.NoError(t, err)
+	_, err = repo.CreateWallet(ctx, models.Wallet{ID: rub})
+	assert.NoError(t, err)
+
+	rateAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	quote, err := repo.CreateQuote(ctx, models.ConversionQuote{
+		ID: uuid.New(), FromWalletID: usd, ToWalletID: rub,
+		FromCurrency: "USD", ToCurrency: "RUB",
+		FromAmount: decimal.NewFromInt(10), ToAmount: decimal.NewFromInt(925),
+		Rate: decimal.RequireFromString("92.5"), RateTimestamp: rateAt,
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	assert.NoError(t, err)
+
+	result, err := repo.ExecuteConversion(ctx, quote.ID)
+	assert.NoError(t, err)
+	assert.True(t, result.FromBalance.Equal(decimal.NewFromInt(90)))
+	assert.True(t, result.ToBalance.Equal(decimal.NewFromInt(925)))
+	assert.NotNil(t, result.Quote.ExecutedAt)
+
+	_, err = repo.ExecuteConversion(ctx, quote.ID)
+	assert.ErrorIs(t, err, repository.ErrQuoteExecuted)
+
+	journal, err := repo.GetTransactions(ctx, rub)
+	assert.NoError(t, err)
+	if assert.Len(t, journal, 1) {
+		assert.Equal(t, "CONVERSION_IN", journal[0].Type)
+		assert.Equal(t, quote.ID, *journal[0].ConversionID)
+		assert.True(t, journal[0].Rate.Equal(decimal.RequireFromString("92.5")))
+		assert.True(t, journal[0].RateTimestamp.Equal(rateAt))
+	}
+
+	expired, err := repo.CreateQuote(ctx, models.ConversionQuote{
+		ID: uuid.New(), FromWalletID: usd, ToWalletID: rub,
+		FromCurrency: "USD", ToCurrency: "RUB",
+		FromAmount: decimal.NewFromInt(1), ToAmount: decimal.RequireFromString("92.5"),
+		Rate: decimal.RequireFromString("92.5"), RateTimestamp: rateAt,
+		ExpiresAt: time.Now().Add(-time.Second),
+	})
+	assert.NoError(t, err)
+	_, err = repo.ExecuteConversion(ctx, expired.ID)
+	assert.ErrorIs(t, err, repository.ErrQuoteExpired)
+
+	tb, err := repo.GetTrialBalance(ctx)
+	assert.NoError(t, err)
+	assert.True(t, tb.Balanced)
+}
+
+func TestReverseTransaction(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-70), "WITHDRAW")
+	assert.NoError(t, err)
+	journal, err := repo.GetTransactions(ctx, walletID)
+	assert.NoError(t, err)
+	deposit, withdraw := journal[0], journal[1]
+
+	part := decimal.NewFromInt(20)
+	result, err := repo.ReverseTransaction(ctx, withdraw.ID, &part, false)
+	assert.NoError(t, err)
+	assert.True(t, result.Balance.Equal(decimal.NewFromInt(50)))
+	assert.Equal(t, withdraw.ID, *result.Reversal.ReversalOf)
+	assert.True(t, result.Original.ReversedAmount.Equal(part))
+
+	tooMuch := decimal.NewFromInt(51)
+	_, err = repo.ReverseTransaction(ctx, withdraw.ID, &tooMuch, false)
+	assert.ErrorIs(t, err, repository.ErrReversalExceedsOriginal)
+	_, err = repo.ReverseTransaction(ctx, withdraw.ID, nil, false)
+	assert.NoError(t, err)
+	_, err = repo.ReverseTransaction(ctx, withdraw.ID, nil, false)
+	assert.ErrorIs(t, err, repository.ErrAlreadyReversed)
+	_, err = repo.ReverseTransaction(ctx, result.Reversal.ID, nil, false)
+	assert.ErrorIs(t, err, repository.ErrNotReversible)
+
+	// Баланс 100: сторно пополнения после повторного списания уже потраченных денег
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-60), "WITHDRAW")
+	assert.NoError(t, err)
+	_, err = repo.ReverseTransaction(ctx, deposit.ID, nil, false)
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+	result, err = repo.ReverseTransaction(ctx, deposit.ID, nil, true)
+	assert.NoError(t, err)
+	assert.True(t, result.Balance.Equal(decimal.NewFromInt(-60)))
+
+	tb, err := repo.GetTrialBalance(ctx)
+	assert.NoError(t, err)
+	assert.True(t, tb.Balanced)
+}
+
+func TestWalletStatus(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger, repository.WithFrozenDeposits(true))
+	ctx := context.Background()
+	walletID, otherID := uuid.New(), uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+	_, err = repo.CreateWallet(ctx, models.Wallet{ID: otherID})
+	assert.NoError(t, err)
+
+	wallet, err := repo.SetWalletStatus(ctx, walletID, models.WalletFrozen, "compliance")
+	assert.NoError(t, err)
+	assert.Equal(t, models.WalletFrozen, wallet.Status)
+	assert.Equal(t, "compliance", *wallet.StatusReason)
+
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-10), "WITHDRAW")
+	assert.ErrorIs(t, err, repository.ErrWalletFrozen)
+	_, err = repo.Transfer(ctx, otherID, walletID, decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, repository.ErrWalletFrozen)
+	_, err = repo.CreateHold(ctx, walletID, decimal.NewFromInt(1), time.Now().Add(time.Minute))
+	assert.ErrorIs(t, err, repository.ErrWalletFrozen)
+	balance, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(5), "DEPOSIT")
+	assert.NoError(t, err)
+	assert.True(t, balance.Equal(decimal.NewFromInt(105)))
+
+	_, err = repo.SetWalletStatus(ctx, walletID, models.WalletClosed, "")
+	assert.ErrorIs(t, err, repository.ErrWalletNotEmpty)
+	_, err = repo.SetWalletStatus(ctx, walletID, models.WalletActive, "")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-105), "WITHDRAW")
+	assert.NoError(t, err)
+
+	_, err = repo.SetWalletStatus(ctx, walletID, models.WalletClosed, "customer request")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(1), "DEPOSIT")
+	assert.ErrorIs(t, err, repository.ErrWalletClosed)
+	_, err = repo.SetWalletStatus(ctx, walletID, models.WalletActive, "")
+	assert.ErrorIs(t, err, repository.ErrWalletClosed)
+}
+
+func TestWalletManagement(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger, repository.WithImplicitCreation(false))
+	ctx := context.Background()
+
+	_, _, err := repo.UpdateBalance(ctx, uuid.New(), decimal.NewFromInt(10), "DEPOSIT")
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+
+	ids := []uuid.UUID{uuid.New(), uuid.New(), uuid.New()}
+	for i, id := range ids {
+		labels := map[string]string{"tier": "gold"}
+		if i == 0 {
+			labels["tier"] = "silver"
+		}
+		wallet, err := repo.CreateWallet(ctx, models.Wallet{ID: id, OwnerID: "user-1", Name: "main", Labels: labels})
+		assert.NoError(t, err)
+		assert.Equal(t, models.DefaultCurrency, wallet.Currency)
+		assert.Equal(t, models.WalletActive, wallet.Status)
+		assert.False(t, wallet.CreatedAt.IsZero())
+	}
+	_, err = repo.CreateWallet(ctx, models.Wallet{ID: ids[0]})
+	assert.ErrorIs(t, err, repository.ErrWalletAlreadyExist)
+
+	_, _, err = repo.UpdateBalance(ctx, ids[0], decimal.NewFromInt(10), "DEPOSIT")
+	assert.NoError(t, err)
+
+	wallets, err := repo.ListWallets(ctx, models.WalletFilter{OwnerID: "user-1", Labels: map[string]string{"tier": "gold"}, Limit: 10})
+	assert.NoError(t, err)
+	if assert.Len(t, wallets, 2) {
+		// Кошельки отдаются от новых к старым
+		assert.False(t, wallets[0].CreatedAt.Before(wallets[1].CreatedAt))
+		next, err := repo.ListWallets(ctx, models.WalletFilter{
+			OwnerID: "user-1",
+			Cursor:  &models.WalletCursor{CreatedAt: wallets[0].CreatedAt, ID: wallets[0].ID},
+			Limit:   10,
+		})
+		assert.NoError(t, err)
+		assert.Len(t, next, 2)
+	}
+
+	name := "savings"
+	labels := map[string]string{"tier": "platinum"}
+	updated, err := repo.UpdateWallet(ctx, ids[0], models.UpdateWalletRequest{Name: &name, Labels: &labels})
+	assert.NoError(t, err)
+	assert.Equal(t, "savings", updated.Name)
+	assert.Equal(t, "user-1", updated.OwnerID)
+	assert.Equal(t, labels, updated.Labels)
+	assert.True(t, updated.Balance.Equal(decimal.NewFromInt(10)))
+	assert.True(t, updated.UpdatedAt.After(updated.CreatedAt))
+
+	_, err = repo.UpdateWallet(ctx, uuid.New(), models.UpdateWalletRequest{Name: &name})
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+func TestOverdraftLimit(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(10), "DEPOSIT")
+	assert.NoError(t, err)
+
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-20), "WITHDRAW")
+	var funds *repository.InsufficientFundsError
+	if assert.ErrorAs(t, err, &funds) {
+		assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+		assert.True(t, funds.AvailableCredit.Equal(decimal.NewFromInt(10)))
+	}
+
+	wallet, err := repo.SetOverdraftLimit(ctx, walletID, decimal.NewFromInt(50), "approved")
+	assert.NoError(t, err)
+	assert.True(t, wallet.OverdraftLimit.Equal(decimal.NewFromInt(50)))
+	assert.True(t, wallet.Available.Equal(decimal.NewFromInt(60)))
+
+	balance, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-55), "WITHDRAW")
+	assert.NoError(t, err)
+	assert.True(t, balance.Equal(decimal.NewFromInt(-45)))
+	_, err = repo.CreateHold(ctx, walletID, decimal.NewFromInt(6), time.Now().Add(time.Minute))
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-6), "WITHDRAW")
+	if assert.ErrorAs(t, err, &funds) {
+		assert.True(t, funds.AvailableCredit.Equal(decimal.NewFromInt(5)))
+	}
+
+	// Лимит можно опустить ниже текущего долга: новые списания отклоняются
+	_, err = repo.SetOverdraftLimit(ctx, walletID, decimal.NewFromInt(20), "")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-1), "WITHDRAW")
+	if assert.ErrorAs(t, err, &funds) {
+		assert.True(t, funds.AvailableCredit.IsZero())
+	}
+	_, err = repo.SetOverdraftLimit(ctx, walletID, decimal.NewFromFloat(0.001), "")
+	assert.ErrorIs(t, err, models.ErrInvalidAmountScale)
+
+	changes, err := repo.GetOverdraftLimitChanges(ctx, walletID)
+	assert.NoError(t, err)
+	if assert.Len(t, changes, 2) {
+		assert.True(t, changes[0].OldLimit.IsZero())
+		assert.True(t, changes[0].NewLimit.Equal(decimal.NewFromInt(50)))
+		assert.Equal(t, "approved", *changes[0].Reason)
+		assert.True(t, changes[1].OldLimit.Equal(decimal.NewFromInt(50)))
+		assert.Nil(t, changes[1].Reason)
+	}
+	_, err = repo.GetOverdraftLimitChanges(ctx, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+func TestSpendingLimits(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID, otherID := uuid.New(), uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(1000), "DEPOSIT")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, otherID, decimal.NewFromInt(1000), "DEPOSIT")
+	assert.NoError(t, err)
+
+	single, daily := decimal.NewFromInt(100), decimal.NewFromInt(150)
+	assert.NoError(t, repo.SetLimitTier(ctx, models.DefaultLimitTier, models.DefaultCurrency, models.SpendingLimit{
+		Operation:       "WITHDRAW",
+		MaxSingleAmount: &single,
+		DailyCap:        &daily,
+	}))
+	maxOps, window := 3, 3600
+	assert.NoError(t, repo.SetLimitTier(ctx, "kyc2", models.DefaultCurrency, models.SpendingLimit{
+		Operation:               "WITHDRAW",
+		MaxOperations:           &maxOps,
+		OperationsWindowSeconds: &window,
+	}))
+
+	var limitErr *repository.LimitExceededError
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-101), "WITHDRAW")
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.ErrorIs(t, err, repository.ErrLimitExceeded)
+		assert.Equal(t, models.LimitMaxSingleAmount, limitErr.Limit)
+	}
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-100), "WITHDRAW")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-60), "WITHDRAW")
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, models.LimitDailyCap, limitErr.Limit)
+		assert.True(t, limitErr.Used.Equal(decimal.NewFromInt(100)))
+	}
+	// Пополнения лимиты списаний не затрагивают
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(500), "DEPOSIT")
+	assert.NoError(t, err)
+
+	// Лимит кошелька переопределяет поле тарифа, остальные поля берутся из тарифа
+	bigger := decimal.NewFromInt(1000)
+	assert.NoError(t, repo.SetWalletLimit(ctx, walletID, models.SpendingLimit{Operation: "WITHDRAW", DailyCap: &bigger}))
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-60), "WITHDRAW")
+	assert.NoError(t, err)
+	limits, err := repo.GetWalletLimits(ctx, walletID)
+	assert.NoError(t, err)
+	if assert.Len(t, limits, 2) {
+		assert.Nil(t, limits[0].DailyCap)
+		assert.True(t, limits[1].DailyCap.Equal(bigger))
+		assert.True(t, limits[1].MaxSingleAmount.Equal(single))
+	}
+
+	_, err = repo.SetWalletTier(ctx, otherID, "unknown")
+	assert.ErrorIs(t, err, repository.ErrLimitTierNotFound)
+	wallet, err := repo.SetWalletTier(ctx, otherID, "kyc2")
+	assert.NoError(t, err)
+	assert.Equal(t, "kyc2", *wallet.LimitTier)
+	for i := 0; i < maxOps; i++ {
+		_, _, err = repo.UpdateBalance(ctx, otherID, decimal.NewFromInt(-200), "WITHDRAW")
+		assert.NoError(t, err)
+	}
+	_, _, err = repo.UpdateBalance(ctx, otherID, decimal.NewFromInt(-1), "WITHDRAW")
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, models.LimitMaxOperations, limitErr.Limit)
+	}
+
+	assert.ErrorIs(t, repo.SetWalletLimit(ctx, uuid.New(), models.SpendingLimit{Operation: "DEPOSIT"}), repository.ErrWalletNotFound)
+}
+
+func TestFees(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	minFee := decimal.NewFromInt(1)
+	_, err := repo.SetFeeSchedule(ctx, models.FeeSchedule{
+		Operation: "WITHDRAW",
+		Currency:  models.DefaultCurrency,
+		Percent:   decimal.NewFromInt(2),
+		MinFee:    &minFee,
+	})
+	assert.NoError(t, err)
+	_, err = repo.SetFeeSchedule(ctx, models.FeeSchedule{
+		Operation: "DEPOSIT",
+		Currency:  models.DefaultCurrency,
+		Tiers: []models.FeeTier{
+			{UpTo: &minFee, Flat: decimal.Zero},
+			{Flat: decimal.RequireFromString("0.5")},
+		},
+	})
+	assert.NoError(t, err)
+	schedules, err := repo.ListFeeSchedules(ctx)
+	assert.NoError(t, err)
+	if assert.Len(t, schedules, 2) {
+		assert.Len(t, schedules[0].Tiers, 2)
+	}
+
+	var fee decimal.Decimal
+	balance, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT", models.WithFeeReport(&fee))
+	assert.NoError(t, err)
+	assert.True(t, fee.Equal(decimal.RequireFromString("0.5")))
+	assert.True(t, balance.Equal(decimal.RequireFromString("99.5")))
+
+	// Списание 98 с комиссией 1.96 больше баланса 99.5
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.RequireFromString("-98"), "WITHDRAW")
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+	balance, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-90), "WITHDRAW", models.WithFeeReport(&fee))
+	assert.NoError(t, err)
+	assert.True(t, fee.Equal(decimal.RequireFromString("1.8")))
+	assert.True(t, balance.Equal(decimal.RequireFromString("7.7")))
+
+	transactions, err := repo.GetTransactions(ctx, walletID)
+	assert.NoError(t, err)
+	if assert.Len(t, transactions, 4) {
+		assert.Equal(t, "DEPOSIT", transactions[0].Type)
+		assert.True(t, transactions[0].BalanceAfter.Equal(decimal.NewFromInt(100)))
+		assert.Equal(t, "FEE", transactions[1].Type)
+		assert.True(t, transactions[1].Amount.Equal(decimal.RequireFromString("-0.5")))
+		assert.Equal(t, *transactions[0].EntryID, *transactions[1].EntryID)
+		assert.Equal(t, "WITHDRAW", transactions[2].Type)
+		assert.Equal(t, "FEE", transactions[3].Type)
+		assert.True(t, transactions[3].BalanceAfter.Equal(decimal.RequireFromString("7.7")))
+	}
+
+	feeAccount, err := repo.GetPostings(ctx, models.SystemAccountID(models.SystemFees, models.DefaultCurrency))
+	assert.NoError(t, err)
+	assert.Len(t, feeAccount, 2)
+	tb, err := repo.GetTrialBalance(ctx)
+	assert.NoError(t, err)
+	assert.True(t, tb.Balanced)
+
+	// Сторно возвращает комиссию пропорционально, полное сторно — целиком
+	part := decimal.NewFromInt(30)
+	reversal, err := repo.ReverseTransaction(ctx, transactions[2].ID, &part, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, reversal.FeeRefund) {
+		assert.Equal(t, "REVERSAL", reversal.FeeRefund.Type)
+		assert.Equal(t, transactions[3].ID, *reversal.FeeRefund.ReversalOf)
+		assert.True(t, reversal.FeeRefund.Amount.Equal(decimal.RequireFromString("0.6")))
+	}
+	assert.True(t, reversal.Balance.Equal(decimal.RequireFromString("38.3")))
+	reversal, err = repo.ReverseTransaction(ctx, transactions[2].ID, nil, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, reversal.FeeRefund) {
+		assert.True(t, reversal.FeeRefund.Amount.Equal(decimal.RequireFromString("1.2")))
+	}
+	assert.True(t, reversal.Balance.Equal(decimal.RequireFromString("99.5")))
+
+	// 0.5 * 33 / 100 = 0.165 округляется до 0.17, остаток комиссии возвращает полное сторно
+	part = decimal.NewFromInt(33)
+	reversal, err = repo.ReverseTransaction(ctx, transactions[0].ID, &part, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, reversal.FeeRefund) {
+		assert.True(t, reversal.FeeRefund.Amount.Equal(decimal.RequireFromString("0.17")))
+	}
+	assert.True(t, reversal.Balance.Equal(decimal.RequireFromString("66.67")))
+	reversal, err = repo.ReverseTransaction(ctx, transactions[0].ID, nil, false)
+	assert.NoError(t, err)
+	if assert.NotNil(t, reversal.FeeRefund) {
+		assert.True(t, reversal.FeeRefund.Amount.Equal(decimal.RequireFromString("0.33")))
+	}
+	assert.True(t, reversal.Balance.IsZero())
+
+	feeAccount, err = repo.GetPostings(ctx, models.SystemAccountID(models.SystemFees, models.DefaultCurrency))
+	assert.NoError(t, err)
+	feeTotal := decimal.Zero
+	for _, p := range feeAccount {
+		feeTotal = feeTotal.Add(p.Amount)
+	}
+	assert.True(t, feeTotal.IsZero())
+	tb, err = repo.GetTrialBalance(ctx)
+	assert.NoError(t, err)
+	assert.True(t, tb.Balanced)
+
+	quoted, err := repo.QuoteFee(ctx, "WITHDRAW", models.DefaultCurrency, decimal.NewFromInt(10))
+	assert.NoError(t, err)
+	assert.True(t, quoted.Equal(minFee))
+	quoted, err = repo.QuoteFee(ctx, "WITHDRAW", "USD", decimal.NewFromInt(10))
+	assert.NoError(t, err)
+	assert.True(t, quoted.IsZero())
+}
+
+func TestSpendingLimits_CaptureTransferConversion(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID, otherID, usdID := uuid.New(), uuid.New(), uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(1000), "DEPOSIT")
+	assert.NoError(t, err)
+	_, err = repo.CreateWallet(ctx, models.Wallet{ID: otherID})
+	assert.NoError(t, err)
+	_, err = repo.CreateWallet(ctx, models.Wallet{ID: usdID, Currency: "USD"})
+	assert.NoError(t, err)
+	daily := decimal.NewFromInt(100)
+	assert.NoError(t, repo.SetWalletLimit(ctx, walletID, models.SpendingLimit{Operation: "WITHDRAW", DailyCap: &daily}))
+
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-60), "WITHDRAW")
+	assert.NoError(t, err)
+
+	// Холд на сумму сверх остатка лимита создаётся, но захватить его целиком нельзя
+	hold, err := repo.CreateHold(ctx, walletID, decimal.NewFromInt(50), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	var limitErr *repository.LimitExceededError
+	_, err = repo.CaptureHold(ctx, hold.Hold.ID, nil)
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.Equal(t, models.LimitDailyCap, limitErr.Limit)
+		assert.True(t, limitErr.Used.Equal(decimal.NewFromInt(60)))
+	}
+	part := decimal.NewFromInt(40)
+	captured, err := repo.CaptureHold(ctx, hold.Hold.ID, &part)
+	assert.NoError(t, err)
+	assert.True(t, captured.Wallet.Balance.Equal(decimal.NewFromInt(900)))
+
+	// Захват израсходовал лимит: списания, переводы и конвертации дальше отклоняются
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-1), "WITHDRAW")
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.True(t, limitErr.Used.Equal(decimal.NewFromInt(100)))
+	}
+	_, err = repo.Transfer(ctx, walletID, otherID, decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, repository.ErrLimitExceeded)
+	quote, err := repo.CreateQuote(ctx, models.ConversionQuote{
+		ID: uuid.New(), FromWalletID: walletID, ToWalletID: usdID,
+		FromCurrency: models.DefaultCurrency, ToCurrency: "USD",
+		FromAmount: decimal.NewFromInt(10), ToAmount: decimal.RequireFromString("0.11"),
+		Rate: decimal.RequireFromString("0.011"), RateTimestamp: time.Now(),
+		ExpiresAt: time.Now().Add(time.Minute),
+	})
+	assert.NoError(t, err)
+	_, err = repo.ExecuteConversion(ctx, quote.ID)
+	assert.ErrorIs(t, err, repository.ErrLimitExceeded)
+
+	// Перевод тоже расходует лимит списаний
+	bigger := decimal.NewFromInt(150)
+	assert.NoError(t, repo.SetWalletLimit(ctx, walletID, models.SpendingLimit{Operation: "WITHDRAW", DailyCap: &bigger}))
+	_, err = repo.Transfer(ctx, walletID, otherID, decimal.NewFromInt(50))
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-1), "WITHDRAW")
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.True(t, limitErr.Used.Equal(decimal.NewFromInt(150)))
+	}
+
+	// Входящий перевод расходует лимит пополнений получателя
+	depositCap := decimal.NewFromInt(60)
+	assert.NoError(t, repo.SetWalletLimit(ctx, otherID, models.SpendingLimit{Operation: "DEPOSIT", DailyCap: &depositCap}))
+	_, _, err = repo.UpdateBalance(ctx, otherID, decimal.NewFromInt(20), "DEPOSIT")
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.True(t, limitErr.Used.Equal(decimal.NewFromInt(50)))
+	}
+	unlimited := decimal.NewFromInt(1000)
+	assert.NoError(t, repo.SetWalletLimit(ctx, walletID, models.SpendingLimit{Operation: "WITHDRAW", DailyCap: &unlimited}))
+	_, err = repo.Transfer(ctx, walletID, otherID, decimal.NewFromInt(20))
+	if assert.ErrorAs(t, err, &limitErr) {
+		assert.True(t, limitErr.Max.Equal(depositCap))
+		assert.True(t, limitErr.Used.Equal(decimal.NewFromInt(50)))
+	}
+}
+
+func TestHolds_ReserveCaptureVoid(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+
+	hold, err := repo.CreateHold(ctx, walletID, decimal.NewFromInt(70), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	assert.True(t, hold.Wallet.Available.Equal(decimal.NewFromInt(30)))
+
+	// Списание и новый холд учитывают уже зарезервированные средства
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-50), "WITHDRAW")
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+	_, err = repo.CreateHold(ctx, walletID, decimal.NewFromInt(31), time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+
+	// Частичное списание освобождает остаток
+	amount := decimal.NewFromInt(40)
+	captured, err := repo.CaptureHold(ctx, hold.Hold.ID, &amount)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HoldCaptured, captured.Hold.Status)
+	assert.True(t, captured.Wallet.Balance.Equal(decimal.NewFromInt(60)))
+	assert.True(t, captured.Wallet.Available.Equal(decimal.NewFromInt(60)))
+
+	_, err = repo.CaptureHold(ctx, hold.Hold.ID, nil)
+	assert.ErrorIs(t, err, repository.ErrHoldNotActive)
+
+	second, err := repo.CreateHold(ctx, walletID, decimal.NewFromInt(60), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	voided, err := repo.VoidHold(ctx, second.Hold.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HoldVoided, voided.Hold.Status)
+	assert.True(t, voided.Wallet.Available.Equal(decimal.NewFromInt(60)))
+
+	// Повтор с тем же ключом идемпотентности не ставит второй холд
+	newIdem := func() *models.Idempotency {
+		return &models.Idempotency{
+			Key:         "hold-key",
+			RequestHash: "hold-hash",
+			Response: func(result models.OperationResult) models.IdempotentResponse {
+				return models.IdempotentResponse{StatusCode: 201, Body: []byte(`{"holdId":"` + result.Hold.Hold.ID.String() + `"}`)}
+			},
+		}
+	}
+	third, err := repo.CreateHold(ctx, walletID, decimal.NewFromInt(10), time.Now().Add(time.Hour), models.WithIdempotency(newIdem()))
+	assert.NoError(t, err)
+	assert.True(t, third.Wallet.Available.Equal(decimal.NewFromInt(50)))
+	retry := newIdem()
+	_, err = repo.CreateHold(ctx, walletID, decimal.NewFromInt(10), time.Now().Add(time.Hour), models.WithIdempotency(retry))
+	assert.NoError(t, err)
+	if assert.NotNil(t, retry.Replayed) {
+		assert.JSONEq(t, `{"holdId":"`+third.Hold.ID.String()+`"}`, string(retry.Replayed.Body))
+	}
+	wallet, err := repo.GetWallet(ctx, walletID)
+	assert.NoError(t, err)
+	assert.True(t, wallet.Held.Equal(decimal.NewFromInt(10)))
+	_, err = repo.VoidHold(ctx, third.Hold.ID)
+	assert.NoError(t, err)
+
+	transactions, err := repo.GetTransactions(ctx, walletID)
+	assert.NoError(t, err)
+	if assert.Len(t, transactions, 2) {
+		assert.Equal(t, "CAPTURE", transactions[1].Type)
+		assert.Equal(t, hold.Hold.ID, *transactions[1].HoldID)
+	}
+}
+
+func TestHolds_Expiry(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+
+	hold, err := repo.CreateHold(ctx, walletID, decimal.NewFromInt(100), time.Now().Add(-time.Second))
+	assert.NoError(t, err)
+
+	// Просроченный холд не блокирует средства и не может быть списан
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-100), "WITHDRAW")
+	assert.NoError(t, err)
+	_, err = repo.CaptureHold(ctx, hold.Hold.ID, nil)
+	assert.ErrorIs(t, err, repository.ErrHoldExpired)
+
+	n, err := repo.ExpireHolds(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	expired, err := repo.GetHold(ctx, hold.Hold.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HoldExpired, expired.Status)
+}
+
+func TestScheduledOperations(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	svc := service.NewWalletService(repo, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+	_, err := repo.CreateWallet(ctx, models.Wallet{ID: walletID})
+	assert.NoError(t, err)
+
+	past := time.Now().Add(-time.Minute)
+	op, err := svc.CreateScheduledOperation(ctx, models.ScheduleRequest{
+		Operation: "DEPOSIT", WalletID: walletID, Amount: decimal.NewFromInt(100), RunAt: &past,
+	})
+	assert.NoError(t, err)
+	_, err = svc.CreateScheduledOperation(ctx, models.ScheduleRequest{
+		Operation: "DEPOSIT", WalletID: uuid.New(), Amount: decimal.NewFromInt(100), RunAt: &past,
+	})
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+
+	// Блокировку срабатывания может держать только одна реплика
+	unlock, ok, err := repo.LockScheduledOperation(ctx, op.ID)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, ok, err = repo.LockScheduledOperation(ctx, op.ID)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	sched := scheduler.New(repo, svc, testLogger)
+	n, err := sched.RunDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	unlock()
+
+	// Срабатывание уже проведено, но реплика упала до записи попытки: повтор не удваивает пополнение
+	_, _, err = repo.UpdateBalance(ctx, walletID, op.Amount, "DEPOSIT", models.WithIdempotency(&models.Idempotency{
+		Key: op.IdempotencyKey(op.OccurrenceAt), RequestHash: op.RequestHash(),
+	}))
+	assert.NoError(t, err)
+	n, err = sched.RunDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	balance, err := repo.GetBalance(ctx, walletID)
+	assert.NoError(t, err)
+	assert.True(t, balance.Equal(decimal.NewFromInt(100)))
+
+	op, err = repo.GetScheduledOperation(ctx, op.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ScheduleCompleted, op.Status)
+	runs, err := repo.GetScheduledRuns(ctx, op.ID)
+	assert.NoError(t, err)
+	if assert.Len(t, runs, 1) {
+		assert.Equal(t, models.RunSucceeded, runs[0].Status)
+	}
+
+	// Регулярное списание без средств: неудача записывается и откладывается на повтор
+	withdrawal, err := svc.CreateScheduledOperation(ctx, models.ScheduleRequest{
+		Operation: "WITHDRAW", WalletID: walletID, Amount: decimal.NewFromInt(500), Cron: "* * * * *",
+	})
+	assert.NoError(t, err)
+	later := scheduler.New(repo, svc, testLogger, scheduler.WithClock(func() time.Time {
+		return withdrawal.OccurrenceAt.Add(time.Second)
+	}))
+	n, err = later.RunDue(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	withdrawal, err = repo.GetScheduledOperation(ctx, withdrawal.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ScheduleActive, withdrawal.Status)
+	assert.Equal(t, 1, withdrawal.Attempts)
+	assert.NotNil(t, withdrawal.LastError)
+	assert.True(t, withdrawal.NextRunAt.After(withdrawal.OccurrenceAt))
+
+	ops, err := repo.ListScheduledOperations(ctx, walletID)
+	assert.NoError(t, err)
+	assert.Len(t, ops, 2)
+	_, err = repo.CancelScheduledOperation(ctx, withdrawal.ID)
+	assert.NoError(t, err)
+	_, err = repo.CancelScheduledOperation(ctx, withdrawal.ID)
+	assert.ErrorIs(t, err, repository.ErrScheduleNotActive)
+	_, err = repo.CancelScheduledOperation(ctx, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrScheduledOperationNotFound)
+}
+
+func TestBalanceAt(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	// Операции по одной в сутки: +100, +50, -30
+	for i, amount := range []int64{100, 50, -30} {
+		opType := "DEPOSIT"
+		if amount < 0 {
+			opType = "WITHDRAW"
+		}
+		_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(amount), opType)
+		assert.NoError(t, err)
+		_, err = pool.Exec(ctx, "UPDATE transactions SET created_at = $1 WHERE id = (SELECT MAX(id) FROM transactions)",
+			day.Add(time.Duration(i)*24*time.Hour+12*time.Hour))
+		assert.NoError(t, err)
+	}
+
+	check := func() {
+		for i, want := range []int64{0, 100, 150, 120} {
+			balance, err := repo.GetBalanceAt(ctx, walletID, day.Add(time.Duration(i)*24*time.Hour))
+			assert.NoError(t, err)
+			assert.True(t, balance.Balance.Equal(decimal.NewFromInt(want)), "day %d: got %s", i, balance.Balance)
+		}
+	}
+	check()
+
+	n, err := repo.TakeBalanceSnapshots(ctx, day.Add(24*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	n, err = repo.TakeBalanceSnapshots(ctx, day.Add(48*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(1), n)
+	// Повтор и сутки без операций не добавляют снимков
+	n, err = repo.TakeBalanceSnapshots(ctx, day.Add(48*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+	n, err = repo.TakeBalanceSnapshots(ctx, day.Add(30*time.Hour))
+	assert.NoError(t, err)
+	assert.Equal(t, int64(0), n)
+	check()
+
+	_, err = repo.GetBalanceAt(ctx, uuid.New(), day)
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+// statementRecorder собирает выписку в память для проверки.
+type statementRecorder struct {
+	header, footer models.Statement
+	lines          []models.StatementLine
+}
+
+func (s *statementRecorder) WriteHeader(st models.Statement) error { s.header = st; return nil }
+func (s *statementRecorder) WriteFooter(st models.Statement) error { s.footer = st; return nil }
+func (s *statementRecorder) WriteLine(line models.StatementLine) error {
+	s.lines = append(s.lines, line)
+	return nil
+}
+
+func TestStreamStatement(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+	day := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+
+	for i, amount := range []int64{100, 50, -30, 10} {
+		opType := "DEPOSIT"
+		if amount < 0 {
+			opType = "WITHDRAW"
+		}
+		_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(amount), opType)
+		assert.NoError(t, err)
+		_, err = pool.Exec(ctx, "UPDATE transactions SET created_at = $1 WHERE id = (SELECT MAX(id) FROM transactions)",
+			day.Add(time.Duration(i)*24*time.Hour))
+		assert.NoError(t, err)
+	}
+	_, err := repo.TakeBalanceSnapshots(ctx, day)
+	assert.NoError(t, err)
+
+	// Период со второго по третий день включительно: остаток на начало 100, операции +50 и -30
+	var rec statementRecorder
+	err = repo.StreamStatement(ctx, walletID, day.Add(24*time.Hour), day.Add(72*time.Hour), &rec)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DefaultCurrency, rec.header.Currency)
+	assert.True(t, rec.header.OpeningBalance.Equal(decimal.NewFromInt(100)))
+	if assert.Len(t, rec.lines, 2) {
+		assert.True(t, rec.lines[0].RunningBalance.Equal(decimal.NewFromInt(150)))
+		assert.True(t, rec.lines[1].RunningBalance.Equal(decimal.NewFromInt(120)))
+	}
+	assert.True(t, rec.footer.ClosingBalance.Equal(decimal.NewFromInt(120)))
+
+	err = repo.StreamStatement(ctx, uuid.New(), day, day.Add(time.Hour), &statementRecorder{})
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+func TestReconcile(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	fromID, toID := uuid.New(), uuid.New()
+
+	_, _, err := repo.UpdateBalance(ctx, fromID, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+	_, err = repo.CreateWallet(ctx, models.Wallet{ID: toID})
+	assert.NoError(t, err)
+	transfer, err := repo.Transfer(ctx, fromID, toID, decimal.NewFromInt(40))
+	assert.NoError(t, err)
+
+	report, err := repo.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.True(t, report.OK, "%+v", report.Issues)
+	assert.Equal(t, int64(2), report.WalletsChecked)
+	assert.Equal(t, int64(3), report.TransactionsChecked)
+
+	// Баланс изменён в обход журнала
+	_, err = pool.Exec(ctx, "UPDATE wallets SET balance = balance - 200 WHERE id = $1", fromID)
+	assert.NoError(t, err)
+	// Нога перевода потеряла сумму, а её проводка — пропала
+	_, err = pool.Exec(ctx, `UPDATE transactions SET amount = 30 WHERE transfer_id = $1 AND type = 'TRANSFER_IN'`, transfer.ID)
+	assert.NoError(t, err)
+	_, err = pool.Exec(ctx, `
+		DELETE FROM ledger_postings
+		WHERE account_id = 'wallet:' || $1::text
+			AND entry_id = (SELECT entry_id FROM transactions WHERE transfer_id = $2 AND type = 'TRANSFER_IN')`,
+		toID, transfer.ID)
+	assert.NoError(t, err)
+
+	report, err = repo.Reconcile(ctx)
+	assert.NoError(t, err)
+	assert.False(t, report.OK)
+	checks := map[string]int{}
+	for _, issue := range report.Issues {
+		checks[issue.Check]++
+	}
+	assert.Equal(t, map[string]int{
+		models.CheckBalanceDrift:        2, // fromID: -140 против 60, toID: 40 против 30
+		models.CheckLedgerDrift:         2,
+		models.CheckNegativeBalance:     1,
+		models.CheckOrphanedTransaction: 1,
+		models.CheckUnbalancedTransfer:  1,
+	}, checks)
+}
+
+func TestVerifyJournalChain(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID, otherID := uuid.New(), uuid.New()
+
+	for _, amount := range []int64{100, 50, 25, 10} {
+		_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(amount), "DEPOSIT")
+		assert.NoError(t, err)
+	}
+	_, _, err := repo.UpdateBalance(ctx, otherID, decimal.NewFromInt(70), "DEPOSIT")
+	assert.NoError(t, err)
+
+	result, err := repo.VerifyJournalChain(ctx, nil)
+	assert.NoError(t, err)
+	assert.True(t, result.OK, "%+v", result.FirstBreak)
+	assert.Equal(t, int64(2), result.WalletsChecked)
+	assert.Equal(t, int64(5), result.RowsChecked)
+
+	txs, err := repo.GetTransactions(ctx, walletID)
+	assert.NoError(t, err)
+	assert.Len(t, txs, 4)
+	assert.Nil(t, txs[0].PrevHash)
+	for i := 1; i < len(txs); i++ {
+		assert.Equal(t, txs[i-1].Hash, *txs[i].PrevHash)
+	}
+	result, err = repo.VerifyJournalChain(ctx, &walletID)
+	assert.NoError(t, err)
+	assert.True(t, result.OK)
+	assert.Equal(t, txs[3].Hash, *result.Head)
+
+	// Удалена последняя строка: цепочка до неё цела, но не сходится с головой кошелька
+	_, err = pool.Exec(ctx, "DELETE FROM transactions WHERE id = $1", txs[3].ID)
+	assert.NoError(t, err)
+	result, err = repo.VerifyJournalChain(ctx, &walletID)
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+	assert.Equal(t, models.ChainHeadMismatch, result.FirstBreak.Reason)
+	assert.Equal(t, txs[2].ID, *result.FirstBreak.TransactionID)
+
+	// Удалена строка из середины: следующая ссылается на пропавший хэш
+	_, err = pool.Exec(ctx, "DELETE FROM transactions WHERE id = $1", txs[1].ID)
+	assert.NoError(t, err)
+	result, err = repo.VerifyJournalChain(ctx, &walletID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.ChainBrokenLink, result.FirstBreak.Reason)
+	assert.Equal(t, txs[2].ID, *result.FirstBreak.TransactionID)
+
+	// Изменена сумма: отчёт указывает на первую по порядку испорченную строку
+	_, err = pool.Exec(ctx, "UPDATE transactions SET amount = 1000 WHERE id = $1", txs[0].ID)
+	assert.NoError(t, err)
+	result, err = repo.VerifyJournalChain(ctx, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, walletID, result.FirstBreak.WalletID)
+	assert.Equal(t, models.ChainHashMismatch, result.FirstBreak.Reason)
+	assert.Equal(t, txs[0].ID, *result.FirstBreak.TransactionID)
+	assert.Equal(t, txs[0].Hash, result.FirstBreak.Actual)
+
+	// reversedAmount вне хэша сверяется с суммой сторно
+	others, err := repo.GetTransactions(ctx, otherID)
+	assert.NoError(t, err)
+	part := decimal.NewFromInt(20)
+	_, err = repo.ReverseTransaction(ctx, others[0].ID, &part, false)
+	assert.NoError(t, err)
+	result, err = repo.VerifyJournalChain(ctx, &otherID)
+	assert.NoError(t, err)
+	assert.True(t, result.OK, "%+v", result.FirstBreak)
+	_, err = pool.Exec(ctx, "UPDATE transactions SET reversed_amount = 70 WHERE id = $1", others[0].ID)
+	assert.NoError(t, err)
+	result, err = repo.VerifyJournalChain(ctx, &otherID)
+	assert.NoError(t, err)
+	if assert.NotNil(t, result.FirstBreak) {
+		assert.Equal(t, models.ChainReversedMismatch, result.FirstBreak.Reason)
+		assert.Equal(t, others[0].ID, *result.FirstBreak.TransactionID)
+		assert.Equal(t, "20", result.FirstBreak.Expected)
+		assert.Equal(t, "70", result.FirstBreak.Actual)
+	}
+
+	missingID := uuid.New()
+	_, err = repo.VerifyJournalChain(ctx, &missingID)
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+func TestOutboxEvents(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID, otherID := uuid.New(), uuid.New()
+	idem := &models.Idempotency{Key: "outbox-deposit", RequestHash: "h"}
+
+	_, _, err := repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT", models.WithIdempotency(idem))
+	assert.NoError(t, err)
+	// Повтор по ключу идемпотентности и отклонённое списание событий не порождают
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT", models.WithIdempotency(idem))
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-500), "WITHDRAW")
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-30), "WITHDRAW")
+	assert.NoError(t, err)
+	_, err = repo.SetWalletStatus(ctx, walletID, models.WalletFrozen, "fraud check")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, otherID, decimal.NewFromInt(5), "DEPOSIT")
+	assert.NoError(t, err)
+
+	now := time.Now().Add(time.Second)
+	pending, err := repo.PendingEvents(ctx, now, 10)
+	assert.NoError(t, err)
+	if !assert.Len(t, pending, 4) {
+		return
+	}
+	assert.Equal(t, []string{models.EventBalanceChanged, models.EventBalanceChanged, models.EventStatusChanged, models.EventBalanceChanged},
+		[]string{pending[0].Type, pending[1].Type, pending[2].Type, pending[3].Type})
+
+	var withdraw models.BalanceChanged
+	assert.NoError(t, json.Unmarshal(pending[1].Data, &withdraw))
+	assert.Equal(t, "WITHDRAW", withdraw.Operation)
+	assert.True(t, withdraw.BalanceBefore.Equal(decimal.NewFromInt(100)))
+	assert.True(t, withdraw.BalanceAfter.Equal(decimal.NewFromInt(70)))
+	txs, err := repo.GetTransactions(ctx, walletID)
+	assert.NoError(t, err)
+	assert.Equal(t, txs[1].ID, withdraw.TransactionID)
+
+	var status models.StatusChanged
+	assert.NoError(t, json.Unmarshal(pending[2].Data, &status))
+	assert.Equal(t, models.StatusChanged{OldStatus: models.WalletActive, Status: models.WalletFrozen, Reason: ptrString("fraud check")}, status)
+
+	// Пока первое событие кошелька ждёт повтора, следующие события кошелька не выдаются
+	assert.NoError(t, repo.MarkEventFailed(ctx, pending[0].ID, now.Add(time.Minute), "broker unavailable"))
+	retry, err := repo.PendingEvents(ctx, now, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, retry, 1) {
+		assert.Equal(t, otherID, retry[0].WalletID)
+	}
+	retry, err = repo.PendingEvents(ctx, now.Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Len(t, retry, 4)
+	assert.Equal(t, 1, retry[0].Attempts)
+
+	assert.NoError(t, repo.MarkEventPublished(ctx, pending[3].ID))
+	retry, err = repo.PendingEvents(ctx, now.Add(time.Minute), 10)
+	assert.NoError(t, err)
+	assert.Len(t, retry, 3)
+
+	unlock, ok, err := repo.LockOutboxRelay(ctx)
+	assert.NoError(t, err)
+	assert.True(t, ok)
+	_, ok, err = repo.LockOutboxRelay(ctx)
+	assert.NoError(t, err)
+	assert.False(t, ok)
+	unlock()
+}
+
+func ptrString(s string) *string {
+	return &s
+}
+
+func TestWalletEventsStream(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	_, err := repo.GetStreamSnapshot(ctx, walletID)
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+	snapshot, err := repo.GetStreamSnapshot(ctx, walletID)
+	assert.NoError(t, err)
+	assert.True(t, snapshot.Balance.Equal(decimal.NewFromInt(100)))
+	assert.NotZero(t, snapshot.LastEventID)
+
+	listenCtx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	listening := make(chan struct{})
+	notified := make(chan uuid.UUID, 10)
+	done := make(chan error, 1)
+	go func() {
+		done <- repo.ListenWalletEvents(listenCtx, func() { close(listening) }, func(id uuid.UUID) { notified <- id })
+	}()
+	select {
+	case <-listening:
+	case <-time.After(10 * time.Second):
+		t.Fatal("listener did not subscribe")
+	}
+
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-30), "WITHDRAW")
+	assert.NoError(t, err)
+	select {
+	case id := <-notified:
+		assert.Equal(t, walletID, id)
+	case <-time.After(10 * time.Second):
+		t.Fatal("no notification after withdraw")
+	}
+
+	events, err := repo.ListWalletEvents(ctx, walletID, snapshot.LastEventID, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 1) {
+		var withdraw models.BalanceChanged
+		assert.NoError(t, json.Unmarshal(events[0].Data, &withdraw))
+		assert.True(t, withdraw.BalanceAfter.Equal(decimal.NewFromInt(70)))
+	}
+	events, err = repo.ListWalletEvents(ctx, walletID, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, events, 2)
+
+	// Перевод меняет балансы обоих кошельков: событие и уведомление приходят каждому
+	otherID := uuid.New()
+	_, err = repo.CreateWallet(ctx, models.Wallet{ID: otherID})
+	assert.NoError(t, err)
+	transfer, err := repo.Transfer(ctx, walletID, otherID, decimal.NewFromInt(20))
+	assert.NoError(t, err)
+	seen := map[uuid.UUID]bool{}
+	for len(seen) < 2 {
+		select {
+		case id := <-notified:
+			seen[id] = true
+		case <-time.After(10 * time.Second):
+			t.Fatalf("no notification after transfer, got %v", seen)
+		}
+	}
+	assert.True(t, seen[walletID] && seen[otherID])
+	for _, tc := range []struct {
+		walletID  uuid.UUID
+		operation string
+		amount    int64
+		after     int64
+	}{
+		{walletID, "TRANSFER_OUT", -20, 50},
+		{otherID, "TRANSFER_IN", 20, 20},
+	} {
+		events, err = repo.ListWalletEvents(ctx, tc.walletID, 0, 10)
+		assert.NoError(t, err)
+		if !assert.NotEmpty(t, events) {
+			continue
+		}
+		var changed models.BalanceChanged
+		assert.NoError(t, json.Unmarshal(events[len(events)-1].Data, &changed))
+		assert.Equal(t, tc.operation, changed.Operation)
+		assert.True(t, changed.Amount.Equal(decimal.NewFromInt(tc.amount)))
+		assert.True(t, changed.BalanceAfter.Equal(decimal.NewFromInt(tc.after)))
+		assert.True(t, changed.BalanceBefore.Equal(decimal.NewFromInt(tc.after-tc.amount)))
+		assert.NotZero(t, changed.TransactionID)
+	}
+	assert.True(t, transfer.ToBalance.Equal(decimal.NewFromInt(20)))
+
+	// Захват холда тоже попадает в поток
+	hold, err := repo.CreateHold(ctx, walletID, decimal.NewFromInt(10), time.Now().Add(time.Hour))
+	assert.NoError(t, err)
+	_, err = repo.CaptureHold(ctx, hold.Hold.ID, nil)
+	assert.NoError(t, err)
+	events, err = repo.ListWalletEvents(ctx, walletID, 0, 10)
+	assert.NoError(t, err)
+	if assert.Len(t, events, 4) {
+		var captured models.BalanceChanged
+		assert.NoError(t, json.Unmarshal(events[3].Data, &captured))
+		assert.Equal(t, "CAPTURE", captured.Operation)
+		assert.True(t, captured.BalanceAfter.Equal(decimal.NewFromInt(40)))
+	}
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(10 * time.Second):
+		t.Fatal("listener did not stop after cancel")
+	}
+}
+
+func TestWebhookDeliveries(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+	walletID := uuid.New()
+	_, err := repo.CreateWallet(ctx, models.Wallet{ID: walletID, OwnerID: "acme"})
+	assert.NoError(t, err)
+
+	threshold := decimal.NewFromInt(80)
+	sub, err := repo.CreateWebhook(ctx, models.WebhookSubscription{
+		ID:                  uuid.New(),
+		OwnerID:             "acme",
+		URL:                 "https://example.com/hook",
+		Secret:              "whsec_test",
+		Events:              []string{models.WebhookWithdraw, models.WebhookLowBalance},
+		LowBalanceThreshold: &threshold,
+	})
+	assert.NoError(t, err)
+	assert.True(t, sub.Active)
+	assert.Equal(t, "whsec_test", sub.Secret)
+	// Чужой кошелёк подписке недоступен
+	_, err = repo.CreateWebhook(ctx, models.WebhookSubscription{
+		ID: uuid.New(), OwnerID: "globex", WalletID: &walletID, URL: "https://example.com", Secret: "s",
+		Events: []string{models.WebhookDeposit},
+	})
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(100), "DEPOSIT")
+	assert.NoError(t, err)
+	_, _, err = repo.UpdateBalance(ctx, walletID, decimal.NewFromInt(-30), "WITHDRAW")
+	assert.NoError(t, err)
+
+	// Событие публикуется дважды (повтор relay), доставок остаётся по одной
+	publisher := webhooks.NewPublisher(repo)
+	pending, err := repo.PendingEvents(ctx, time.Now().Add(time.Second), 10)
+	assert.NoError(t, err)
+	for range 2 {
+		for _, event := range pending {
+			assert.NoError(t, publisher.Publish(ctx, event))
+		}
+	}
+	deliveries, err := repo.ListWebhookDeliveries(ctx, sub.ID, "", 10)
+	assert.NoError(t, err)
+	if !assert.Len(t, deliveries, 2) {
+		return
+	}
+	assert.ElementsMatch(t, []string{models.WebhookWithdraw, models.WebhookLowBalance},
+		[]string{deliveries[0].Event, deliveries[1].Event})
+
+	now := time.Now().Add(time.Second)
+	claimed, err := repo.ClaimWebhookDeliveries(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	if !assert.Len(t, claimed, 2) {
+		return
+	}
+	assert.Equal(t, "https://example.com/hook", claimed[0].URL)
+	assert.Equal(t, "whsec_test", claimed[0].Secret)
+	// Взятые доставки скрыты от других реплик на время аренды
+	again, err := repo.ClaimWebhookDeliveries(ctx, now, time.Minute, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, again)
+
+	dead := claimed[0]
+	dead.Status = models.DeliveryDead
+	dead.Attempts = 8
+	assert.NoError(t, repo.RecordWebhookAttempt(ctx, dead))
+	deadOnly, err := repo.ListWebhookDeliveries(ctx, sub.ID, models.DeliveryDead, 10)
+	assert.NoError(t, err)
+	assert.Len(t, deadOnly, 1)
+
+	requeued, err := repo.RetryWebhookDelivery(ctx, sub.ID, dead.ID)
+	assert.NoError(t, err)
+	assert.Equal(t, models.DeliveryPending, requeued.Status)
+	assert.Equal(t, 0, requeued.Attempts)
+	_, err = repo.RetryWebhookDelivery(ctx, sub.ID, dead.ID)
+	assert.ErrorIs(t, err, repository.ErrDeliveryNotRetryable)
+	_, err = repo.RetryWebhookDelivery(ctx, sub.ID, 1_000_000)
+	assert.ErrorIs(t, err, repository.ErrWebhookDeliveryNotFound)
+
+	deleted, err := repo.DeleteWebhook(ctx, sub.ID)
+	assert.NoError(t, err)
+	assert.False(t, deleted.Active)
+	pendingDeliveries, err := repo.ListWebhookDeliveries(ctx, sub.ID, models.DeliveryPending, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, pendingDeliveries)
+	subs, err := repo.WebhookSubscriptionsForWallet(ctx, walletID)
+	assert.NoError(t, err)
+	assert.Empty(t, subs)
+}
+
+func TestAPIKeys(t *testing.T) {
+	pool, teardown := testutil.SetupTestDB(t)
+	defer teardown()
+	repo := repository.NewWalletPGRepository(pool, testLogger)
+	ctx := context.Background()
+
+	key, err := repo.CreateAPIKey(ctx, models.APIKey{ID: uuid.New(), Name: "billing", KeyHash: strings.Repeat("a", 64)})
+	assert.NoError(t, err)
+	assert.Equal(t, "billing", key.Name)
+	assert.Empty(t, key.Scopes)
+	assert.Nil(t, key.RevokedAt)
+	ops, err := repo.CreateAPIKey(ctx, models.APIKey{
+		ID: uuid.New(), Name: "ops", KeyHash: strings.Repeat("d", 64), Scopes: []string{models.ScopeAdmin},
+	})
+	assert.NoError(t, err)
+	found, err := repo.GetAPIKeyByHash(ctx, strings.Repeat("d", 64))
+	assert.NoError(t, err)
+	assert.Equal(t, ops.ID, found.ID)
+	assert.Equal(t, []string{models.ScopeAdmin}, found.Scopes)
+	// Имя и хэш уникальны
+	_, err = repo.CreateAPIKey(ctx, models.APIKey{ID: uuid.New(), Name: "billing", KeyHash: strings.Repeat("b", 64)})
+	assert.ErrorIs(t, err, repository.ErrAPIKeyExists)
+	_, err = repo.CreateAPIKey(ctx, models.APIKey{ID: uuid.New(), Name: "payouts", KeyHash: strings.Repeat("a", 64)})
+	assert.ErrorIs(t, err, repository.ErrAPIKeyExists)
+
+	found, err = repo.GetAPIKeyByHash(ctx, strings.Repeat("a", 64))
+	assert.NoError(t, err)
+	assert.Equal(t, key.ID, found.ID)
+	_, err = repo.GetAPIKeyByHash(ctx, strings.Repeat("c", 64))
+	assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound)
+
+	revoked, err := repo.RevokeAPIKey(ctx, key.ID)
+	assert.NoError(t, err)
+	assert.NotNil(t, revoked.RevokedAt)
+	again, err := repo.RevokeAPIKey(ctx, key.ID)
+	assert.NoError(t, err)
+	assert.True(t, revoked.RevokedAt.Equal(*again.RevokedAt))
+	_, err = repo.RevokeAPIKey(ctx, uuid.New())
+	assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound)
+	// Отозванный ключ не проходит аутентификацию, но остаётся в списке
+	_, err = repo.GetAPIKeyByHash(ctx, strings.Repeat("a", 64))
+	assert.ErrorIs(t, err, repository.ErrAPIKeyNotFound)
+	keys, err := repo.ListAPIKeys(ctx)
+	assert.NoError(t, err)
+	assert.Len(t, keys, 2)
+}
@@ -0,0 +1,99 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+// reconcileChecks — запросы проверок сверки. Каждый возвращает нарушения в колонках
+// models.ReconciliationIssue.
+var reconcileChecks = []struct {
+	name  string
+	query string
+}{
+	{models.CheckBalanceDrift, `
+		SELECT w.id AS wallet_id, NULL::bigint AS transaction_id, NULL::uuid AS transfer_id,
+			COALESCE(t.sum, 0) AS expected, w.balance AS actual, '' AS details
+		FROM wallets w
+		LEFT JOIN (SELECT wallet_id, SUM(amount) AS sum FROM transactions GROUP BY wallet_id) t ON t.wallet_id = w.id
+		WHERE w.balance <> COALESCE(t.sum, 0)
+		ORDER BY w.id`},
+	{models.CheckLedgerDrift, `
+		SELECT w.id AS wallet_id, NULL::bigint AS transaction_id, NULL::uuid AS transfer_id,
+			COALESCE(p.sum, 0) AS expected, w.balance AS actual, '' AS details
+		FROM wallets w
+		LEFT JOIN (
+			SELECT a.wallet_id, SUM(lp.amount) AS sum
+			FROM ledger_postings lp JOIN ledger_accounts a ON a.id = lp.account_id
+			WHERE a.kind = 'WALLET'
+			GROUP BY a.wallet_id
+		) p ON p.wallet_id = w.id
+		WHERE w.balance <> COALESCE(p.sum, 0)
+		ORDER BY w.id`},
+	{models.CheckNegativeBalance, `
+		SELECT id AS wallet_id, NULL::bigint AS transaction_id, NULL::uuid AS transfer_id,
+			-overdraft_limit AS expected, balance AS actual, 'balance is below the overdraft limit' AS details
+		FROM wallets
+		WHERE balance + overdraft_limit < 0
+		ORDER BY id`},
+	{models.CheckOrphanedTransaction, `
+		SELECT t.wallet_id, t.id::bigint AS transaction_id, t.transfer_id,
+			NULL::numeric AS expected, t.amount AS actual,
+			'ledger entry ' || t.entry_id || ' has no posting for the wallet' AS details
+		FROM transactions t
+		WHERE t.entry_id IS NOT NULL
+			AND NOT EXISTS (
+				SELECT 1 FROM ledger_postings lp
+				WHERE lp.entry_id = t.entry_id AND lp.account_id = 'wallet:' || t.wallet_id
+			)
+		ORDER BY t.id`},
+	{models.CheckUnbalancedTransfer, `
+		SELECT NULL::uuid AS wallet_id, NULL::bigint AS transaction_id, transfer_id,
+			0::numeric AS expected, SUM(amount) AS actual, COUNT(*) || ' legs' AS details
+		FROM transactions
+		WHERE transfer_id IS NOT NULL AND type IN ('TRANSFER_IN', 'TRANSFER_OUT')
+		GROUP BY transfer_id
+		HAVING SUM(amount) <> 0 OR COUNT(*) <> 2
+		ORDER BY transfer_id`},
+}
+
+// Reconcile проверяет инварианты главной книги по согласованному снимку БД.
+func (r *WalletPGRepository) Reconcile(ctx context.Context) (models.ReconciliationReport, error) {
+	report := models.ReconciliationReport{StartedAt: time.Now().UTC(), Issues: []models.ReconciliationIssue{}}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return report, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, "SELECT (SELECT COUNT(*) FROM wallets), (SELECT COUNT(*) FROM transactions)").
+		Scan(&report.WalletsChecked, &report.TransactionsChecked)
+	if err != nil {
+		r.logger.Error("Failed to count reconciled rows", slog.Any("err", err))
+		return report, err
+	}
+	for _, check := range reconcileChecks {
+		rows, err := tx.Query(ctx, check.query)
+		if err != nil {
+			r.logger.Error("Failed to run reconciliation check", slog.String("check", check.name), slog.Any("err", err))
+			return report, fmt.Errorf("%s: %w", check.name, err)
+		}
+		issues, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ReconciliationIssue])
+		if err != nil {
+			r.logger.Error("Failed to scan reconciliation check", slog.String("check", check.name), slog.Any("err", err))
+			return report, fmt.Errorf("%s: %w", check.name, err)
+		}
+		for i := range issues {
+			issues[i].Check = check.name
+		}
+		report.Issues = append(report.Issues, issues...)
+	}
+	report.OK = len(report.Issues) == 0
+	report.FinishedAt = time.Now().UTC()
+	return report, nil
+}
@@ -0,0 +1,255 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+var (
+	ErrTransactionNotFound     = errors.New("transaction not found")
+	ErrNotReversible           = errors.New("only DEPOSIT and WITHDRAW operations can be reversed")
+	ErrAlreadyReversed         = errors.New("transaction has already been fully reversed")
+	ErrReversalExceedsOriginal = errors.New("reversal amount exceeds the unreversed part of the transaction")
+)
+
+// ReverseTransaction сторнирует операцию целиком (amount == nil) или частично вместе с частью комиссии.
+func (r *WalletPGRepository) ReverseTransaction(
+	ctx context.Context,
+	transactionID int64,
+	amount *decimal.Decimal,
+	allowNegative bool,
+	opts ...models.OperationOption,
+) (models.ReversalResult, error) {
+	options := models.NewOperationOptions(opts...)
+	var result models.ReversalResult
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.Int64("transaction_id", transactionID),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.Int64("transaction_id", transactionID),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	if idem := options.Idempotency; idem != nil {
+		replayed, err := r.claimIdempotencyKey(ctx, tx, idem)
+		if err != nil {
+			return result, err
+		}
+		if replayed {
+			return result, nil
+		}
+	}
+
+	original, err := r.lockTransaction(ctx, tx, transactionID)
+	if err != nil {
+		return result, err
+	}
+	result.Original = original
+	if original.Type != "DEPOSIT" && original.Type != "WITHDRAW" {
+		return result, ErrNotReversible
+	}
+	remaining := original.Amount.Abs().Sub(original.ReversedAmount)
+	if !remaining.IsPositive() {
+		return result, ErrAlreadyReversed
+	}
+	reversed := remaining
+	if amount != nil {
+		reversed = *amount
+	}
+	if !reversed.IsPositive() {
+		return result, ErrInvalidAmount
+	}
+	if reversed.GreaterThan(remaining) {
+		return result, ErrReversalExceedsOriginal
+	}
+
+	currency, err := r.walletCurrency(ctx, tx, original.WalletID)
+	if err != nil {
+		return result, err
+	}
+	// Компенсирующая запись повторяет счета исходной операции с обратными знаками
+	walletDelta, counterparty := reversed.Neg(), models.SystemExternalCashIn
+	if original.Type == "WITHDRAW" {
+		walletDelta, counterparty = reversed, models.SystemExternalCashOut
+	}
+	entry := models.LedgerEntry{
+		Type: "REVERSAL",
+		Postings: []models.Posting{
+			{AccountID: models.WalletAccountID(original.WalletID), Amount: walletDelta, Currency: currency},
+			{AccountID: models.SystemAccountID(counterparty, currency), Amount: walletDelta.Neg(), Currency: currency},
+		},
+	}
+	fee, hasFee, err := r.lockFee(ctx, tx, original)
+	if err != nil {
+		return result, err
+	}
+	var refund decimal.Decimal
+	if hasFee {
+		refund, err = feeRefund(fee, original, original.ReversedAmount.Add(reversed), currency)
+		if err != nil {
+			return result, err
+		}
+	}
+	if refund.IsPositive() {
+		entry.Postings = append(entry.Postings,
+			models.Posting{AccountID: models.WalletAccountID(original.WalletID), Amount: refund, Currency: currency, ReversalOf: &fee.ID},
+			models.Posting{AccountID: models.SystemAccountID(models.SystemFees, currency), Amount: refund.Neg(), Currency: currency},
+		)
+	}
+	balances, err := r.postEntry(ctx, tx, &entry, journalMeta{reversalOf: &original.ID, allowNegative: allowNegative})
+	if err != nil {
+		result.Balance = balances[original.WalletID]
+		return result, err
+	}
+
+	if original.ReversedAmount, err = r.addReversedAmount(ctx, tx, original.ID, reversed); err != nil {
+		return result, err
+	}
+	reversal, err := r.reversalRow(ctx, tx, entry.ID, original.ID)
+	if err != nil {
+		return result, err
+	}
+	reversalResult := models.ReversalResult{
+		Reversal: reversal,
+		Original: original,
+		Balance:  balances[original.WalletID],
+	}
+	if refund.IsPositive() {
+		if _, err := r.addReversedAmount(ctx, tx, fee.ID, refund); err != nil {
+			return result, err
+		}
+		feeReversal, err := r.reversalRow(ctx, tx, entry.ID, fee.ID)
+		if err != nil {
+			return result, err
+		}
+		reversalResult.FeeRefund = &feeReversal
+	}
+	opResult := models.OperationResult{Balance: reversalResult.Balance, Reversal: &reversalResult}
+	if err := r.storeIdempotentResponse(ctx, tx, options.Idempotency, opResult); err != nil {
+		return result, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.Int64("transaction_id", transactionID),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	return reversalResult, nil
+}
+
+// addReversedAmount увеличивает сторнированную часть строки журнала и возвращает новое значение.
+func (r *WalletPGRepository) addReversedAmount(ctx context.Context, tx pgx.Tx, transactionID int64, amount decimal.Decimal) (decimal.Decimal, error) {
+	var reversedAmount decimal.Decimal
+	err := tx.QueryRow(ctx, `
+		UPDATE transactions SET reversed_amount = reversed_amount + $1
+		WHERE id = $2
+		RETURNING reversed_amount`, amount, transactionID).Scan(&reversedAmount)
+	if err != nil {
+		r.logger.Error("Failed to update reversed amount",
+			slog.Int64("transaction_id", transactionID),
+			slog.Any("err", err),
+		)
+	}
+	return reversedAmount, err
+}
+
+// reversalRow возвращает строку сторно записи entryID, ссылающуюся на строку transactionID.
+func (r *WalletPGRepository) reversalRow(ctx context.Context, tx pgx.Tx, entryID, transactionID int64) (models.Transaction, error) {
+	rows, err := tx.Query(ctx, "SELECT "+transactionColumns+" FROM transactions WHERE entry_id = $1 AND reversal_of = $2",
+		entryID, transactionID)
+	if err != nil {
+		r.logger.Error("Failed to select reversal",
+			slog.Int64("transaction_id", transactionID),
+			slog.Any("err", err),
+		)
+		return models.Transaction{}, err
+	}
+	reversal, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.Transaction])
+	if err != nil {
+		r.logger.Error("Failed to scan reversal",
+			slog.Int64("transaction_id", transactionID),
+			slog.Any("err", err),
+		)
+	}
+	return reversal, err
+}
+
+// lockFee блокирует строку комиссии исходной операции; ok = false, если комиссии не было.
+func (r *WalletPGRepository) lockFee(ctx context.Context, tx pgx.Tx, original models.Transaction) (models.Transaction, bool, error) {
+	if original.EntryID == nil {
+		return models.Transaction{}, false, nil
+	}
+	rows, err := tx.Query(ctx, "SELECT "+transactionColumns+` FROM transactions
+		WHERE entry_id = $1 AND wallet_id = $2 AND type = 'FEE'
+		FOR UPDATE`, *original.EntryID, original.WalletID)
+	if err != nil {
+		r.logger.Error("Failed to select fee for update",
+			slog.Int64("transaction_id", original.ID),
+			slog.Any("err", err),
+		)
+		return models.Transaction{}, false, err
+	}
+	fee, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.Transaction])
+	if err == pgx.ErrNoRows {
+		return fee, false, nil
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan fee",
+			slog.Int64("transaction_id", original.ID),
+			slog.Any("err", err),
+		)
+		return fee, false, err
+	}
+	return fee, true, nil
+}
+
+// feeRefund — часть комиссии fee, пропорциональная сторнированной сумме reversedTotal.
+func feeRefund(fee, original models.Transaction, reversedTotal decimal.Decimal, currency string) (decimal.Decimal, error) {
+	units, err := models.MinorUnits(currency)
+	if err != nil {
+		return decimal.Zero, err
+	}
+	refunded := fee.Amount.Abs().Mul(reversedTotal).Div(original.Amount.Abs()).Round(units)
+	return refunded.Sub(fee.ReversedAmount), nil
+}
+
+// lockTransaction блокирует строку журнала, чтобы параллельные сторно одной операции
+// выполнялись по очереди.
+func (r *WalletPGRepository) lockTransaction(ctx context.Context, tx pgx.Tx, transactionID int64) (models.Transaction, error) {
+	rows, err := tx.Query(ctx, "SELECT "+transactionColumns+" FROM transactions WHERE id = $1 FOR UPDATE", transactionID)
+	if err != nil {
+		r.logger.Error("Failed to select transaction for update",
+			slog.Int64("transaction_id", transactionID),
+			slog.Any("err", err),
+		)
+		return models.Transaction{}, err
+	}
+	transaction, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.Transaction])
+	if err == pgx.ErrNoRows {
+		return transaction, ErrTransactionNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan transaction",
+			slog.Int64("transaction_id", transactionID),
+			slog.Any("err", err),
+		)
+	}
+	return transaction, err
+}
@@ -0,0 +1,267 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+var (
+	ErrScheduledOperationNotFound = errors.New("scheduled operation not found")
+	ErrScheduleNotActive          = errors.New("scheduled operation is not active")
+)
+
+const scheduledOperationColumns = `id, operation, wallet_id, to_wallet_id, amount, currency, cron, status,
+	occurrence_at, next_run_at, attempts, max_attempts, last_error, created_at, updated_at`
+
+// CreateScheduledOperation сохраняет запланированную операцию. Кошельки должны существовать.
+func (r *WalletPGRepository) CreateScheduledOperation(
+	ctx context.Context,
+	op models.ScheduledOperation,
+) (models.ScheduledOperation, error) {
+	rows, err := r.pool.Query(ctx, `
+		INSERT INTO scheduled_operations
+			(id, operation, wallet_id, to_wallet_id, amount, currency, cron, occurrence_at, next_run_at, max_attempts)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, $9)
+		RETURNING `+scheduledOperationColumns,
+		op.ID, op.Operation, op.WalletID, op.ToWalletID, op.Amount, op.Currency, op.Cron, op.OccurrenceAt, op.MaxAttempts)
+	if err != nil {
+		r.logger.Error("Failed to insert scheduled operation",
+			slog.String("scheduled_operation_id", op.ID.String()),
+			slog.Any("err", err),
+		)
+		return models.ScheduledOperation{}, err
+	}
+	created, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.ScheduledOperation])
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23503" {
+			return models.ScheduledOperation{}, ErrWalletNotFound
+		}
+		r.logger.Error("Failed to insert scheduled operation",
+			slog.String("scheduled_operation_id", op.ID.String()),
+			slog.Any("err", err),
+		)
+		return models.ScheduledOperation{}, err
+	}
+	return created, nil
+}
+
+func (r *WalletPGRepository) GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+scheduledOperationColumns+" FROM scheduled_operations WHERE id = $1", id)
+	if err != nil {
+		r.logger.Error("Failed to query scheduled operation",
+			slog.String("scheduled_operation_id", id.String()),
+			slog.Any("err", err),
+		)
+		return models.ScheduledOperation{}, err
+	}
+	op, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.ScheduledOperation])
+	if err == pgx.ErrNoRows {
+		return models.ScheduledOperation{}, ErrScheduledOperationNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan scheduled operation",
+			slog.String("scheduled_operation_id", id.String()),
+			slog.Any("err", err),
+		)
+		return models.ScheduledOperation{}, err
+	}
+	return op, nil
+}
+
+// ListScheduledOperations возвращает операции, в которых кошелёк участвует как источник
+// или получатель, от новых к старым.
+func (r *WalletPGRepository) ListScheduledOperations(
+	ctx context.Context,
+	walletID uuid.UUID,
+) ([]models.ScheduledOperation, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx)
+
+	var exists bool
+	if err := tx.QueryRow(ctx, "SELECT EXISTS(SELECT 1 FROM wallets WHERE id = $1)", walletID).Scan(&exists); err != nil {
+		r.logger.Error("Failed to check wallet",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	if !exists {
+		return nil, ErrWalletNotFound
+	}
+	rows, err := tx.Query(ctx, `
+		SELECT `+scheduledOperationColumns+`
+		FROM scheduled_operations
+		WHERE wallet_id = $1 OR to_wallet_id = $1
+		ORDER BY created_at DESC, id DESC`, walletID)
+	if err != nil {
+		r.logger.Error("Failed to query scheduled operations",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	ops, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ScheduledOperation])
+	if err != nil {
+		r.logger.Error("Failed to scan scheduled operations",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	if ops == nil {
+		ops = []models.ScheduledOperation{}
+	}
+	return ops, nil
+}
+
+// CancelScheduledOperation отменяет активную операцию. Уже начатое срабатывание доводится
+// до конца, но следующих не будет.
+func (r *WalletPGRepository) CancelScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE scheduled_operations SET status = $2, updated_at = NOW()
+		WHERE id = $1 AND status = $3
+		RETURNING `+scheduledOperationColumns, id, models.ScheduleCancelled, models.ScheduleActive)
+	if err != nil {
+		r.logger.Error("Failed to cancel scheduled operation",
+			slog.String("scheduled_operation_id", id.String()),
+			slog.Any("err", err),
+		)
+		return models.ScheduledOperation{}, err
+	}
+	op, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.ScheduledOperation])
+	if err == pgx.ErrNoRows {
+		if _, err := r.GetScheduledOperation(ctx, id); err != nil {
+			return models.ScheduledOperation{}, err
+		}
+		return models.ScheduledOperation{}, ErrScheduleNotActive
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan scheduled operation",
+			slog.String("scheduled_operation_id", id.String()),
+			slog.Any("err", err),
+		)
+		return models.ScheduledOperation{}, err
+	}
+	return op, nil
+}
+
+// GetScheduledRuns возвращает попытки исполнения операции в порядке их выполнения.
+func (r *WalletPGRepository) GetScheduledRuns(ctx context.Context, id uuid.UUID) ([]models.ScheduledRun, error) {
+	if _, err := r.GetScheduledOperation(ctx, id); err != nil {
+		return nil, err
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT id, scheduled_operation_id, occurrence_at, attempt, status, error, created_at
+		FROM scheduled_operation_runs
+		WHERE scheduled_operation_id = $1
+		ORDER BY id`, id)
+	if err != nil {
+		r.logger.Error("Failed to query scheduled runs",
+			slog.String("scheduled_operation_id", id.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	runs, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ScheduledRun])
+	if err != nil {
+		r.logger.Error("Failed to scan scheduled runs",
+			slog.String("scheduled_operation_id", id.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	if runs == nil {
+		runs = []models.ScheduledRun{}
+	}
+	return runs, nil
+}
+
+// DueScheduledOperations возвращает активные операции, время исполнения которых наступило.
+func (r *WalletPGRepository) DueScheduledOperations(
+	ctx context.Context,
+	now time.Time,
+	limit int,
+) ([]models.ScheduledOperation, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+scheduledOperationColumns+`
+		FROM scheduled_operations
+		WHERE status = $1 AND next_run_at <= $2
+		ORDER BY next_run_at
+		LIMIT $3`, models.ScheduleActive, now, limit)
+	if err != nil {
+		r.logger.Error("Failed to query due scheduled operations", slog.Any("err", err))
+		return nil, err
+	}
+	ops, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.ScheduledOperation])
+	if err != nil {
+		r.logger.Error("Failed to scan due scheduled operations", slog.Any("err", err))
+		return nil, err
+	}
+	return ops, nil
+}
+
+// LockScheduledOperation берёт advisory-блокировку операции, чтобы её срабатывание
+// исполняла только одна реплика.
+func (r *WalletPGRepository) LockScheduledOperation(
+	ctx context.Context,
+	id uuid.UUID,
+) (unlock func(), ok bool, err error) {
+	return r.tryAdvisoryLock(ctx, "scheduled_operation:"+id.String())
+}
+
+// RecordScheduledRun записывает попытку run и новое состояние операции op одной транзакцией.
+// Если операцию успели отменить, её состояние не меняется, но попытка всё равно записывается.
+func (r *WalletPGRepository) RecordScheduledRun(
+	ctx context.Context,
+	op models.ScheduledOperation,
+	run models.ScheduledRun,
+) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("scheduled_operation_id", op.ID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `
+		UPDATE scheduled_operations
+		SET status = $2, occurrence_at = $3, next_run_at = $4, attempts = $5, last_error = $6, updated_at = NOW()
+		WHERE id = $1 AND status = $7`,
+		op.ID, op.Status, op.OccurrenceAt, op.NextRunAt, op.Attempts, op.LastError, models.ScheduleActive)
+	if err != nil {
+		r.logger.Error("Failed to update scheduled operation",
+			slog.String("scheduled_operation_id", op.ID.String()),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	_, err = tx.Exec(ctx, `
+		INSERT INTO scheduled_operation_runs (scheduled_operation_id, occurrence_at, attempt, status, error)
+		VALUES ($1, $2, $3, $4, $5)`,
+		op.ID, run.OccurrenceAt, run.Attempt, run.Status, run.Error)
+	if err != nil {
+		r.logger.Error("Failed to insert scheduled run",
+			slog.String("scheduled_operation_id", op.ID.String()),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	return tx.Commit(ctx)
+}
@@ -0,0 +1,176 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/shopspring/decimal"
+)
+
+// GetBalanceAt восстанавливает баланс кошелька на момент at по последнему снимку и журналу.
+func (r *WalletPGRepository) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (models.BalanceAt, error) {
+	result := models.BalanceAt{WalletID: walletID, At: at}
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return result, err
+	}
+	defer tx.Rollback(ctx)
+
+	err = tx.QueryRow(ctx, "SELECT currency FROM wallets WHERE id = $1", walletID).Scan(&result.Currency)
+	if err == pgx.ErrNoRows {
+		return result, ErrWalletNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to select wallet",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return result, err
+	}
+	if result.Balance, err = r.balanceAsOf(ctx, tx, walletID, at, true); err != nil {
+		return result, err
+	}
+	return result, nil
+}
+
+// balanceAsOf считает баланс по операциям до момента at — включительно или строго до него —
+// от ближайшего предшествующего снимка.
+func (r *WalletPGRepository) balanceAsOf(
+	ctx context.Context,
+	tx pgx.Tx,
+	walletID uuid.UUID,
+	at time.Time,
+	inclusive bool,
+) (decimal.Decimal, error) {
+	cmp := "<"
+	if inclusive {
+		cmp = "<="
+	}
+	var balance decimal.Decimal
+	err := tx.QueryRow(ctx, `
+		WITH s AS (
+			SELECT as_of, balance FROM balance_snapshots
+			WHERE wallet_id = $1 AND as_of `+cmp+` $2
+			ORDER BY as_of DESC
+			LIMIT 1
+		)
+		SELECT COALESCE((SELECT balance FROM s), 0) + COALESCE((
+			SELECT SUM(amount) FROM transactions
+			WHERE wallet_id = $1
+				AND created_at `+cmp+` $2
+				AND created_at > COALESCE((SELECT as_of FROM s), '-infinity')
+		), 0)`, walletID, at).Scan(&balance)
+	if err != nil {
+		r.logger.Error("Failed to compute balance at",
+			slog.String("wallet_id", walletID.String()),
+			slog.Time("at", at),
+			slog.Any("err", err),
+		)
+	}
+	return balance, err
+}
+
+// TakeBalanceSnapshots записывает снимки балансов на момент asOf для кошельков с новыми операциями.
+func (r *WalletPGRepository) TakeBalanceSnapshots(ctx context.Context, asOf time.Time) (int64, error) {
+	tag, err := r.pool.Exec(ctx, `
+		INSERT INTO balance_snapshots (wallet_id, as_of, balance)
+		SELECT w.id, $1, COALESCE(s.balance, 0) + t.amount
+		FROM wallets w
+		LEFT JOIN LATERAL (
+			SELECT as_of, balance FROM balance_snapshots bs
+			WHERE bs.wallet_id = w.id AND bs.as_of <= $1
+			ORDER BY as_of DESC
+			LIMIT 1
+		) s ON TRUE
+		CROSS JOIN LATERAL (
+			SELECT SUM(amount) AS amount, COUNT(*) AS n FROM transactions tr
+			WHERE tr.wallet_id = w.id
+				AND tr.created_at <= $1
+				AND tr.created_at > COALESCE(s.as_of, '-infinity')
+		) t
+		WHERE t.n > 0
+		ON CONFLICT (wallet_id, as_of) DO NOTHING`, asOf)
+	if err != nil {
+		r.logger.Error("Failed to take balance snapshots",
+			slog.Time("as_of", asOf),
+			slog.Any("err", err),
+		)
+		return 0, err
+	}
+	return tag.RowsAffected(), nil
+}
+
+// StreamStatement выводит выписку за период [from, to) в w, читая операции курсором.
+func (r *WalletPGRepository) StreamStatement(
+	ctx context.Context,
+	walletID uuid.UUID,
+	from, to time.Time,
+	w models.StatementWriter,
+) error {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{IsoLevel: pgx.RepeatableRead, AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx)
+
+	statement := models.Statement{WalletID: walletID, From: from, To: to}
+	err = tx.QueryRow(ctx, "SELECT currency FROM wallets WHERE id = $1", walletID).Scan(&statement.Currency)
+	if err == pgx.ErrNoRows {
+		return ErrWalletNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to select wallet",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	if statement.OpeningBalance, err = r.balanceAsOf(ctx, tx, walletID, from, false); err != nil {
+		return err
+	}
+	if err := w.WriteHeader(statement); err != nil {
+		return err
+	}
+
+	rows, err := tx.Query(ctx, `
+		SELECT `+transactionColumns+`
+		FROM transactions
+		WHERE wallet_id = $1 AND created_at >= $2 AND created_at < $3
+		ORDER BY created_at, id`, walletID, from, to)
+	if err != nil {
+		r.logger.Error("Failed to query statement transactions",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	defer rows.Close()
+	balance := statement.OpeningBalance
+	for rows.Next() {
+		t, err := pgx.RowToStructByName[models.Transaction](rows)
+		if err != nil {
+			r.logger.Error("Failed to scan statement transaction",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+			return err
+		}
+		balance = balance.Add(t.Amount)
+		if err := w.WriteLine(models.StatementLine{Transaction: t, RunningBalance: balance}); err != nil {
+			return err
+		}
+	}
+	if err := rows.Err(); err != nil {
+		r.logger.Error("Failed to read statement transactions",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	statement.ClosingBalance = balance
+	return w.WriteFooter(statement)
+}
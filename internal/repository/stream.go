@@ -0,0 +1,91 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// WalletEventsChannel — канал NOTIFY, в который триггер outbox_events пишет id кошелька.
+const WalletEventsChannel = "wallet_events"
+
+// GetStreamSnapshot возвращает баланс кошелька и id его последнего события одним запросом,
+// то есть из одного снимка БД: события после LastEventID в баланс ещё не вошли.
+func (r *WalletPGRepository) GetStreamSnapshot(ctx context.Context, walletID uuid.UUID) (models.StreamSnapshot, error) {
+	snapshot := models.StreamSnapshot{WalletID: walletID}
+	err := r.pool.QueryRow(ctx, `
+		SELECT w.currency, w.balance,
+			(SELECT COALESCE(MAX(e.id), 0) FROM outbox_events e WHERE e.wallet_id = w.id)
+		FROM wallets w
+		WHERE w.id = $1`, walletID).Scan(&snapshot.Currency, &snapshot.Balance, &snapshot.LastEventID)
+	if err == pgx.ErrNoRows {
+		return snapshot, ErrWalletNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to select stream snapshot",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+	}
+	return snapshot, err
+}
+
+// ListWalletEvents возвращает события кошелька с id больше afterID в порядке записи.
+func (r *WalletPGRepository) ListWalletEvents(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.Event, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+eventColumns+`
+		FROM outbox_events
+		WHERE wallet_id = $1 AND id > $2
+		ORDER BY id
+		LIMIT $3`, walletID, afterID, limit)
+	if err != nil {
+		r.logger.Error("Failed to query wallet events",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	events, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Event])
+	if err != nil {
+		r.logger.Error("Failed to scan wallet events",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return nil, err
+	}
+	return events, nil
+}
+
+// ListenWalletEvents вызывает notify для каждого уведомления WalletEventsChannel до отмены ctx.
+func (r *WalletPGRepository) ListenWalletEvents(
+	ctx context.Context,
+	onListen func(),
+	notify func(walletID uuid.UUID),
+) error {
+	pooled, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return err
+	}
+	// После LISTEN соединение нельзя возвращать в пул: его получил бы чужой запрос
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+	if _, err := conn.Exec(ctx, "LISTEN "+WalletEventsChannel); err != nil {
+		return err
+	}
+	onListen()
+	for {
+		n, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return err
+		}
+		walletID, err := uuid.Parse(n.Payload)
+		if err != nil {
+			r.logger.Warn("Unexpected wallet event notification", slog.String("payload", n.Payload))
+			continue
+		}
+		notify(walletID)
+	}
+}
@@ -0,0 +1,94 @@
+package repository
+
+import (
+	"context"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+// SetWalletStatus переводит кошелёк в статус status; закрыть можно только пустой кошелёк без холдов.
+func (r *WalletPGRepository) SetWalletStatus(
+	ctx context.Context,
+	walletID uuid.UUID,
+	status, reason string,
+) (models.Wallet, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.Wallet{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	var current string
+	err = tx.QueryRow(ctx, "SELECT status FROM wallets WHERE id = $1 FOR UPDATE", walletID).Scan(&current)
+	if err == pgx.ErrNoRows {
+		return models.Wallet{}, ErrWalletNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to select wallet for update",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.Wallet{}, err
+	}
+	if current == models.WalletClosed && status != models.WalletClosed {
+		return models.Wallet{}, ErrWalletClosed
+	}
+
+	if current != status {
+		if status == models.WalletClosed {
+			wallet, err := r.walletTx(ctx, tx, walletID)
+			if err != nil {
+				return wallet, err
+			}
+			if !wallet.Balance.IsZero() || !wallet.Held.IsZero() {
+				return wallet, ErrWalletNotEmpty
+			}
+		}
+		var reasonArg *string
+		if reason != "" {
+			reasonArg = &reason
+		}
+		_, err = tx.Exec(ctx, `
+			UPDATE wallets SET status = $1, status_reason = $2, status_changed_at = NOW()
+			WHERE id = $3`, status, reasonArg, walletID)
+		if err != nil {
+			r.logger.Error("Failed to update wallet status",
+				slog.String("wallet_id", walletID.String()),
+				slog.String("status", status),
+				slog.Any("err", err),
+			)
+			return models.Wallet{}, err
+		}
+		event := models.StatusChanged{OldStatus: current, Status: status, Reason: reasonArg}
+		if err := r.enqueueEvent(ctx, tx, models.EventStatusChanged, walletID, event); err != nil {
+			return models.Wallet{}, err
+		}
+	}
+
+	wallet, err := r.walletTx(ctx, tx, walletID)
+	if err != nil {
+		return wallet, err
+	}
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return wallet, err
+	}
+	return wallet, nil
+}
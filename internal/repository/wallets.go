@@ -0,0 +1,216 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"test_wallet/internal/models"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// walletSelect выбирает кошельки вместе с суммой действующих холдов и доступным балансом.
+const walletSelect = `
+	SELECT w.id, w.currency, w.status, w.status_reason, w.status_changed_at,
+		w.owner_id, w.name, w.labels, w.balance, h.held, w.overdraft_limit, w.limit_tier,
+		w.balance - h.held + w.overdraft_limit AS available,
+		w.created_at, w.updated_at
+	FROM wallets w
+	CROSS JOIN LATERAL (
+		SELECT COALESCE(SUM(amount), 0) AS held FROM holds
+		WHERE wallet_id = w.id AND status = 'ACTIVE' AND expires_at > NOW()
+	) h`
+
+func (r *WalletPGRepository) walletTx(ctx context.Context, tx pgx.Tx, walletID uuid.UUID) (models.Wallet, error) {
+	rows, err := tx.Query(ctx, walletSelect+" WHERE w.id = $1", walletID)
+	if err == nil {
+		var wallet models.Wallet
+		wallet, err = pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.Wallet])
+		if err == nil {
+			return wallet, nil
+		}
+	}
+	if err == pgx.ErrNoRows {
+		return models.Wallet{ID: walletID}, ErrWalletNotFound
+	}
+	r.logger.Error("Failed to get wallet",
+		slog.String("wallet_id", walletID.String()),
+		slog.Any("err", err),
+	)
+	return models.Wallet{ID: walletID}, err
+}
+
+// GetWallet возвращает кошелёк с учётным и доступным балансами.
+func (r *WalletPGRepository) GetWallet(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{AccessMode: pgx.ReadOnly})
+	if err != nil {
+		return models.Wallet{}, err
+	}
+	defer tx.Rollback(ctx)
+	return r.walletTx(ctx, tx, walletID)
+}
+
+// CreateWallet создаёт пустой кошелёк вместе с его счётом в главной книге.
+// Пустая валюта заменяется валютой по умолчанию.
+func (r *WalletPGRepository) CreateWallet(ctx context.Context, wallet models.Wallet) (models.Wallet, error) {
+	if wallet.Currency == "" {
+		wallet.Currency = r.defaultCurrency
+	}
+	if wallet.Labels == nil {
+		wallet.Labels = map[string]string{}
+	}
+
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.String("wallet_id", wallet.ID.String()),
+			slog.Any("err", err),
+		)
+		return wallet, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("wallet_id", wallet.ID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	_, err = tx.Exec(ctx, `
+		WITH w AS (
+			INSERT INTO wallets (id, balance, currency, owner_id, name, labels)
+			VALUES ($1, 0, $2, $3, $4, $5)
+			RETURNING id, currency
+		)
+		INSERT INTO ledger_accounts (id, kind, wallet_id, currency)
+		SELECT 'wallet:' || id, 'WALLET', id, currency FROM w`,
+		wallet.ID, wallet.Currency, wallet.OwnerID, wallet.Name, wallet.Labels)
+	if err != nil {
+		var pgErr *pgconn.PgError
+		if errors.As(err, &pgErr) && pgErr.Code == "23505" {
+			return wallet, ErrWalletAlreadyExist
+		}
+		r.logger.Error("Failed to create wallet",
+			slog.String("wallet_id", wallet.ID.String()),
+			slog.Any("err", err),
+		)
+		return wallet, err
+	}
+	created, err := r.walletTx(ctx, tx, wallet.ID)
+	if err != nil {
+		return wallet, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.String("wallet_id", wallet.ID.String()),
+			slog.Any("err", err),
+		)
+		return wallet, err
+	}
+	return created, nil
+}
+
+// ListWallets возвращает до filter.Limit кошельков после filter.Cursor, от новых к старым.
+func (r *WalletPGRepository) ListWallets(ctx context.Context, filter models.WalletFilter) ([]models.Wallet, error) {
+	query := walletSelect + " WHERE TRUE"
+	var args []any
+	addCond := func(cond string, arg any) {
+		args = append(args, arg)
+		query += fmt.Sprintf(" AND "+cond, len(args))
+	}
+	if filter.OwnerID != "" {
+		addCond("w.owner_id = $%d", filter.OwnerID)
+	}
+	if filter.Status != "" {
+		addCond("w.status = $%d", filter.Status)
+	}
+	if len(filter.Labels) > 0 {
+		addCond("w.labels @> $%d", filter.Labels)
+	}
+	if filter.Cursor != nil {
+		args = append(args, filter.Cursor.CreatedAt, filter.Cursor.ID)
+		query += fmt.Sprintf(" AND (w.created_at, w.id) < ($%d, $%d)", len(args)-1, len(args))
+	}
+	args = append(args, filter.Limit)
+	query += fmt.Sprintf(" ORDER BY w.created_at DESC, w.id DESC LIMIT $%d", len(args))
+
+	rows, err := r.pool.Query(ctx, query, args...)
+	if err != nil {
+		r.logger.Error("Failed to query wallets", slog.Any("err", err))
+		return nil, err
+	}
+	wallets, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.Wallet])
+	if err != nil {
+		r.logger.Error("Failed to scan wallets", slog.Any("err", err))
+		return nil, err
+	}
+	return wallets, nil
+}
+
+// UpdateWallet меняет метаданные кошелька; nil-поля update остаются без изменений.
+func (r *WalletPGRepository) UpdateWallet(
+	ctx context.Context,
+	walletID uuid.UUID,
+	update models.UpdateWalletRequest,
+) (models.Wallet, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		r.logger.Error("Failed to begin transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.Wallet{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	// nil-интерфейс передаётся как NULL, и COALESCE оставляет метки без изменений
+	var labels any
+	if update.Labels != nil {
+		labels = *update.Labels
+		if *update.Labels == nil {
+			labels = map[string]string{}
+		}
+	}
+	tag, err := tx.Exec(ctx, `
+		UPDATE wallets SET
+			owner_id = COALESCE($1, owner_id),
+			name = COALESCE($2, name),
+			labels = COALESCE($3, labels),
+			updated_at = NOW()
+		WHERE id = $4`, update.OwnerID, update.Name, labels, walletID)
+	if err != nil {
+		r.logger.Error("Failed to update wallet",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return models.Wallet{}, err
+	}
+	if tag.RowsAffected() == 0 {
+		return models.Wallet{}, ErrWalletNotFound
+	}
+	wallet, err := r.walletTx(ctx, tx, walletID)
+	if err != nil {
+		return wallet, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		r.logger.Error("Failed to commit transaction",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return wallet, err
+	}
+	return wallet, nil
+}
@@ -0,0 +1,308 @@
+package repository
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/jackc/pgx/v5"
+)
+
+var (
+	ErrWebhookNotFound         = errors.New("webhook not found")
+	ErrWebhookDeliveryNotFound = errors.New("webhook delivery not found")
+	ErrDeliveryNotRetryable    = errors.New("webhook delivery is not dead or its webhook is deleted")
+)
+
+const (
+	webhookColumns  = `id, owner_id, wallet_id, url, events, low_balance_threshold, active, created_at, updated_at`
+	deliveryColumns = `id, subscription_id, event_id, event, payload, status, attempts, next_attempt_at,
+	last_status_code, last_error, created_at, updated_at, delivered_at`
+)
+
+// CreateWebhook сохраняет подписку. Кошелёк подписки должен принадлежать её владельцу,
+// иначе возвращается ErrWalletNotFound.
+func (r *WalletPGRepository) CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	if sub.WalletID != nil {
+		var owned bool
+		err := r.pool.QueryRow(ctx, "SELECT owner_id = $2 FROM wallets WHERE id = $1", *sub.WalletID, sub.OwnerID).Scan(&owned)
+		if err == pgx.ErrNoRows || (err == nil && !owned) {
+			return models.WebhookSubscription{}, ErrWalletNotFound
+		}
+		if err != nil {
+			r.logger.Error("Failed to select wallet",
+				slog.String("wallet_id", sub.WalletID.String()),
+				slog.Any("err", err),
+			)
+			return models.WebhookSubscription{}, err
+		}
+	}
+	rows, err := r.pool.Query(ctx, `
+		INSERT INTO webhook_subscriptions (id, owner_id, wallet_id, url, secret, events, low_balance_threshold)
+		VALUES ($1, $2, $3, $4, $5, $6, $7)
+		RETURNING `+webhookColumns,
+		sub.ID, sub.OwnerID, sub.WalletID, sub.URL, sub.Secret, sub.Events, sub.LowBalanceThreshold)
+	if err != nil {
+		r.logger.Error("Failed to insert webhook", slog.String("owner_id", sub.OwnerID), slog.Any("err", err))
+		return models.WebhookSubscription{}, err
+	}
+	created, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.WebhookSubscription])
+	if err != nil {
+		r.logger.Error("Failed to scan webhook", slog.String("owner_id", sub.OwnerID), slog.Any("err", err))
+		return models.WebhookSubscription{}, err
+	}
+	created.Secret = sub.Secret
+	return created, nil
+}
+
+func (r *WalletPGRepository) GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx, "SELECT "+webhookColumns+" FROM webhook_subscriptions WHERE id = $1", id)
+	if err != nil {
+		r.logger.Error("Failed to query webhook", slog.String("webhook_id", id.String()), slog.Any("err", err))
+		return models.WebhookSubscription{}, err
+	}
+	sub, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.WebhookSubscription])
+	if err == pgx.ErrNoRows {
+		return sub, ErrWebhookNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan webhook", slog.String("webhook_id", id.String()), slog.Any("err", err))
+	}
+	return sub, err
+}
+
+// ListWebhooks возвращает подписки владельца, включая удалённые, от новых к старым.
+func (r *WalletPGRepository) ListWebhooks(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+webhookColumns+`
+		FROM webhook_subscriptions
+		WHERE owner_id = $1
+		ORDER BY created_at DESC, id`, ownerID)
+	if err != nil {
+		r.logger.Error("Failed to query webhooks", slog.String("owner_id", ownerID), slog.Any("err", err))
+		return nil, err
+	}
+	subs, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.WebhookSubscription])
+	if err != nil {
+		r.logger.Error("Failed to scan webhooks", slog.String("owner_id", ownerID), slog.Any("err", err))
+		return nil, err
+	}
+	if subs == nil {
+		subs = []models.WebhookSubscription{}
+	}
+	return subs, nil
+}
+
+// DeleteWebhook отключает подписку. Журнал её доставок сохраняется, а недоставленные
+// доставки переходят в DEAD.
+func (r *WalletPGRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	tx, err := r.pool.BeginTx(ctx, pgx.TxOptions{})
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	defer func() {
+		if err := tx.Rollback(ctx); err != nil && err != pgx.ErrTxClosed {
+			r.logger.Error("Failed to rollback transaction",
+				slog.String("webhook_id", id.String()),
+				slog.Any("err", err),
+			)
+		}
+	}()
+
+	rows, err := tx.Query(ctx, `
+		UPDATE webhook_subscriptions SET active = FALSE, updated_at = NOW()
+		WHERE id = $1
+		RETURNING `+webhookColumns, id)
+	if err != nil {
+		r.logger.Error("Failed to update webhook", slog.String("webhook_id", id.String()), slog.Any("err", err))
+		return models.WebhookSubscription{}, err
+	}
+	sub, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.WebhookSubscription])
+	if err == pgx.ErrNoRows {
+		return sub, ErrWebhookNotFound
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan webhook", slog.String("webhook_id", id.String()), slog.Any("err", err))
+		return sub, err
+	}
+	_, err = tx.Exec(ctx, `
+		UPDATE webhook_deliveries SET status = $2, last_error = 'webhook deleted', updated_at = NOW()
+		WHERE subscription_id = $1 AND status = $3`, id, models.DeliveryDead, models.DeliveryPending)
+	if err != nil {
+		r.logger.Error("Failed to cancel webhook deliveries", slog.String("webhook_id", id.String()), slog.Any("err", err))
+		return sub, err
+	}
+	return sub, tx.Commit(ctx)
+}
+
+// WebhookSubscriptionsForWallet возвращает активные подписки на события кошелька walletID:
+// подписки на сам кошелёк и подписки его владельца без указания кошелька.
+func (r *WalletPGRepository) WebhookSubscriptionsForWallet(ctx context.Context, walletID uuid.UUID) ([]models.WebhookSubscription, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT s.id, s.owner_id, s.wallet_id, s.url, s.events, s.low_balance_threshold, s.active,
+			s.created_at, s.updated_at
+		FROM webhook_subscriptions s
+		JOIN wallets w ON w.id = $1
+		WHERE s.active
+			AND (s.wallet_id = w.id OR (s.wallet_id IS NULL AND w.owner_id <> '' AND s.owner_id = w.owner_id))`,
+		walletID)
+	if err != nil {
+		r.logger.Error("Failed to query wallet webhooks", slog.String("wallet_id", walletID.String()), slog.Any("err", err))
+		return nil, err
+	}
+	subs, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.WebhookSubscription])
+	if err != nil {
+		r.logger.Error("Failed to scan wallet webhooks", slog.String("wallet_id", walletID.String()), slog.Any("err", err))
+		return nil, err
+	}
+	return subs, nil
+}
+
+// CreateWebhookDeliveries ставит доставки в очередь. Уже созданные доставки того же события
+// пропускаются; возвращается число новых.
+func (r *WalletPGRepository) CreateWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) (int64, error) {
+	batch := &pgx.Batch{}
+	for _, d := range deliveries {
+		batch.Queue(`
+			INSERT INTO webhook_deliveries (subscription_id, event_id, event, payload)
+			VALUES ($1, $2, $3, $4)
+			ON CONFLICT (subscription_id, event_id, event) DO NOTHING`,
+			d.SubscriptionID, d.EventID, d.Event, d.Payload)
+	}
+	results := r.pool.SendBatch(ctx, batch)
+	defer results.Close()
+	var created int64
+	for range deliveries {
+		tag, err := results.Exec()
+		if err != nil {
+			r.logger.Error("Failed to insert webhook delivery", slog.Any("err", err))
+			return created, err
+		}
+		created += tag.RowsAffected()
+	}
+	return created, nil
+}
+
+// ClaimWebhookDeliveries забирает наступившие доставки, сдвигая их next_attempt_at на lease.
+func (r *WalletPGRepository) ClaimWebhookDeliveries(
+	ctx context.Context,
+	now time.Time,
+	lease time.Duration,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH due AS (
+			SELECT d.id, s.url, s.secret
+			FROM webhook_deliveries d
+			JOIN webhook_subscriptions s ON s.id = d.subscription_id AND s.active
+			WHERE d.status = $1 AND d.next_attempt_at <= $2
+			ORDER BY d.next_attempt_at, d.id
+			LIMIT $4
+			FOR UPDATE OF d SKIP LOCKED
+		)
+		UPDATE webhook_deliveries d SET next_attempt_at = $3, updated_at = NOW()
+		FROM due
+		WHERE d.id = due.id
+		RETURNING d.id, d.subscription_id, d.event_id, d.event, d.payload, d.status, d.attempts,
+			d.next_attempt_at, d.last_status_code, d.last_error, d.created_at, d.updated_at,
+			d.delivered_at, due.url, due.secret`,
+		models.DeliveryPending, now, now.Add(lease), limit)
+	if err != nil {
+		r.logger.Error("Failed to claim webhook deliveries", slog.Any("err", err))
+		return nil, err
+	}
+	deliveries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (models.WebhookDelivery, error) {
+		var d models.WebhookDelivery
+		err := row.Scan(&d.ID, &d.SubscriptionID, &d.EventID, &d.Event, &d.Payload, &d.Status, &d.Attempts,
+			&d.NextAttemptAt, &d.LastStatusCode, &d.LastError, &d.CreatedAt, &d.UpdatedAt,
+			&d.DeliveredAt, &d.URL, &d.Secret)
+		return d, err
+	})
+	if err != nil {
+		r.logger.Error("Failed to scan webhook deliveries", slog.Any("err", err))
+		return nil, err
+	}
+	return deliveries, nil
+}
+
+// RecordWebhookAttempt сохраняет результат попытки доставки.
+func (r *WalletPGRepository) RecordWebhookAttempt(ctx context.Context, d models.WebhookDelivery) error {
+	_, err := r.pool.Exec(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $2, attempts = $3, next_attempt_at = $4, last_status_code = $5, last_error = $6,
+			delivered_at = $7, updated_at = NOW()
+		WHERE id = $1`,
+		d.ID, d.Status, d.Attempts, d.NextAttemptAt, d.LastStatusCode, d.LastError, d.DeliveredAt)
+	if err != nil {
+		r.logger.Error("Failed to record webhook attempt", slog.Int64("delivery_id", d.ID), slog.Any("err", err))
+	}
+	return err
+}
+
+// ListWebhookDeliveries возвращает журнал доставок подписки от новых к старым, при status != ""
+// — только доставки в этом статусе.
+func (r *WalletPGRepository) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	status string,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	if _, err := r.GetWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+deliveryColumns+`
+		FROM webhook_deliveries
+		WHERE subscription_id = $1 AND ($2::text = '' OR status = $2)
+		ORDER BY id DESC
+		LIMIT $3`, webhookID, status, limit)
+	if err != nil {
+		r.logger.Error("Failed to query webhook deliveries", slog.String("webhook_id", webhookID.String()), slog.Any("err", err))
+		return nil, err
+	}
+	deliveries, err := pgx.CollectRows(rows, pgx.RowToStructByName[models.WebhookDelivery])
+	if err != nil {
+		r.logger.Error("Failed to scan webhook deliveries", slog.String("webhook_id", webhookID.String()), slog.Any("err", err))
+		return nil, err
+	}
+	if deliveries == nil {
+		deliveries = []models.WebhookDelivery{}
+	}
+	return deliveries, nil
+}
+
+// RetryWebhookDelivery возвращает доставку из DEAD в очередь с новым счётчиком попыток.
+func (r *WalletPGRepository) RetryWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (models.WebhookDelivery, error) {
+	rows, err := r.pool.Query(ctx, `
+		UPDATE webhook_deliveries
+		SET status = $3, attempts = 0, next_attempt_at = NOW(), updated_at = NOW()
+		WHERE id = $1 AND subscription_id = $2 AND status = $4
+			AND EXISTS (SELECT 1 FROM webhook_subscriptions WHERE id = $2 AND active)
+		RETURNING `+deliveryColumns,
+		deliveryID, webhookID, models.DeliveryPending, models.DeliveryDead)
+	if err != nil {
+		r.logger.Error("Failed to retry webhook delivery", slog.Int64("delivery_id", deliveryID), slog.Any("err", err))
+		return models.WebhookDelivery{}, err
+	}
+	delivery, err := pgx.CollectExactlyOneRow(rows, pgx.RowToStructByName[models.WebhookDelivery])
+	if err == pgx.ErrNoRows {
+		var exists bool
+		err := r.pool.QueryRow(ctx,
+			"SELECT EXISTS (SELECT 1 FROM webhook_deliveries WHERE id = $1 AND subscription_id = $2)",
+			deliveryID, webhookID).Scan(&exists)
+		if err != nil {
+			return models.WebhookDelivery{}, err
+		}
+		if !exists {
+			return models.WebhookDelivery{}, ErrWebhookDeliveryNotFound
+		}
+		return models.WebhookDelivery{}, ErrDeliveryNotRetryable
+	}
+	if err != nil {
+		r.logger.Error("Failed to scan webhook delivery", slog.Int64("delivery_id", deliveryID), slog.Any("err", err))
+	}
+	return delivery, err
+}
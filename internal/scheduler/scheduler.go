@@ -0,0 +1,220 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"test_wallet/internal/models"
+	"test_wallet/internal/poller"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+//go:generate mockgen -source=scheduler.go -destination=../../test/mock_scheduler.go -package=test
+
+// Store — хранилище запланированных операций.
+type Store interface {
+	DueScheduledOperations(ctx context.Context, now time.Time, limit int) ([]models.ScheduledOperation, error)
+	LockScheduledOperation(ctx context.Context, id uuid.UUID) (unlock func(), ok bool, err error)
+	GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error)
+	RecordScheduledRun(ctx context.Context, op models.ScheduledOperation, run models.ScheduledRun) error
+}
+
+// Executor проводит операции над балансом; в cmd/server это service.WalletService, так что
+// запланированные операции проходят те же проверки, что и запросы через API.
+type Executor interface {
+	Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error)
+	Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, error)
+	Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.TransferResult, error)
+}
+
+const (
+	DefaultInterval   = 10 * time.Second
+	DefaultRetryDelay = time.Minute
+	DefaultBatchSize  = 100
+	// maxRetryDelay — дольше часа неудавшееся срабатывание не откладывается.
+	maxRetryDelay = time.Hour
+)
+
+// Scheduler исполняет наступившие запланированные операции.
+type Scheduler struct {
+	store      Store
+	executor   Executor
+	logger     *slog.Logger
+	interval   time.Duration
+	retryDelay time.Duration
+	batchSize  int
+	now        func() time.Time
+}
+
+type Option func(*Scheduler)
+
+// WithInterval задаёт период опроса наступивших операций.
+func WithInterval(interval time.Duration) Option {
+	return func(s *Scheduler) {
+		s.interval = interval
+	}
+}
+
+// WithRetryDelay задаёт задержку повтора после первой неудачи срабатывания (см. poller.Backoff).
+func WithRetryDelay(delay time.Duration) Option {
+	return func(s *Scheduler) {
+		s.retryDelay = delay
+	}
+}
+
+// WithClock задаёт часы, по которым определяются наступившие срабатывания; нужен тестам.
+func WithClock(now func() time.Time) Option {
+	return func(s *Scheduler) {
+		s.now = now
+	}
+}
+
+func New(store Store, executor Executor, logger *slog.Logger, opts ...Option) *Scheduler {
+	s := &Scheduler{
+		store:      store,
+		executor:   executor,
+		logger:     logger,
+		interval:   DefaultInterval,
+		retryDelay: DefaultRetryDelay,
+		batchSize:  DefaultBatchSize,
+		now:        time.Now,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+// Run опрашивает хранилище до отмены ctx.
+func (s *Scheduler) Run(ctx context.Context) {
+	poller.Run(ctx, s.interval, s.logger, "Scheduler run failed", s.RunDue)
+}
+
+// RunDue исполняет наступившие операции и возвращает число выполненных попыток.
+func (s *Scheduler) RunDue(ctx context.Context) (int, error) {
+	due, err := s.store.DueScheduledOperations(ctx, s.now(), s.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	n := 0
+	for _, op := range due {
+		if ctx.Err() != nil {
+			return n, ctx.Err()
+		}
+		ran, err := s.runOne(ctx, op.ID)
+		if err != nil {
+			s.logger.Error("Scheduled operation failed to run",
+				slog.String("scheduled_operation_id", op.ID.String()),
+				slog.Any("err", err),
+			)
+			continue
+		}
+		if ran {
+			n++
+		}
+	}
+	return n, nil
+}
+
+func (s *Scheduler) runOne(ctx context.Context, id uuid.UUID) (bool, error) {
+	unlock, ok, err := s.store.LockScheduledOperation(ctx, id)
+	if err != nil || !ok {
+		return false, err
+	}
+	defer unlock()
+
+	// Перечитываем под блокировкой: другая реплика могла исполнить срабатывание,
+	// пока мы ждали своей очереди
+	op, err := s.store.GetScheduledOperation(ctx, id)
+	if err != nil {
+		return false, err
+	}
+	now := s.now()
+	if op.Status != models.ScheduleActive || op.NextRunAt.After(now) {
+		return false, nil
+	}
+
+	run := models.ScheduledRun{
+		ScheduledOperationID: op.ID,
+		OccurrenceAt:         op.OccurrenceAt,
+		Attempt:              op.Attempts + 1,
+		Status:               models.RunSucceeded,
+	}
+	attrs := []any{
+		slog.String("scheduled_operation_id", op.ID.String()),
+		slog.String("operation", op.Operation),
+		slog.Time("occurrence_at", op.OccurrenceAt),
+		slog.Int("attempt", run.Attempt),
+	}
+	if execErr := s.execute(ctx, op); execErr != nil {
+		msg := execErr.Error()
+		run.Status = models.RunFailed
+		run.Error = &msg
+		op.LastError = &msg
+		op.Attempts = run.Attempt
+		switch {
+		case op.Attempts < op.MaxAttempts:
+			op.NextRunAt = now.Add(poller.Backoff(s.retryDelay, maxRetryDelay, op.Attempts))
+			s.logger.Warn("Scheduled operation failed, will retry", append(attrs, slog.Any("err", execErr))...)
+		case op.Cron != nil:
+			s.logger.Error("Scheduled occurrence failed, skipping to next", append(attrs, slog.Any("err", execErr))...)
+			s.advance(&op, now)
+		default:
+			op.Status = models.ScheduleFailed
+			s.logger.Error("Scheduled operation failed", append(attrs, slog.Any("err", execErr))...)
+		}
+	} else {
+		op.LastError = nil
+		s.advance(&op, now)
+		s.logger.Info("Scheduled operation executed", attrs...)
+	}
+	return true, s.store.RecordScheduledRun(ctx, op, run)
+}
+
+// execute проводит срабатывание с ключом идемпотентности, привязанным к его плановому времени.
+func (s *Scheduler) execute(ctx context.Context, op models.ScheduledOperation) error {
+	opts := []models.OperationOption{
+		models.WithIdempotency(&models.Idempotency{
+			Key:         op.IdempotencyKey(op.OccurrenceAt),
+			RequestHash: op.RequestHash(),
+		}),
+	}
+	if op.Currency != nil {
+		opts = append(opts, models.WithCurrency(*op.Currency))
+	}
+	var err error
+	switch op.Operation {
+	case "DEPOSIT":
+		_, _, err = s.executor.Deposit(ctx, op.WalletID, op.Amount, opts...)
+	case "WITHDRAW":
+		_, err = s.executor.Withdraw(ctx, op.WalletID, op.Amount, opts...)
+	case "TRANSFER":
+		_, err = s.executor.Transfer(ctx, op.WalletID, *op.ToWalletID, op.Amount, opts...)
+	default:
+		err = models.ErrInvalidSchedule
+	}
+	return err
+}
+
+// advance переводит операцию к следующему срабатыванию. Пропущенные (например, пока сервис
+// был остановлен) срабатывания регулярной операции не догоняются: следующее берётся после now.
+func (s *Scheduler) advance(op *models.ScheduledOperation, now time.Time) {
+	op.Attempts = 0
+	if op.Cron == nil {
+		op.Status = models.ScheduleCompleted
+		return
+	}
+	cron, err := ParseCron(*op.Cron)
+	var next time.Time
+	if err == nil {
+		next = cron.Next(now)
+	}
+	if next.IsZero() {
+		op.Status = models.ScheduleCompleted
+		return
+	}
+	op.OccurrenceAt = next
+	op.NextRunAt = next
+}
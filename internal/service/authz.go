@@ -0,0 +1,125 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"test_wallet/internal/auth"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+
+	"github.com/google/uuid"
+)
+
+// owner возвращает владельца, от имени которого выполняется вызов; ok = false, если
+// владелец не проверяется: аутентификация отключена или у вызывающего есть право admin.
+func owner(ctx context.Context) (subject string, ok bool) {
+	principal, ok := auth.FromContext(ctx)
+	if !ok || principal.HasScope(models.ScopeAdmin) {
+		return "", false
+	}
+	return principal.Subject, true
+}
+
+// authorizeWallet возвращает models.ErrWalletAccessDenied, если кошелёк принадлежит не
+// вызывающему.
+func (s *WalletService) authorizeWallet(ctx context.Context, walletID uuid.UUID) error {
+	subject, ok := owner(ctx)
+	if !ok {
+		return nil
+	}
+	wallet, err := s.ledger.GetWallet(ctx, walletID)
+	if err != nil {
+		return err
+	}
+	if wallet.OwnerID != subject {
+		return models.ErrWalletAccessDenied
+	}
+	return nil
+}
+
+// authorizeDeposit — authorizeWallet для пополнения: кошелёк, который пополнение создаст,
+// достаётся вызывающему.
+func (s *WalletService) authorizeDeposit(
+	ctx context.Context,
+	walletID uuid.UUID,
+	opts []models.OperationOption,
+) ([]models.OperationOption, error) {
+	subject, ok := owner(ctx)
+	if !ok {
+		return opts, nil
+	}
+	err := s.authorizeWallet(ctx, walletID)
+	if errors.Is(err, repository.ErrWalletNotFound) {
+		return append(opts, models.WithOwner(subject)), nil
+	}
+	return opts, err
+}
+
+// authorizeHold проверяет владельца кошелька, на котором стоит холд.
+func (s *WalletService) authorizeHold(ctx context.Context, holdID uuid.UUID) error {
+	if _, ok := owner(ctx); !ok {
+		return nil
+	}
+	hold, err := s.holds.GetHold(ctx, holdID)
+	if err != nil {
+		return err
+	}
+	return s.authorizeWallet(ctx, hold.WalletID)
+}
+
+// authorizeQuote проверяет владельца кошелька, с которого списывает котировка.
+func (s *WalletService) authorizeQuote(ctx context.Context, quoteID uuid.UUID) error {
+	if _, ok := owner(ctx); !ok {
+		return nil
+	}
+	quote, err := s.conversions.GetQuote(ctx, quoteID)
+	if err != nil {
+		return err
+	}
+	return s.authorizeWallet(ctx, quote.FromWalletID)
+}
+
+// authorizeSchedule проверяет владельца кошелька запланированной операции.
+func (s *WalletService) authorizeSchedule(ctx context.Context, id uuid.UUID) error {
+	if _, ok := owner(ctx); !ok {
+		return nil
+	}
+	op, err := s.schedules.GetScheduledOperation(ctx, id)
+	if err != nil {
+		return err
+	}
+	return s.authorizeWallet(ctx, op.WalletID)
+}
+
+// webhookOwner возвращает владельца подписок: вызывающего или, если владелец не проверяется,
+// ownerID из запроса.
+func webhookOwner(ctx context.Context, ownerID string) (string, error) {
+	subject, ok := owner(ctx)
+	switch {
+	case !ok && ownerID == "":
+		return "", fmt.Errorf("%w: ownerId is required", models.ErrInvalidWebhook)
+	case !ok:
+		return ownerID, nil
+	case ownerID != "" && ownerID != subject:
+		return "", models.ErrWebhookAccessDenied
+	}
+	return subject, nil
+}
+
+// authorizeWebhook возвращает models.ErrWebhookAccessDenied, если подписка принадлежит не
+// вызывающему.
+func (s *WalletService) authorizeWebhook(ctx context.Context, id uuid.UUID) error {
+	subject, ok := owner(ctx)
+	if !ok {
+		return nil
+	}
+	sub, err := s.webhooks.GetWebhook(ctx, id)
+	if err != nil {
+		return err
+	}
+	if sub.OwnerID != subject {
+		return models.ErrWebhookAccessDenied
+	}
+	return nil
+}
@@ -2,9 +2,15 @@ package service
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"log/slog"
+	"test_wallet/internal/models"
 	"test_wallet/internal/repository"
+	"test_wallet/internal/scheduler"
+	"test_wallet/internal/webhooks"
 	"time"
 
 	"github.com/google/uuid"
@@ -12,147 +18,1479 @@ import (
 	"github.com/shopspring/decimal"
 )
 
-//go:generate mockgen -source=service.go -destination=../../test/mock_wallet_repository.go -package=test WalletRepository
+//go:generate mockgen -source=service.go -destination=../../test/mock_wallet_repository.go -package=test
 
-type WalletRepository interface {
-	UpdateBalance(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opType string) (decimal.Decimal, bool, error)
+// LedgerRepository — кошельки, операции с балансом и журнал.
+type LedgerRepository interface {
+	UpdateBalance(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opType string, opts ...models.OperationOption) (decimal.Decimal, bool, error)
+	Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.TransferResult, error)
 	GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error)
+	GetWallet(ctx context.Context, walletID uuid.UUID) (models.Wallet, error)
+	ListTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) ([]models.Transaction, error)
+	LookupIdempotentResponse(ctx context.Context, idem *models.Idempotency) (bool, error)
+	GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (models.BalanceAt, error)
+	StreamStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error
+	CreateWallet(ctx context.Context, wallet models.Wallet) (models.Wallet, error)
+	ListWallets(ctx context.Context, filter models.WalletFilter) ([]models.Wallet, error)
+	UpdateWallet(ctx context.Context, walletID uuid.UUID, update models.UpdateWalletRequest) (models.Wallet, error)
+	GetWalletLimits(ctx context.Context, walletID uuid.UUID) ([]models.SpendingLimit, error)
+	QuoteFee(ctx context.Context, opType, currency string, amount decimal.Decimal) (decimal.Decimal, error)
+}
+
+// HoldRepository — холды.
+type HoldRepository interface {
+	CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, expiresAt time.Time, opts ...models.OperationOption) (models.HoldResult, error)
+	CaptureHold(ctx context.Context, holdID uuid.UUID, amount *decimal.Decimal) (models.HoldResult, error)
+	VoidHold(ctx context.Context, holdID uuid.UUID) (models.HoldResult, error)
+	GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error)
+	ExpireHolds(ctx context.Context) (int64, error)
+}
+
+// ConversionRepository — котировки и конвертации.
+type ConversionRepository interface {
+	CreateQuote(ctx context.Context, quote models.ConversionQuote) (models.ConversionQuote, error)
+	GetQuote(ctx context.Context, quoteID uuid.UUID) (models.ConversionQuote, error)
+	ExecuteConversion(ctx context.Context, quoteID uuid.UUID, opts ...models.OperationOption) (models.ConversionResult, error)
+}
+
+// ScheduleRepository — запланированные операции.
+type ScheduleRepository interface {
+	CreateScheduledOperation(ctx context.Context, op models.ScheduledOperation) (models.ScheduledOperation, error)
+	GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error)
+	ListScheduledOperations(ctx context.Context, walletID uuid.UUID) ([]models.ScheduledOperation, error)
+	CancelScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error)
+	GetScheduledRuns(ctx context.Context, id uuid.UUID) ([]models.ScheduledRun, error)
+}
+
+// WebhookRepository — подписки на вебхуки и журнал доставок.
+type WebhookRepository interface {
+	CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error)
+	GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error)
+	ListWebhooks(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error)
+	DeleteWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error)
+	ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error)
+	RetryWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (models.WebhookDelivery, error)
+}
+
+// EventRepository — события кошелька из outbox.
+type EventRepository interface {
+	GetStreamSnapshot(ctx context.Context, walletID uuid.UUID) (models.StreamSnapshot, error)
+	ListWalletEvents(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.Event, error)
+}
+
+// AdminRepository — административные операции и проверки книги.
+type AdminRepository interface {
+	ReverseTransaction(ctx context.Context, transactionID int64, amount *decimal.Decimal, allowNegative bool, opts ...models.OperationOption) (models.ReversalResult, error)
+	SetWalletStatus(ctx context.Context, walletID uuid.UUID, status, reason string) (models.Wallet, error)
+	SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit decimal.Decimal, reason string) (models.Wallet, error)
+	GetOverdraftLimitChanges(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftLimitChange, error)
+	SetLimitTier(ctx context.Context, tier, currency string, limit models.SpendingLimit) error
+	SetWalletLimit(ctx context.Context, walletID uuid.UUID, limit models.SpendingLimit) error
+	SetWalletTier(ctx context.Context, walletID uuid.UUID, tier string) (models.Wallet, error)
+	SetFeeSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error)
+	ListFeeSchedules(ctx context.Context) ([]models.FeeSchedule, error)
+	GetTrialBalance(ctx context.Context) (models.TrialBalance, error)
+	TakeBalanceSnapshots(ctx context.Context, asOf time.Time) (int64, error)
+	Reconcile(ctx context.Context) (models.ReconciliationReport, error)
+	VerifyJournalChain(ctx context.Context, walletID *uuid.UUID) (models.ChainVerification, error)
+}
+
+// WalletRepository — хранилище, которое нужно сервису; реализуется repository.WalletPGRepository.
+type WalletRepository interface {
+	LedgerRepository
+	HoldRepository
+	ConversionRepository
+	ScheduleRepository
+	WebhookRepository
+	EventRepository
+	AdminRepository
+}
+
+// RateProvider возвращает текущий курс обмена from → to.
+type RateProvider interface {
+	Rate(ctx context.Context, from, to string) (models.Rate, error)
 }
 
+const (
+	DefaultHoldTTL  = 24 * time.Hour
+	MaxHoldTTL      = 30 * 24 * time.Hour
+	DefaultQuoteTTL = time.Minute
+	// SnapshotLag — сколько ждать после окончания суток перед снимком балансов, чтобы
+	// транзакции, начатые до полуночи, успели зафиксироваться.
+	SnapshotLag = 5 * time.Minute
+)
+
 type WalletService struct {
-	repo       WalletRepository
-	logger     *slog.Logger
-	maxRetries int
+	ledger      LedgerRepository
+	holds       HoldRepository
+	conversions ConversionRepository
+	schedules   ScheduleRepository
+	webhooks    WebhookRepository
+	events      EventRepository
+	admin       AdminRepository
+	logger      *slog.Logger
+	maxRetries  int
+	rates       RateProvider
+	quoteTTL    time.Duration
+	// reversalPolicy определяет, что делать со сторно пополнения, если средства уже потрачены.
+	reversalPolicy string
 }
 
-func NewWalletService(repo WalletRepository, logger *slog.Logger) *WalletService {
-	return &WalletService{
-		repo:       repo,
-		logger:     logger,
-		maxRetries: 3, // можно вынести в .env
+type Option func(*WalletService)
+
+// WithRateProvider включает конвертацию валют с курсами от provider.
+func WithRateProvider(provider RateProvider) Option {
+	return func(s *WalletService) {
+		s.rates = provider
 	}
 }
 
-func (s *WalletService) Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal) (decimal.Decimal, bool, error) {
-	var lastErr error
-	for i := 0; i < s.maxRetries; i++ {
-		balance, created, err := s.repo.UpdateBalance(ctx, walletID, amount, "DEPOSIT")
-		if err == nil {
-			return balance, created, nil
+// WithQuoteTTL задаёт срок действия котировки конвертации.
+func WithQuoteTTL(ttl time.Duration) Option {
+	return func(s *WalletService) {
+		s.quoteTTL = ttl
+	}
+}
+
+// WithReversalPolicy задаёт политику сторно: models.ReversalPolicyFail или
+// models.ReversalPolicyAllowNegative.
+func WithReversalPolicy(policy string) Option {
+	return func(s *WalletService) {
+		s.reversalPolicy = policy
+	}
+}
+
+func NewWalletService(repo WalletRepository, logger *slog.Logger, opts ...Option) *WalletService {
+	s := &WalletService{
+		ledger:      repo,
+		holds:       repo,
+		conversions: repo,
+		schedules:   repo,
+		webhooks:    repo,
+		events:      repo,
+		admin:       repo,
+		logger:      logger,
+		maxRetries:  3, // можно вынести в .env
+		quoteTTL:    DefaultQuoteTTL,
+
+		reversalPolicy: models.ReversalPolicyFail,
+	}
+	for _, opt := range opts {
+		opt(s)
+	}
+	return s
+}
+
+func (s *WalletService) Deposit(
+	ctx context.Context,
+	walletID uuid.UUID,
+	amount decimal.Decimal,
+	opts ...models.OperationOption,
+) (decimal.Decimal, bool, error) {
+	opts, err := s.authorizeDeposit(ctx, walletID, opts)
+	if err != nil {
+		return decimal.Zero, false, err
+	}
+	var (
+		balance decimal.Decimal
+		created bool
+	)
+	err = s.retry(ctx, "deposit", func() error {
+		var err error
+		balance, created, err = s.ledger.UpdateBalance(ctx, walletID, amount, "DEPOSIT", opts...)
+		return err
+	})
+	if err != nil {
+		s.logOperationError("Deposit failed", err,
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("amount", amount),
+		)
+	}
+	return balance, created, err
+}
+
+func (s *WalletService) Withdraw(
+	ctx context.Context,
+	walletID uuid.UUID,
+	amount decimal.Decimal,
+	opts ...models.OperationOption,
+) (decimal.Decimal, error) {
+	if amount.IsZero() || amount.IsNegative() {
+		s.logger.Error("Withdraw failed: amount must be positive",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("amount", amount),
+		)
+		return decimal.Zero, repository.ErrInvalidAmount
+	}
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return decimal.Zero, err
+	}
+	var balance decimal.Decimal
+	err := s.retry(ctx, "withdraw", func() error {
+		var err error
+		balance, _, err = s.ledger.UpdateBalance(ctx, walletID, amount.Neg(), "WITHDRAW", opts...)
+		return err
+	})
+	if err != nil {
+		s.logOperationError("Withdraw failed", err,
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("amount", amount),
+		)
+	}
+	return balance, err
+}
+
+func (s *WalletService) Transfer(
+	ctx context.Context,
+	fromID, toID uuid.UUID,
+	amount decimal.Decimal,
+	opts ...models.OperationOption,
+) (models.TransferResult, error) {
+	if amount.IsZero() || amount.IsNegative() {
+		s.logger.Error("Transfer failed: amount must be positive",
+			slog.String("from_wallet_id", fromID.String()),
+			slog.String("to_wallet_id", toID.String()),
+			slog.Any("amount", amount),
+		)
+		return models.TransferResult{}, repository.ErrInvalidAmount
+	}
+	if fromID == toID {
+		s.logger.Warn("Transfer failed: same wallet",
+			slog.String("wallet_id", fromID.String()),
+		)
+		return models.TransferResult{}, repository.ErrSameWallet
+	}
+	if err := s.authorizeWallet(ctx, fromID); err != nil {
+		return models.TransferResult{}, err
+	}
+	var result models.TransferResult
+	err := s.retry(ctx, "transfer", func() error {
+		var err error
+		result, err = s.ledger.Transfer(ctx, fromID, toID, amount, opts...)
+		return err
+	})
+	if err != nil {
+		s.logOperationError("Transfer failed", err,
+			slog.String("from_wallet_id", fromID.String()),
+			slog.String("to_wallet_id", toID.String()),
+			slog.Any("amount", amount),
+		)
+	}
+	return result, err
+}
+
+func (s *WalletService) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return decimal.Zero, err
+	}
+	balance, err := s.ledger.GetBalance(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWalletNotFound) {
+			s.logger.Warn("GetBalance: wallet not found",
+				slog.String("wallet_id", walletID.String()),
+			)
+			return balance, repository.ErrWalletNotFound
 		}
-		if isRetryableError(err) {
-			s.logger.Warn("Retrying deposit",
+		s.logger.Error("GetBalance failed",
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("err", err),
+		)
+		return balance, err
+	}
+	// Не логируем успешное получение баланса
+	return balance, nil
+}
+
+// GetWallet возвращает кошелёк с учётным и доступным балансом и суммой холдов.
+func (s *WalletService) GetWallet(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return models.Wallet{}, err
+	}
+	wallet, err := s.ledger.GetWallet(ctx, walletID)
+	if err != nil {
+		if errors.Is(err, repository.ErrWalletNotFound) {
+			s.logger.Warn("GetWallet: wallet not found", slog.String("wallet_id", walletID.String()))
+		} else {
+			s.logger.Error("GetWallet failed",
 				slog.String("wallet_id", walletID.String()),
-				slog.Int("attempt", i+1),
 				slog.Any("err", err),
 			)
-			time.Sleep(time.Duration(1<<i) * 10 * time.Microsecond)
-			lastErr = err
-			continue
 		}
+		return wallet, err
+	}
+	return wallet, nil
+}
 
+// GetBalanceAt возвращает баланс кошелька на момент at по журналу операций.
+func (s *WalletService) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (models.BalanceAt, error) {
+	if at.After(time.Now()) {
+		return models.BalanceAt{WalletID: walletID, At: at}, models.ErrTimestampInFuture
+	}
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return models.BalanceAt{WalletID: walletID, At: at}, err
+	}
+	balance, err := s.ledger.GetBalanceAt(ctx, walletID, at.UTC())
+	if err != nil {
 		if errors.Is(err, repository.ErrWalletNotFound) {
-			s.logger.Error("Deposit failed: wallet not found",
+			s.logger.Warn("GetBalanceAt: wallet not found", slog.String("wallet_id", walletID.String()))
+		} else {
+			s.logger.Error("GetBalanceAt failed",
 				slog.String("wallet_id", walletID.String()),
-				slog.Any("amount", amount),
+				slog.Time("at", at),
+				slog.Any("err", err),
 			)
-			return balance, false, repository.ErrWalletNotFound
 		}
-		if errors.Is(err, repository.ErrInsufficientFunds) {
-			s.logger.Error("Deposit failed: insufficient funds (should not happen for deposit)",
+		return balance, err
+	}
+	return balance, nil
+}
+
+// ExportStatement выводит выписку по кошельку за период [from, to) в w.
+func (s *WalletService) ExportStatement(
+	ctx context.Context,
+	walletID uuid.UUID,
+	from, to time.Time,
+	w models.StatementWriter,
+) error {
+	if !from.Before(to) {
+		return models.ErrInvalidStatementRange
+	}
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return err
+	}
+	err := s.ledger.StreamStatement(ctx, walletID, from.UTC(), to.UTC(), w)
+	if err != nil {
+		if errors.Is(err, repository.ErrWalletNotFound) {
+			s.logger.Warn("ExportStatement: wallet not found", slog.String("wallet_id", walletID.String()))
+		} else {
+			s.logger.Error("ExportStatement failed",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+		}
+	}
+	return err
+}
+
+// Reconcile сверяет балансы кошельков с журналом и главной книгой. Найденные расхождения
+// логируются с уровнем Error: в исправной системе их быть не должно.
+func (s *WalletService) Reconcile(ctx context.Context) (models.ReconciliationReport, error) {
+	report, err := s.admin.Reconcile(ctx)
+	if err != nil {
+		s.logger.Error("Reconciliation failed", slog.Any("err", err))
+		return report, err
+	}
+	if !report.OK {
+		s.logger.Error("Reconciliation found ledger drift",
+			slog.Int("issues", len(report.Issues)),
+			slog.Any("report", report),
+		)
+		return report, nil
+	}
+	s.logger.Info("Reconciliation passed",
+		slog.Int64("wallets", report.WalletsChecked),
+		slog.Int64("transactions", report.TransactionsChecked),
+	)
+	return report, nil
+}
+
+// VerifyJournalChain проверяет хэш-цепочку журнала одного или всех кошельков.
+func (s *WalletService) VerifyJournalChain(ctx context.Context, walletID *uuid.UUID) (models.ChainVerification, error) {
+	result, err := s.admin.VerifyJournalChain(ctx, walletID)
+	if err != nil {
+		if !errors.Is(err, repository.ErrWalletNotFound) {
+			s.logger.Error("Journal chain verification failed", slog.Any("err", err))
+		}
+		return result, err
+	}
+	if !result.OK {
+		s.logger.Error("Journal hash chain is broken", slog.Any("break", result.FirstBreak))
+		return result, nil
+	}
+	s.logger.Info("Journal hash chain verified",
+		slog.Int64("wallets", result.WalletsChecked),
+		slog.Int64("transactions", result.RowsChecked),
+	)
+	return result, nil
+}
+
+// SnapshotBalances снимает балансы на конец последних завершившихся суток (UTC).
+// Вызывается периодически из cmd/server; повторные вызовы за те же сутки ничего не меняют.
+func (s *WalletService) SnapshotBalances(ctx context.Context) {
+	asOf := time.Now().UTC().Add(-SnapshotLag).Truncate(24 * time.Hour)
+	n, err := s.admin.TakeBalanceSnapshots(ctx, asOf)
+	if err != nil {
+		s.logger.Error("SnapshotBalances failed", slog.Time("as_of", asOf), slog.Any("err", err))
+		return
+	}
+	if n > 0 {
+		s.logger.Info("Balance snapshots taken", slog.Time("as_of", asOf), slog.Int64("count", n))
+	}
+}
+
+// GetTransactions возвращает страницу истории операций и курсор следующей страницы.
+func (s *WalletService) GetTransactions(
+	ctx context.Context,
+	walletID uuid.UUID,
+	filter models.TransactionFilter,
+) (models.TransactionPage, error) {
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return models.TransactionPage{}, err
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = models.DefaultTransactionsLimit
+	}
+	if filter.Limit > models.MaxTransactionsLimit {
+		filter.Limit = models.MaxTransactionsLimit
+	}
+	limit := filter.Limit
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	filter.Limit++
+
+	transactions, err := s.ledger.ListTransactions(ctx, walletID, filter)
+	if err != nil {
+		if errors.Is(err, repository.ErrWalletNotFound) {
+			s.logger.Warn("GetTransactions: wallet not found",
 				slog.String("wallet_id", walletID.String()),
-				slog.Any("amount", amount),
-				slog.Any("balance", balance),
 			)
-			return balance, false, repository.ErrInsufficientFunds
+			return models.TransactionPage{}, repository.ErrWalletNotFound
 		}
-		s.logger.Error("Deposit failed: unknown error",
+		s.logger.Error("GetTransactions failed",
 			slog.String("wallet_id", walletID.String()),
-			slog.Any("amount", amount),
 			slog.Any("err", err),
 		)
-		return balance, created, err
+		return models.TransactionPage{}, err
 	}
-	s.logger.Error("Deposit failed after retries",
-		slog.String("wallet_id", walletID.String()),
-		slog.Any("amount", amount),
-		slog.Any("err", lastErr),
-	)
-	return decimal.Zero, false, lastErr
+
+	page := models.TransactionPage{Transactions: transactions}
+	if len(transactions) > limit {
+		page.Transactions = transactions[:limit]
+		last := page.Transactions[limit-1]
+		page.NextCursor = models.TransactionCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if page.Transactions == nil {
+		page.Transactions = []models.Transaction{}
+	}
+	return page, nil
 }
 
-func (s *WalletService) Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal) (decimal.Decimal, error) {
+// GetTrialBalance возвращает сальдо всех счетов главной книги. Несведённые книги
+// не считаются ошибкой запроса, но логируются.
+func (s *WalletService) GetTrialBalance(ctx context.Context) (models.TrialBalance, error) {
+	tb, err := s.admin.GetTrialBalance(ctx)
+	if err != nil {
+		s.logger.Error("GetTrialBalance failed", slog.Any("err", err))
+		return tb, err
+	}
+	if !tb.Balanced {
+		s.logger.Error("Ledger is out of balance",
+			slog.Any("totals", tb.Totals),
+			slog.Any("unbalanced_entries", tb.UnbalancedEntries),
+		)
+	}
+	return tb, nil
+}
+
+// CreateHold резервирует amount на кошельке на время ttl (DefaultHoldTTL, если ttl == 0).
+func (s *WalletService) CreateHold(
+	ctx context.Context,
+	walletID uuid.UUID,
+	amount decimal.Decimal,
+	ttl time.Duration,
+	opts ...models.OperationOption,
+) (models.HoldResult, error) {
 	if amount.IsZero() || amount.IsNegative() {
-		s.logger.Error("Withdraw failed: amount must be positive",
+		return models.HoldResult{}, repository.ErrInvalidAmount
+	}
+	if ttl <= 0 {
+		ttl = DefaultHoldTTL
+	}
+	if ttl > MaxHoldTTL {
+		ttl = MaxHoldTTL
+	}
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return models.HoldResult{}, err
+	}
+	var result models.HoldResult
+	err := s.retry(ctx, "create hold", func() error {
+		var err error
+		result, err = s.holds.CreateHold(ctx, walletID, amount, time.Now().Add(ttl), opts...)
+		return err
+	})
+	if err != nil {
+		s.logHoldError("CreateHold failed", err,
 			slog.String("wallet_id", walletID.String()),
 			slog.Any("amount", amount),
 		)
-		return decimal.Zero, repository.ErrInvalidAmount
 	}
-	var lastErr error
-	for i := 0; i < s.maxRetries; i++ {
-		balance, _, err := s.repo.UpdateBalance(ctx, walletID, amount.Neg(), "WITHDRAW")
-		if err == nil {
-			return balance, nil
+	return result, err
+}
+
+// CaptureHold списывает холд целиком или частично (amount != nil).
+func (s *WalletService) CaptureHold(ctx context.Context, holdID uuid.UUID, amount *decimal.Decimal) (models.HoldResult, error) {
+	if err := s.authorizeHold(ctx, holdID); err != nil {
+		return models.HoldResult{}, err
+	}
+	var result models.HoldResult
+	err := s.retry(ctx, "capture hold", func() error {
+		var err error
+		result, err = s.holds.CaptureHold(ctx, holdID, amount)
+		return err
+	})
+	if err != nil {
+		s.logHoldError("CaptureHold failed", err, slog.String("hold_id", holdID.String()))
+	}
+	return result, err
+}
+
+func (s *WalletService) VoidHold(ctx context.Context, holdID uuid.UUID) (models.HoldResult, error) {
+	if err := s.authorizeHold(ctx, holdID); err != nil {
+		return models.HoldResult{}, err
+	}
+	var result models.HoldResult
+	err := s.retry(ctx, "void hold", func() error {
+		var err error
+		result, err = s.holds.VoidHold(ctx, holdID)
+		return err
+	})
+	if err != nil {
+		s.logHoldError("VoidHold failed", err, slog.String("hold_id", holdID.String()))
+	}
+	return result, err
+}
+
+func (s *WalletService) GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	if err := s.authorizeHold(ctx, holdID); err != nil {
+		return models.Hold{}, err
+	}
+	hold, err := s.holds.GetHold(ctx, holdID)
+	if err != nil {
+		s.logHoldError("GetHold failed", err, slog.String("hold_id", holdID.String()))
+	}
+	return hold, err
+}
+
+// ExpireHolds помечает просроченные холды. Вызывается периодически из cmd/server.
+func (s *WalletService) ExpireHolds(ctx context.Context) {
+	n, err := s.holds.ExpireHolds(ctx)
+	if err != nil {
+		s.logger.Error("ExpireHolds failed", slog.Any("err", err))
+		return
+	}
+	if n > 0 {
+		s.logger.Info("Expired holds", slog.Int64("count", n))
+	}
+}
+
+// QuoteConversion фиксирует курс для конвертации amount из валюты кошелька fromID в валюту
+// кошелька toID. Котировку можно исполнить через ExecuteConversion до её истечения.
+func (s *WalletService) QuoteConversion(
+	ctx context.Context,
+	fromID, toID uuid.UUID,
+	amount decimal.Decimal,
+) (models.ConversionQuote, error) {
+	quote, err := s.quoteConversion(ctx, fromID, toID, amount)
+	if err != nil {
+		s.logConversionError("QuoteConversion failed", err,
+			slog.String("from_wallet_id", fromID.String()),
+			slog.String("to_wallet_id", toID.String()),
+			slog.Any("amount", amount),
+		)
+	}
+	return quote, err
+}
+
+func (s *WalletService) quoteConversion(
+	ctx context.Context,
+	fromID, toID uuid.UUID,
+	amount decimal.Decimal,
+) (models.ConversionQuote, error) {
+	if !amount.IsPositive() {
+		return models.ConversionQuote{}, repository.ErrInvalidAmount
+	}
+	if fromID == toID {
+		return models.ConversionQuote{}, repository.ErrSameWallet
+	}
+	if s.rates == nil {
+		return models.ConversionQuote{}, models.ErrRatesUnavailable
+	}
+	if err := s.authorizeWallet(ctx, fromID); err != nil {
+		return models.ConversionQuote{}, err
+	}
+	from, err := s.ledger.GetWallet(ctx, fromID)
+	if err != nil {
+		return models.ConversionQuote{}, err
+	}
+	to, err := s.ledger.GetWallet(ctx, toID)
+	if err != nil {
+		return models.ConversionQuote{}, err
+	}
+	if from.Currency == to.Currency {
+		return models.ConversionQuote{}, models.ErrSameCurrency
+	}
+	if err := models.ValidateScale(amount, from.Currency); err != nil {
+		return models.ConversionQuote{}, err
+	}
+
+	rate, err := s.rates.Rate(ctx, from.Currency, to.Currency)
+	if err != nil {
+		return models.ConversionQuote{}, err
+	}
+	converted, err := rate.Convert(amount)
+	if err != nil {
+		return models.ConversionQuote{}, err
+	}
+	if !converted.IsPositive() {
+		return models.ConversionQuote{}, repository.ErrInvalidAmount
+	}
+	return s.conversions.CreateQuote(ctx, models.ConversionQuote{
+		ID:            uuid.New(),
+		FromWalletID:  fromID,
+		ToWalletID:    toID,
+		FromCurrency:  from.Currency,
+		ToCurrency:    to.Currency,
+		FromAmount:    amount,
+		ToAmount:      converted,
+		Rate:          rate.Value,
+		RateTimestamp: rate.Timestamp,
+		ExpiresAt:     time.Now().Add(s.quoteTTL),
+	})
+}
+
+func (s *WalletService) GetQuote(ctx context.Context, quoteID uuid.UUID) (models.ConversionQuote, error) {
+	if err := s.authorizeQuote(ctx, quoteID); err != nil {
+		return models.ConversionQuote{}, err
+	}
+	quote, err := s.conversions.GetQuote(ctx, quoteID)
+	if err != nil {
+		s.logConversionError("GetQuote failed", err, slog.String("quote_id", quoteID.String()))
+	}
+	return quote, err
+}
+
+// ExecuteConversion исполняет ранее выданную котировку по зафиксированному в ней курсу.
+func (s *WalletService) ExecuteConversion(
+	ctx context.Context,
+	quoteID uuid.UUID,
+	opts ...models.OperationOption,
+) (models.ConversionResult, error) {
+	if err := s.authorizeQuote(ctx, quoteID); err != nil {
+		return models.ConversionResult{}, err
+	}
+	var result models.ConversionResult
+	err := s.retry(ctx, "conversion", func() error {
+		var err error
+		result, err = s.conversions.ExecuteConversion(ctx, quoteID, opts...)
+		return err
+	})
+	if err != nil {
+		s.logConversionError("ExecuteConversion failed", err, slog.String("quote_id", quoteID.String()))
+	}
+	return result, err
+}
+
+// Convert конвертирует amount по текущему курсу: котировка создаётся и сразу исполняется.
+func (s *WalletService) Convert(
+	ctx context.Context,
+	fromID, toID uuid.UUID,
+	amount decimal.Decimal,
+	opts ...models.OperationOption,
+) (models.ConversionResult, error) {
+	if err := s.authorizeWallet(ctx, fromID); err != nil {
+		return models.ConversionResult{}, err
+	}
+	if idem := models.NewOperationOptions(opts...).Idempotency; idem != nil {
+		replayed, err := s.ledger.LookupIdempotentResponse(ctx, idem)
+		if err != nil {
+			s.logConversionError("Convert failed", err, slog.String("idempotency_key", idem.Key))
+			return models.ConversionResult{}, err
 		}
-		if isRetryableError(err) {
-			s.logger.Warn("Retrying withdraw",
-				slog.String("wallet_id", walletID.String()),
-				slog.Int("attempt", i+1),
+		if replayed {
+			return models.ConversionResult{}, nil
+		}
+	}
+	quote, err := s.QuoteConversion(ctx, fromID, toID, amount)
+	if err != nil {
+		return models.ConversionResult{Quote: quote}, err
+	}
+	return s.ExecuteConversion(ctx, quote.ID, opts...)
+}
+
+// ReverseTransaction сторнирует операцию с учётом политики сторно сервиса.
+func (s *WalletService) ReverseTransaction(
+	ctx context.Context,
+	transactionID int64,
+	amount *decimal.Decimal,
+	opts ...models.OperationOption,
+) (models.ReversalResult, error) {
+	if amount != nil && !amount.IsPositive() {
+		return models.ReversalResult{}, repository.ErrInvalidAmount
+	}
+	allowNegative := s.reversalPolicy == models.ReversalPolicyAllowNegative
+	var result models.ReversalResult
+	err := s.retry(ctx, "reversal", func() error {
+		var err error
+		result, err = s.admin.ReverseTransaction(ctx, transactionID, amount, allowNegative, opts...)
+		return err
+	})
+	if err != nil {
+		attrs := []any{slog.Int64("transaction_id", transactionID), slog.Any("err", err)}
+		switch {
+		case errors.Is(err, repository.ErrTransactionNotFound),
+			errors.Is(err, repository.ErrNotReversible),
+			errors.Is(err, repository.ErrAlreadyReversed),
+			errors.Is(err, repository.ErrReversalExceedsOriginal),
+			errors.Is(err, repository.ErrInsufficientFunds),
+			errors.Is(err, repository.ErrIdempotencyKeyUsed),
+			isCurrencyError(err),
+			isWalletStateError(err):
+			s.logger.Warn("ReverseTransaction rejected", attrs...)
+		default:
+			s.logger.Error("ReverseTransaction failed", attrs...)
+		}
+		return result, err
+	}
+	if result.Balance.IsNegative() {
+		s.logger.Warn("Reversal left wallet with negative balance",
+			slog.Int64("transaction_id", transactionID),
+			slog.String("wallet_id", result.Original.WalletID.String()),
+			slog.Any("balance", result.Balance),
+		)
+	}
+	return result, nil
+}
+
+// CreateWallet явно создаёт пустой кошелёк. Если идентификатор не указан, он генерируется.
+func (s *WalletService) CreateWallet(ctx context.Context, req models.CreateWalletRequest) (models.Wallet, error) {
+	if req.Currency != "" && !models.IsSupportedCurrency(req.Currency) {
+		return models.Wallet{}, models.ErrUnsupportedCurrency
+	}
+	if err := models.ValidateLabels(req.Labels); err != nil {
+		return models.Wallet{}, err
+	}
+	if subject, ok := owner(ctx); ok {
+		if req.OwnerID != "" && req.OwnerID != subject {
+			return models.Wallet{}, models.ErrWalletAccessDenied
+		}
+		req.OwnerID = subject
+	}
+	wallet := models.Wallet{
+		ID:       uuid.New(),
+		Currency: req.Currency,
+		OwnerID:  req.OwnerID,
+		Name:     req.Name,
+		Labels:   req.Labels,
+	}
+	if req.WalletID != nil {
+		wallet.ID = *req.WalletID
+	}
+	created, err := s.ledger.CreateWallet(ctx, wallet)
+	if err != nil {
+		if errors.Is(err, repository.ErrWalletAlreadyExist) {
+			s.logger.Warn("CreateWallet rejected: wallet already exists",
+				slog.String("wallet_id", wallet.ID.String()),
+			)
+		} else {
+			s.logger.Error("CreateWallet failed",
+				slog.String("wallet_id", wallet.ID.String()),
 				slog.Any("err", err),
 			)
-			time.Sleep(time.Duration(1<<i) * 10 * time.Microsecond)
-			lastErr = err
-			continue
 		}
+		return created, err
+	}
+	return created, nil
+}
+
+// ListWallets возвращает страницу кошельков вызывающего с курсором следующей страницы.
+func (s *WalletService) ListWallets(ctx context.Context, filter models.WalletFilter) (models.WalletPage, error) {
+	if subject, ok := owner(ctx); ok {
+		if filter.OwnerID != "" && filter.OwnerID != subject {
+			return models.WalletPage{}, models.ErrWalletAccessDenied
+		}
+		filter.OwnerID = subject
+	}
+	if filter.Limit <= 0 {
+		filter.Limit = models.DefaultWalletsLimit
+	}
+	if filter.Limit > models.MaxWalletsLimit {
+		filter.Limit = models.MaxWalletsLimit
+	}
+	limit := filter.Limit
+	// Запрашиваем на одну запись больше, чтобы понять, есть ли следующая страница
+	filter.Limit++
+
+	wallets, err := s.ledger.ListWallets(ctx, filter)
+	if err != nil {
+		s.logger.Error("ListWallets failed", slog.Any("err", err))
+		return models.WalletPage{}, err
+	}
+	page := models.WalletPage{Wallets: wallets}
+	if len(wallets) > limit {
+		page.Wallets = wallets[:limit]
+		last := page.Wallets[limit-1]
+		page.NextCursor = models.WalletCursor{CreatedAt: last.CreatedAt, ID: last.ID}.Encode()
+	}
+	if page.Wallets == nil {
+		page.Wallets = []models.Wallet{}
+	}
+	return page, nil
+}
 
+// UpdateWallet меняет владельца, название или метки кошелька.
+func (s *WalletService) UpdateWallet(
+	ctx context.Context,
+	walletID uuid.UUID,
+	update models.UpdateWalletRequest,
+) (models.Wallet, error) {
+	if update.Labels != nil {
+		if err := models.ValidateLabels(*update.Labels); err != nil {
+			return models.Wallet{}, err
+		}
+	}
+	if subject, ok := owner(ctx); ok && update.OwnerID != nil && *update.OwnerID != subject {
+		return models.Wallet{}, models.ErrWalletAccessDenied
+	}
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return models.Wallet{}, err
+	}
+	wallet, err := s.ledger.UpdateWallet(ctx, walletID, update)
+	if err != nil {
 		if errors.Is(err, repository.ErrWalletNotFound) {
-			s.logger.Error("Withdraw failed: wallet not found",
+			s.logger.Warn("UpdateWallet: wallet not found", slog.String("wallet_id", walletID.String()))
+		} else {
+			s.logger.Error("UpdateWallet failed",
 				slog.String("wallet_id", walletID.String()),
-				slog.Any("amount", amount),
+				slog.Any("err", err),
 			)
-			return balance, repository.ErrWalletNotFound
 		}
-		if errors.Is(err, repository.ErrInsufficientFunds) {
-			s.logger.Warn("Withdraw failed: insufficient funds",
-				slog.String("wallet_id", walletID.String()),
-				slog.Any("amount", amount),
-				slog.Any("balance", balance),
-			)
-			return balance, repository.ErrInsufficientFunds
+		return wallet, err
+	}
+	return wallet, nil
+}
+
+// SetWalletStatus замораживает, размораживает или закрывает кошелёк.
+func (s *WalletService) SetWalletStatus(
+	ctx context.Context,
+	walletID uuid.UUID,
+	status, reason string,
+) (models.Wallet, error) {
+	var wallet models.Wallet
+	err := s.retry(ctx, "set wallet status", func() error {
+		var err error
+		wallet, err = s.admin.SetWalletStatus(ctx, walletID, status, reason)
+		return err
+	})
+	if err != nil {
+		attrs := []any{
+			slog.String("wallet_id", walletID.String()),
+			slog.String("status", status),
+			slog.Any("err", err),
+		}
+		if errors.Is(err, repository.ErrWalletNotFound) || isWalletStateError(err) {
+			s.logger.Warn("SetWalletStatus rejected", attrs...)
+		} else {
+			s.logger.Error("SetWalletStatus failed", attrs...)
 		}
-		s.logger.Error("Withdraw failed: unknown error",
+		return wallet, err
+	}
+	s.logger.Info("Wallet status changed",
+		slog.String("wallet_id", walletID.String()),
+		slog.String("status", wallet.Status),
+		slog.String("reason", reason),
+	)
+	return wallet, nil
+}
+
+// SetOverdraftLimit меняет лимит овердрафта кошелька. Изменение записывается в журнал
+// вместе с причиной.
+func (s *WalletService) SetOverdraftLimit(
+	ctx context.Context,
+	walletID uuid.UUID,
+	limit decimal.Decimal,
+	reason string,
+) (models.Wallet, error) {
+	if limit.IsNegative() {
+		return models.Wallet{}, repository.ErrInvalidAmount
+	}
+	var wallet models.Wallet
+	err := s.retry(ctx, "set overdraft limit", func() error {
+		var err error
+		wallet, err = s.admin.SetOverdraftLimit(ctx, walletID, limit, reason)
+		return err
+	})
+	if err != nil {
+		attrs := []any{
+			slog.String("wallet_id", walletID.String()),
+			slog.Any("limit", limit),
+			slog.Any("err", err),
+		}
+		if errors.Is(err, repository.ErrWalletNotFound) || isWalletStateError(err) || isCurrencyError(err) {
+			s.logger.Warn("SetOverdraftLimit rejected", attrs...)
+		} else {
+			s.logger.Error("SetOverdraftLimit failed", attrs...)
+		}
+		return wallet, err
+	}
+	s.logger.Info("Overdraft limit changed",
+		slog.String("wallet_id", walletID.String()),
+		slog.Any("limit", wallet.OverdraftLimit),
+		slog.String("reason", reason),
+	)
+	return wallet, nil
+}
+
+func (s *WalletService) GetOverdraftLimitChanges(
+	ctx context.Context,
+	walletID uuid.UUID,
+) ([]models.OverdraftLimitChange, error) {
+	changes, err := s.admin.GetOverdraftLimitChanges(ctx, walletID)
+	if err != nil && !errors.Is(err, repository.ErrWalletNotFound) {
+		s.logger.Error("GetOverdraftLimitChanges failed",
 			slog.String("wallet_id", walletID.String()),
-			slog.Any("amount", amount),
 			slog.Any("err", err),
 		)
-		return balance, err
 	}
-	s.logger.Error("Withdraw failed after retries",
+	return changes, err
+}
+
+// SetLimitTier задаёт лимиты тарифа для кошельков в валюте currency.
+func (s *WalletService) SetLimitTier(ctx context.Context, tier, currency string, limit models.SpendingLimit) error {
+	if tier == "" {
+		return fmt.Errorf("%w: tier is required", models.ErrInvalidLimit)
+	}
+	if !models.IsSupportedCurrency(currency) {
+		return models.ErrUnsupportedCurrency
+	}
+	if err := validateLimit(limit, currency); err != nil {
+		return err
+	}
+	if err := s.admin.SetLimitTier(ctx, tier, currency, limit); err != nil {
+		s.logger.Error("SetLimitTier failed",
+			slog.String("tier", tier),
+			slog.String("currency", currency),
+			slog.Any("err", err),
+		)
+		return err
+	}
+	s.logger.Info("Limit tier changed",
+		slog.String("tier", tier),
+		slog.String("currency", currency),
+		slog.String("operation", limit.Operation),
+	)
+	return nil
+}
+
+// SetWalletLimit задаёт лимиты кошелька, переопределяющие лимиты его тарифа.
+func (s *WalletService) SetWalletLimit(ctx context.Context, walletID uuid.UUID, limit models.SpendingLimit) error {
+	wallet, err := s.ledger.GetWallet(ctx, walletID)
+	if err != nil {
+		return err
+	}
+	if err := validateLimit(limit, wallet.Currency); err != nil {
+		return err
+	}
+	if err := s.admin.SetWalletLimit(ctx, walletID, limit); err != nil {
+		if !errors.Is(err, repository.ErrWalletNotFound) {
+			s.logger.Error("SetWalletLimit failed",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
+			)
+		}
+		return err
+	}
+	s.logger.Info("Wallet limit changed",
 		slog.String("wallet_id", walletID.String()),
-		slog.Any("amount", amount),
-		slog.Any("err", lastErr),
+		slog.String("operation", limit.Operation),
 	)
-	return decimal.Zero, lastErr
+	return nil
 }
 
-func (s *WalletService) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
-	balance, err := s.repo.GetBalance(ctx, walletID)
+func (s *WalletService) SetWalletTier(ctx context.Context, walletID uuid.UUID, tier string) (models.Wallet, error) {
+	wallet, err := s.admin.SetWalletTier(ctx, walletID, tier)
 	if err != nil {
-		if errors.Is(err, repository.ErrWalletNotFound) {
-			s.logger.Warn("GetBalance: wallet not found",
+		if errors.Is(err, repository.ErrWalletNotFound) || errors.Is(err, repository.ErrLimitTierNotFound) {
+			s.logger.Warn("SetWalletTier rejected",
 				slog.String("wallet_id", walletID.String()),
+				slog.String("tier", tier),
+				slog.Any("err", err),
+			)
+		} else {
+			s.logger.Error("SetWalletTier failed",
+				slog.String("wallet_id", walletID.String()),
+				slog.Any("err", err),
 			)
-			return balance, repository.ErrWalletNotFound
 		}
-		s.logger.Error("GetBalance failed",
+		return wallet, err
+	}
+	s.logger.Info("Wallet limit tier changed",
+		slog.String("wallet_id", walletID.String()),
+		slog.String("tier", tier),
+	)
+	return wallet, nil
+}
+
+func (s *WalletService) GetWalletLimits(ctx context.Context, walletID uuid.UUID) ([]models.SpendingLimit, error) {
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return nil, err
+	}
+	limits, err := s.ledger.GetWalletLimits(ctx, walletID)
+	if err != nil && !errors.Is(err, repository.ErrWalletNotFound) {
+		s.logger.Error("GetWalletLimits failed",
 			slog.String("wallet_id", walletID.String()),
 			slog.Any("err", err),
 		)
-		return balance, err
 	}
-	// Не логируем успешное получение баланса
-	return balance, nil
+	return limits, err
+}
+
+// QuoteFee считает комиссию за операцию без движения денег. Валюта берётся из кошелька,
+// если он указан.
+func (s *WalletService) QuoteFee(ctx context.Context, req models.FeeQuoteRequest) (models.FeeQuote, error) {
+	quote := models.FeeQuote{OperationType: req.OperationType, Amount: req.Amount, Currency: req.Currency}
+	if !req.Amount.IsPositive() {
+		return quote, repository.ErrInvalidAmount
+	}
+	if req.WalletID != nil {
+		if err := s.authorizeWallet(ctx, *req.WalletID); err != nil {
+			return quote, err
+		}
+		wallet, err := s.ledger.GetWallet(ctx, *req.WalletID)
+		if err != nil {
+			return quote, err
+		}
+		if req.Currency != "" && req.Currency != wallet.Currency {
+			return quote, repository.ErrCurrencyMismatch
+		}
+		quote.Currency = wallet.Currency
+	}
+	if quote.Currency == "" {
+		return quote, models.ErrUnsupportedCurrency
+	}
+	if err := models.ValidateScale(req.Amount, quote.Currency); err != nil {
+		return quote, err
+	}
+	fee, err := s.ledger.QuoteFee(ctx, req.OperationType, quote.Currency, req.Amount)
+	if err != nil {
+		s.logger.Error("QuoteFee failed",
+			slog.String("operation", req.OperationType),
+			slog.String("currency", quote.Currency),
+			slog.Any("err", err),
+		)
+		return quote, err
+	}
+	quote.Fee = fee
+	quote.BalanceChange = req.Amount.Sub(fee)
+	if req.OperationType == "WITHDRAW" {
+		quote.BalanceChange = req.Amount.Add(fee).Neg()
+	}
+	return quote, nil
+}
+
+func (s *WalletService) SetFeeSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error) {
+	if err := schedule.Validate(); err != nil {
+		return schedule, err
+	}
+	saved, err := s.admin.SetFeeSchedule(ctx, schedule)
+	if err != nil {
+		s.logger.Error("SetFeeSchedule failed",
+			slog.String("operation", schedule.Operation),
+			slog.String("currency", schedule.Currency),
+			slog.Any("err", err),
+		)
+		return saved, err
+	}
+	s.logger.Info("Fee schedule changed",
+		slog.String("operation", schedule.Operation),
+		slog.String("currency", schedule.Currency),
+	)
+	return saved, nil
+}
+
+func (s *WalletService) ListFeeSchedules(ctx context.Context) ([]models.FeeSchedule, error) {
+	schedules, err := s.admin.ListFeeSchedules(ctx)
+	if err != nil {
+		s.logger.Error("ListFeeSchedules failed", slog.Any("err", err))
+	}
+	return schedules, err
+}
+
+// CreateScheduledOperation планирует разовую (RunAt) или регулярную (Cron) операцию.
+// Исполняет её scheduler.Scheduler.
+func (s *WalletService) CreateScheduledOperation(
+	ctx context.Context,
+	req models.ScheduleRequest,
+) (models.ScheduledOperation, error) {
+	if !req.Amount.IsPositive() {
+		return models.ScheduledOperation{}, repository.ErrInvalidAmount
+	}
+	if (req.Operation == "TRANSFER") != (req.ToWalletID != nil) {
+		return models.ScheduledOperation{}, fmt.Errorf("%w: toWalletId is required for TRANSFER only", models.ErrInvalidSchedule)
+	}
+	if req.ToWalletID != nil && *req.ToWalletID == req.WalletID {
+		return models.ScheduledOperation{}, repository.ErrSameWallet
+	}
+	if req.Currency != "" && !models.IsSupportedCurrency(req.Currency) {
+		return models.ScheduledOperation{}, models.ErrUnsupportedCurrency
+	}
+
+	now := time.Now().UTC()
+	op := models.ScheduledOperation{
+		ID:           uuid.New(),
+		Operation:    req.Operation,
+		WalletID:     req.WalletID,
+		ToWalletID:   req.ToWalletID,
+		Amount:       req.Amount,
+		OccurrenceAt: now,
+		MaxAttempts:  models.DefaultScheduleMaxAttempts,
+	}
+	if req.MaxAttempts > 0 {
+		op.MaxAttempts = req.MaxAttempts
+	}
+	if req.Currency != "" {
+		op.Currency = &req.Currency
+	}
+	switch {
+	case req.Cron != "":
+		cron, err := scheduler.ParseCron(req.Cron)
+		if err != nil {
+			return models.ScheduledOperation{}, err
+		}
+		start := now
+		if req.RunAt != nil && req.RunAt.After(now) {
+			// Next ищет строго после момента, а RunAt сам может быть срабатыванием
+			start = req.RunAt.Add(-time.Nanosecond)
+		}
+		op.OccurrenceAt = cron.Next(start)
+		if op.OccurrenceAt.IsZero() {
+			return models.ScheduledOperation{}, fmt.Errorf("%w: cron never fires", models.ErrInvalidSchedule)
+		}
+		op.Cron = &req.Cron
+	case req.RunAt != nil:
+		op.OccurrenceAt = req.RunAt.UTC()
+	default:
+		return models.ScheduledOperation{}, fmt.Errorf("%w: runAt or cron is required", models.ErrInvalidSchedule)
+	}
+
+	if err := s.authorizeWallet(ctx, req.WalletID); err != nil {
+		return models.ScheduledOperation{}, err
+	}
+	wallet, err := s.ledger.GetWallet(ctx, req.WalletID)
+	if err != nil {
+		return models.ScheduledOperation{}, err
+	}
+	if req.Currency != "" && req.Currency != wallet.Currency {
+		return models.ScheduledOperation{}, repository.ErrCurrencyMismatch
+	}
+	if err := models.ValidateScale(req.Amount, wallet.Currency); err != nil {
+		return models.ScheduledOperation{}, err
+	}
+
+	created, err := s.schedules.CreateScheduledOperation(ctx, op)
+	if err != nil {
+		s.logger.Error("CreateScheduledOperation failed",
+			slog.String("wallet_id", req.WalletID.String()),
+			slog.Any("err", err),
+		)
+		return created, err
+	}
+	s.logger.Info("Scheduled operation created",
+		slog.String("scheduled_operation_id", created.ID.String()),
+		slog.String("operation", created.Operation),
+		slog.Time("occurrence_at", created.OccurrenceAt),
+	)
+	return created, nil
+}
+
+func (s *WalletService) GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	if err := s.authorizeSchedule(ctx, id); err != nil {
+		return models.ScheduledOperation{}, err
+	}
+	return s.schedules.GetScheduledOperation(ctx, id)
+}
+
+func (s *WalletService) ListScheduledOperations(ctx context.Context, walletID uuid.UUID) ([]models.ScheduledOperation, error) {
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return nil, err
+	}
+	return s.schedules.ListScheduledOperations(ctx, walletID)
+}
+
+func (s *WalletService) CancelScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	if err := s.authorizeSchedule(ctx, id); err != nil {
+		return models.ScheduledOperation{}, err
+	}
+	op, err := s.schedules.CancelScheduledOperation(ctx, id)
+	if err != nil {
+		s.logger.Warn("CancelScheduledOperation failed",
+			slog.String("scheduled_operation_id", id.String()),
+			slog.Any("err", err),
+		)
+		return op, err
+	}
+	s.logger.Info("Scheduled operation cancelled", slog.String("scheduled_operation_id", id.String()))
+	return op, nil
+}
+
+func (s *WalletService) GetScheduledRuns(ctx context.Context, id uuid.UUID) ([]models.ScheduledRun, error) {
+	if err := s.authorizeSchedule(ctx, id); err != nil {
+		return nil, err
+	}
+	return s.schedules.GetScheduledRuns(ctx, id)
+}
+
+// CreateWebhook регистрирует подписку владельца кошельков на события и генерирует секрет
+// для подписи доставок. Секрет возвращается только здесь.
+func (s *WalletService) CreateWebhook(ctx context.Context, req models.WebhookRequest) (models.WebhookSubscription, error) {
+	if err := webhooks.CheckURL(req.URL); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	ownerID, err := webhookOwner(ctx, req.OwnerID)
+	if err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	if req.WalletID != nil {
+		if err := s.authorizeWallet(ctx, *req.WalletID); err != nil {
+			return models.WebhookSubscription{}, err
+		}
+	}
+	sub := models.WebhookSubscription{
+		ID:       uuid.New(),
+		OwnerID:  ownerID,
+		WalletID: req.WalletID,
+		URL:      req.URL,
+	}
+	for _, event := range req.Events {
+		if !sub.Subscribed(event) {
+			sub.Events = append(sub.Events, event)
+		}
+	}
+	if sub.Subscribed(models.WebhookLowBalance) != (req.LowBalanceThreshold != nil) {
+		return models.WebhookSubscription{}, fmt.Errorf("%w: lowBalanceThreshold is required for low_balance only", models.ErrInvalidWebhook)
+	}
+	sub.LowBalanceThreshold = req.LowBalanceThreshold
+	secret := make([]byte, 32)
+	if _, err := rand.Read(secret); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	sub.Secret = "whsec_" + hex.EncodeToString(secret)
+
+	created, err := s.webhooks.CreateWebhook(ctx, sub)
+	if err != nil {
+		s.logger.Error("CreateWebhook failed", slog.String("owner_id", ownerID), slog.Any("err", err))
+		return created, err
+	}
+	s.logger.Info("Webhook created",
+		slog.String("webhook_id", created.ID.String()),
+		slog.String("owner_id", created.OwnerID),
+		slog.Any("events", created.Events),
+	)
+	return created, nil
+}
+
+func (s *WalletService) GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	if err := s.authorizeWebhook(ctx, id); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	return s.webhooks.GetWebhook(ctx, id)
+}
+
+// ListWebhooks возвращает подписки владельца ownerID; пустой ownerID — подписки вызывающего.
+func (s *WalletService) ListWebhooks(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	ownerID, err := webhookOwner(ctx, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	return s.webhooks.ListWebhooks(ctx, ownerID)
+}
+
+func (s *WalletService) DeleteWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	if err := s.authorizeWebhook(ctx, id); err != nil {
+		return models.WebhookSubscription{}, err
+	}
+	sub, err := s.webhooks.DeleteWebhook(ctx, id)
+	if err != nil {
+		s.logger.Warn("DeleteWebhook failed", slog.String("webhook_id", id.String()), slog.Any("err", err))
+		return sub, err
+	}
+	s.logger.Info("Webhook deleted", slog.String("webhook_id", id.String()))
+	return sub, nil
+}
+
+// ListWebhookDeliveries возвращает журнал доставок подписки; status фильтрует по статусу доставки.
+func (s *WalletService) ListWebhookDeliveries(
+	ctx context.Context,
+	webhookID uuid.UUID,
+	status string,
+	limit int,
+) ([]models.WebhookDelivery, error) {
+	switch status {
+	case "", models.DeliveryPending, models.DeliveryDelivered, models.DeliveryDead:
+	default:
+		return nil, fmt.Errorf("%w: unknown delivery status %q", models.ErrInvalidWebhook, status)
+	}
+	if limit <= 0 {
+		limit = models.DefaultWebhookDeliveriesLimit
+	}
+	limit = min(limit, models.MaxWebhookDeliveriesLimit)
+	if err := s.authorizeWebhook(ctx, webhookID); err != nil {
+		return nil, err
+	}
+	return s.webhooks.ListWebhookDeliveries(ctx, webhookID, status, limit)
+}
+
+// RetryWebhookDelivery повторно ставит в очередь доставку в статусе DEAD.
+func (s *WalletService) RetryWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (models.WebhookDelivery, error) {
+	if err := s.authorizeWebhook(ctx, webhookID); err != nil {
+		return models.WebhookDelivery{}, err
+	}
+	delivery, err := s.webhooks.RetryWebhookDelivery(ctx, webhookID, deliveryID)
+	if err != nil {
+		s.logger.Warn("RetryWebhookDelivery failed",
+			slog.String("webhook_id", webhookID.String()),
+			slog.Int64("delivery_id", deliveryID),
+			slog.Any("err", err),
+		)
+		return delivery, err
+	}
+	s.logger.Info("Webhook delivery requeued",
+		slog.String("webhook_id", webhookID.String()),
+		slog.Int64("delivery_id", deliveryID),
+	)
+	return delivery, nil
+}
+
+// GetEventStreamSnapshot возвращает баланс, с которого начинается поток событий кошелька.
+func (s *WalletService) GetEventStreamSnapshot(ctx context.Context, walletID uuid.UUID) (models.StreamSnapshot, error) {
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return models.StreamSnapshot{}, err
+	}
+	return s.events.GetStreamSnapshot(ctx, walletID)
+}
+
+// GetWalletEvents возвращает до limit событий кошелька после события afterID.
+func (s *WalletService) GetWalletEvents(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.Event, error) {
+	if err := s.authorizeWallet(ctx, walletID); err != nil {
+		return nil, err
+	}
+	events, err := s.events.ListWalletEvents(ctx, walletID, afterID, limit)
+	if err != nil && ctx.Err() == nil {
+		s.logger.Error("GetWalletEvents failed",
+			slog.String("wallet_id", walletID.String()),
+			slog.Int64("after_id", afterID),
+			slog.Any("err", err),
+		)
+	}
+	return events, err
+}
+
+// validateLimit проверяет лимит и точность его сумм в валюте currency.
+func validateLimit(limit models.SpendingLimit, currency string) error {
+	if err := limit.Validate(); err != nil {
+		return err
+	}
+	for _, amount := range []*decimal.Decimal{limit.MaxSingleAmount, limit.DailyCap, limit.WeeklyCap, limit.MonthlyCap} {
+		if amount == nil {
+			continue
+		}
+		if err := models.ValidateScale(*amount, currency); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logOperationError пишет отказ пополнения, списания или перевода: ожидаемые отказы —
+// предупреждением, остальные ошибки — ошибкой.
+func (s *WalletService) logOperationError(msg string, err error, attrs ...any) {
+	attrs = append(attrs, slog.Any("err", err))
+	switch {
+	case errors.Is(err, repository.ErrWalletNotFound),
+		errors.Is(err, repository.ErrInsufficientFunds),
+		errors.Is(err, repository.ErrIdempotencyKeyUsed),
+		errors.Is(err, repository.ErrLimitExceeded),
+		errors.Is(err, models.ErrWalletAccessDenied),
+		isCurrencyError(err),
+		isWalletStateError(err):
+		s.logger.Warn(msg, attrs...)
+	default:
+		s.logger.Error(msg, attrs...)
+	}
+}
+
+func (s *WalletService) logConversionError(msg string, err error, attrs ...any) {
+	attrs = append(attrs, slog.Any("err", err))
+	switch {
+	case errors.Is(err, repository.ErrWalletNotFound),
+		errors.Is(err, repository.ErrInsufficientFunds),
+		errors.Is(err, repository.ErrInvalidAmount),
+		errors.Is(err, repository.ErrSameWallet),
+		errors.Is(err, repository.ErrIdempotencyKeyUsed),
+		errors.Is(err, repository.ErrQuoteNotFound),
+		errors.Is(err, repository.ErrQuoteExpired),
+		errors.Is(err, repository.ErrQuoteExecuted),
+		errors.Is(err, models.ErrSameCurrency),
+		errors.Is(err, models.ErrRateNotFound),
+		errors.Is(err, models.ErrWalletAccessDenied),
+		isCurrencyError(err),
+		isWalletStateError(err):
+		s.logger.Warn(msg, attrs...)
+	default:
+		s.logger.Error(msg, attrs...)
+	}
+}
+
+func (s *WalletService) logHoldError(msg string, err error, attrs ...any) {
+	attrs = append(attrs, slog.Any("err", err))
+	switch {
+	case errors.Is(err, repository.ErrWalletNotFound),
+		errors.Is(err, repository.ErrInsufficientFunds),
+		errors.Is(err, repository.ErrInvalidAmount),
+		errors.Is(err, repository.ErrHoldNotFound),
+		errors.Is(err, repository.ErrHoldNotActive),
+		errors.Is(err, repository.ErrHoldExpired),
+		errors.Is(err, repository.ErrCaptureExceedHold),
+		errors.Is(err, models.ErrWalletAccessDenied),
+		isCurrencyError(err),
+		isWalletStateError(err):
+		s.logger.Warn(msg, attrs...)
+	default:
+		s.logger.Error(msg, attrs...)
+	}
+}
+
+// isWalletStateError сообщает, что операция отклонена из-за статуса кошелька.
+func isWalletStateError(err error) bool {
+	return errors.Is(err, repository.ErrWalletFrozen) ||
+		errors.Is(err, repository.ErrWalletClosed) ||
+		errors.Is(err, repository.ErrWalletNotEmpty)
+}
+
+// isCurrencyError сообщает, что операция отклонена из-за валюты или точности суммы.
+func isCurrencyError(err error) bool {
+	return errors.Is(err, repository.ErrCurrencyMismatch) ||
+		errors.Is(err, models.ErrUnsupportedCurrency) ||
+		errors.Is(err, models.ErrInvalidAmountScale)
+}
+
+// retry выполняет op, повторяя её при ошибках сериализации и взаимоблокировки.
+func (s *WalletService) retry(ctx context.Context, name string, op func() error) error {
+	var err error
+	for i := 0; i < s.maxRetries; i++ {
+		err = op()
+		if err == nil || !isRetryableError(err) {
+			return err
+		}
+		s.logger.Warn("Retrying "+name,
+			slog.Int("attempt", i+1),
+			slog.Any("err", err),
+		)
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(time.Duration(1<<i) * 10 * time.Microsecond):
+		}
+	}
+	return err
 }
 
 func isRetryableError(err error) bool {
@@ -7,6 +7,7 @@ import (
 	"sync"
 	"testing"
 
+	"test_wallet/internal/models"
 	"test_wallet/internal/repository"
 	"test_wallet/internal/service"
 	"test_wallet/internal/testutil"
@@ -63,7 +64,7 @@ func TestService_ConcurrentDeposits(t *testing.T) {
 	repo := repository.NewWalletPGRepository(pool, testLogger)
 	svc := service.NewWalletService(repo, testLogger)
 	walletID := uuid.New()
-	_ = repo.CreateWallet(context.Background(), walletID)
+	_, _ = repo.CreateWallet(context.Background(), models.Wallet{ID: walletID})
 
 	var wg sync.WaitGroup
 	for i := 0; i < 1000; i++ {
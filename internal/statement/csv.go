@@ -0,0 +1,46 @@
+package statement
+
+import (
+	"encoding/csv"
+	"io"
+	"strconv"
+	"test_wallet/internal/models"
+	"time"
+)
+
+// csvWriter выводит выписку таблицей с колонкой record: первая строка — остаток на начало
+// периода (opening), последняя — на конец (closing), между ними — операции (transaction).
+type csvWriter struct {
+	w *csv.Writer
+}
+
+func NewCSVWriter(w io.Writer) models.StatementWriter {
+	return &csvWriter{w: csv.NewWriter(w)}
+}
+
+func (c *csvWriter) WriteHeader(s models.Statement) error {
+	if err := c.w.Write([]string{"record", "date", "id", "type", "amount", "balance", "currency"}); err != nil {
+		return err
+	}
+	return c.w.Write([]string{"opening", s.From.UTC().Format(time.RFC3339), "", "", "", s.OpeningBalance.String(), s.Currency})
+}
+
+func (c *csvWriter) WriteLine(line models.StatementLine) error {
+	return c.w.Write([]string{
+		"transaction",
+		line.CreatedAt.UTC().Format(time.RFC3339Nano),
+		strconv.FormatInt(line.ID, 10),
+		line.Type,
+		line.Amount.String(),
+		line.RunningBalance.String(),
+		"",
+	})
+}
+
+func (c *csvWriter) WriteFooter(s models.Statement) error {
+	if err := c.w.Write([]string{"closing", s.To.UTC().Format(time.RFC3339), "", "", "", s.ClosingBalance.String(), s.Currency}); err != nil {
+		return err
+	}
+	c.w.Flush()
+	return c.w.Error()
+}
@@ -0,0 +1,53 @@
+package statement
+
+import (
+	"bufio"
+	"encoding/json"
+	"io"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+// ndjsonWriter выводит по JSON-объекту на строку; поле record различает остаток на начало
+// (opening), операции (transaction) и остаток на конец (closing).
+type ndjsonWriter struct {
+	buf *bufio.Writer
+	enc *json.Encoder
+}
+
+func NewNDJSONWriter(w io.Writer) models.StatementWriter {
+	buf := bufio.NewWriter(w)
+	return &ndjsonWriter{buf: buf, enc: json.NewEncoder(buf)}
+}
+
+type ndjsonBalance struct {
+	Record   string          `json:"record"`
+	WalletID uuid.UUID       `json:"walletId"`
+	Currency string          `json:"currency"`
+	At       time.Time       `json:"at"`
+	Balance  decimal.Decimal `json:"balance"`
+}
+
+type ndjsonLine struct {
+	Record string `json:"record"`
+	models.StatementLine
+}
+
+func (n *ndjsonWriter) WriteHeader(s models.Statement) error {
+	return n.enc.Encode(ndjsonBalance{Record: "opening", WalletID: s.WalletID, Currency: s.Currency, At: s.From, Balance: s.OpeningBalance})
+}
+
+func (n *ndjsonWriter) WriteLine(line models.StatementLine) error {
+	return n.enc.Encode(ndjsonLine{Record: "transaction", StatementLine: line})
+}
+
+func (n *ndjsonWriter) WriteFooter(s models.Statement) error {
+	err := n.enc.Encode(ndjsonBalance{Record: "closing", WalletID: s.WalletID, Currency: s.Currency, At: s.To, Balance: s.ClosingBalance})
+	if err != nil {
+		return err
+	}
+	return n.buf.Flush()
+}
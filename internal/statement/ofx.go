@@ -0,0 +1,72 @@
+package statement
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"strconv"
+	"test_wallet/internal/models"
+	"time"
+)
+
+// ofxWriter выводит выписку в OFX 2.2 (XML).
+type ofxWriter struct {
+	buf *bufio.Writer
+}
+
+func NewOFXWriter(w io.Writer) models.StatementWriter {
+	return &ofxWriter{buf: bufio.NewWriter(w)}
+}
+
+func ofxTime(t time.Time) string {
+	return t.UTC().Format("20060102150405.000") + "[0:GMT]"
+}
+
+// ofxTransactionType сопоставляет тип операции журнала с TRNTYPE.
+func ofxTransactionType(line models.StatementLine) string {
+	switch line.Type {
+	case "DEPOSIT":
+		return "DEP"
+	case "TRANSFER_IN", "TRANSFER_OUT":
+		return "XFER"
+	case "FEE":
+		return "FEE"
+	}
+	if line.Amount.IsNegative() {
+		return "DEBIT"
+	}
+	return "CREDIT"
+}
+
+func (o *ofxWriter) WriteHeader(s models.Statement) error {
+	_, err := fmt.Fprintf(o.buf, `<?xml version="1.0" encoding="UTF-8" standalone="no"?>
+<?OFX OFXHEADER="200" VERSION="220" SECURITY="NONE" OLDFILEUID="NONE" NEWFILEUID="NONE"?>
+<OFX>
+<SIGNONMSGSRSV1><SONRS><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS><DTSERVER>%s</DTSERVER><LANGUAGE>RUS</LANGUAGE></SONRS></SIGNONMSGSRSV1>
+<BANKMSGSRSV1><STMTTRNRS><TRNUID>%s</TRNUID><STATUS><CODE>0</CODE><SEVERITY>INFO</SEVERITY></STATUS>
+<STMTRS><CURDEF>%s</CURDEF>
+<BANKACCTFROM><BANKID>test_wallet</BANKID><ACCTID>%s</ACCTID><ACCTTYPE>CHECKING</ACCTTYPE></BANKACCTFROM>
+<BANKTRANLIST><DTSTART>%s</DTSTART><DTEND>%s</DTEND>
+`, ofxTime(time.Now()), s.WalletID, s.Currency, s.WalletID, ofxTime(s.From), ofxTime(s.To))
+	return err
+}
+
+func (o *ofxWriter) WriteLine(line models.StatementLine) error {
+	_, err := fmt.Fprintf(o.buf,
+		"<STMTTRN><TRNTYPE>%s</TRNTYPE><DTPOSTED>%s</DTPOSTED><TRNAMT>%s</TRNAMT><FITID>%s</FITID><NAME>%s</NAME></STMTTRN>\n",
+		ofxTransactionType(line), ofxTime(line.CreatedAt), line.Amount, strconv.FormatInt(line.ID, 10), line.Type)
+	return err
+}
+
+func (o *ofxWriter) WriteFooter(s models.Statement) error {
+	_, err := fmt.Fprintf(o.buf, `</BANKTRANLIST>
+<LEDGERBAL><BALAMT>%s</BALAMT><DTASOF>%s</DTASOF></LEDGERBAL>
+<BALLIST><BAL><NAME>OPENING</NAME><DESC>Opening balance</DESC><BALTYPE>DOLLAR</BALTYPE><VALUE>%s</VALUE><DTASOF>%s</DTASOF></BAL></BALLIST>
+</STMTRS></STMTTRNRS></BANKMSGSRSV1>
+</OFX>
+`, s.ClosingBalance, ofxTime(s.To), s.OpeningBalance, ofxTime(s.From))
+	if err != nil {
+		return err
+	}
+	return o.buf.Flush()
+}
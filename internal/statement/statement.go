@@ -0,0 +1,34 @@
+// Package statement выводит выписки по кошельку в форматах CSV, NDJSON и OFX.
+package statement
+
+import (
+	"fmt"
+	"io"
+	"test_wallet/internal/models"
+)
+
+// Format описывает формат выписки для HTTP-ответа.
+type Format struct {
+	ContentType string
+	Extension   string
+	newWriter   func(w io.Writer) models.StatementWriter
+}
+
+var formats = map[string]Format{
+	models.StatementCSV:    {ContentType: "text/csv; charset=utf-8", Extension: "csv", newWriter: NewCSVWriter},
+	models.StatementNDJSON: {ContentType: "application/x-ndjson", Extension: "ndjson", newWriter: NewNDJSONWriter},
+	models.StatementOFX:    {ContentType: "application/x-ofx", Extension: "ofx", newWriter: NewOFXWriter},
+}
+
+// Lookup возвращает формат по имени: csv, ndjson или ofx.
+func Lookup(name string) (Format, error) {
+	f, ok := formats[name]
+	if !ok {
+		return Format{}, fmt.Errorf("format must be one of %s, %s, %s", models.StatementCSV, models.StatementNDJSON, models.StatementOFX)
+	}
+	return f, nil
+}
+
+func (f Format) NewWriter(w io.Writer) models.StatementWriter {
+	return f.newWriter(w)
+}
@@ -0,0 +1,128 @@
+package stream
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+// Listener — источник уведомлений о новых событиях кошельков (LISTEN/NOTIFY PostgreSQL).
+// ListenWalletEvents блокируется до отмены ctx или потери соединения.
+type Listener interface {
+	ListenWalletEvents(ctx context.Context, onListen func(), notify func(walletID uuid.UUID)) error
+}
+
+const (
+	// reconnectDelay — пауза перед повторной подпиской после потери соединения.
+	reconnectDelay = time.Second
+	maxReconnect   = 30 * time.Second
+)
+
+// Hub держит одну подписку LISTEN на реплику и будит SSE-подписчиков кошелька.
+type Hub struct {
+	listener Listener
+	logger   *slog.Logger
+
+	mu     sync.Mutex
+	subs   map[uuid.UUID]map[chan struct{}]struct{}
+	closed bool
+}
+
+func NewHub(listener Listener, logger *slog.Logger) *Hub {
+	return &Hub{
+		listener: listener,
+		logger:   logger,
+		subs:     make(map[uuid.UUID]map[chan struct{}]struct{}),
+	}
+}
+
+// Subscribe возвращает канал сигналов о новых событиях кошелька walletID и функцию отписки.
+// Канал закрывается, когда Run завершается, — по нему потоки узнают об остановке сервера.
+func (h *Hub) Subscribe(walletID uuid.UUID) (<-chan struct{}, func()) {
+	ch := make(chan struct{}, 1)
+	h.mu.Lock()
+	if h.closed {
+		h.mu.Unlock()
+		close(ch)
+		return ch, func() {}
+	}
+	if h.subs[walletID] == nil {
+		h.subs[walletID] = make(map[chan struct{}]struct{})
+	}
+	h.subs[walletID][ch] = struct{}{}
+	h.mu.Unlock()
+	return ch, func() {
+		h.mu.Lock()
+		defer h.mu.Unlock()
+		delete(h.subs[walletID], ch)
+		if len(h.subs[walletID]) == 0 {
+			delete(h.subs, walletID)
+		}
+	}
+}
+
+// Run слушает уведомления до отмены ctx, переподписываясь после потери соединения.
+func (h *Hub) Run(ctx context.Context) {
+	defer h.close()
+	delay := reconnectDelay
+	for {
+		err := h.listener.ListenWalletEvents(ctx, func() {
+			delay = reconnectDelay
+			h.wakeAll()
+		}, h.wake)
+		if ctx.Err() != nil {
+			return
+		}
+		h.logger.Error("Wallet events listener failed, reconnecting",
+			slog.Duration("delay", delay),
+			slog.Any("err", err),
+		)
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(delay):
+		}
+		delay = min(delay*2, maxReconnect)
+	}
+}
+
+func (h *Hub) close() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.closed = true
+	for _, subs := range h.subs {
+		for ch := range subs {
+			close(ch)
+		}
+	}
+	clear(h.subs)
+}
+
+func (h *Hub) wake(walletID uuid.UUID) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for ch := range h.subs[walletID] {
+		signal(ch)
+	}
+}
+
+func (h *Hub) wakeAll() {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, subs := range h.subs {
+		for ch := range subs {
+			signal(ch)
+		}
+	}
+}
+
+// signal не блокируется: если подписчик ещё не забрал прошлый сигнал, новый с ним склеивается.
+func signal(ch chan struct{}) {
+	select {
+	case ch <- struct{}{}:
+	default:
+	}
+}
@@ -5,6 +5,9 @@ import (
 	"fmt"
 	"io"
 	"os"
+	"path/filepath"
+	"runtime"
+	"sort"
 	"testing"
 	"time"
 
@@ -15,6 +18,12 @@ import (
 
 // SetupTestDB запускает контейнер Postgres, ждёт его готовности, применяет миграции и возвращает пул и функцию очистки.
 func SetupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
+	return SetupTestDBWithSeed(t, "", nil)
+}
+
+// SetupTestDBWithSeed как SetupTestDB, но перед миграцией before (имя файла) вызывает seed: так
+// проверяются миграции на БД с данными, записанными прежней версией сервиса.
+func SetupTestDBWithSeed(t *testing.T, before string, seed func(ctx context.Context, pool *pgxpool.Pool)) (*pgxpool.Pool, func()) {
 	ctx := context.Background()
 	postgresC, err := tcpostgres.Run(ctx,
 		"postgres:17-alpine",
@@ -52,24 +61,34 @@ func SetupTestDB(t *testing.T) (*pgxpool.Pool, func()) {
 	assert.NoError(t, err, "Postgres did not become ready in time")
 
 	// Миграции
-	_, err = pool.Exec(ctx, `
-		CREATE TABLE IF NOT EXISTS wallets (
-			id UUID PRIMARY KEY,
-			balance DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (balance >= 0)
-		);
-		CREATE TABLE IF NOT EXISTS transactions (
-			id SERIAL PRIMARY KEY,
-			wallet_id UUID NOT NULL REFERENCES wallets(id),
-			type VARCHAR(10) NOT NULL CHECK (type IN ('DEPOSIT', 'WITHDRAW')),
-			amount DECIMAL(15, 2) NOT NULL,
-			created_at TIMESTAMPTZ DEFAULT NOW()
-		);
-		CREATE INDEX IF NOT EXISTS idx_transactions_wallet ON transactions(wallet_id);
-	`)
-	assert.NoError(t, err)
+	applyMigrations(t, ctx, pool, before, seed)
 
 	return pool, func() {
 		pool.Close()
 		postgresC.Terminate(ctx)
 	}
 }
+
+// applyMigrations применяет SQL-файлы из каталога migrations в лексикографическом порядке.
+func applyMigrations(
+	t *testing.T,
+	ctx context.Context,
+	pool *pgxpool.Pool,
+	before string,
+	seed func(ctx context.Context, pool *pgxpool.Pool),
+) {
+	_, file, _, _ := runtime.Caller(0)
+	dir := filepath.Join(filepath.Dir(file), "..", "..", "migrations")
+	files, err := filepath.Glob(filepath.Join(dir, "*.sql"))
+	assert.NoError(t, err)
+	sort.Strings(files)
+	for _, f := range files {
+		if seed != nil && filepath.Base(f) == before {
+			seed(ctx, pool)
+		}
+		sql, err := os.ReadFile(f)
+		assert.NoError(t, err)
+		_, err = pool.Exec(ctx, string(sql))
+		assert.NoError(t, err, "migration %s failed", filepath.Base(f))
+	}
+}
@@ -0,0 +1,74 @@
+package webhooks
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"net/netip"
+	"net/url"
+	"strings"
+	"syscall"
+	"test_wallet/internal/models"
+	"time"
+)
+
+// ErrForbiddenAddress — получатель вебхука во внутренней сети: loopback, link-local,
+// частные диапазоны и т. п.
+var ErrForbiddenAddress = errors.New("webhook address is not public")
+
+// sharedAddressSpace — 100.64.0.0/10 (RFC 6598), адреса операторского NAT.
+var sharedAddressSpace = netip.MustParsePrefix("100.64.0.0/10")
+
+const dialTimeout = 5 * time.Second
+
+// IsPublicAddr сообщает, можно ли отправлять вебхук на адрес addr.
+func IsPublicAddr(addr netip.Addr) bool {
+	addr = addr.Unmap()
+	return addr.IsGlobalUnicast() && !addr.IsPrivate() && !sharedAddressSpace.Contains(addr)
+}
+
+// CheckURL проверяет, что адрес подписки — https и не localhost или внутренний IP-адрес.
+func CheckURL(raw string) error {
+	u, err := url.Parse(raw)
+	if err != nil || u.Scheme != "https" || u.Hostname() == "" {
+		return fmt.Errorf("%w: url must be an absolute https URL", models.ErrInvalidWebhook)
+	}
+	host := strings.TrimSuffix(strings.ToLower(u.Hostname()), ".")
+	if host == "localhost" || strings.HasSuffix(host, ".localhost") {
+		return fmt.Errorf("%w: %w", models.ErrInvalidWebhook, ErrForbiddenAddress)
+	}
+	if addr, err := netip.ParseAddr(host); err == nil && !IsPublicAddr(addr) {
+		return fmt.Errorf("%w: %w", models.ErrInvalidWebhook, ErrForbiddenAddress)
+	}
+	return nil
+}
+
+// NewHTTPClient возвращает клиент, который соединяется только с публичными адресами.
+func NewHTTPClient(timeout time.Duration) *http.Client {
+	dialer := &net.Dialer{Timeout: dialTimeout, Control: dialControl}
+	return &http.Client{
+		Timeout: timeout,
+		Transport: &http.Transport{
+			DialContext:         dialer.DialContext,
+			ForceAttemptHTTP2:   true,
+			MaxIdleConns:        100,
+			IdleConnTimeout:     90 * time.Second,
+			TLSHandshakeTimeout: dialTimeout,
+		},
+		CheckRedirect: func(*http.Request, []*http.Request) error {
+			return http.ErrUseLastResponse
+		},
+	}
+}
+
+func dialControl(_, address string, _ syscall.RawConn) error {
+	addrPort, err := netip.ParseAddrPort(address)
+	if err != nil {
+		return err
+	}
+	if !IsPublicAddr(addrPort.Addr()) {
+		return fmt.Errorf("%w: %s", ErrForbiddenAddress, addrPort.Addr())
+	}
+	return nil
+}
@@ -0,0 +1,195 @@
+package webhooks
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"sync"
+	"test_wallet/internal/models"
+	"test_wallet/internal/poller"
+	"time"
+)
+
+const (
+	DefaultInterval    = 5 * time.Second
+	DefaultRetryDelay  = 30 * time.Second
+	DefaultMaxAttempts = 8
+	DefaultBatchSize   = 100
+	// concurrency — сколько доставок отправляется одновременно.
+	concurrency        = 8
+	defaultHTTPTimeout = 10 * time.Second
+	// claimLease — на сколько взятая доставка скрывается от других реплик; должен
+	// перекрывать отправку всей пачки.
+	claimLease = 5 * time.Minute
+	// maxRetryDelay — получатель может быть недоступен долго, поэтому потолок задержки большой.
+	maxRetryDelay = 6 * time.Hour
+)
+
+// Dispatcher отправляет доставки вебхуков с повторами и экспоненциальной задержкой.
+type Dispatcher struct {
+	store       Store
+	logger      *slog.Logger
+	client      *http.Client
+	interval    time.Duration
+	retryDelay  time.Duration
+	maxAttempts int
+	batchSize   int
+	now         func() time.Time
+}
+
+type Option func(*Dispatcher)
+
+// WithInterval задаёт период опроса журнала доставок.
+func WithInterval(interval time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.interval = interval
+	}
+}
+
+// WithRetryDelay задаёт задержку повторной доставки после первой неудачи (см. poller.Backoff).
+func WithRetryDelay(delay time.Duration) Option {
+	return func(d *Dispatcher) {
+		d.retryDelay = delay
+	}
+}
+
+// WithMaxAttempts задаёт число попыток, после которого доставка переходит в DEAD.
+func WithMaxAttempts(n int) Option {
+	return func(d *Dispatcher) {
+		d.maxAttempts = n
+	}
+}
+
+// WithHTTPClient подменяет HTTP-клиент; по умолчанию — NewHTTPClient с таймаутом 10 секунд.
+func WithHTTPClient(client *http.Client) Option {
+	return func(d *Dispatcher) {
+		d.client = client
+	}
+}
+
+// WithClock задаёт часы для расписания повторов и подписи запросов; нужен тестам.
+func WithClock(now func() time.Time) Option {
+	return func(d *Dispatcher) {
+		d.now = now
+	}
+}
+
+func NewDispatcher(store Store, logger *slog.Logger, opts ...Option) *Dispatcher {
+	d := &Dispatcher{
+		store:       store,
+		logger:      logger,
+		client:      NewHTTPClient(defaultHTTPTimeout),
+		interval:    DefaultInterval,
+		retryDelay:  DefaultRetryDelay,
+		maxAttempts: DefaultMaxAttempts,
+		batchSize:   DefaultBatchSize,
+		now:         time.Now,
+	}
+	for _, opt := range opts {
+		opt(d)
+	}
+	return d
+}
+
+// Run отправляет доставки до отмены ctx.
+func (d *Dispatcher) Run(ctx context.Context) {
+	poller.Run(ctx, d.interval, d.logger, "Webhook dispatch failed", d.DispatchDue)
+}
+
+// DispatchDue отправляет наступившие доставки и возвращает число успешных.
+func (d *Dispatcher) DispatchDue(ctx context.Context) (int, error) {
+	due, err := d.store.ClaimWebhookDeliveries(ctx, d.now(), claimLease, d.batchSize)
+	if err != nil {
+		return 0, err
+	}
+	var (
+		wg        sync.WaitGroup
+		mu        sync.Mutex
+		delivered int
+		sem       = make(chan struct{}, concurrency)
+	)
+	for _, delivery := range due {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(delivery models.WebhookDelivery) {
+			defer func() {
+				<-sem
+				wg.Done()
+			}()
+			ok, err := d.deliver(ctx, delivery)
+			if err != nil {
+				d.logger.Error("Failed to record webhook attempt",
+					slog.Int64("delivery_id", delivery.ID),
+					slog.Any("err", err),
+				)
+			}
+			if ok {
+				mu.Lock()
+				delivered++
+				mu.Unlock()
+			}
+		}(delivery)
+	}
+	wg.Wait()
+	return delivered, nil
+}
+
+// deliver отправляет доставку и записывает результат попытки.
+func (d *Dispatcher) deliver(ctx context.Context, delivery models.WebhookDelivery) (bool, error) {
+	statusCode, sendErr := d.send(ctx, delivery)
+	now := d.now()
+	delivery.Attempts++
+	attrs := []any{
+		slog.Int64("delivery_id", delivery.ID),
+		slog.String("subscription_id", delivery.SubscriptionID.String()),
+		slog.String("event", delivery.Event),
+		slog.Int("attempt", delivery.Attempts),
+	}
+	if statusCode != 0 {
+		delivery.LastStatusCode = &statusCode
+	}
+	if sendErr == nil {
+		delivery.Status = models.DeliveryDelivered
+		delivery.LastError = nil
+		delivery.DeliveredAt = &now
+		d.logger.Info("Webhook delivered", attrs...)
+		return true, d.store.RecordWebhookAttempt(ctx, delivery)
+	}
+
+	msg := sendErr.Error()
+	delivery.LastError = &msg
+	if delivery.Attempts >= d.maxAttempts {
+		delivery.Status = models.DeliveryDead
+		d.logger.Error("Webhook delivery is dead", append(attrs, slog.Any("err", sendErr))...)
+	} else {
+		delivery.NextAttemptAt = now.Add(poller.Backoff(d.retryDelay, maxRetryDelay, delivery.Attempts))
+		d.logger.Warn("Webhook delivery failed, will retry", append(attrs, slog.Any("err", sendErr))...)
+	}
+	return false, d.store.RecordWebhookAttempt(ctx, delivery)
+}
+
+// send отправляет подписанный POST-запрос и возвращает код ответа (0, если ответа не было).
+func (d *Dispatcher) send(ctx context.Context, delivery models.WebhookDelivery) (int, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, delivery.URL, bytes.NewReader(delivery.Payload))
+	if err != nil {
+		return 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(EventHeader, delivery.Event)
+	req.Header.Set(DeliveryHeader, strconv.FormatInt(delivery.ID, 10))
+	req.Header.Set(SignatureHeader, Sign(delivery.Secret, d.now(), delivery.Payload))
+	resp, err := d.client.Do(req)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, io.LimitReader(resp.Body, 64<<10))
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return resp.StatusCode, fmt.Errorf("unexpected status %d", resp.StatusCode)
+	}
+	return resp.StatusCode, nil
+}
@@ -0,0 +1,118 @@
+package webhooks
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"strconv"
+	"test_wallet/internal/models"
+	"time"
+
+	"github.com/google/uuid"
+)
+
+//go:generate mockgen -source=webhooks.go -destination=../../test/mock_webhooks.go -package=test -mock_names=Store=MockWebhookStore
+
+// Store — подписки и журнал доставок вебхуков.
+type Store interface {
+	WebhookSubscriptionsForWallet(ctx context.Context, walletID uuid.UUID) ([]models.WebhookSubscription, error)
+	CreateWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) (int64, error)
+	ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error)
+	RecordWebhookAttempt(ctx context.Context, delivery models.WebhookDelivery) error
+}
+
+const (
+	SignatureHeader = "X-Webhook-Signature"
+	EventHeader     = "X-Webhook-Event"
+	DeliveryHeader  = "X-Webhook-Delivery"
+)
+
+// Sign возвращает заголовок X-Webhook-Signature: "t=<unix-время>,v1=<HMAC-SHA256>".
+func Sign(secret string, timestamp time.Time, body []byte) string {
+	ts := strconv.FormatInt(timestamp.Unix(), 10)
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(ts))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return "t=" + ts + ",v1=" + hex.EncodeToString(mac.Sum(nil))
+}
+
+// Publisher — events.EventPublisher, который раскладывает события по доставкам подписок.
+type Publisher struct {
+	store Store
+}
+
+func NewPublisher(store Store) *Publisher {
+	return &Publisher{store: store}
+}
+
+func (p *Publisher) Publish(ctx context.Context, event models.Event) error {
+	subs, err := p.store.WebhookSubscriptionsForWallet(ctx, event.WalletID)
+	if err != nil || len(subs) == 0 {
+		return err
+	}
+	var deliveries []models.WebhookDelivery
+	for _, sub := range subs {
+		kinds, err := Match(sub, event)
+		if err != nil {
+			return err
+		}
+		for _, kind := range kinds {
+			payload, err := json.Marshal(models.WebhookPayload{
+				EventID:    event.EventID,
+				Event:      kind,
+				WalletID:   event.WalletID,
+				OccurredAt: event.CreatedAt,
+				Data:       event.Data,
+			})
+			if err != nil {
+				return err
+			}
+			deliveries = append(deliveries, models.WebhookDelivery{
+				SubscriptionID: sub.ID,
+				EventID:        event.EventID,
+				Event:          kind,
+				Payload:        payload,
+			})
+		}
+	}
+	if len(deliveries) == 0 {
+		return nil
+	}
+	_, err = p.store.CreateWebhookDeliveries(ctx, deliveries)
+	return err
+}
+
+// Match возвращает события вебхука, которые порождает событие outbox для подписки sub.
+// low_balance приходит, когда операция опускает баланс с уровня не ниже порога до уровня ниже него.
+func Match(sub models.WebhookSubscription, event models.Event) ([]string, error) {
+	var kinds []string
+	switch event.Type {
+	case models.EventBalanceChanged:
+		var data models.BalanceChanged
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, err
+		}
+		switch {
+		case data.Operation == "DEPOSIT" && sub.Subscribed(models.WebhookDeposit):
+			kinds = append(kinds, models.WebhookDeposit)
+		case data.Operation == "WITHDRAW" && sub.Subscribed(models.WebhookWithdraw):
+			kinds = append(kinds, models.WebhookWithdraw)
+		}
+		if threshold := sub.LowBalanceThreshold; threshold != nil && sub.Subscribed(models.WebhookLowBalance) &&
+			!data.BalanceBefore.LessThan(*threshold) && data.BalanceAfter.LessThan(*threshold) {
+			kinds = append(kinds, models.WebhookLowBalance)
+		}
+	case models.EventStatusChanged:
+		var data models.StatusChanged
+		if err := json.Unmarshal(event.Data, &data); err != nil {
+			return nil, err
+		}
+		if data.Status == models.WalletFrozen && sub.Subscribed(models.WebhookWalletFrozen) {
+			kinds = append(kinds, models.WebhookWalletFrozen)
+		}
+	}
+	return kinds, nil
+}
@@ -0,0 +1,17 @@
+ALTER TABLE transactions ADD COLUMN balance_after DECIMAL(15, 2) NOT NULL DEFAULT 0;
+ALTER TABLE transactions ALTER COLUMN balance_after DROP DEFAULT;
+
+UPDATE transactions SET created_at = NOW() WHERE created_at IS NULL;
+ALTER TABLE transactions ALTER COLUMN created_at SET NOT NULL;
+
+CREATE INDEX idx_transactions_wallet_created ON transactions(wallet_id, created_at, id);
+
+-- До появления журнала балансы менялись без записей в transactions. Переносим текущие
+-- балансы строками OPENING_BALANCE, чтобы сумма журнала кошелька совпадала с его балансом.
+ALTER TABLE transactions ALTER COLUMN type TYPE VARCHAR(20);
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('OPENING_BALANCE', 'DEPOSIT', 'WITHDRAW'));
+
+INSERT INTO transactions (wallet_id, type, amount, balance_after)
+SELECT id, 'OPENING_BALANCE', balance, balance FROM wallets WHERE balance <> 0 ORDER BY id;
@@ -0,0 +1,7 @@
+ALTER TABLE transactions ALTER COLUMN type TYPE VARCHAR(20);
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('OPENING_BALANCE', 'DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT'));
+
+ALTER TABLE transactions ADD COLUMN transfer_id UUID;
+CREATE INDEX idx_transactions_transfer ON transactions(transfer_id) WHERE transfer_id IS NOT NULL;
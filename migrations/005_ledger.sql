@@ -0,0 +1,50 @@
+CREATE TABLE ledger_accounts (
+    id VARCHAR(64) PRIMARY KEY,
+    kind VARCHAR(10) NOT NULL CHECK (kind IN ('WALLET', 'SYSTEM')),
+    wallet_id UUID UNIQUE REFERENCES wallets(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((kind = 'WALLET') = (wallet_id IS NOT NULL))
+);
+
+CREATE TABLE ledger_entries (
+    id BIGSERIAL PRIMARY KEY,
+    type VARCHAR(20) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE TABLE ledger_postings (
+    id BIGSERIAL PRIMARY KEY,
+    entry_id BIGINT NOT NULL REFERENCES ledger_entries(id),
+    account_id VARCHAR(64) NOT NULL REFERENCES ledger_accounts(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount <> 0),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_ledger_postings_entry ON ledger_postings(entry_id);
+CREATE INDEX idx_ledger_postings_account ON ledger_postings(account_id, id);
+
+ALTER TABLE transactions ADD COLUMN entry_id BIGINT REFERENCES ledger_entries(id);
+
+INSERT INTO ledger_accounts (id, kind) VALUES
+    ('system:external_cash_in', 'SYSTEM'),
+    ('system:external_cash_out', 'SYSTEM'),
+    ('system:fees', 'SYSTEM');
+
+INSERT INTO ledger_accounts (id, kind, wallet_id)
+SELECT 'wallet:' || id, 'WALLET', id FROM wallets;
+
+-- Переносим текущие балансы входящими остатками, чтобы книги сходились с первого дня.
+DO $$
+DECLARE
+    w RECORD;
+    new_entry_id BIGINT;
+BEGIN
+    FOR w IN SELECT id, balance FROM wallets WHERE balance <> 0 LOOP
+        INSERT INTO ledger_entries (type) VALUES ('OPENING_BALANCE') RETURNING id INTO new_entry_id;
+        INSERT INTO ledger_postings (entry_id, account_id, amount) VALUES
+            (new_entry_id, 'wallet:' || w.id, w.balance),
+            (new_entry_id, 'system:external_cash_in', -w.balance);
+        UPDATE transactions SET entry_id = new_entry_id
+        WHERE wallet_id = w.id AND type = 'OPENING_BALANCE';
+    END LOOP;
+END $$;
@@ -0,0 +1,18 @@
+CREATE TABLE holds (
+    id UUID PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    amount DECIMAL(15, 2) NOT NULL CHECK (amount > 0),
+    captured_amount DECIMAL(15, 2) NOT NULL DEFAULT 0 CHECK (captured_amount >= 0 AND captured_amount <= amount),
+    status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE' CHECK (status IN ('ACTIVE', 'CAPTURED', 'VOIDED', 'EXPIRED')),
+    expires_at TIMESTAMPTZ NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_holds_wallet_active ON holds(wallet_id) WHERE status = 'ACTIVE';
+CREATE INDEX idx_holds_expiry ON holds(expires_at) WHERE status = 'ACTIVE';
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('OPENING_BALANCE', 'DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'CAPTURE'));
+ALTER TABLE transactions ADD COLUMN hold_id UUID REFERENCES holds(id);
@@ -0,0 +1,23 @@
+-- Денежные колонки расширяются до 4 знаков, чтобы вместить валюты с 3 знаками (BHD, KWD).
+-- Допустимое для конкретной валюты число знаков проверяет приложение.
+ALTER TABLE wallets ALTER COLUMN balance TYPE DECIMAL(19, 4);
+ALTER TABLE transactions ALTER COLUMN amount TYPE DECIMAL(19, 4);
+ALTER TABLE transactions ALTER COLUMN balance_after TYPE DECIMAL(19, 4);
+ALTER TABLE ledger_postings ALTER COLUMN amount TYPE DECIMAL(19, 4);
+ALTER TABLE holds ALTER COLUMN amount TYPE DECIMAL(19, 4);
+ALTER TABLE holds ALTER COLUMN captured_amount TYPE DECIMAL(19, 4);
+
+-- Существующие кошельки считаются рублёвыми.
+ALTER TABLE wallets ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE wallets ALTER COLUMN currency DROP DEFAULT;
+
+ALTER TABLE ledger_accounts ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE ledger_accounts ALTER COLUMN currency DROP DEFAULT;
+ALTER TABLE ledger_postings ADD COLUMN currency CHAR(3) NOT NULL DEFAULT 'RUB';
+ALTER TABLE ledger_postings ALTER COLUMN currency DROP DEFAULT;
+
+-- Системные счета ведутся отдельно по каждой валюте: system:<name>:<currency>.
+ALTER TABLE ledger_postings DROP CONSTRAINT ledger_postings_account_id_fkey;
+ALTER TABLE ledger_postings ADD CONSTRAINT ledger_postings_account_id_fkey
+    FOREIGN KEY (account_id) REFERENCES ledger_accounts(id) ON UPDATE CASCADE;
+UPDATE ledger_accounts SET id = id || ':RUB' WHERE kind = 'SYSTEM';
@@ -0,0 +1,25 @@
+-- Котировки конвертации: курс фиксируется при создании и действует до expires_at.
+-- Исполненная котировка помечается executed_at и не может быть исполнена повторно.
+CREATE TABLE fx_quotes (
+    id UUID PRIMARY KEY,
+    from_wallet_id UUID NOT NULL REFERENCES wallets(id),
+    to_wallet_id UUID NOT NULL REFERENCES wallets(id),
+    from_currency CHAR(3) NOT NULL,
+    to_currency CHAR(3) NOT NULL,
+    from_amount DECIMAL(19, 4) NOT NULL CHECK (from_amount > 0),
+    to_amount DECIMAL(19, 4) NOT NULL CHECK (to_amount > 0),
+    rate DECIMAL(24, 12) NOT NULL CHECK (rate > 0),
+    rate_timestamp TIMESTAMPTZ NOT NULL,
+    expires_at TIMESTAMPTZ NOT NULL,
+    executed_at TIMESTAMPTZ,
+    entry_id BIGINT REFERENCES ledger_entries(id),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('OPENING_BALANCE', 'DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'CAPTURE',
+                    'CONVERSION_IN', 'CONVERSION_OUT'));
+ALTER TABLE transactions ADD COLUMN conversion_id UUID REFERENCES fx_quotes(id);
+ALTER TABLE transactions ADD COLUMN rate DECIMAL(24, 12);
+ALTER TABLE transactions ADD COLUMN rate_timestamp TIMESTAMPTZ;
@@ -0,0 +1,14 @@
+-- Сторно прошлых операций. Строка сторно ссылается на исходную через reversal_of,
+-- а исходная хранит уже сторнированную сумму, чтобы сторно не превысило операцию.
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('OPENING_BALANCE', 'DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'CAPTURE',
+                    'CONVERSION_IN', 'CONVERSION_OUT', 'REVERSAL'));
+ALTER TABLE transactions ADD COLUMN reversal_of INT REFERENCES transactions(id);
+ALTER TABLE transactions ADD COLUMN reversed_amount DECIMAL(19, 4) NOT NULL DEFAULT 0
+    CHECK (reversed_amount >= 0 AND reversed_amount <= ABS(amount));
+CREATE INDEX idx_transactions_reversal_of ON transactions(reversal_of) WHERE reversal_of IS NOT NULL;
+
+-- При политике allow_negative сторно пополнения может увести баланс в минус.
+-- Неотрицательность остальных списаний проверяет приложение.
+ALTER TABLE wallets DROP CONSTRAINT wallets_balance_check;
@@ -0,0 +1,6 @@
+-- Жизненный цикл кошелька: ACTIVE → FROZEN ⇄ ACTIVE, ACTIVE/FROZEN → CLOSED.
+-- CLOSED — конечное состояние.
+ALTER TABLE wallets ADD COLUMN status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE'
+    CHECK (status IN ('ACTIVE', 'FROZEN', 'CLOSED'));
+ALTER TABLE wallets ADD COLUMN status_reason VARCHAR(255);
+ALTER TABLE wallets ADD COLUMN status_changed_at TIMESTAMPTZ;
@@ -0,0 +1,10 @@
+-- Метаданные кошелька для явного создания и управления через API.
+ALTER TABLE wallets ADD COLUMN owner_id VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE wallets ADD COLUMN name VARCHAR(255) NOT NULL DEFAULT '';
+ALTER TABLE wallets ADD COLUMN labels JSONB NOT NULL DEFAULT '{}';
+ALTER TABLE wallets ADD COLUMN created_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+ALTER TABLE wallets ADD COLUMN updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW();
+
+CREATE INDEX idx_wallets_created_at_id ON wallets(created_at, id);
+CREATE INDEX idx_wallets_owner_id ON wallets(owner_id) WHERE owner_id <> '';
+CREATE INDEX idx_wallets_labels ON wallets USING GIN (labels);
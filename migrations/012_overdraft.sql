@@ -0,0 +1,16 @@
+-- Овердрафт: списание проходит, пока balance - held >= -overdraft_limit.
+-- Ограничение на balance в БД не вводится: сторно по политике allow_negative
+-- может увести баланс ниже лимита, остальные списания проверяет приложение.
+ALTER TABLE wallets ADD COLUMN overdraft_limit DECIMAL(19, 4) NOT NULL DEFAULT 0
+    CHECK (overdraft_limit >= 0);
+
+-- Журнал изменений лимита овердрафта.
+CREATE TABLE overdraft_limit_changes (
+    id SERIAL PRIMARY KEY,
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    old_limit DECIMAL(19, 4) NOT NULL,
+    new_limit DECIMAL(19, 4) NOT NULL,
+    reason VARCHAR(255),
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_overdraft_limit_changes_wallet ON overdraft_limit_changes(wallet_id, id);
@@ -0,0 +1,32 @@
+-- Лимиты операций: максимальная сумма одной операции, накопительные лимиты за календарные
+-- сутки, неделю и месяц (UTC) и число операций в скользящем окне. Лимиты задаются тарифом
+-- (tier, например уровень KYC) отдельно для каждой валюты и могут быть переопределены
+-- для конкретного кошелька. Кошельки без тарифа подчиняются тарифу 'default'.
+CREATE TABLE limit_tiers (
+    tier VARCHAR(63) NOT NULL,
+    currency CHAR(3) NOT NULL,
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('DEPOSIT', 'WITHDRAW')),
+    max_single_amount DECIMAL(19, 4) CHECK (max_single_amount > 0),
+    daily_cap DECIMAL(19, 4) CHECK (daily_cap > 0),
+    weekly_cap DECIMAL(19, 4) CHECK (weekly_cap > 0),
+    monthly_cap DECIMAL(19, 4) CHECK (monthly_cap > 0),
+    max_operations INT CHECK (max_operations > 0),
+    operations_window_seconds INT CHECK (operations_window_seconds > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (tier, currency, operation)
+);
+
+CREATE TABLE wallet_limits (
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('DEPOSIT', 'WITHDRAW')),
+    max_single_amount DECIMAL(19, 4) CHECK (max_single_amount > 0),
+    daily_cap DECIMAL(19, 4) CHECK (daily_cap > 0),
+    weekly_cap DECIMAL(19, 4) CHECK (weekly_cap > 0),
+    monthly_cap DECIMAL(19, 4) CHECK (monthly_cap > 0),
+    max_operations INT CHECK (max_operations > 0),
+    operations_window_seconds INT CHECK (operations_window_seconds > 0),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (wallet_id, operation)
+);
+
+ALTER TABLE wallets ADD COLUMN limit_tier VARCHAR(63);
@@ -0,0 +1,20 @@
+-- Тарифы комиссий по типу операции и валюте. Комиссия = flat + amount * percent / 100,
+-- где flat и percent берутся из первой подходящей ступени tiers (по возрастанию upTo),
+-- а если ступеней нет — из самого тарифа; результат ограничивается min_fee и max_fee.
+CREATE TABLE fee_schedules (
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('DEPOSIT', 'WITHDRAW')),
+    currency CHAR(3) NOT NULL,
+    flat DECIMAL(19, 4) NOT NULL DEFAULT 0 CHECK (flat >= 0),
+    percent DECIMAL(9, 6) NOT NULL DEFAULT 0 CHECK (percent >= 0 AND percent <= 100),
+    min_fee DECIMAL(19, 4) CHECK (min_fee >= 0),
+    max_fee DECIMAL(19, 4) CHECK (max_fee >= 0),
+    tiers JSONB NOT NULL DEFAULT '[]',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (operation, currency)
+);
+
+-- Комиссия отражается в журнале отдельной строкой FEE той же записи главной книги.
+ALTER TABLE transactions DROP CONSTRAINT transactions_type_check;
+ALTER TABLE transactions ADD CONSTRAINT transactions_type_check
+    CHECK (type IN ('OPENING_BALANCE', 'DEPOSIT', 'WITHDRAW', 'TRANSFER_IN', 'TRANSFER_OUT', 'CAPTURE',
+                    'CONVERSION_IN', 'CONVERSION_OUT', 'REVERSAL', 'FEE'));
@@ -0,0 +1,36 @@
+-- Отложенные и регулярные операции. occurrence_at — плановое время текущего срабатывания
+-- (по нему строится ключ идемпотентности), next_run_at — когда пытаться его исполнить:
+-- после неудачи next_run_at сдвигается на время повтора, а occurrence_at остаётся прежним.
+CREATE TABLE scheduled_operations (
+    id UUID PRIMARY KEY,
+    operation VARCHAR(10) NOT NULL CHECK (operation IN ('DEPOSIT', 'WITHDRAW', 'TRANSFER')),
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    to_wallet_id UUID REFERENCES wallets(id),
+    amount DECIMAL(19, 4) NOT NULL CHECK (amount > 0),
+    currency CHAR(3),
+    cron VARCHAR(255),
+    status VARCHAR(10) NOT NULL DEFAULT 'ACTIVE'
+        CHECK (status IN ('ACTIVE', 'COMPLETED', 'CANCELLED', 'FAILED')),
+    occurrence_at TIMESTAMPTZ NOT NULL,
+    next_run_at TIMESTAMPTZ NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    max_attempts INT NOT NULL DEFAULT 3 CHECK (max_attempts > 0),
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    CHECK ((operation = 'TRANSFER') = (to_wallet_id IS NOT NULL))
+);
+CREATE INDEX idx_scheduled_operations_due ON scheduled_operations(next_run_at) WHERE status = 'ACTIVE';
+CREATE INDEX idx_scheduled_operations_wallet ON scheduled_operations(wallet_id, created_at);
+
+-- Каждая попытка исполнения, успешная или нет.
+CREATE TABLE scheduled_operation_runs (
+    id BIGSERIAL PRIMARY KEY,
+    scheduled_operation_id UUID NOT NULL REFERENCES scheduled_operations(id),
+    occurrence_at TIMESTAMPTZ NOT NULL,
+    attempt INT NOT NULL,
+    status VARCHAR(10) NOT NULL CHECK (status IN ('SUCCEEDED', 'FAILED')),
+    error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+CREATE INDEX idx_scheduled_operation_runs_operation ON scheduled_operation_runs(scheduled_operation_id, id);
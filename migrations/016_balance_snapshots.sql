@@ -0,0 +1,10 @@
+-- Снимки балансов для запросов баланса на момент времени: баланс на момент T равен
+-- последнему снимку не позже T плюс сумма операций журнала между снимком и T.
+-- Снимок пишется только для кошельков, у которых были операции после предыдущего снимка.
+CREATE TABLE balance_snapshots (
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    as_of TIMESTAMPTZ NOT NULL,
+    balance DECIMAL(19, 4) NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    PRIMARY KEY (wallet_id, as_of)
+);
@@ -0,0 +1,69 @@
+-- Хэш-цепочка журнала: каждая строка transactions хранит SHA-256 своего содержимого вместе
+-- с хэшем предыдущей строки того же кошелька (prev_hash), а wallets.journal_hash — хэш
+-- последней строки. Правка, удаление или вставка строки в обход приложения разрывает цепочку.
+-- reversed_amount в хэш не входит: он меняется при сторно.
+CREATE FUNCTION journal_row_hash(
+    id BIGINT, wallet_id UUID, type TEXT, amount NUMERIC, balance_after NUMERIC, entry_id BIGINT,
+    transfer_id UUID, hold_id UUID, conversion_id UUID, rate NUMERIC, rate_timestamp TIMESTAMPTZ,
+    reversal_of BIGINT, created_at TIMESTAMPTZ, prev_hash TEXT
+) RETURNS CHAR(64) AS $$
+    SELECT encode(sha256(convert_to(
+        id::text || '|' || wallet_id::text || '|' || type || '|' || amount::text || '|' ||
+        balance_after::text || '|' || COALESCE(entry_id::text, '') || '|' ||
+        COALESCE(transfer_id::text, '') || '|' || COALESCE(hold_id::text, '') || '|' ||
+        COALESCE(conversion_id::text, '') || '|' || COALESCE(rate::text, '') || '|' ||
+        COALESCE(EXTRACT(EPOCH FROM rate_timestamp)::text, '') || '|' ||
+        COALESCE(reversal_of::text, '') || '|' || EXTRACT(EPOCH FROM created_at)::text || '|' ||
+        COALESCE(prev_hash, ''),
+        'UTF8')), 'hex')
+$$ LANGUAGE SQL IMMUTABLE;
+
+ALTER TABLE transactions ADD COLUMN prev_hash CHAR(64);
+ALTER TABLE transactions ADD COLUMN hash CHAR(64);
+ALTER TABLE wallets ADD COLUMN journal_hash CHAR(64);
+CREATE INDEX idx_transactions_wallet_id ON transactions(wallet_id, id);
+
+-- Выстраиваем цепочку по уже существующим строкам.
+DO $$
+DECLARE
+    t RECORD;
+    prev CHAR(64);
+    cur_wallet UUID;
+BEGIN
+    FOR t IN SELECT * FROM transactions ORDER BY wallet_id, id LOOP
+        IF cur_wallet IS DISTINCT FROM t.wallet_id THEN
+            IF cur_wallet IS NOT NULL THEN
+                UPDATE wallets SET journal_hash = prev WHERE id = cur_wallet;
+            END IF;
+            cur_wallet := t.wallet_id;
+            prev := NULL;
+        END IF;
+        UPDATE transactions SET prev_hash = prev,
+            hash = journal_row_hash(t.id, t.wallet_id, t.type, t.amount, t.balance_after, t.entry_id,
+                t.transfer_id, t.hold_id, t.conversion_id, t.rate, t.rate_timestamp, t.reversal_of,
+                t.created_at, prev)
+        WHERE id = t.id
+        RETURNING hash INTO prev;
+    END LOOP;
+    IF cur_wallet IS NOT NULL THEN
+        UPDATE wallets SET journal_hash = prev WHERE id = cur_wallet;
+    END IF;
+END $$;
+
+ALTER TABLE transactions ALTER COLUMN hash SET NOT NULL;
+
+-- Новые строки сцепляются триггером. Приложение вставляет строки журнала, удерживая
+-- блокировку строки кошелька, поэтому цепочка кошелька строится последовательно.
+CREATE FUNCTION transactions_chain() RETURNS TRIGGER AS $$
+BEGIN
+    SELECT journal_hash INTO NEW.prev_hash FROM wallets WHERE id = NEW.wallet_id FOR UPDATE;
+    NEW.hash := journal_row_hash(NEW.id, NEW.wallet_id, NEW.type, NEW.amount, NEW.balance_after,
+        NEW.entry_id, NEW.transfer_id, NEW.hold_id, NEW.conversion_id, NEW.rate, NEW.rate_timestamp,
+        NEW.reversal_of, NEW.created_at, NEW.prev_hash);
+    UPDATE wallets SET journal_hash = NEW.hash WHERE id = NEW.wallet_id;
+    RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER transactions_chain BEFORE INSERT ON transactions
+    FOR EACH ROW EXECUTE FUNCTION transactions_chain();
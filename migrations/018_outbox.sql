@@ -0,0 +1,16 @@
+-- Transactional outbox: событие пишется в той же транзакции, что и изменение кошелька,
+-- а relay публикует его после коммита. published_at IS NULL — событие ещё не доставлено.
+CREATE TABLE outbox_events (
+    id BIGSERIAL PRIMARY KEY,
+    event_id UUID NOT NULL UNIQUE,
+    type VARCHAR(64) NOT NULL,
+    wallet_id UUID NOT NULL REFERENCES wallets(id),
+    payload JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_error TEXT,
+    published_at TIMESTAMPTZ
+);
+
+CREATE INDEX idx_outbox_events_pending ON outbox_events(wallet_id, id) WHERE published_at IS NULL;
@@ -0,0 +1,37 @@
+-- Подписки партнёров (owner_id кошельков) на события их кошельков.
+CREATE TABLE webhook_subscriptions (
+    id UUID PRIMARY KEY,
+    owner_id VARCHAR(255) NOT NULL,
+    wallet_id UUID REFERENCES wallets(id),
+    url TEXT NOT NULL,
+    secret TEXT NOT NULL,
+    events TEXT[] NOT NULL,
+    low_balance_threshold DECIMAL(19, 4),
+    active BOOLEAN NOT NULL DEFAULT TRUE,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW()
+);
+
+CREATE INDEX idx_webhook_subscriptions_owner_id ON webhook_subscriptions(owner_id) WHERE active;
+
+-- Журнал доставок: одна строка на (подписка, событие, вид события), повторная публикация
+-- того же события из outbox не создаёт второй доставки.
+CREATE TABLE webhook_deliveries (
+    id BIGSERIAL PRIMARY KEY,
+    subscription_id UUID NOT NULL REFERENCES webhook_subscriptions(id),
+    event_id UUID NOT NULL,
+    event VARCHAR(32) NOT NULL,
+    payload JSONB NOT NULL,
+    status VARCHAR(16) NOT NULL DEFAULT 'PENDING'
+        CHECK (status IN ('PENDING', 'DELIVERED', 'DEAD')),
+    attempts INT NOT NULL DEFAULT 0,
+    next_attempt_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    last_status_code INT,
+    last_error TEXT,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    delivered_at TIMESTAMPTZ,
+    UNIQUE (subscription_id, event_id, event)
+);
+
+CREATE INDEX idx_webhook_deliveries_pending ON webhook_deliveries(next_attempt_at) WHERE status = 'PENDING';
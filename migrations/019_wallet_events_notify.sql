@@ -0,0 +1,14 @@
+-- Уведомление о новом событии кошелька для SSE-подписчиков всех реплик. NOTIFY доставляется
+-- при коммите, так что подписчики узнают только о зафиксированных изменениях; сами события
+-- они читают из outbox_events.
+CREATE INDEX idx_outbox_events_wallet ON outbox_events(wallet_id, id);
+
+CREATE FUNCTION outbox_events_notify() RETURNS TRIGGER AS $$
+BEGIN
+    PERFORM pg_notify('wallet_events', NEW.wallet_id::text);
+    RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE TRIGGER outbox_events_notify AFTER INSERT ON outbox_events
+    FOR EACH ROW EXECUTE FUNCTION outbox_events_notify();
@@ -0,0 +1,11 @@
+-- Статические API-ключи. Хранится только SHA-256 ключа (hex); отозванный ключ остаётся в
+-- таблице с revoked_at, чтобы его имя не переиспользовалось незаметно. scopes — права ключа,
+-- например admin для административных эндпоинтов.
+CREATE TABLE api_keys (
+    id UUID PRIMARY KEY,
+    name VARCHAR(255) NOT NULL UNIQUE,
+    key_hash CHAR(64) NOT NULL UNIQUE,
+    scopes TEXT[] NOT NULL DEFAULT '{}',
+    created_at TIMESTAMPTZ NOT NULL DEFAULT NOW(),
+    revoked_at TIMESTAMPTZ
+);
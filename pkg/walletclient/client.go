@@ -0,0 +1,423 @@
+// Package walletclient — Go-клиент HTTP API кошельков (/api/v1): пополнение, списание, баланс и
+// история операций.
+//
+// Клиент сам назначает пополнениям и списаниям ключ идемпотентности и повторяет запрос с тем же
+// ключом, если сервис ответил 503 или соединение оборвалось, поэтому повтор не проводит операцию
+// второй раз. Ошибки API возвращаются как *APIError и сравниваются через errors.Is с
+// ErrWalletNotFound, ErrInsufficientFunds и другими ошибками пакета.
+package walletclient
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"math/rand/v2"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	defaultTimeout     = 30 * time.Second
+	defaultMaxAttempts = 4
+	defaultRetryDelay  = 200 * time.Millisecond
+	maxRetryDelay      = 5 * time.Second
+
+	idempotencyKeyHeader     = "Idempotency-Key"
+	idempotentReplayedHeader = "Idempotent-Replayed"
+)
+
+// Client — клиент API кошельков. Безопасен для одновременного использования.
+type Client struct {
+	baseURL     string
+	httpClient  *http.Client
+	timeout     time.Duration
+	maxAttempts int
+	retryDelay  time.Duration
+	newKey      func() string
+	// authHeader и authValue — заголовок с учётными данными, см. WithAPIKey и WithBearerToken
+	authHeader string
+	authValue  string
+}
+
+type Option func(*Client)
+
+// WithHTTPClient задаёт HTTP-клиент (транспорт, TLS, прокси).
+func WithHTTPClient(client *http.Client) Option {
+	return func(c *Client) {
+		c.httpClient = client
+	}
+}
+
+// WithTimeout задаёт срок вызова вместе со всеми повторами, если у контекста вызова нет
+// своего дедлайна. 0 — без ограничения. По умолчанию 30 секунд.
+func WithTimeout(timeout time.Duration) Option {
+	return func(c *Client) {
+		c.timeout = timeout
+	}
+}
+
+// WithMaxAttempts задаёт число попыток запроса, включая первую; 1 отключает повторы.
+func WithMaxAttempts(n int) Option {
+	return func(c *Client) {
+		c.maxAttempts = n
+	}
+}
+
+// WithRetryDelay задаёт базовую задержку перед повтором: retryDelay, 2·retryDelay, 4·retryDelay...
+// (не больше 5 секунд), каждая со случайным разбросом от половины до полной величины.
+func WithRetryDelay(delay time.Duration) Option {
+	return func(c *Client) {
+		c.retryDelay = delay
+	}
+}
+
+// WithIdempotencyKeyGenerator заменяет генератор ключей идемпотентности (по умолчанию UUID v4).
+func WithIdempotencyKeyGenerator(newKey func() string) Option {
+	return func(c *Client) {
+		c.newKey = newKey
+	}
+}
+
+// WithAPIKey передаёт в каждом запросе API-ключ в заголовке X-API-Key.
+func WithAPIKey(key string) Option {
+	return func(c *Client) {
+		c.authHeader, c.authValue = "X-API-Key", key
+	}
+}
+
+// WithBearerToken передаёт в каждом запросе JWT в заголовке Authorization: Bearer.
+func WithBearerToken(token string) Option {
+	return func(c *Client) {
+		c.authHeader, c.authValue = "Authorization", "Bearer "+token
+	}
+}
+
+// New создаёт клиент; baseURL — адрес сервиса, например http://localhost:8080.
+func New(baseURL string, opts ...Option) *Client {
+	c := &Client{
+		baseURL:     strings.TrimRight(baseURL, "/") + "/api/v1",
+		httpClient:  http.DefaultClient,
+		timeout:     defaultTimeout,
+		maxAttempts: defaultMaxAttempts,
+		retryDelay:  defaultRetryDelay,
+		newKey:      uuid.NewString,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	c.maxAttempts = max(c.maxAttempts, 1)
+	return c
+}
+
+// OperationResult — итог пополнения или списания.
+type OperationResult struct {
+	Balance decimal.Decimal
+	// Fee — удержанная комиссия, ноль, если её не было.
+	Fee decimal.Decimal
+	// Created — кошелёк создан этим пополнением.
+	Created bool
+	// Replayed — ответ повторён сервисом по ключу идемпотентности: операция была проведена раньше.
+	Replayed bool
+	// IdempotencyKey — ключ, с которым отправлялся запрос.
+	IdempotencyKey string
+}
+
+// OperationOption — необязательный параметр Deposit и Withdraw.
+type OperationOption func(*walletRequest)
+
+// WithIdempotencyKey задаёт свой ключ идемпотентности вместо сгенерированного, например чтобы
+// безопасно повторить операцию после перезапуска вызывающего сервиса.
+func WithIdempotencyKey(key string) OperationOption {
+	return func(r *walletRequest) {
+		r.key = key
+	}
+}
+
+// WithCurrency задаёт ожидаемую валюту кошелька (ISO 4217); для кошелька, создаваемого
+// пополнением, — его валюту.
+func WithCurrency(currency string) OperationOption {
+	return func(r *walletRequest) {
+		r.Currency = currency
+	}
+}
+
+type walletRequest struct {
+	WalletID      uuid.UUID       `json:"walletId"`
+	OperationType string          `json:"operationType"`
+	Amount        decimal.Decimal `json:"amount"`
+	Currency      string          `json:"currency,omitempty"`
+	key           string
+}
+
+type balanceResponse struct {
+	Balance decimal.Decimal  `json:"balance"`
+	Fee     *decimal.Decimal `json:"fee"`
+}
+
+// Deposit пополняет кошелёк на amount.
+func (c *Client) Deposit(
+	ctx context.Context,
+	walletID uuid.UUID,
+	amount decimal.Decimal,
+	opts ...OperationOption,
+) (*OperationResult, error) {
+	return c.operation(ctx, "DEPOSIT", walletID, amount, opts)
+}
+
+// Withdraw списывает amount с кошелька. При нехватке средств возвращает *APIError с
+// ErrInsufficientFunds и заполненным AvailableCredit.
+func (c *Client) Withdraw(
+	ctx context.Context,
+	walletID uuid.UUID,
+	amount decimal.Decimal,
+	opts ...OperationOption,
+) (*OperationResult, error) {
+	return c.operation(ctx, "WITHDRAW", walletID, amount, opts)
+}
+
+func (c *Client) operation(
+	ctx context.Context,
+	operationType string,
+	walletID uuid.UUID,
+	amount decimal.Decimal,
+	opts []OperationOption,
+) (*OperationResult, error) {
+	req := walletRequest{WalletID: walletID, OperationType: operationType, Amount: amount}
+	for _, opt := range opts {
+		opt(&req)
+	}
+	if req.key == "" {
+		req.key = c.newKey()
+	}
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var resp balanceResponse
+	header, status, err := c.do(ctx, http.MethodPost, "/wallet", nil, body, req.key, &resp)
+	if err != nil {
+		return nil, err
+	}
+	result := &OperationResult{
+		Balance:        resp.Balance,
+		Created:        status == http.StatusCreated,
+		Replayed:       header.Get(idempotentReplayedHeader) == "true",
+		IdempotencyKey: req.key,
+	}
+	if resp.Fee != nil {
+		result.Fee = *resp.Fee
+	}
+	return result, nil
+}
+
+// GetBalance возвращает текущий баланс кошелька.
+func (c *Client) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
+	var resp balanceResponse
+	if _, _, err := c.do(ctx, http.MethodGet, "/wallets/"+walletID.String(), nil, nil, "", &resp); err != nil {
+		return decimal.Zero, err
+	}
+	return resp.Balance, nil
+}
+
+// ListTransactions возвращает страницу истории операций кошелька от новых к старым. Следующая
+// страница запрашивается с Cursor = NextCursor предыдущей; на последней NextCursor пуст.
+func (c *Client) ListTransactions(
+	ctx context.Context,
+	walletID uuid.UUID,
+	filter TransactionFilter,
+) (*TransactionPage, error) {
+	var page TransactionPage
+	path := "/wallets/" + walletID.String() + "/transactions"
+	if _, _, err := c.do(ctx, http.MethodGet, path, filter.query(), nil, "", &page); err != nil {
+		return nil, err
+	}
+	return &page, nil
+}
+
+// do отправляет запрос, повторяя его при ответе 503 и сетевых ошибках, и разбирает ответ 2xx в
+// out. Все попытки отправляются с одним ключом идемпотентности key.
+func (c *Client) do(
+	ctx context.Context,
+	method, path string,
+	query url.Values,
+	body []byte,
+	key string,
+	out any,
+) (http.Header, int, error) {
+	if _, ok := ctx.Deadline(); !ok && c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+	u := c.baseURL + path
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+
+	var lastErr error
+	for attempt := 1; ; attempt++ {
+		header, status, err := c.attempt(ctx, method, u, body, key, out)
+		if err == nil {
+			return header, status, nil
+		}
+		lastErr = err
+		if attempt >= c.maxAttempts || !retryable(ctx, err) {
+			return nil, 0, lastErr
+		}
+		timer := time.NewTimer(c.backoff(attempt))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return nil, 0, fmt.Errorf("%w (last error: %w)", ctx.Err(), lastErr)
+		case <-timer.C:
+		}
+	}
+}
+
+func (c *Client) attempt(
+	ctx context.Context,
+	method, u string,
+	body []byte,
+	key string,
+	out any,
+) (http.Header, int, error) {
+	var reader io.Reader
+	if body != nil {
+		reader = bytes.NewReader(body)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if key != "" {
+		req.Header.Set(idempotencyKeyHeader, key)
+	}
+	if c.authHeader != "" {
+		req.Header.Set(c.authHeader, c.authValue)
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, 0, err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, 0, newAPIError(resp.StatusCode, data)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return nil, 0, fmt.Errorf("decode %s %s response: %w", method, u, err)
+	}
+	return resp.Header, resp.StatusCode, nil
+}
+
+// retryable сообщает, стоит ли повторить запрос: сервис ответил 503 или запрос не дошёл до
+// ответа, а срок вызова ещё не истёк.
+func retryable(ctx context.Context, err error) bool {
+	if ctx.Err() != nil {
+		return false
+	}
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode == http.StatusServiceUnavailable
+	}
+	return true
+}
+
+// backoff — задержка перед попыткой attempt+1: retryDelay, 2·retryDelay, 4·retryDelay... с
+// разбросом от половины до полной величины, чтобы клиенты не повторяли запросы одновременно.
+func (c *Client) backoff(attempt int) time.Duration {
+	delay := c.retryDelay
+	for i := 1; i < attempt && delay < maxRetryDelay; i++ {
+		delay *= 2
+	}
+	delay = min(delay, maxRetryDelay)
+	if delay <= 0 {
+		return 0
+	}
+	half := delay / 2
+	return half + rand.N(delay-half+1)
+}
+
+// TransactionFilter — параметры ListTransactions; нулевые поля не ограничивают выборку.
+type TransactionFilter struct {
+	// OperationType — тип операции журнала, например DEPOSIT или WITHDRAW.
+	OperationType string
+	// MinAmount и MaxAmount сравниваются с модулем суммы операции.
+	MinAmount *decimal.Decimal
+	MaxAmount *decimal.Decimal
+	// From включительно, To — не включительно.
+	From *time.Time
+	To   *time.Time
+	// Limit — размер страницы от 1 до 200, 0 — по умолчанию сервиса (50).
+	Limit  int
+	Cursor string
+}
+
+func (f TransactionFilter) query() url.Values {
+	q := url.Values{}
+	if f.OperationType != "" {
+		q.Set("type", f.OperationType)
+	}
+	if f.MinAmount != nil {
+		q.Set("minAmount", f.MinAmount.String())
+	}
+	if f.MaxAmount != nil {
+		q.Set("maxAmount", f.MaxAmount.String())
+	}
+	if f.From != nil {
+		q.Set("from", f.From.Format(time.RFC3339Nano))
+	}
+	if f.To != nil {
+		q.Set("to", f.To.Format(time.RFC3339Nano))
+	}
+	if f.Limit > 0 {
+		q.Set("limit", strconv.Itoa(f.Limit))
+	}
+	if f.Cursor != "" {
+		q.Set("cursor", f.Cursor)
+	}
+	return q
+}
+
+type TransactionPage struct {
+	Transactions []Transaction `json:"transactions"`
+	NextCursor   string        `json:"nextCursor,omitempty"`
+}
+
+// Transaction — строка журнала операций кошелька.
+type Transaction struct {
+	ID             int64            `json:"id"`
+	WalletID       uuid.UUID        `json:"walletId"`
+	OperationType  string           `json:"operationType"`
+	Amount         decimal.Decimal  `json:"amount"`
+	BalanceAfter   decimal.Decimal  `json:"balanceAfter"`
+	EntryID        *int64           `json:"entryId,omitempty"`
+	TransferID     *uuid.UUID       `json:"transferId,omitempty"`
+	HoldID         *uuid.UUID       `json:"holdId,omitempty"`
+	ConversionID   *uuid.UUID       `json:"conversionId,omitempty"`
+	Rate           *decimal.Decimal `json:"rate,omitempty"`
+	RateTimestamp  *time.Time       `json:"rateTimestamp,omitempty"`
+	ReversalOf     *int64           `json:"reversalOf,omitempty"`
+	ReversedAmount decimal.Decimal  `json:"reversedAmount"`
+	CreatedAt      time.Time        `json:"createdAt"`
+	PrevHash       *string          `json:"prevHash,omitempty"`
+	Hash           string           `json:"hash"`
+}
@@ -0,0 +1,114 @@
+package walletclient
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+
+	"github.com/shopspring/decimal"
+)
+
+// Ошибки API. Тексты совпадают с текстами ошибок сервиса, *APIError сравнивается с ними через
+// errors.Is.
+var (
+	ErrWalletNotFound     = errors.New("wallet not found")
+	ErrWalletFrozen       = errors.New("wallet is frozen")
+	ErrWalletClosed       = errors.New("wallet is closed")
+	ErrInsufficientFunds  = errors.New("insufficient funds")
+	ErrLimitExceeded      = errors.New("spending limit exceeded")
+	ErrIdempotencyKeyUsed = errors.New("idempotency key already used with a different request")
+	ErrInvalidRequest     = errors.New("invalid request")
+	ErrUnauthorized       = errors.New("unauthorized")
+	ErrForbidden          = errors.New("forbidden")
+	ErrUnavailable        = errors.New("service unavailable")
+)
+
+// APIError — ответ сервиса с кодом не 2xx.
+type APIError struct {
+	StatusCode int
+	// Message — поле error ответа.
+	Message string
+	// Details — подробности ошибки проверки запроса.
+	Details string
+	// AvailableCredit — сколько ещё можно списать (ErrInsufficientFunds).
+	AvailableCredit *decimal.Decimal
+	// Limit, Max и Used — нарушенный лимит, его значение и израсходованная часть (ErrLimitExceeded).
+	Limit string
+	Max   *decimal.Decimal
+	Used  *decimal.Decimal
+
+	kind error
+}
+
+func (e *APIError) Error() string {
+	msg := fmt.Sprintf("wallet api: %d: %s", e.StatusCode, e.Message)
+	if e.Details != "" {
+		msg += ": " + e.Details
+	}
+	return msg
+}
+
+// Unwrap возвращает ошибку пакета, соответствующую ответу, или nil для неизвестных ошибок.
+func (e *APIError) Unwrap() error {
+	return e.kind
+}
+
+func newAPIError(status int, body []byte) *APIError {
+	var resp struct {
+		Error           string           `json:"error"`
+		Details         string           `json:"details"`
+		AvailableCredit *decimal.Decimal `json:"availableCredit"`
+		Limit           string           `json:"limit"`
+		Max             *decimal.Decimal `json:"max"`
+		Used            *decimal.Decimal `json:"used"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error == "" {
+		resp.Error = http.StatusText(status)
+	}
+	e := &APIError{
+		StatusCode:      status,
+		Message:         resp.Error,
+		Details:         resp.Details,
+		AvailableCredit: resp.AvailableCredit,
+		Limit:           resp.Limit,
+		Max:             resp.Max,
+		Used:            resp.Used,
+	}
+	e.kind = errorKind(e)
+	return e
+}
+
+// errorKind сопоставляет ответ ошибке пакета по статусу и тексту, как их формирует сервис.
+func errorKind(e *APIError) error {
+	switch e.StatusCode {
+	case http.StatusNotFound:
+		if e.Message == ErrWalletNotFound.Error() {
+			return ErrWalletNotFound
+		}
+	case http.StatusConflict:
+		if e.Message == ErrInsufficientFunds.Error() {
+			return ErrInsufficientFunds
+		}
+	case http.StatusLocked:
+		return ErrWalletFrozen
+	case http.StatusGone:
+		return ErrWalletClosed
+	case http.StatusUnprocessableEntity:
+		if e.Limit != "" {
+			return ErrLimitExceeded
+		}
+		if e.Message == ErrIdempotencyKeyUsed.Error() {
+			return ErrIdempotencyKeyUsed
+		}
+	case http.StatusBadRequest:
+		return ErrInvalidRequest
+	case http.StatusUnauthorized:
+		return ErrUnauthorized
+	case http.StatusForbidden:
+		return ErrForbidden
+	case http.StatusServiceUnavailable:
+		return ErrUnavailable
+	}
+	return nil
+}
@@ -0,0 +1,490 @@
+package test
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	walletv1 "test_wallet/api/wallet/v1"
+	"test_wallet/internal/auth"
+	"test_wallet/internal/handlers"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+	"test_wallet/internal/service"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+var testJWTSecret = []byte("test-secret-of-reasonable-length")
+
+func signToken(t *testing.T, method jwt.SigningMethod, key any, kid string, claims jwt.Claims) string {
+	t.Helper()
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+	return signed
+}
+
+func validClaims(subject string) jwt.RegisteredClaims {
+	return jwt.RegisteredClaims{
+		Subject:   subject,
+		Issuer:    "https://id.example.com",
+		Audience:  jwt.ClaimStrings{"wallet"},
+		IssuedAt:  jwt.NewNumericDate(time.Now()),
+		ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+	}
+}
+
+func bearer(token string) func(string) string {
+	return func(name string) string {
+		if name == "Authorization" {
+			return "Bearer " + token
+		}
+		return ""
+	}
+}
+
+func b64(b []byte) string {
+	return base64.RawURLEncoding.EncodeToString(b)
+}
+
+func TestAPIKeyAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	store := NewMockAPIKeyStore(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService,
+		handlers.WithAuthenticator(auth.NewAPIKeyAuthenticator(store)))
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	validKey, err := auth.GenerateAPIKey()
+	require.NoError(t, err)
+	revokedKey, _ := auth.GenerateAPIKey()
+	brokenKey, _ := auth.GenerateAPIKey()
+	keyID := uuid.New()
+	walletID := uuid.New()
+
+	store.EXPECT().GetAPIKeyByHash(gomock.Any(), auth.HashAPIKey(validKey)).
+		Return(models.APIKey{ID: keyID, Name: "billing"}, nil)
+	store.EXPECT().GetAPIKeyByHash(gomock.Any(), auth.HashAPIKey(revokedKey)).
+		Return(models.APIKey{}, repository.ErrAPIKeyNotFound)
+	store.EXPECT().GetAPIKeyByHash(gomock.Any(), auth.HashAPIKey(brokenKey)).
+		Return(models.APIKey{}, errors.New("connection refused"))
+	mockService.EXPECT().
+		GetWallet(gomock.Any(), walletID).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (models.Wallet, error) {
+			principal, ok := auth.FromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, "billing", principal.Subject)
+			assert.Equal(t, models.AuthMethodAPIKey, principal.Method)
+			assert.Equal(t, &keyID, principal.APIKeyID)
+			return models.Wallet{ID: walletID, Balance: decimal.NewFromInt(10)}, nil
+		})
+
+	cases := []struct {
+		key    string
+		status int
+		body   string
+	}{
+		{"", http.StatusUnauthorized, `{"error":"missing credentials"}`},
+		{validKey, http.StatusOK, `{"balance":"10","held":"0","available":"0"}`},
+		{revokedKey, http.StatusUnauthorized, `{"error":"invalid credentials"}`},
+		// Ключ без префикса отклоняется без запроса к БД
+		{"not-a-wallet-key", http.StatusUnauthorized, `{"error":"invalid credentials"}`},
+		{brokenKey, http.StatusServiceUnavailable, `{"error":"authentication unavailable"}`},
+	}
+	for _, tc := range cases {
+		req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String(), nil)
+		if tc.key != "" {
+			req.Header.Set(auth.APIKeyHeader, tc.key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.status, w.Code, tc.key)
+		assert.JSONEq(t, tc.body, w.Body.String(), tc.key)
+	}
+
+	// Без учётных данных запрос отклоняется до проверки тела и не доходит до сервиса
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	// Спецификация доступна без аутентификации
+	req, _ = http.NewRequest("GET", "/api/v1/openapi.json", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+}
+
+func TestJWTAuthentication(t *testing.T) {
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	otherRSAKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	edPublic, edPrivate, err := ed25519.GenerateKey(rand.Reader)
+	require.NoError(t, err)
+
+	jwks, err := json.Marshal(map[string]any{"keys": []map[string]string{
+		{"kty": "RSA", "kid": "rsa-1", "use": "sig", "n": b64(rsaKey.N.Bytes()), "e": b64(big.NewInt(int64(rsaKey.E)).Bytes())},
+		{"kty": "EC", "kid": "ec-1", "crv": "P-256", "x": b64(ecKey.X.FillBytes(make([]byte, 32))), "y": b64(ecKey.Y.FillBytes(make([]byte, 32)))},
+		{"kty": "OKP", "kid": "ed-1", "crv": "Ed25519", "x": b64(edPublic)},
+		// Ключ шифрования не используется для проверки подписи
+		{"kty": "RSA", "kid": "enc-1", "use": "enc", "n": b64(otherRSAKey.N.Bytes()), "e": "AQAB"},
+	}})
+	require.NoError(t, err)
+	jwksFile := filepath.Join(t.TempDir(), "jwks.json")
+	require.NoError(t, os.WriteFile(jwksFile, jwks, 0o600))
+	keys, err := auth.LoadJWKSFile(jwksFile)
+	require.NoError(t, err)
+	assert.Len(t, keys, 3)
+	keys = append(keys, auth.HMACKey(testJWTSecret))
+
+	authenticator := auth.NewJWTAuthenticator(keys,
+		auth.WithIssuer("https://id.example.com"),
+		auth.WithAudience("wallet"),
+	)
+	ctx := context.Background()
+
+	expired := validClaims("user-1")
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Hour))
+	noExpiry := validClaims("user-1")
+	noExpiry.ExpiresAt = nil
+	wrongIssuer := validClaims("user-1")
+	wrongIssuer.Issuer = "https://evil.example.com"
+	wrongAudience := validClaims("user-1")
+	wrongAudience.Audience = jwt.ClaimStrings{"billing"}
+	rsaPublicPEM := pem.EncodeToMemory(&pem.Block{Type: "RSA PUBLIC KEY", Bytes: x509.MarshalPKCS1PublicKey(&rsaKey.PublicKey)})
+	unsigned, err := jwt.NewWithClaims(jwt.SigningMethodNone, validClaims("user-1")).SignedString(jwt.UnsafeAllowNoneSignatureType)
+	require.NoError(t, err)
+
+	valid := map[string]string{
+		"HS256":        signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", validClaims("user-1")),
+		"RS256":        signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-1", validClaims("user-1")),
+		"RS256 no kid": signToken(t, jwt.SigningMethodRS256, rsaKey, "", validClaims("user-1")),
+		"ES256":        signToken(t, jwt.SigningMethodES256, ecKey, "ec-1", validClaims("user-1")),
+		"EdDSA":        signToken(t, jwt.SigningMethodEdDSA, edPrivate, "ed-1", validClaims("user-1")),
+	}
+	for name, token := range valid {
+		principal, err := authenticator.Authenticate(ctx, bearer(token))
+		require.NoError(t, err, name)
+		assert.Equal(t, models.Principal{Subject: "user-1", Method: models.AuthMethodJWT}, principal, name)
+	}
+
+	invalid := map[string]string{
+		"expired":         signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", expired),
+		"no exp":          signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", noExpiry),
+		"wrong issuer":    signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", wrongIssuer),
+		"wrong audience":  signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", wrongAudience),
+		"no sub":          signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", validClaims("")),
+		"wrong secret":    signToken(t, jwt.SigningMethodHS256, []byte("another-secret"), "", validClaims("user-1")),
+		"unknown kid":     signToken(t, jwt.SigningMethodRS256, rsaKey, "rsa-2", validClaims("user-1")),
+		"other rsa key":   signToken(t, jwt.SigningMethodRS256, otherRSAKey, "rsa-1", validClaims("user-1")),
+		"encryption key":  signToken(t, jwt.SigningMethodRS256, otherRSAKey, "enc-1", validClaims("user-1")),
+		"alg confusion":   signToken(t, jwt.SigningMethodHS256, rsaPublicPEM, "rsa-1", validClaims("user-1")),
+		"alg none":        unsigned,
+		"malformed token": "not.a.jwt",
+	}
+	for name, token := range invalid {
+		_, err := authenticator.Authenticate(ctx, bearer(token))
+		assert.ErrorIs(t, err, auth.ErrInvalidCredentials, name)
+	}
+
+	_, err = authenticator.Authenticate(ctx, func(string) string { return "" })
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+	_, err = authenticator.Authenticate(ctx, func(string) string { return "Basic dXNlcjpwYXNz" })
+	assert.ErrorIs(t, err, auth.ErrNoCredentials)
+}
+
+func TestAuthenticationChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	store := NewMockAPIKeyStore(ctrl)
+	chain := auth.Chain{
+		auth.NewAPIKeyAuthenticator(store),
+		auth.NewJWTAuthenticator(auth.KeySet{auth.HMACKey(testJWTSecret)}),
+	}
+	handler := handlers.NewWalletHTTPHandler(mockService, handlers.WithAuthenticator(chain))
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+	walletID := uuid.New()
+	token := signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", validClaims("user-1"))
+
+	mockService.EXPECT().
+		GetWallet(gomock.Any(), walletID).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (models.Wallet, error) {
+			principal, _ := auth.FromContext(ctx)
+			assert.Equal(t, models.AuthMethodJWT, principal.Method)
+			assert.Equal(t, "user-1", principal.Subject)
+			return models.Wallet{ID: walletID, Balance: decimal.NewFromInt(10)}, nil
+		})
+	store.EXPECT().GetAPIKeyByHash(gomock.Any(), gomock.Any()).Return(models.APIKey{}, repository.ErrAPIKeyNotFound)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	// Решает первый аутентификатор, нашедший свои учётные данные: неверный API-ключ не
+	// компенсируется верным токеном
+	req, _ = http.NewRequest("GET", "/api/v1/wallets/"+walletID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set(auth.APIKeyHeader, "wk_revoked")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/wallets/"+walletID.String(), nil)
+	req.Header.Set("Authorization", "Basic dXNlcjpwYXNz")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnauthorized, w.Code)
+	assert.JSONEq(t, `{"error":"missing credentials"}`, w.Body.String())
+}
+
+func TestAdminScopeAuthorization(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	store := NewMockAPIKeyStore(ctrl)
+	chain := auth.Chain{
+		auth.NewAPIKeyAuthenticator(store),
+		auth.NewJWTAuthenticator(auth.KeySet{auth.HMACKey(testJWTSecret)}),
+	}
+	handler := handlers.NewWalletHTTPHandler(mockService, handlers.WithAuthenticator(chain))
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+	walletID := uuid.New()
+
+	userKey, _ := auth.GenerateAPIKey()
+	adminKey, _ := auth.GenerateAPIKey()
+	store.EXPECT().GetAPIKeyByHash(gomock.Any(), auth.HashAPIKey(userKey)).
+		Return(models.APIKey{ID: uuid.New(), Name: "billing", Scopes: []string{}}, nil).AnyTimes()
+	store.EXPECT().GetAPIKeyByHash(gomock.Any(), auth.HashAPIKey(adminKey)).
+		Return(models.APIKey{ID: uuid.New(), Name: "ops", Scopes: []string{models.ScopeAdmin}}, nil).AnyTimes()
+	userClaims := struct {
+		jwt.RegisteredClaims
+		Scope string `json:"scope"`
+	}{validClaims("user-1"), "wallet:read wallet:write"}
+	adminClaims := userClaims
+	adminClaims.Scope = "wallet:read admin"
+	userToken := signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", userClaims)
+	adminToken := signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", adminClaims)
+
+	// Сервис вызывается только для вызывающих с правом admin
+	mockService.EXPECT().
+		SetWalletStatus(gomock.Any(), walletID, models.WalletFrozen, "").
+		Times(2).
+		Return(models.Wallet{ID: walletID, Status: models.WalletFrozen}, nil)
+	mockService.EXPECT().
+		ReverseTransaction(gomock.Any(), int64(5), (*decimal.Decimal)(nil)).
+		Times(2).
+		Return(models.ReversalResult{}, repository.ErrAlreadyReversed)
+	mockService.EXPECT().
+		GetTrialBalance(gomock.Any()).
+		Times(2).
+		Return(models.TrialBalance{Balanced: true}, nil)
+
+	requests := []struct {
+		method, path, body string
+		adminStatus        int
+	}{
+		{"POST", "/api/v1/admin/wallets/" + walletID.String() + "/freeze", "", http.StatusOK},
+		{"PUT", "/api/v1/admin/wallets/" + walletID.String() + "/overdraft", `{"limit": "1000000"}`, 0},
+		{"PUT", "/api/v1/admin/fee-schedules", `{}`, 0},
+		{"POST", "/api/v1/transactions/5/reverse", "", http.StatusConflict},
+		{"GET", "/api/v1/admin/ledger/trial-balance", "", http.StatusOK},
+	}
+	credentials := []struct {
+		name  string
+		set   func(*http.Request)
+		admin bool
+	}{
+		{"api key without scope", func(r *http.Request) { r.Header.Set(auth.APIKeyHeader, userKey) }, false},
+		{"jwt without scope", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+userToken) }, false},
+		{"admin api key", func(r *http.Request) { r.Header.Set(auth.APIKeyHeader, adminKey) }, true},
+		{"admin jwt", func(r *http.Request) { r.Header.Set("Authorization", "Bearer "+adminToken) }, true},
+	}
+	for _, cred := range credentials {
+		for _, rq := range requests {
+			if cred.admin && rq.adminStatus == 0 {
+				continue
+			}
+			req, _ := http.NewRequest(rq.method, rq.path, strings.NewReader(rq.body))
+			req.Header.Set("Content-Type", "application/json")
+			cred.set(req)
+			w := httptest.NewRecorder()
+			r.ServeHTTP(w, req)
+			if cred.admin {
+				assert.Equal(t, rq.adminStatus, w.Code, cred.name+" "+rq.path)
+				continue
+			}
+			assert.Equal(t, http.StatusForbidden, w.Code, cred.name+" "+rq.path)
+			assert.JSONEq(t, `{"error":"insufficient scope","required":"admin"}`, w.Body.String())
+		}
+	}
+}
+
+func TestGRPCAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	authenticator := auth.NewJWTAuthenticator(auth.KeySet{auth.HMACKey(testJWTSecret)})
+	client := newGRPCClient(t, mockService, grpc.UnaryInterceptor(handlers.UnaryAuthInterceptor(authenticator)))
+	walletID := uuid.New()
+	token := signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", validClaims("user-1"))
+
+	mockService.EXPECT().
+		GetBalance(gomock.Any(), walletID).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (decimal.Decimal, error) {
+			principal, ok := auth.FromContext(ctx)
+			assert.True(t, ok)
+			assert.Equal(t, "user-1", principal.Subject)
+			return decimal.NewFromInt(10), nil
+		})
+
+	_, err := client.GetBalance(context.Background(), &walletv1.GetBalanceRequest{WalletId: walletID.String()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer not.a.jwt")
+	_, err = client.GetBalance(ctx, &walletv1.GetBalanceRequest{WalletId: walletID.String()})
+	assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	assert.Equal(t, "invalid credentials", status.Convert(err).Message())
+
+	ctx = metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	resp, err := client.GetBalance(ctx, &walletv1.GetBalanceRequest{WalletId: walletID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, "10", resp.GetBalance())
+}
+
+func TestWalletOwnership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	walletID := uuid.New()
+	amount := decimal.NewFromInt(10)
+	ownerCtx := auth.NewContext(context.Background(), models.Principal{Subject: "user-1"})
+	strangerCtx := auth.NewContext(context.Background(), models.Principal{Subject: "user-2"})
+	adminCtx := auth.NewContext(context.Background(), models.Principal{Subject: "ops", Scopes: []string{models.ScopeAdmin}})
+
+	mockRepo.EXPECT().GetWallet(gomock.Any(), walletID).Return(models.Wallet{ID: walletID, OwnerID: "user-1"}, nil).AnyTimes()
+
+	// Списывают владелец, admin и вызывающий без аутентификации
+	mockRepo.EXPECT().
+		UpdateBalance(gomock.Any(), walletID, amount.Neg(), "WITHDRAW").
+		Times(3).
+		Return(decimal.NewFromInt(90), false, nil)
+	for _, ctx := range []context.Context{ownerCtx, adminCtx, context.Background()} {
+		_, err := svc.Withdraw(ctx, walletID, amount)
+		assert.NoError(t, err)
+	}
+	_, err := svc.Withdraw(strangerCtx, walletID, amount)
+	assert.ErrorIs(t, err, models.ErrWalletAccessDenied)
+
+	toID := uuid.New()
+	_, err = svc.Transfer(strangerCtx, walletID, toID, amount)
+	assert.ErrorIs(t, err, models.ErrWalletAccessDenied)
+
+	holdID := uuid.New()
+	mockRepo.EXPECT().GetHold(gomock.Any(), holdID).Return(models.Hold{ID: holdID, WalletID: walletID}, nil)
+	_, err = svc.CaptureHold(strangerCtx, holdID, nil)
+	assert.ErrorIs(t, err, models.ErrWalletAccessDenied)
+
+	// Кошелёк, созданный пополнением, достаётся вызывающему
+	newID := uuid.New()
+	mockRepo.EXPECT().GetWallet(gomock.Any(), newID).Return(models.Wallet{}, repository.ErrWalletNotFound)
+	mockRepo.EXPECT().
+		UpdateBalance(gomock.Any(), newID, amount, "DEPOSIT", gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, _ string, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			assert.Equal(t, "user-2", models.NewOperationOptions(opts...).Owner)
+			return amount, true, nil
+		})
+	_, created, err := svc.Deposit(strangerCtx, newID, amount)
+	assert.NoError(t, err)
+	assert.True(t, created)
+
+	_, err = svc.CreateWallet(strangerCtx, models.CreateWalletRequest{OwnerID: "user-1"})
+	assert.ErrorIs(t, err, models.ErrWalletAccessDenied)
+	mockRepo.EXPECT().
+		CreateWallet(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, wallet models.Wallet) (models.Wallet, error) {
+			assert.Equal(t, "user-2", wallet.OwnerID)
+			return wallet, nil
+		})
+	_, err = svc.CreateWallet(strangerCtx, models.CreateWalletRequest{})
+	assert.NoError(t, err)
+
+	mockRepo.EXPECT().
+		ListWallets(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, filter models.WalletFilter) ([]models.Wallet, error) {
+			assert.Equal(t, "user-2", filter.OwnerID)
+			return nil, nil
+		})
+	_, err = svc.ListWallets(strangerCtx, models.WalletFilter{})
+	assert.NoError(t, err)
+	_, err = svc.ListWallets(strangerCtx, models.WalletFilter{OwnerID: "user-1"})
+	assert.ErrorIs(t, err, models.ErrWalletAccessDenied)
+}
+
+func TestWalletOwnershipErrors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	authenticator := auth.NewJWTAuthenticator(auth.KeySet{auth.HMACKey(testJWTSecret)})
+	handler := handlers.NewWalletHTTPHandler(svc, handlers.WithAuthenticator(authenticator))
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+	client := newGRPCClient(t, svc, grpc.UnaryInterceptor(handlers.UnaryAuthInterceptor(authenticator)))
+	walletID := uuid.New()
+	token := signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", validClaims("user-2"))
+
+	mockRepo.EXPECT().GetWallet(gomock.Any(), walletID).Return(models.Wallet{ID: walletID, OwnerID: "user-1"}, nil).Times(2)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String(), nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusForbidden, w.Code)
+	assert.JSONEq(t, `{"error":"wallet belongs to another owner"}`, w.Body.String())
+
+	ctx := metadata.AppendToOutgoingContext(context.Background(), "authorization", "Bearer "+token)
+	_, err := client.GetBalance(ctx, &walletv1.GetBalanceRequest{WalletId: walletID.String()})
+	assert.Equal(t, codes.PermissionDenied, status.Code(err))
+}
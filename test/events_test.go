@@ -0,0 +1,156 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"test_wallet/internal/events"
+	"test_wallet/internal/models"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// publisherFunc — EventPublisher из функции.
+type publisherFunc func(ctx context.Context, event models.Event) error
+
+func (f publisherFunc) Publish(ctx context.Context, event models.Event) error {
+	return f(ctx, event)
+}
+
+func testEvent(walletID uuid.UUID, id int64) models.Event {
+	return models.Event{
+		ID:        id,
+		EventID:   uuid.New(),
+		Type:      models.EventBalanceChanged,
+		WalletID:  walletID,
+		Data:      json.RawMessage(`{"operation":"DEPOSIT","amount":"10"}`),
+		CreatedAt: utc("2024-03-01T12:00:00Z"),
+	}
+}
+
+func TestRelay_OrderPerWallet(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := NewMockOutboxStore(ctrl)
+	now := utc("2024-03-01T12:00:00Z")
+	first, second := uuid.New(), uuid.New()
+	pending := []models.Event{testEvent(first, 1), testEvent(first, 2), testEvent(second, 3)}
+	pending[0].Attempts = 2
+
+	var published []int64
+	publisher := publisherFunc(func(_ context.Context, event models.Event) error {
+		if event.ID == 1 {
+			return errors.New("broker unavailable")
+		}
+		published = append(published, event.ID)
+		return nil
+	})
+	relay := events.NewRelay(store, publisher, testLogger,
+		events.WithClock(func() time.Time { return now }),
+		events.WithRetryDelay(time.Second),
+	)
+
+	store.EXPECT().LockOutboxRelay(gomock.Any()).Return(func() {}, true, nil)
+	store.EXPECT().PendingEvents(gomock.Any(), now, events.DefaultBatchSize).Return(pending, nil)
+	// Третья попытка: задержка 4 секунды; второе событие кошелька ждёт первое
+	store.EXPECT().MarkEventFailed(gomock.Any(), int64(1), now.Add(4*time.Second), "broker unavailable").Return(nil)
+	store.EXPECT().MarkEventPublished(gomock.Any(), int64(3)).Return(nil)
+
+	n, err := relay.RelayPending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.Equal(t, []int64{3}, published)
+}
+
+func TestRelay_SkipsWhenLocked(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := NewMockOutboxStore(ctrl)
+	relay := events.NewRelay(store, publisherFunc(func(context.Context, models.Event) error {
+		t.Fatal("must not publish without the relay lock")
+		return nil
+	}), testLogger)
+
+	store.EXPECT().LockOutboxRelay(gomock.Any()).Return(nil, false, nil)
+	n, err := relay.RelayPending(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestMultiPublisher(t *testing.T) {
+	var calls int
+	ok := publisherFunc(func(context.Context, models.Event) error { calls++; return nil })
+	failing := publisherFunc(func(context.Context, models.Event) error { calls++; return errors.New("down") })
+
+	err := events.Multi(ok, failing, ok).Publish(context.Background(), testEvent(uuid.New(), 1))
+	assert.EqualError(t, err, "down")
+	assert.Equal(t, 3, calls)
+}
+
+func TestWriterPublisher(t *testing.T) {
+	var buf bytes.Buffer
+	publisher := events.NewWriterPublisher(&buf)
+	event := testEvent(uuid.New(), 1)
+	assert.NoError(t, publisher.Publish(context.Background(), event))
+	assert.NoError(t, publisher.Publish(context.Background(), event))
+
+	lines := bytes.Split(bytes.TrimSpace(buf.Bytes()), []byte("\n"))
+	assert.Len(t, lines, 2)
+	assert.JSONEq(t, `{"eventId":"`+event.EventID.String()+`","type":"wallet.balance_changed",
+		"walletId":"`+event.WalletID.String()+`","occurredAt":"2024-03-01T12:00:00Z",
+		"data":{"operation":"DEPOSIT","amount":"10"}}`, string(lines[0]))
+}
+
+func TestFilePublisher(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "events.ndjson")
+	publisher, err := events.NewFilePublisher(path)
+	assert.NoError(t, err)
+	first, second := testEvent(uuid.New(), 1), testEvent(uuid.New(), 2)
+	assert.NoError(t, publisher.Publish(context.Background(), first))
+	assert.NoError(t, publisher.Publish(context.Background(), second))
+	assert.NoError(t, publisher.Close())
+
+	file, err := os.Open(path)
+	assert.NoError(t, err)
+	defer file.Close()
+	var ids []uuid.UUID
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var event models.Event
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		ids = append(ids, event.EventID)
+	}
+	assert.Equal(t, []uuid.UUID{first.EventID, second.EventID}, ids)
+}
+
+func TestHTTPPublisher(t *testing.T) {
+	event := testEvent(uuid.New(), 1)
+	status := http.StatusAccepted
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, http.MethodPost, r.Method)
+		assert.Equal(t, event.EventID.String(), r.Header.Get(events.EventIDHeader))
+		body, _ := io.ReadAll(r.Body)
+		var received models.Event
+		assert.NoError(t, json.Unmarshal(body, &received))
+		assert.Equal(t, event.WalletID, received.WalletID)
+		w.WriteHeader(status)
+	}))
+	defer srv.Close()
+
+	publisher := events.NewHTTPPublisher(srv.URL, nil)
+	assert.NoError(t, publisher.Publish(context.Background(), event))
+
+	status = http.StatusInternalServerError
+	assert.ErrorContains(t, publisher.Publish(context.Background(), event), "unexpected status 500")
+}
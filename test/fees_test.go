@@ -0,0 +1,98 @@
+package test
+
+import (
+	"context"
+	"test_wallet/internal/models"
+	"test_wallet/internal/service"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func dec(s string) *decimal.Decimal {
+	d := decimal.RequireFromString(s)
+	return &d
+}
+
+func TestFeeSchedule_Compute(t *testing.T) {
+	schedule := models.FeeSchedule{
+		Operation: "WITHDRAW",
+		Currency:  "RUB",
+		Flat:      decimal.NewFromInt(10),
+		Percent:   decimal.RequireFromString("1.5"),
+		MinFee:    dec("15"),
+		MaxFee:    dec("500"),
+	}
+	cases := []struct{ amount, fee string }{
+		{"100", "15"},        // 10 + 1.5 < минимума
+		{"1000", "25"},       // 10 + 15
+		{"333.33", "15"},     // 10 + 4.99995 → минимум
+		{"1234.57", "28.52"}, // 10 + 18.51855 → округление до копеек
+		{"100000", "500"},    // максимум
+	}
+	for _, c := range cases {
+		fee, err := schedule.Compute(decimal.RequireFromString(c.amount))
+		assert.NoError(t, err)
+		assert.True(t, fee.Equal(decimal.RequireFromString(c.fee)), "amount %s: got %s", c.amount, fee)
+	}
+
+	schedule.Tiers = []models.FeeTier{
+		{UpTo: dec("1000"), Percent: decimal.NewFromInt(2)},
+		{UpTo: dec("10000"), Flat: decimal.NewFromInt(5), Percent: decimal.NewFromInt(1)},
+		{Flat: decimal.NewFromInt(50)},
+	}
+	schedule.MinFee, schedule.MaxFee = nil, nil
+	for _, c := range []struct{ amount, fee string }{{"1000", "20"}, {"1000.01", "15"}, {"50000", "50"}} {
+		fee, err := schedule.Compute(decimal.RequireFromString(c.amount))
+		assert.NoError(t, err)
+		assert.True(t, fee.Equal(decimal.RequireFromString(c.fee)), "amount %s: got %s", c.amount, fee)
+	}
+}
+
+func TestFeeSchedule_Validate(t *testing.T) {
+	valid := models.FeeSchedule{Operation: "DEPOSIT", Currency: "USD", Percent: decimal.NewFromInt(1)}
+	assert.NoError(t, valid.Validate())
+
+	invalid := []models.FeeSchedule{
+		{Operation: "DEPOSIT", Currency: "USD", Percent: decimal.NewFromInt(101)},
+		{Operation: "DEPOSIT", Currency: "USD", Flat: decimal.NewFromInt(-1)},
+		{Operation: "DEPOSIT", Currency: "USD", MinFee: dec("10"), MaxFee: dec("5")},
+		{Operation: "DEPOSIT", Currency: "USD", Tiers: []models.FeeTier{{}, {UpTo: dec("10")}}},
+		{Operation: "DEPOSIT", Currency: "USD", Tiers: []models.FeeTier{{UpTo: dec("10")}, {UpTo: dec("10")}}},
+	}
+	for _, s := range invalid {
+		assert.ErrorIs(t, s.Validate(), models.ErrInvalidFeeSchedule)
+	}
+	assert.ErrorIs(t, models.FeeSchedule{Operation: "DEPOSIT", Currency: "USD", Flat: decimal.RequireFromString("0.001")}.Validate(),
+		models.ErrInvalidAmountScale)
+}
+
+func TestQuoteFee(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	walletID := uuid.New()
+	mockRepo.EXPECT().GetWallet(gomock.Any(), walletID).Return(models.Wallet{ID: walletID, Currency: "USD"}, nil)
+	mockRepo.EXPECT().QuoteFee(gomock.Any(), "WITHDRAW", "USD", decimal.NewFromInt(100)).Return(decimal.NewFromInt(2), nil)
+
+	quote, err := service.NewWalletService(mockRepo, testLogger).QuoteFee(context.Background(), models.FeeQuoteRequest{
+		WalletID:      &walletID,
+		OperationType: "WITHDRAW",
+		Amount:        decimal.NewFromInt(100),
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "USD", quote.Currency)
+	assert.True(t, quote.Fee.Equal(decimal.NewFromInt(2)))
+	assert.True(t, quote.BalanceChange.Equal(decimal.NewFromInt(-102)))
+
+	_, err = service.NewWalletService(mockRepo, testLogger).QuoteFee(context.Background(), models.FeeQuoteRequest{
+		OperationType: "DEPOSIT",
+		Amount:        decimal.RequireFromString("1.001"),
+		Currency:      "USD",
+	})
+	assert.ErrorIs(t, err, models.ErrInvalidAmountScale)
+}
@@ -0,0 +1,223 @@
+package test
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	walletv1 "test_wallet/api/wallet/v1"
+	"test_wallet/internal/handlers"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/grpc/test/bufconn"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// newGRPCClient поднимает WalletGRPCServer в памяти и возвращает клиент к нему.
+func newGRPCClient(t *testing.T, service handlers.WalletOperations, opts ...grpc.ServerOption) walletv1.WalletServiceClient {
+	listener := bufconn.Listen(1 << 20)
+	server := grpc.NewServer(opts...)
+	handlers.NewWalletGRPCServer(service).Register(server)
+	go func() { _ = server.Serve(listener) }()
+	t.Cleanup(server.Stop)
+
+	conn, err := grpc.NewClient("passthrough:///bufnet",
+		grpc.WithContextDialer(func(ctx context.Context, _ string) (net.Conn, error) {
+			return listener.DialContext(ctx)
+		}),
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+	)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = conn.Close() })
+	return walletv1.NewWalletServiceClient(conn)
+}
+
+func TestGRPCDeposit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletOperations(ctrl)
+	client := newGRPCClient(t, mockService)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.RequireFromString("100.50"), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			var o models.OperationOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			assert.Equal(t, "RUB", o.Currency)
+			assert.Equal(t, "dep-1", o.Idempotency.Key)
+			*o.Fee = decimal.NewFromInt(1)
+			return decimal.RequireFromString("99.50"), true, nil
+		})
+	resp, err := client.Deposit(metadata.AppendToOutgoingContext(ctx, handlers.IdempotencyKeyMetadata, "dep-1"),
+		&walletv1.DepositRequest{WalletId: walletID.String(), Amount: "100.50", Currency: "RUB"})
+	require.NoError(t, err)
+	assert.Equal(t, "99.5", resp.GetBalance())
+	assert.Equal(t, "1", resp.GetFee())
+	assert.True(t, resp.GetCreated())
+
+	// Повтор по ключу идемпотентности отдаёт сохранённый ответ, в том числе записанный HTTP API
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(10), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			var o models.OperationOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			o.Idempotency.Replayed = &models.IdempotentResponse{StatusCode: 201, Body: []byte(`{"balance":"10"}`)}
+			return decimal.Zero, false, nil
+		})
+	var header metadata.MD
+	resp, err = client.Deposit(ctx, &walletv1.DepositRequest{WalletId: walletID.String(), Amount: "10", RequestId: "dep-2"},
+		grpc.Header(&header))
+	require.NoError(t, err)
+	assert.Equal(t, "10", resp.GetBalance())
+	assert.True(t, resp.GetCreated())
+	assert.Equal(t, []string{"true"}, header.Get(handlers.IdempotentReplayedMetadata))
+
+	_, err = client.Deposit(ctx, &walletv1.DepositRequest{WalletId: "not-a-uuid", Amount: "10"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Deposit(ctx, &walletv1.DepositRequest{WalletId: walletID.String(), Amount: "-5"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.Deposit(ctx, &walletv1.DepositRequest{WalletId: walletID.String(), Amount: "5", Currency: "XXX"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestGRPCWithdraw_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletOperations(ctrl)
+	client := newGRPCClient(t, mockService)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(500), gomock.Any()).
+		Return(decimal.NewFromInt(100), &repository.InsufficientFundsError{AvailableCredit: decimal.NewFromInt(150)})
+	_, err := client.Withdraw(ctx, &walletv1.WithdrawRequest{WalletId: walletID.String(), Amount: "500"})
+	st := status.Convert(err)
+	assert.Equal(t, codes.FailedPrecondition, st.Code())
+	if assert.Len(t, st.Details(), 1) {
+		info := st.Details()[0].(*errdetails.ErrorInfo)
+		assert.Equal(t, "INSUFFICIENT_FUNDS", info.GetReason())
+		assert.Equal(t, "150", info.GetMetadata()["availableCredit"])
+	}
+
+	cases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{repository.ErrWalletNotFound, codes.NotFound},
+		{repository.ErrWalletFrozen, codes.FailedPrecondition},
+		{repository.ErrWalletClosed, codes.FailedPrecondition},
+		{repository.ErrIdempotencyKeyUsed, codes.FailedPrecondition},
+		{models.ErrInvalidAmountScale, codes.FailedPrecondition},
+		{repository.ErrInvalidAmount, codes.InvalidArgument},
+		{context.DeadlineExceeded, codes.DeadlineExceeded},
+		{assert.AnError, codes.Unavailable},
+	}
+	for _, tc := range cases {
+		mockService.EXPECT().
+			Withdraw(gomock.Any(), walletID, decimal.NewFromInt(1), gomock.Any()).
+			Return(decimal.Zero, tc.err)
+		_, err := client.Withdraw(ctx, &walletv1.WithdrawRequest{WalletId: walletID.String(), Amount: "1"})
+		assert.Equal(t, tc.code, status.Code(err), tc.err.Error())
+	}
+}
+
+func TestGRPCGetBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletOperations(ctrl)
+	client := newGRPCClient(t, mockService)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	mockService.EXPECT().GetBalance(gomock.Any(), walletID).Return(decimal.NewFromInt(42), nil)
+	resp, err := client.GetBalance(ctx, &walletv1.GetBalanceRequest{WalletId: walletID.String()})
+	require.NoError(t, err)
+	assert.Equal(t, "42", resp.GetBalance())
+
+	mockService.EXPECT().GetBalance(gomock.Any(), walletID).Return(decimal.Zero, repository.ErrWalletNotFound)
+	_, err = client.GetBalance(ctx, &walletv1.GetBalanceRequest{WalletId: walletID.String()})
+	assert.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestGRPCListTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletOperations(ctrl)
+	client := newGRPCClient(t, mockService)
+	ctx := context.Background()
+	walletID := uuid.New()
+	from := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	cursor := models.TransactionCursor{CreatedAt: from.Add(time.Hour), ID: 7}
+
+	transferID := uuid.New()
+	entryID := int64(3)
+	mockService.EXPECT().
+		GetTransactions(gomock.Any(), walletID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, filter models.TransactionFilter) (models.TransactionPage, error) {
+			assert.Equal(t, "TRANSFER_IN", filter.OperationType)
+			assert.True(t, filter.MinAmount.Equal(decimal.NewFromInt(10)))
+			assert.Nil(t, filter.MaxAmount)
+			assert.True(t, filter.From.Equal(from))
+			assert.Nil(t, filter.To)
+			assert.Equal(t, &cursor, filter.Cursor)
+			assert.Equal(t, 20, filter.Limit)
+			return models.TransactionPage{
+				Transactions: []models.Transaction{{
+					ID:           5,
+					WalletID:     walletID,
+					Type:         "TRANSFER_IN",
+					Amount:       decimal.NewFromInt(15),
+					BalanceAfter: decimal.NewFromInt(115),
+					EntryID:      &entryID,
+					TransferID:   &transferID,
+					CreatedAt:    from,
+					Hash:         "abc",
+				}},
+				NextCursor: "next",
+			}, nil
+		})
+	resp, err := client.ListTransactions(ctx, &walletv1.ListTransactionsRequest{
+		WalletId:      walletID.String(),
+		OperationType: "TRANSFER_IN",
+		MinAmount:     "10",
+		From:          timestamppb.New(from),
+		PageSize:      20,
+		PageToken:     cursor.Encode(),
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "next", resp.GetNextPageToken())
+	if assert.Len(t, resp.GetTransactions(), 1) {
+		tx := resp.GetTransactions()[0]
+		assert.Equal(t, int64(5), tx.GetId())
+		assert.Equal(t, "115", tx.GetBalanceAfter())
+		assert.Equal(t, int64(3), tx.GetEntryId())
+		assert.Equal(t, transferID.String(), tx.GetTransferId())
+		assert.Empty(t, tx.GetHoldId())
+		assert.True(t, tx.GetCreatedAt().AsTime().Equal(from))
+	}
+
+	_, err = client.ListTransactions(ctx, &walletv1.ListTransactionsRequest{WalletId: walletID.String(), PageSize: 1000})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	_, err = client.ListTransactions(ctx, &walletv1.ListTransactionsRequest{WalletId: walletID.String(), OperationType: "BOGUS"})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
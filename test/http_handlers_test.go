@@ -2,11 +2,16 @@ package test
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"fmt"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"test_wallet/internal/handlers"
+	"test_wallet/internal/models"
 	"testing"
+	"time"
 
 	"test_wallet/internal/repository"
 
@@ -27,7 +32,7 @@ func TestHandleWalletOperation_Deposit_Success(t *testing.T) {
 
 	walletID := uuid.New()
 	mockService.EXPECT().
-		Deposit(gomock.Any(), walletID, decimal.NewFromInt(100)).
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(100), gomock.Any()).
 		Return(decimal.NewFromInt(200), false, nil)
 
 	body, _ := json.Marshal(map[string]interface{}{
@@ -55,7 +60,7 @@ func TestHandleWalletOperation_Withdraw_InsufficientFunds(t *testing.T) {
 
 	walletID := uuid.New()
 	mockService.EXPECT().
-		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(100)).
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(100), gomock.Any()).
 		Return(decimal.NewFromInt(0), repository.ErrInsufficientFunds)
 
 	body, _ := json.Marshal(map[string]interface{}{
@@ -91,6 +96,38 @@ func TestHandleWalletOperation_InvalidRequest(t *testing.T) {
 	assert.Contains(t, w.Body.String(), "invalid request")
 }
 
+func TestHandleWalletOperation_Currency(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(100), gomock.Any(), gomock.Any()).
+		Return(decimal.Zero, false, repository.ErrCurrencyMismatch)
+
+	for currency, status := range map[string]int{
+		"USD": http.StatusUnprocessableEntity,
+		"XXX": http.StatusBadRequest,
+	} {
+		body, _ := json.Marshal(map[string]interface{}{
+			"walletId":      walletID,
+			"operationType": "DEPOSIT",
+			"amount":        "100",
+			"currency":      currency,
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, status, w.Code, currency)
+	}
+}
+
 func TestHandleGetBalance_Success(t *testing.T) {
 	ctrl := gomock.NewController(t)
 	defer ctrl.Finish()
@@ -101,15 +138,20 @@ func TestHandleGetBalance_Success(t *testing.T) {
 
 	walletID := uuid.New()
 	mockService.EXPECT().
-		GetBalance(gomock.Any(), walletID).
-		Return(decimal.NewFromInt(500), nil)
+		GetWallet(gomock.Any(), walletID).
+		Return(models.Wallet{
+			ID:        walletID,
+			Balance:   decimal.NewFromInt(500),
+			Held:      decimal.NewFromInt(120),
+			Available: decimal.NewFromInt(380),
+		}, nil)
 
 	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String(), nil)
 	w := httptest.NewRecorder()
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
-	assert.Contains(t, w.Body.String(), "500")
+	assert.JSONEq(t, `{"balance":"500","held":"120","available":"380"}`, w.Body.String())
 }
 
 func TestHandleGetBalance_NotFound(t *testing.T) {
@@ -122,8 +164,8 @@ func TestHandleGetBalance_NotFound(t *testing.T) {
 
 	walletID := uuid.New()
 	mockService.EXPECT().
-		GetBalance(gomock.Any(), walletID).
-		Return(decimal.Zero, repository.ErrWalletNotFound)
+		GetWallet(gomock.Any(), walletID).
+		Return(models.Wallet{}, repository.ErrWalletNotFound)
 
 	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String(), nil)
 	w := httptest.NewRecorder()
@@ -148,3 +190,1048 @@ func TestHandleGetBalance_InvalidUUID(t *testing.T) {
 	assert.Equal(t, http.StatusBadRequest, w.Code)
 	assert.Contains(t, w.Body.String(), "invalid wallet_id")
 }
+
+func TestHandleGetTransactions_Filters(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	from := time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+	minAmount := decimal.NewFromInt(10)
+	cursor := models.TransactionCursor{CreatedAt: from.Add(time.Hour), ID: 42}
+	mockService.EXPECT().
+		GetTransactions(gomock.Any(), walletID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, filter models.TransactionFilter) (models.TransactionPage, error) {
+			assert.Equal(t, "WITHDRAW", filter.OperationType)
+			assert.True(t, filter.MinAmount.Equal(minAmount))
+			assert.Nil(t, filter.MaxAmount)
+			assert.True(t, filter.From.Equal(from))
+			assert.Equal(t, int64(42), filter.Cursor.ID)
+			assert.Equal(t, 10, filter.Limit)
+			return models.TransactionPage{
+				Transactions: []models.Transaction{{ID: 41, WalletID: walletID, Type: "WITHDRAW", Amount: decimal.NewFromInt(-15)}},
+				NextCursor:   "next",
+			}, nil
+		})
+
+	url := "/api/v1/wallets/" + walletID.String() + "/transactions?type=WITHDRAW&minAmount=10&from=" +
+		from.Format(time.RFC3339) + "&cursor=" + cursor.Encode() + "&limit=10"
+	req, _ := http.NewRequest("GET", url, nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"nextCursor":"next"`)
+	assert.Contains(t, w.Body.String(), `"amount":"-15"`)
+}
+
+func TestHandleGetTransactions_InvalidQuery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	for _, query := range []string{"type=TRANSFER", "minAmount=abc", "from=yesterday", "cursor=!!!", "limit=0"} {
+		req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/transactions?"+query, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestHandleGetTransactions_NotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	mockService.EXPECT().
+		GetTransactions(gomock.Any(), walletID, gomock.Any()).
+		Return(models.TransactionPage{}, repository.ErrWalletNotFound)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/transactions", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleWalletOperation_IdempotencyKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	var stored *models.IdempotentResponse
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(100), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			idem := models.NewOperationOptions(opts...).Idempotency
+			assert.Equal(t, "key-1", idem.Key)
+			if stored != nil {
+				idem.Replayed = stored
+				return decimal.Zero, false, nil
+			}
+			resp := idem.Response(models.OperationResult{Balance: decimal.NewFromInt(100), Created: true})
+			stored = &resp
+			return decimal.NewFromInt(100), true, nil
+		}).Times(2)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"walletId":      walletID,
+		"operationType": "DEPOSIT",
+		"amount":        "100",
+	})
+	var responses []*httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(handlers.IdempotencyKeyHeader, "key-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		responses = append(responses, w)
+	}
+
+	assert.Equal(t, http.StatusCreated, responses[0].Code)
+	assert.Equal(t, http.StatusCreated, responses[1].Code)
+	assert.JSONEq(t, responses[0].Body.String(), responses[1].Body.String())
+	assert.Empty(t, responses[0].Header().Get(handlers.IdempotentReplayedHeader))
+	assert.Equal(t, "true", responses[1].Header().Get(handlers.IdempotentReplayedHeader))
+}
+
+func TestHandleWalletOperation_IdempotencyKeyReused(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(100), gomock.Any(), gomock.Any()).
+		Return(decimal.Zero, repository.ErrIdempotencyKeyUsed)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"walletId":      walletID,
+		"operationType": "WITHDRAW",
+		"amount":        "100",
+		"requestId":     "key-2",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+}
+
+func TestHandleWalletOperation_IdempotencyKeyConflict(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"walletId":      uuid.New(),
+		"operationType": "DEPOSIT",
+		"amount":        "100",
+		"requestId":     "key-a",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IdempotencyKeyHeader, "key-b")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleTransfer_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	fromID, toID := uuid.New(), uuid.New()
+	mockService.EXPECT().
+		Transfer(gomock.Any(), fromID, toID, decimal.NewFromInt(25)).
+		Return(models.TransferResult{
+			ID:           uuid.New(),
+			FromWalletID: fromID,
+			ToWalletID:   toID,
+			Amount:       decimal.NewFromInt(25),
+			FromBalance:  decimal.NewFromInt(75),
+			ToBalance:    decimal.NewFromInt(25),
+		}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"fromWalletId": fromID,
+		"toWalletId":   toID,
+		"amount":       "25",
+	})
+	req, _ := http.NewRequest("POST", "/api/v1/transfers", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"fromBalance":"75"`)
+	assert.Contains(t, w.Body.String(), `"toBalance":"25"`)
+}
+
+func TestHandleTransfer_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	fromID, toID := uuid.New(), uuid.New()
+	mockService.EXPECT().
+		Transfer(gomock.Any(), fromID, toID, decimal.NewFromInt(500)).
+		Return(models.TransferResult{}, repository.ErrInsufficientFunds)
+	mockService.EXPECT().
+		Transfer(gomock.Any(), fromID, fromID, decimal.NewFromInt(500)).
+		Return(models.TransferResult{}, repository.ErrSameWallet)
+
+	cases := []struct {
+		to     uuid.UUID
+		status int
+	}{
+		{toID, http.StatusConflict},
+		{fromID, http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		body, _ := json.Marshal(map[string]interface{}{
+			"fromWalletId": fromID,
+			"toWalletId":   tc.to,
+			"amount":       "500",
+		})
+		req, _ := http.NewRequest("POST", "/api/v1/transfers", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, tc.status, w.Code)
+	}
+}
+
+func TestHandleGetTrialBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	mockService.EXPECT().
+		GetTrialBalance(gomock.Any()).
+		Return(models.TrialBalance{
+			Accounts: []models.AccountBalance{
+				{AccountID: models.SystemAccountID(models.SystemExternalCashIn, "RUB"), Currency: "RUB", Balance: decimal.NewFromInt(-100)},
+				{AccountID: models.WalletAccountID(uuid.New()), Currency: "RUB", Balance: decimal.NewFromInt(100)},
+			},
+			Totals:            map[string]decimal.Decimal{"RUB": decimal.Zero},
+			UnbalancedEntries: []int64{},
+			Balanced:          true,
+		}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/admin/ledger/trial-balance", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"balanced":true`)
+	assert.Contains(t, w.Body.String(), "system:external_cash_in:RUB")
+	assert.Contains(t, w.Body.String(), `"totals":{"RUB":"0"}`)
+}
+
+func TestHandleHolds(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID, holdID := uuid.New(), uuid.New()
+	mockService.EXPECT().
+		CreateHold(gomock.Any(), walletID, decimal.NewFromInt(30), 10*time.Minute).
+		Return(models.HoldResult{
+			Hold:   models.Hold{ID: holdID, WalletID: walletID, Amount: decimal.NewFromInt(30), Status: models.HoldActive},
+			Wallet: models.Wallet{ID: walletID, Balance: decimal.NewFromInt(100), Held: decimal.NewFromInt(30), Available: decimal.NewFromInt(70)},
+		}, nil)
+	captureAmount := decimal.NewFromInt(20)
+	mockService.EXPECT().
+		CaptureHold(gomock.Any(), holdID, &captureAmount).
+		Return(models.HoldResult{}, repository.ErrCaptureExceedHold)
+	mockService.EXPECT().
+		VoidHold(gomock.Any(), holdID).
+		Return(models.HoldResult{}, repository.ErrHoldNotActive)
+
+	body := []byte(`{"amount": "30", "ttlSeconds": 600}`)
+	req, _ := http.NewRequest("POST", "/api/v1/wallets/"+walletID.String()+"/holds", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"available":"70"`)
+
+	body = []byte(`{"amount": "20"}`)
+	req, _ = http.NewRequest("POST", "/api/v1/holds/"+holdID.String()+"/capture", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+
+	req, _ = http.NewRequest("POST", "/api/v1/holds/"+holdID.String()+"/void", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestHandleCreateHold_IdempotencyKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID, holdID := uuid.New(), uuid.New()
+	var stored *models.IdempotentResponse
+	mockService.EXPECT().
+		CreateHold(gomock.Any(), walletID, decimal.NewFromInt(30), time.Duration(0), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, _ time.Duration, opts ...models.OperationOption) (models.HoldResult, error) {
+			idem := models.NewOperationOptions(opts...).Idempotency
+			assert.Equal(t, "hold-1", idem.Key)
+			if stored != nil {
+				idem.Replayed = stored
+				return models.HoldResult{}, nil
+			}
+			result := models.HoldResult{
+				Hold:   models.Hold{ID: holdID, WalletID: walletID, Amount: decimal.NewFromInt(30), Status: models.HoldActive},
+				Wallet: models.Wallet{ID: walletID, Balance: decimal.NewFromInt(100), Held: decimal.NewFromInt(30), Available: decimal.NewFromInt(70)},
+			}
+			resp := idem.Response(models.OperationResult{Hold: &result})
+			stored = &resp
+			return result, nil
+		}).Times(2)
+
+	var responses []*httptest.ResponseRecorder
+	for i := 0; i < 2; i++ {
+		req, _ := http.NewRequest("POST", "/api/v1/wallets/"+walletID.String()+"/holds", bytes.NewBufferString(`{"amount": "30"}`))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(handlers.IdempotencyKeyHeader, "hold-1")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		responses = append(responses, w)
+	}
+
+	assert.Equal(t, http.StatusCreated, responses[0].Code)
+	assert.Equal(t, http.StatusCreated, responses[1].Code)
+	assert.JSONEq(t, responses[0].Body.String(), responses[1].Body.String())
+	assert.Contains(t, responses[1].Body.String(), holdID.String())
+	assert.Equal(t, "true", responses[1].Header().Get(handlers.IdempotentReplayedHeader))
+
+	// Ключ в заголовке и requestId в теле должны совпадать
+	req, _ := http.NewRequest("POST", "/api/v1/wallets/"+walletID.String()+"/holds",
+		bytes.NewBufferString(`{"amount": "30", "requestId": "hold-2"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IdempotencyKeyHeader, "hold-1")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleConversion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	fromID, toID, quoteID := uuid.New(), uuid.New(), uuid.New()
+	quote := models.ConversionQuote{
+		ID: quoteID, FromWalletID: fromID, ToWalletID: toID,
+		FromCurrency: "USD", ToCurrency: "RUB",
+		FromAmount: decimal.NewFromInt(10), ToAmount: decimal.NewFromInt(925),
+		Rate: decimal.RequireFromString("92.5"),
+	}
+	mockService.EXPECT().
+		QuoteConversion(gomock.Any(), fromID, toID, decimal.NewFromInt(10)).
+		Return(quote, nil)
+	mockService.EXPECT().
+		ExecuteConversion(gomock.Any(), quoteID).
+		Return(models.ConversionResult{Quote: quote, FromBalance: decimal.NewFromInt(90), ToBalance: decimal.NewFromInt(925)}, nil)
+	mockService.EXPECT().
+		ExecuteConversion(gomock.Any(), quoteID).
+		Return(models.ConversionResult{}, repository.ErrQuoteExecuted)
+
+	body, _ := json.Marshal(map[string]interface{}{"fromWalletId": fromID, "toWalletId": toID, "amount": "10"})
+	req, _ := http.NewRequest("POST", "/api/v1/conversions/quotes", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), quoteID.String())
+
+	body, _ = json.Marshal(map[string]interface{}{"quoteId": quoteID})
+	for _, status := range []int{http.StatusOK, http.StatusConflict} {
+		req, _ = http.NewRequest("POST", "/api/v1/conversions", bytes.NewBuffer(body))
+		req.Header.Set("Content-Type", "application/json")
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code)
+	}
+
+	req, _ = http.NewRequest("POST", "/api/v1/conversions", bytes.NewBufferString(`{}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleReverseTransaction(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	reversalOf := int64(5)
+	mockService.EXPECT().
+		ReverseTransaction(gomock.Any(), int64(5), (*decimal.Decimal)(nil)).
+		Return(models.ReversalResult{
+			Reversal: models.Transaction{ID: 9, Type: "REVERSAL", Amount: decimal.NewFromInt(-100), ReversalOf: &reversalOf},
+			Original: models.Transaction{ID: 5, Type: "DEPOSIT", Amount: decimal.NewFromInt(100), ReversedAmount: decimal.NewFromInt(100)},
+		}, nil)
+	mockService.EXPECT().
+		ReverseTransaction(gomock.Any(), int64(5), (*decimal.Decimal)(nil)).
+		Return(models.ReversalResult{}, repository.ErrAlreadyReversed)
+
+	for _, status := range []int{http.StatusCreated, http.StatusConflict} {
+		req, _ := http.NewRequest("POST", "/api/v1/transactions/5/reverse", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, status, w.Code)
+		if status == http.StatusCreated {
+			assert.Contains(t, w.Body.String(), `"reversalOf":5`)
+		}
+	}
+
+	req, _ := http.NewRequest("POST", "/api/v1/transactions/abc/reverse", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleWalletStatus(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	mockService.EXPECT().
+		SetWalletStatus(gomock.Any(), walletID, models.WalletFrozen, "sanctions check").
+		Return(models.Wallet{ID: walletID, Status: models.WalletFrozen}, nil)
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(10), gomock.Any()).
+		Return(decimal.NewFromInt(100), repository.ErrWalletFrozen)
+	mockService.EXPECT().
+		SetWalletStatus(gomock.Any(), walletID, models.WalletClosed, "").
+		Return(models.Wallet{}, repository.ErrWalletNotEmpty)
+
+	req, _ := http.NewRequest("POST", "/api/v1/admin/wallets/"+walletID.String()+"/freeze",
+		bytes.NewBufferString(`{"reason": "sanctions check"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"FROZEN"`)
+
+	body, _ := json.Marshal(map[string]interface{}{"walletId": walletID, "operationType": "WITHDRAW", "amount": "10"})
+	req, _ = http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusLocked, w.Code)
+
+	req, _ = http.NewRequest("POST", "/api/v1/admin/wallets/"+walletID.String()+"/close", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+}
+
+func TestHandleWalletManagement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	mockService.EXPECT().
+		CreateWallet(gomock.Any(), models.CreateWalletRequest{
+			WalletID: &walletID,
+			OwnerID:  "user-1",
+			Labels:   map[string]string{"tier": "gold"},
+		}).
+		Return(models.Wallet{ID: walletID, OwnerID: "user-1"}, nil)
+	mockService.EXPECT().
+		CreateWallet(gomock.Any(), gomock.Any()).
+		Return(models.Wallet{}, repository.ErrWalletAlreadyExist)
+	mockService.EXPECT().
+		ListWallets(gomock.Any(), models.WalletFilter{
+			OwnerID: "user-1",
+			Status:  models.WalletActive,
+			Labels:  map[string]string{"tier": "gold", "region": "eu"},
+			Limit:   10,
+		}).
+		Return(models.WalletPage{Wallets: []models.Wallet{{ID: walletID}}, NextCursor: "abc"}, nil)
+	name := "savings"
+	mockService.EXPECT().
+		UpdateWallet(gomock.Any(), walletID, models.UpdateWalletRequest{Name: &name}).
+		Return(models.Wallet{ID: walletID, Name: name}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"walletId": walletID, "ownerId": "user-1", "labels": map[string]string{"tier": "gold"}})
+	req, _ := http.NewRequest("POST", "/api/v1/wallets", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+
+	req, _ = http.NewRequest("POST", "/api/v1/wallets", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/wallets?ownerId=user-1&status=ACTIVE&label=tier:gold&label=region:eu&limit=10", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"nextCursor":"abc"`)
+
+	req, _ = http.NewRequest("GET", "/api/v1/wallets?label=broken", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest("PATCH", "/api/v1/wallets/"+walletID.String(), bytes.NewBufferString(`{"name": "savings"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"name":"savings"`)
+}
+
+func TestHandleOverdraftLimit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	mockService.EXPECT().
+		SetOverdraftLimit(gomock.Any(), walletID, decimal.NewFromInt(100), "approved").
+		Return(models.Wallet{ID: walletID, OverdraftLimit: decimal.NewFromInt(100)}, nil)
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(500), gomock.Any()).
+		Return(decimal.NewFromInt(-20), &repository.InsufficientFundsError{
+			WalletID:        walletID,
+			AvailableCredit: decimal.NewFromInt(80),
+		})
+	mockService.EXPECT().
+		GetOverdraftLimitChanges(gomock.Any(), walletID).
+		Return([]models.OverdraftLimitChange{{WalletID: walletID, NewLimit: decimal.NewFromInt(100)}}, nil)
+
+	req, _ := http.NewRequest("PUT", "/api/v1/admin/wallets/"+walletID.String()+"/overdraft",
+		bytes.NewBufferString(`{"limit": "100", "reason": "approved"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"overdraftLimit":"100"`)
+
+	req, _ = http.NewRequest("PUT", "/api/v1/admin/wallets/"+walletID.String()+"/overdraft",
+		bytes.NewBufferString(`{"limit": "-1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	body, _ := json.Marshal(map[string]interface{}{"walletId": walletID, "operationType": "WITHDRAW", "amount": "500"})
+	req, _ = http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"availableCredit":"80"`)
+	assert.Contains(t, w.Body.String(), `"balance":"-20"`)
+
+	req, _ = http.NewRequest("GET", "/api/v1/admin/wallets/"+walletID.String()+"/overdraft/changes", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"newLimit":"100"`)
+}
+
+func TestHandleSpendingLimits(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	daily := decimal.NewFromInt(500)
+	mockService.EXPECT().
+		SetLimitTier(gomock.Any(), "kyc1", "RUB", models.SpendingLimit{Operation: "WITHDRAW", DailyCap: &daily}).
+		Return(nil)
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(700), gomock.Any()).
+		Return(decimal.Zero, false, &repository.LimitExceededError{
+			Limit: models.LimitDailyCap,
+			Max:   decimal.NewFromInt(500),
+			Used:  decimal.NewFromInt(100),
+		})
+	mockService.EXPECT().
+		SetWalletTier(gomock.Any(), walletID, "missing").
+		Return(models.Wallet{}, repository.ErrLimitTierNotFound)
+
+	req, _ := http.NewRequest("PUT", "/api/v1/admin/limit-tiers/kyc1",
+		bytes.NewBufferString(`{"currency": "RUB", "operation": "WITHDRAW", "dailyCap": "500"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	req, _ = http.NewRequest("PUT", "/api/v1/admin/limit-tiers/kyc1",
+		bytes.NewBufferString(`{"currency": "RUB", "operation": "HOLD"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	body, _ := json.Marshal(map[string]interface{}{"walletId": walletID, "operationType": "DEPOSIT", "amount": "700"})
+	req, _ = http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusUnprocessableEntity, w.Code)
+	assert.Contains(t, w.Body.String(), `"limit":"daily_cap"`)
+	assert.Contains(t, w.Body.String(), `"max":"500"`)
+	assert.Contains(t, w.Body.String(), `"used":"100"`)
+
+	req, _ = http.NewRequest("PUT", "/api/v1/admin/wallets/"+walletID.String()+"/tier", bytes.NewBufferString(`{"tier": "missing"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestHandleFees(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(100), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, error) {
+			if fee := models.NewOperationOptions(opts...).Fee; fee != nil {
+				*fee = decimal.RequireFromString("1.5")
+			}
+			return decimal.RequireFromString("48.5"), nil
+		})
+	mockService.EXPECT().
+		QuoteFee(gomock.Any(), models.FeeQuoteRequest{WalletID: &walletID, OperationType: "WITHDRAW", Amount: decimal.NewFromInt(100)}).
+		Return(models.FeeQuote{Fee: decimal.RequireFromString("1.5"), BalanceChange: decimal.RequireFromString("-101.5")}, nil)
+	mockService.EXPECT().
+		SetFeeSchedule(gomock.Any(), gomock.Any()).
+		Return(models.FeeSchedule{}, models.ErrInvalidFeeSchedule)
+
+	body, _ := json.Marshal(map[string]interface{}{"walletId": walletID, "operationType": "WITHDRAW", "amount": "100"})
+	req, _ := http.NewRequest("POST", "/api/v1/wallet", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"balance":"48.5","fee":"1.5"}`, w.Body.String())
+
+	req, _ = http.NewRequest("POST", "/api/v1/fees/quote", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"balanceChange":"-101.5"`)
+
+	req, _ = http.NewRequest("PUT", "/api/v1/admin/fee-schedules",
+		bytes.NewBufferString(`{"operation": "DEPOSIT", "currency": "RUB", "percent": "150"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleScheduledOperations(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	scheduleID := uuid.New()
+	cron := "0 9 * * *"
+	mockService.EXPECT().
+		CreateScheduledOperation(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, req models.ScheduleRequest) (models.ScheduledOperation, error) {
+			assert.Equal(t, "DEPOSIT", req.Operation)
+			assert.Equal(t, cron, req.Cron)
+			return models.ScheduledOperation{ID: scheduleID, Operation: req.Operation, WalletID: req.WalletID,
+				Amount: req.Amount, Cron: &cron, Status: models.ScheduleActive}, nil
+		})
+	mockService.EXPECT().
+		CancelScheduledOperation(gomock.Any(), scheduleID).
+		Return(models.ScheduledOperation{}, repository.ErrScheduleNotActive)
+	mockService.EXPECT().
+		GetScheduledRuns(gomock.Any(), scheduleID).
+		Return([]models.ScheduledRun{{ScheduledOperationID: scheduleID, Attempt: 1, Status: models.RunSucceeded}}, nil)
+
+	body, _ := json.Marshal(map[string]interface{}{"operation": "DEPOSIT", "walletId": walletID, "amount": "100", "cron": cron})
+	req, _ := http.NewRequest("POST", "/api/v1/scheduled-operations", bytes.NewBuffer(body))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"ACTIVE"`)
+
+	req, _ = http.NewRequest("POST", "/api/v1/scheduled-operations",
+		bytes.NewBufferString(`{"operation": "REFUND", "walletId": "`+walletID.String()+`", "amount": "1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest("POST", "/api/v1/scheduled-operations/"+scheduleID.String()+"/cancel", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/scheduled-operations/"+scheduleID.String()+"/runs", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"SUCCEEDED"`)
+}
+
+func TestHandleGetBalanceAt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	mockService.EXPECT().
+		GetBalanceAt(gomock.Any(), walletID, at).
+		Return(models.BalanceAt{WalletID: walletID, Currency: "RUB", Balance: decimal.NewFromInt(150), At: at}, nil)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/balance?at=2024-03-01T00:00:00Z", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"walletId":"`+walletID.String()+`","currency":"RUB","balance":"150","at":"2024-03-01T00:00:00Z"}`, w.Body.String())
+
+	req, _ = http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/balance?at=yesterday", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleGetStatement(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 4, 1, 0, 0, 0, 0, time.UTC)
+	mockService.EXPECT().
+		ExportStatement(gomock.Any(), walletID, from, to, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, from, to time.Time, w models.StatementWriter) error {
+			s := models.Statement{WalletID: walletID, Currency: "RUB", From: from, To: to}
+			assert.NoError(t, w.WriteHeader(s))
+			return w.WriteFooter(s)
+		})
+	mockService.EXPECT().
+		ExportStatement(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return(repository.ErrWalletNotFound)
+
+	req, _ := http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/statement?format=ndjson&from=2024-03-01&to=2024-03-31", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "application/x-ndjson", w.Header().Get("Content-Type"))
+	assert.Contains(t, w.Header().Get("Content-Disposition"), ".ndjson")
+	assert.Equal(t, 2, strings.Count(w.Body.String(), "\n"))
+
+	req, _ = http.NewRequest("GET", "/api/v1/wallets/"+uuid.New().String()+"/statement?from=2024-03-01", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.Contains(t, w.Header().Get("Content-Type"), "application/json")
+
+	for _, query := range []string{"format=pdf&from=2024-03-01", "", "from=March"} {
+		req, _ = http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/statement?"+query, nil)
+		w = httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		assert.Equal(t, http.StatusBadRequest, w.Code, query)
+	}
+}
+
+func TestHandleVerifyJournalChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	txID := int64(7)
+	mockService.EXPECT().
+		VerifyJournalChain(gomock.Any(), &walletID).
+		Return(models.ChainVerification{
+			WalletID:       &walletID,
+			WalletsChecked: 1,
+			RowsChecked:    7,
+			FirstBreak: &models.ChainBreak{
+				WalletID: walletID, TransactionID: &txID, Reason: models.ChainBrokenLink,
+				Expected: "aa", Actual: "bb",
+			},
+		}, nil)
+	req, _ := http.NewRequest("GET", "/api/v1/admin/ledger/chain/verify?walletId="+walletID.String(), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"walletId":"`+walletID.String()+`","walletsChecked":1,"rowsChecked":7,"ok":false,
+		"firstBreak":{"walletId":"`+walletID.String()+`","transactionId":7,"reason":"broken_link","expected":"aa","actual":"bb"}}`,
+		w.Body.String())
+
+	mockService.EXPECT().
+		VerifyJournalChain(gomock.Any(), gomock.Nil()).
+		Return(models.ChainVerification{WalletsChecked: 3, RowsChecked: 12, OK: true}, nil)
+	req, _ = http.NewRequest("GET", "/api/v1/admin/ledger/chain/verify", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"walletsChecked":3,"rowsChecked":12,"ok":true}`, w.Body.String())
+
+	req, _ = http.NewRequest("GET", "/api/v1/admin/ledger/chain/verify?walletId=nope", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+}
+
+func TestHandleWebhooks(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+	webhookID := uuid.New()
+
+	mockService.EXPECT().
+		CreateWebhook(gomock.Any(), models.WebhookRequest{
+			OwnerID: "acme", URL: "https://example.com/hook", Events: []string{"deposit"},
+		}).
+		Return(models.WebhookSubscription{
+			ID: webhookID, OwnerID: "acme", URL: "https://example.com/hook", Secret: "whsec_1",
+			Events: []string{"deposit"}, Active: true,
+		}, nil)
+	body := `{"ownerId":"acme","url":"https://example.com/hook","events":["deposit"]}`
+	req, _ := http.NewRequest("POST", "/api/v1/webhooks", strings.NewReader(body))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusCreated, w.Code)
+	assert.Contains(t, w.Body.String(), `"secret":"whsec_1"`)
+
+	// Неизвестный вид события отсекается при разборе запроса
+	body = `{"ownerId":"acme","url":"https://example.com/hook","events":["refund"]}`
+	req, _ = http.NewRequest("POST", "/api/v1/webhooks", strings.NewReader(body))
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	// Без аутентификации владельца надо указать явно
+	mockService.EXPECT().
+		ListWebhooks(gomock.Any(), "").
+		Return(nil, fmt.Errorf("%w: ownerId is required", models.ErrInvalidWebhook))
+	req, _ = http.NewRequest("GET", "/api/v1/webhooks", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.EXPECT().
+		ListWebhookDeliveries(gomock.Any(), webhookID, models.DeliveryDead, 10).
+		Return([]models.WebhookDelivery{{ID: 3, SubscriptionID: webhookID, Status: models.DeliveryDead, Attempts: 8}}, nil)
+	req, _ = http.NewRequest("GET", "/api/v1/webhooks/"+webhookID.String()+"/deliveries?status=DEAD&limit=10", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"status":"DEAD"`)
+
+	req, _ = http.NewRequest("GET", "/api/v1/webhooks/"+webhookID.String()+"/deliveries?limit=1000", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.EXPECT().
+		RetryWebhookDelivery(gomock.Any(), webhookID, int64(3)).
+		Return(models.WebhookDelivery{}, repository.ErrDeliveryNotRetryable)
+	req, _ = http.NewRequest("POST", "/api/v1/webhooks/"+webhookID.String()+"/deliveries/3/retry", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	mockService.EXPECT().
+		DeleteWebhook(gomock.Any(), webhookID).
+		Return(models.WebhookSubscription{}, repository.ErrWebhookNotFound)
+	req, _ = http.NewRequest("DELETE", "/api/v1/webhooks/"+webhookID.String(), nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+// fakeEventStream — EventStream с уже выставленным сигналом о новых событиях.
+type fakeEventStream struct {
+	wake chan struct{}
+}
+
+func (s fakeEventStream) Subscribe(uuid.UUID) (<-chan struct{}, func()) {
+	return s.wake, func() {}
+}
+
+func TestHandleWalletEvents(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	wake := make(chan struct{}, 1)
+	handler := handlers.NewWalletHTTPHandler(mockService, handlers.WithEventStream(fakeEventStream{wake: wake}))
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	walletID := uuid.New()
+	event := func(id int64) models.Event {
+		return models.Event{
+			ID:       id,
+			EventID:  uuid.New(),
+			Type:     models.EventBalanceChanged,
+			WalletID: walletID,
+			Data:     json.RawMessage(`{"balanceAfter":"10"}`),
+		}
+	}
+	snapshot := models.StreamSnapshot{
+		WalletID:    walletID,
+		Currency:    "RUB",
+		Balance:     decimal.NewFromInt(10),
+		LastEventID: 4,
+	}
+
+	// Продолжение с Last-Event-ID: без snapshot, события после 5, затем дочитывание по сигналу
+	ctx, cancel := context.WithCancel(context.Background())
+	wake <- struct{}{}
+	gomock.InOrder(
+		mockService.EXPECT().GetEventStreamSnapshot(gomock.Any(), walletID).Return(snapshot, nil),
+		mockService.EXPECT().
+			GetWalletEvents(gomock.Any(), walletID, int64(5), 100).
+			Return([]models.Event{event(6), event(7)}, nil),
+		mockService.EXPECT().
+			GetWalletEvents(gomock.Any(), walletID, int64(7), 100).
+			DoAndReturn(func(context.Context, uuid.UUID, int64, int) ([]models.Event, error) {
+				cancel()
+				return []models.Event{event(8)}, nil
+			}),
+	)
+	req, _ := http.NewRequestWithContext(ctx, "GET", "/api/v1/wallets/"+walletID.String()+"/events", nil)
+	req.Header.Set(handlers.LastEventIDHeader, "5")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "text/event-stream", w.Header().Get("Content-Type"))
+	body := w.Body.String()
+	assert.True(t, strings.HasPrefix(body, "retry: 3000\n\n"))
+	assert.NotContains(t, body, "event: snapshot")
+	assert.Contains(t, body, "id: 6\nevent: wallet.balance_changed\ndata: {")
+	assert.Less(t, strings.Index(body, "id: 7\n"), strings.Index(body, "id: 8\n"))
+
+	// Новый поток начинается со snapshot, id которого — позиция последнего события
+	ctx, cancel = context.WithCancel(context.Background())
+	mockService.EXPECT().GetEventStreamSnapshot(gomock.Any(), walletID).Return(snapshot, nil)
+	mockService.EXPECT().
+		GetWalletEvents(gomock.Any(), walletID, int64(4), 100).
+		DoAndReturn(func(context.Context, uuid.UUID, int64, int) ([]models.Event, error) {
+			cancel()
+			return nil, nil
+		})
+	req, _ = http.NewRequestWithContext(ctx, "GET", "/api/v1/wallets/"+walletID.String()+"/events", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `id: 4`+"\nevent: snapshot\ndata: "+`{"walletId":"`+walletID.String()+`","currency":"RUB","balance":"10"}`)
+
+	req, _ = http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/events?lastEventId=abc", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	mockService.EXPECT().
+		GetEventStreamSnapshot(gomock.Any(), walletID).
+		Return(models.StreamSnapshot{}, repository.ErrWalletNotFound)
+	req, _ = http.NewRequest("GET", "/api/v1/wallets/"+walletID.String()+"/events", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
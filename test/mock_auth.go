@@ -0,0 +1,51 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: apikey.go
+
+// Package test is a generated GoMock package.
+package test
+
+import (
+	context "context"
+	reflect "reflect"
+	models "test_wallet/internal/models"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockAPIKeyStore is a mock of APIKeyStore interface.
+type MockAPIKeyStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockAPIKeyStoreMockRecorder
+}
+
+// MockAPIKeyStoreMockRecorder is the mock recorder for MockAPIKeyStore.
+type MockAPIKeyStoreMockRecorder struct {
+	mock *MockAPIKeyStore
+}
+
+// NewMockAPIKeyStore creates a new mock instance.
+func NewMockAPIKeyStore(ctrl *gomock.Controller) *MockAPIKeyStore {
+	mock := &MockAPIKeyStore{ctrl: ctrl}
+	mock.recorder = &MockAPIKeyStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAPIKeyStore) EXPECT() *MockAPIKeyStoreMockRecorder {
+	return m.recorder
+}
+
+// GetAPIKeyByHash mocks base method.
+func (m *MockAPIKeyStore) GetAPIKeyByHash(ctx context.Context, hash string) (models.APIKey, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetAPIKeyByHash", ctx, hash)
+	ret0, _ := ret[0].(models.APIKey)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetAPIKeyByHash indicates an expected call of GetAPIKeyByHash.
+func (mr *MockAPIKeyStoreMockRecorder) GetAPIKeyByHash(ctx, hash interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetAPIKeyByHash", reflect.TypeOf((*MockAPIKeyStore)(nil).GetAPIKeyByHash), ctx, hash)
+}
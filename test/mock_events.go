@@ -0,0 +1,96 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: relay.go
+
+// Package test is a generated GoMock package.
+package test
+
+import (
+	context "context"
+	reflect "reflect"
+	models "test_wallet/internal/models"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+)
+
+// MockOutboxStore is a mock of Store interface.
+type MockOutboxStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockOutboxStoreMockRecorder
+}
+
+// MockOutboxStoreMockRecorder is the mock recorder for MockOutboxStore.
+type MockOutboxStoreMockRecorder struct {
+	mock *MockOutboxStore
+}
+
+// NewMockOutboxStore creates a new mock instance.
+func NewMockOutboxStore(ctrl *gomock.Controller) *MockOutboxStore {
+	mock := &MockOutboxStore{ctrl: ctrl}
+	mock.recorder = &MockOutboxStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockOutboxStore) EXPECT() *MockOutboxStoreMockRecorder {
+	return m.recorder
+}
+
+// LockOutboxRelay mocks base method.
+func (m *MockOutboxStore) LockOutboxRelay(ctx context.Context) (func(), bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockOutboxRelay", ctx)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LockOutboxRelay indicates an expected call of LockOutboxRelay.
+func (mr *MockOutboxStoreMockRecorder) LockOutboxRelay(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockOutboxRelay", reflect.TypeOf((*MockOutboxStore)(nil).LockOutboxRelay), ctx)
+}
+
+// MarkEventFailed mocks base method.
+func (m *MockOutboxStore) MarkEventFailed(ctx context.Context, id int64, nextAttemptAt time.Time, reason string) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventFailed", ctx, id, nextAttemptAt, reason)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventFailed indicates an expected call of MarkEventFailed.
+func (mr *MockOutboxStoreMockRecorder) MarkEventFailed(ctx, id, nextAttemptAt, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventFailed", reflect.TypeOf((*MockOutboxStore)(nil).MarkEventFailed), ctx, id, nextAttemptAt, reason)
+}
+
+// MarkEventPublished mocks base method.
+func (m *MockOutboxStore) MarkEventPublished(ctx context.Context, id int64) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MarkEventPublished", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// MarkEventPublished indicates an expected call of MarkEventPublished.
+func (mr *MockOutboxStoreMockRecorder) MarkEventPublished(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MarkEventPublished", reflect.TypeOf((*MockOutboxStore)(nil).MarkEventPublished), ctx, id)
+}
+
+// PendingEvents mocks base method.
+func (m *MockOutboxStore) PendingEvents(ctx context.Context, now time.Time, limit int) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PendingEvents", ctx, now, limit)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PendingEvents indicates an expected call of PendingEvents.
+func (mr *MockOutboxStoreMockRecorder) PendingEvents(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PendingEvents", reflect.TypeOf((*MockOutboxStore)(nil).PendingEvents), ctx, now, limit)
+}
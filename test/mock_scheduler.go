@@ -0,0 +1,183 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: scheduler.go
+
+// Package test is a generated GoMock package.
+package test
+
+import (
+	context "context"
+	reflect "reflect"
+	models "test_wallet/internal/models"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+	decimal "github.com/shopspring/decimal"
+)
+
+// MockStore is a mock of Store interface.
+type MockStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockStoreMockRecorder
+}
+
+// MockStoreMockRecorder is the mock recorder for MockStore.
+type MockStoreMockRecorder struct {
+	mock *MockStore
+}
+
+// NewMockStore creates a new mock instance.
+func NewMockStore(ctrl *gomock.Controller) *MockStore {
+	mock := &MockStore{ctrl: ctrl}
+	mock.recorder = &MockStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockStore) EXPECT() *MockStoreMockRecorder {
+	return m.recorder
+}
+
+// DueScheduledOperations mocks base method.
+func (m *MockStore) DueScheduledOperations(ctx context.Context, now time.Time, limit int) ([]models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DueScheduledOperations", ctx, now, limit)
+	ret0, _ := ret[0].([]models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DueScheduledOperations indicates an expected call of DueScheduledOperations.
+func (mr *MockStoreMockRecorder) DueScheduledOperations(ctx, now, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DueScheduledOperations", reflect.TypeOf((*MockStore)(nil).DueScheduledOperations), ctx, now, limit)
+}
+
+// GetScheduledOperation mocks base method.
+func (m *MockStore) GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledOperation indicates an expected call of GetScheduledOperation.
+func (mr *MockStoreMockRecorder) GetScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledOperation", reflect.TypeOf((*MockStore)(nil).GetScheduledOperation), ctx, id)
+}
+
+// LockScheduledOperation mocks base method.
+func (m *MockStore) LockScheduledOperation(ctx context.Context, id uuid.UUID) (func(), bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LockScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(func())
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// LockScheduledOperation indicates an expected call of LockScheduledOperation.
+func (mr *MockStoreMockRecorder) LockScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LockScheduledOperation", reflect.TypeOf((*MockStore)(nil).LockScheduledOperation), ctx, id)
+}
+
+// RecordScheduledRun mocks base method.
+func (m *MockStore) RecordScheduledRun(ctx context.Context, op models.ScheduledOperation, run models.ScheduledRun) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordScheduledRun", ctx, op, run)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordScheduledRun indicates an expected call of RecordScheduledRun.
+func (mr *MockStoreMockRecorder) RecordScheduledRun(ctx, op, run interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordScheduledRun", reflect.TypeOf((*MockStore)(nil).RecordScheduledRun), ctx, op, run)
+}
+
+// MockExecutor is a mock of Executor interface.
+type MockExecutor struct {
+	ctrl     *gomock.Controller
+	recorder *MockExecutorMockRecorder
+}
+
+// MockExecutorMockRecorder is the mock recorder for MockExecutor.
+type MockExecutorMockRecorder struct {
+	mock *MockExecutor
+}
+
+// NewMockExecutor creates a new mock instance.
+func NewMockExecutor(ctrl *gomock.Controller) *MockExecutor {
+	mock := &MockExecutor{ctrl: ctrl}
+	mock.recorder = &MockExecutorMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockExecutor) EXPECT() *MockExecutorMockRecorder {
+	return m.recorder
+}
+
+// Deposit mocks base method.
+func (m *MockExecutor) Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Deposit", varargs...)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockExecutorMockRecorder) Deposit(ctx, walletID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockExecutor)(nil).Deposit), varargs...)
+}
+
+// Transfer mocks base method.
+func (m *MockExecutor) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.TransferResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, fromID, toID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Transfer", varargs...)
+	ret0, _ := ret[0].(models.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockExecutorMockRecorder) Transfer(ctx, fromID, toID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, fromID, toID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockExecutor)(nil).Transfer), varargs...)
+}
+
+// Withdraw mocks base method.
+func (m *MockExecutor) Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Withdraw", varargs...)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Withdraw indicates an expected call of Withdraw.
+func (mr *MockExecutorMockRecorder) Withdraw(ctx, walletID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockExecutor)(nil).Withdraw), varargs...)
+}
@@ -7,37 +7,54 @@ package test
 import (
 	context "context"
 	reflect "reflect"
+	models "test_wallet/internal/models"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	decimal "github.com/shopspring/decimal"
 )
 
-// MockWalletRepository is a mock of WalletRepository interface.
-type MockWalletRepository struct {
+// MockLedgerRepository is a mock of LedgerRepository interface.
+type MockLedgerRepository struct {
 	ctrl     *gomock.Controller
-	recorder *MockWalletRepositoryMockRecorder
+	recorder *MockLedgerRepositoryMockRecorder
 }
 
-// MockWalletRepositoryMockRecorder is the mock recorder for MockWalletRepository.
-type MockWalletRepositoryMockRecorder struct {
-	mock *MockWalletRepository
+// MockLedgerRepositoryMockRecorder is the mock recorder for MockLedgerRepository.
+type MockLedgerRepositoryMockRecorder struct {
+	mock *MockLedgerRepository
 }
 
-// NewMockWalletRepository creates a new mock instance.
-func NewMockWalletRepository(ctrl *gomock.Controller) *MockWalletRepository {
-	mock := &MockWalletRepository{ctrl: ctrl}
-	mock.recorder = &MockWalletRepositoryMockRecorder{mock}
+// NewMockLedgerRepository creates a new mock instance.
+func NewMockLedgerRepository(ctrl *gomock.Controller) *MockLedgerRepository {
+	mock := &MockLedgerRepository{ctrl: ctrl}
+	mock.recorder = &MockLedgerRepositoryMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockWalletRepository) EXPECT() *MockWalletRepositoryMockRecorder {
+func (m *MockLedgerRepository) EXPECT() *MockLedgerRepositoryMockRecorder {
 	return m.recorder
 }
 
+// CreateWallet mocks base method.
+func (m *MockLedgerRepository) CreateWallet(ctx context.Context, wallet models.Wallet) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, wallet)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockLedgerRepositoryMockRecorder) CreateWallet(ctx, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockLedgerRepository)(nil).CreateWallet), ctx, wallet)
+}
+
 // GetBalance mocks base method.
-func (m *MockWalletRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
+func (m *MockLedgerRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBalance", ctx, walletID)
 	ret0, _ := ret[0].(decimal.Decimal)
@@ -46,15 +63,158 @@ func (m *MockWalletRepository) GetBalance(ctx context.Context, walletID uuid.UUI
 }
 
 // GetBalance indicates an expected call of GetBalance.
-func (mr *MockWalletRepositoryMockRecorder) GetBalance(ctx, walletID interface{}) *gomock.Call {
+func (mr *MockLedgerRepositoryMockRecorder) GetBalance(ctx, walletID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletRepository)(nil).GetBalance), ctx, walletID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockLedgerRepository)(nil).GetBalance), ctx, walletID)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockLedgerRepository) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (models.BalanceAt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, walletID, at)
+	ret0, _ := ret[0].(models.BalanceAt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockLedgerRepositoryMockRecorder) GetBalanceAt(ctx, walletID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockLedgerRepository)(nil).GetBalanceAt), ctx, walletID, at)
+}
+
+// GetWallet mocks base method.
+func (m *MockLedgerRepository) GetWallet(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, walletID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockLedgerRepositoryMockRecorder) GetWallet(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockLedgerRepository)(nil).GetWallet), ctx, walletID)
+}
+
+// GetWalletLimits mocks base method.
+func (m *MockLedgerRepository) GetWalletLimits(ctx context.Context, walletID uuid.UUID) ([]models.SpendingLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLimits", ctx, walletID)
+	ret0, _ := ret[0].([]models.SpendingLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLimits indicates an expected call of GetWalletLimits.
+func (mr *MockLedgerRepositoryMockRecorder) GetWalletLimits(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLimits", reflect.TypeOf((*MockLedgerRepository)(nil).GetWalletLimits), ctx, walletID)
+}
+
+// ListTransactions mocks base method.
+func (m *MockLedgerRepository) ListTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, walletID, filter)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockLedgerRepositoryMockRecorder) ListTransactions(ctx, walletID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockLedgerRepository)(nil).ListTransactions), ctx, walletID, filter)
+}
+
+// ListWallets mocks base method.
+func (m *MockLedgerRepository) ListWallets(ctx context.Context, filter models.WalletFilter) ([]models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", ctx, filter)
+	ret0, _ := ret[0].([]models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockLedgerRepositoryMockRecorder) ListWallets(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockLedgerRepository)(nil).ListWallets), ctx, filter)
+}
+
+// LookupIdempotentResponse mocks base method.
+func (m *MockLedgerRepository) LookupIdempotentResponse(ctx context.Context, idem *models.Idempotency) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupIdempotentResponse", ctx, idem)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupIdempotentResponse indicates an expected call of LookupIdempotentResponse.
+func (mr *MockLedgerRepositoryMockRecorder) LookupIdempotentResponse(ctx, idem interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupIdempotentResponse", reflect.TypeOf((*MockLedgerRepository)(nil).LookupIdempotentResponse), ctx, idem)
+}
+
+// QuoteFee mocks base method.
+func (m *MockLedgerRepository) QuoteFee(ctx context.Context, opType, currency string, amount decimal.Decimal) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteFee", ctx, opType, currency, amount)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteFee indicates an expected call of QuoteFee.
+func (mr *MockLedgerRepositoryMockRecorder) QuoteFee(ctx, opType, currency, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteFee", reflect.TypeOf((*MockLedgerRepository)(nil).QuoteFee), ctx, opType, currency, amount)
+}
+
+// StreamStatement mocks base method.
+func (m *MockLedgerRepository) StreamStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamStatement", ctx, walletID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamStatement indicates an expected call of StreamStatement.
+func (mr *MockLedgerRepositoryMockRecorder) StreamStatement(ctx, walletID, from, to, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamStatement", reflect.TypeOf((*MockLedgerRepository)(nil).StreamStatement), ctx, walletID, from, to, w)
+}
+
+// Transfer mocks base method.
+func (m *MockLedgerRepository) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.TransferResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, fromID, toID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Transfer", varargs...)
+	ret0, _ := ret[0].(models.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockLedgerRepositoryMockRecorder) Transfer(ctx, fromID, toID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, fromID, toID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockLedgerRepository)(nil).Transfer), varargs...)
 }
 
 // UpdateBalance mocks base method.
-func (m *MockWalletRepository) UpdateBalance(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opType string) (decimal.Decimal, bool, error) {
+func (m *MockLedgerRepository) UpdateBalance(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opType string, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "UpdateBalance", ctx, walletID, amount, opType)
+	varargs := []interface{}{ctx, walletID, amount, opType}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateBalance", varargs...)
 	ret0, _ := ret[0].(decimal.Decimal)
 	ret1, _ := ret[1].(bool)
 	ret2, _ := ret[2].(error)
@@ -62,7 +222,1473 @@ func (m *MockWalletRepository) UpdateBalance(ctx context.Context, walletID uuid.
 }
 
 // UpdateBalance indicates an expected call of UpdateBalance.
-func (mr *MockWalletRepositoryMockRecorder) UpdateBalance(ctx, walletID, amount, opType interface{}) *gomock.Call {
+func (mr *MockLedgerRepositoryMockRecorder) UpdateBalance(ctx, walletID, amount, opType interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount, opType}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockLedgerRepository)(nil).UpdateBalance), varargs...)
+}
+
+// UpdateWallet mocks base method.
+func (m *MockLedgerRepository) UpdateWallet(ctx context.Context, walletID uuid.UUID, update models.UpdateWalletRequest) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWallet", ctx, walletID, update)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWallet indicates an expected call of UpdateWallet.
+func (mr *MockLedgerRepositoryMockRecorder) UpdateWallet(ctx, walletID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWallet", reflect.TypeOf((*MockLedgerRepository)(nil).UpdateWallet), ctx, walletID, update)
+}
+
+// MockHoldRepository is a mock of HoldRepository interface.
+type MockHoldRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldRepositoryMockRecorder
+}
+
+// MockHoldRepositoryMockRecorder is the mock recorder for MockHoldRepository.
+type MockHoldRepositoryMockRecorder struct {
+	mock *MockHoldRepository
+}
+
+// NewMockHoldRepository creates a new mock instance.
+func NewMockHoldRepository(ctrl *gomock.Controller) *MockHoldRepository {
+	mock := &MockHoldRepository{ctrl: ctrl}
+	mock.recorder = &MockHoldRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHoldRepository) EXPECT() *MockHoldRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CaptureHold mocks base method.
+func (m *MockHoldRepository) CaptureHold(ctx context.Context, holdID uuid.UUID, amount *decimal.Decimal) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, holdID, amount)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockHoldRepositoryMockRecorder) CaptureHold(ctx, holdID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockHoldRepository)(nil).CaptureHold), ctx, holdID, amount)
+}
+
+// CreateHold mocks base method.
+func (m *MockHoldRepository) CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, expiresAt time.Time, opts ...models.OperationOption) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount, expiresAt}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateHold", varargs...)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockHoldRepositoryMockRecorder) CreateHold(ctx, walletID, amount, expiresAt interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount, expiresAt}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockHoldRepository)(nil).CreateHold), varargs...)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockHoldRepository) ExpireHolds(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockHoldRepositoryMockRecorder) ExpireHolds(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockHoldRepository)(nil).ExpireHolds), ctx)
+}
+
+// GetHold mocks base method.
+func (m *MockHoldRepository) GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, holdID)
+	ret0, _ := ret[0].(models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockHoldRepositoryMockRecorder) GetHold(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockHoldRepository)(nil).GetHold), ctx, holdID)
+}
+
+// VoidHold mocks base method.
+func (m *MockHoldRepository) VoidHold(ctx context.Context, holdID uuid.UUID) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHold", ctx, holdID)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHold indicates an expected call of VoidHold.
+func (mr *MockHoldRepositoryMockRecorder) VoidHold(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHold", reflect.TypeOf((*MockHoldRepository)(nil).VoidHold), ctx, holdID)
+}
+
+// MockConversionRepository is a mock of ConversionRepository interface.
+type MockConversionRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversionRepositoryMockRecorder
+}
+
+// MockConversionRepositoryMockRecorder is the mock recorder for MockConversionRepository.
+type MockConversionRepositoryMockRecorder struct {
+	mock *MockConversionRepository
+}
+
+// NewMockConversionRepository creates a new mock instance.
+func NewMockConversionRepository(ctrl *gomock.Controller) *MockConversionRepository {
+	mock := &MockConversionRepository{ctrl: ctrl}
+	mock.recorder = &MockConversionRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversionRepository) EXPECT() *MockConversionRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateQuote mocks base method.
+func (m *MockConversionRepository) CreateQuote(ctx context.Context, quote models.ConversionQuote) (models.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuote", ctx, quote)
+	ret0, _ := ret[0].(models.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockConversionRepositoryMockRecorder) CreateQuote(ctx, quote interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockConversionRepository)(nil).CreateQuote), ctx, quote)
+}
+
+// ExecuteConversion mocks base method.
+func (m *MockConversionRepository) ExecuteConversion(ctx context.Context, quoteID uuid.UUID, opts ...models.OperationOption) (models.ConversionResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, quoteID}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecuteConversion", varargs...)
+	ret0, _ := ret[0].(models.ConversionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteConversion indicates an expected call of ExecuteConversion.
+func (mr *MockConversionRepositoryMockRecorder) ExecuteConversion(ctx, quoteID interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, quoteID}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteConversion", reflect.TypeOf((*MockConversionRepository)(nil).ExecuteConversion), varargs...)
+}
+
+// GetQuote mocks base method.
+func (m *MockConversionRepository) GetQuote(ctx context.Context, quoteID uuid.UUID) (models.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuote", ctx, quoteID)
+	ret0, _ := ret[0].(models.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuote indicates an expected call of GetQuote.
+func (mr *MockConversionRepositoryMockRecorder) GetQuote(ctx, quoteID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuote", reflect.TypeOf((*MockConversionRepository)(nil).GetQuote), ctx, quoteID)
+}
+
+// MockScheduleRepository is a mock of ScheduleRepository interface.
+type MockScheduleRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduleRepositoryMockRecorder
+}
+
+// MockScheduleRepositoryMockRecorder is the mock recorder for MockScheduleRepository.
+type MockScheduleRepositoryMockRecorder struct {
+	mock *MockScheduleRepository
+}
+
+// NewMockScheduleRepository creates a new mock instance.
+func NewMockScheduleRepository(ctrl *gomock.Controller) *MockScheduleRepository {
+	mock := &MockScheduleRepository{ctrl: ctrl}
+	mock.recorder = &MockScheduleRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduleRepository) EXPECT() *MockScheduleRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CancelScheduledOperation mocks base method.
+func (m *MockScheduleRepository) CancelScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledOperation indicates an expected call of CancelScheduledOperation.
+func (mr *MockScheduleRepositoryMockRecorder) CancelScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledOperation", reflect.TypeOf((*MockScheduleRepository)(nil).CancelScheduledOperation), ctx, id)
+}
+
+// CreateScheduledOperation mocks base method.
+func (m *MockScheduleRepository) CreateScheduledOperation(ctx context.Context, op models.ScheduledOperation) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledOperation", ctx, op)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledOperation indicates an expected call of CreateScheduledOperation.
+func (mr *MockScheduleRepositoryMockRecorder) CreateScheduledOperation(ctx, op interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledOperation", reflect.TypeOf((*MockScheduleRepository)(nil).CreateScheduledOperation), ctx, op)
+}
+
+// GetScheduledOperation mocks base method.
+func (m *MockScheduleRepository) GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledOperation indicates an expected call of GetScheduledOperation.
+func (mr *MockScheduleRepositoryMockRecorder) GetScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledOperation", reflect.TypeOf((*MockScheduleRepository)(nil).GetScheduledOperation), ctx, id)
+}
+
+// GetScheduledRuns mocks base method.
+func (m *MockScheduleRepository) GetScheduledRuns(ctx context.Context, id uuid.UUID) ([]models.ScheduledRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledRuns", ctx, id)
+	ret0, _ := ret[0].([]models.ScheduledRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledRuns indicates an expected call of GetScheduledRuns.
+func (mr *MockScheduleRepositoryMockRecorder) GetScheduledRuns(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledRuns", reflect.TypeOf((*MockScheduleRepository)(nil).GetScheduledRuns), ctx, id)
+}
+
+// ListScheduledOperations mocks base method.
+func (m *MockScheduleRepository) ListScheduledOperations(ctx context.Context, walletID uuid.UUID) ([]models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledOperations", ctx, walletID)
+	ret0, _ := ret[0].([]models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledOperations indicates an expected call of ListScheduledOperations.
+func (mr *MockScheduleRepositoryMockRecorder) ListScheduledOperations(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledOperations", reflect.TypeOf((*MockScheduleRepository)(nil).ListScheduledOperations), ctx, walletID)
+}
+
+// MockWebhookRepository is a mock of WebhookRepository interface.
+type MockWebhookRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookRepositoryMockRecorder
+}
+
+// MockWebhookRepositoryMockRecorder is the mock recorder for MockWebhookRepository.
+type MockWebhookRepositoryMockRecorder struct {
+	mock *MockWebhookRepository
+}
+
+// NewMockWebhookRepository creates a new mock instance.
+func NewMockWebhookRepository(ctrl *gomock.Controller) *MockWebhookRepository {
+	mock := &MockWebhookRepository{ctrl: ctrl}
+	mock.recorder = &MockWebhookRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookRepository) EXPECT() *MockWebhookRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookRepository) CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, sub)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) CreateWebhook(ctx, sub interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).CreateWebhook), ctx, sub)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).DeleteWebhook), ctx, id)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWebhookRepository) GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWebhookRepositoryMockRecorder) GetWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWebhookRepository)(nil).GetWebhook), ctx, id)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockWebhookRepository) ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, webhookID, status, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockWebhookRepositoryMockRecorder) ListWebhookDeliveries(ctx, webhookID, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockWebhookRepository)(nil).ListWebhookDeliveries), ctx, webhookID, status, limit)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockWebhookRepository) ListWebhooks(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, ownerID)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockWebhookRepositoryMockRecorder) ListWebhooks(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockWebhookRepository)(nil).ListWebhooks), ctx, ownerID)
+}
+
+// RetryWebhookDelivery mocks base method.
+func (m *MockWebhookRepository) RetryWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryWebhookDelivery", ctx, webhookID, deliveryID)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryWebhookDelivery indicates an expected call of RetryWebhookDelivery.
+func (mr *MockWebhookRepositoryMockRecorder) RetryWebhookDelivery(ctx, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryWebhookDelivery", reflect.TypeOf((*MockWebhookRepository)(nil).RetryWebhookDelivery), ctx, webhookID, deliveryID)
+}
+
+// MockEventRepository is a mock of EventRepository interface.
+type MockEventRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventRepositoryMockRecorder
+}
+
+// MockEventRepositoryMockRecorder is the mock recorder for MockEventRepository.
+type MockEventRepositoryMockRecorder struct {
+	mock *MockEventRepository
+}
+
+// NewMockEventRepository creates a new mock instance.
+func NewMockEventRepository(ctrl *gomock.Controller) *MockEventRepository {
+	mock := &MockEventRepository{ctrl: ctrl}
+	mock.recorder = &MockEventRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventRepository) EXPECT() *MockEventRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetStreamSnapshot mocks base method.
+func (m *MockEventRepository) GetStreamSnapshot(ctx context.Context, walletID uuid.UUID) (models.StreamSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStreamSnapshot", ctx, walletID)
+	ret0, _ := ret[0].(models.StreamSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStreamSnapshot indicates an expected call of GetStreamSnapshot.
+func (mr *MockEventRepositoryMockRecorder) GetStreamSnapshot(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStreamSnapshot", reflect.TypeOf((*MockEventRepository)(nil).GetStreamSnapshot), ctx, walletID)
+}
+
+// ListWalletEvents mocks base method.
+func (m *MockEventRepository) ListWalletEvents(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletEvents", ctx, walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletEvents indicates an expected call of ListWalletEvents.
+func (mr *MockEventRepositoryMockRecorder) ListWalletEvents(ctx, walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletEvents", reflect.TypeOf((*MockEventRepository)(nil).ListWalletEvents), ctx, walletID, afterID, limit)
+}
+
+// MockAdminRepository is a mock of AdminRepository interface.
+type MockAdminRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminRepositoryMockRecorder
+}
+
+// MockAdminRepositoryMockRecorder is the mock recorder for MockAdminRepository.
+type MockAdminRepositoryMockRecorder struct {
+	mock *MockAdminRepository
+}
+
+// NewMockAdminRepository creates a new mock instance.
+func NewMockAdminRepository(ctrl *gomock.Controller) *MockAdminRepository {
+	mock := &MockAdminRepository{ctrl: ctrl}
+	mock.recorder = &MockAdminRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminRepository) EXPECT() *MockAdminRepositoryMockRecorder {
+	return m.recorder
+}
+
+// GetOverdraftLimitChanges mocks base method.
+func (m *MockAdminRepository) GetOverdraftLimitChanges(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftLimitChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftLimitChanges", ctx, walletID)
+	ret0, _ := ret[0].([]models.OverdraftLimitChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftLimitChanges indicates an expected call of GetOverdraftLimitChanges.
+func (mr *MockAdminRepositoryMockRecorder) GetOverdraftLimitChanges(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftLimitChanges", reflect.TypeOf((*MockAdminRepository)(nil).GetOverdraftLimitChanges), ctx, walletID)
+}
+
+// GetTrialBalance mocks base method.
+func (m *MockAdminRepository) GetTrialBalance(ctx context.Context) (models.TrialBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrialBalance", ctx)
+	ret0, _ := ret[0].(models.TrialBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrialBalance indicates an expected call of GetTrialBalance.
+func (mr *MockAdminRepositoryMockRecorder) GetTrialBalance(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrialBalance", reflect.TypeOf((*MockAdminRepository)(nil).GetTrialBalance), ctx)
+}
+
+// ListFeeSchedules mocks base method.
+func (m *MockAdminRepository) ListFeeSchedules(ctx context.Context) ([]models.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeeSchedules", ctx)
+	ret0, _ := ret[0].([]models.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeeSchedules indicates an expected call of ListFeeSchedules.
+func (mr *MockAdminRepositoryMockRecorder) ListFeeSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeeSchedules", reflect.TypeOf((*MockAdminRepository)(nil).ListFeeSchedules), ctx)
+}
+
+// Reconcile mocks base method.
+func (m *MockAdminRepository) Reconcile(ctx context.Context) (models.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].(models.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockAdminRepositoryMockRecorder) Reconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockAdminRepository)(nil).Reconcile), ctx)
+}
+
+// ReverseTransaction mocks base method.
+func (m *MockAdminRepository) ReverseTransaction(ctx context.Context, transactionID int64, amount *decimal.Decimal, allowNegative bool, opts ...models.OperationOption) (models.ReversalResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, transactionID, amount, allowNegative}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReverseTransaction", varargs...)
+	ret0, _ := ret[0].(models.ReversalResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransaction indicates an expected call of ReverseTransaction.
+func (mr *MockAdminRepositoryMockRecorder) ReverseTransaction(ctx, transactionID, amount, allowNegative interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, transactionID, amount, allowNegative}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransaction", reflect.TypeOf((*MockAdminRepository)(nil).ReverseTransaction), varargs...)
+}
+
+// SetFeeSchedule mocks base method.
+func (m *MockAdminRepository) SetFeeSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFeeSchedule", ctx, schedule)
+	ret0, _ := ret[0].(models.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFeeSchedule indicates an expected call of SetFeeSchedule.
+func (mr *MockAdminRepositoryMockRecorder) SetFeeSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeeSchedule", reflect.TypeOf((*MockAdminRepository)(nil).SetFeeSchedule), ctx, schedule)
+}
+
+// SetLimitTier mocks base method.
+func (m *MockAdminRepository) SetLimitTier(ctx context.Context, tier, currency string, limit models.SpendingLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLimitTier", ctx, tier, currency, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLimitTier indicates an expected call of SetLimitTier.
+func (mr *MockAdminRepositoryMockRecorder) SetLimitTier(ctx, tier, currency, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimitTier", reflect.TypeOf((*MockAdminRepository)(nil).SetLimitTier), ctx, tier, currency, limit)
+}
+
+// SetOverdraftLimit mocks base method.
+func (m *MockAdminRepository) SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit decimal.Decimal, reason string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverdraftLimit", ctx, walletID, limit, reason)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOverdraftLimit indicates an expected call of SetOverdraftLimit.
+func (mr *MockAdminRepositoryMockRecorder) SetOverdraftLimit(ctx, walletID, limit, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverdraftLimit", reflect.TypeOf((*MockAdminRepository)(nil).SetOverdraftLimit), ctx, walletID, limit, reason)
+}
+
+// SetWalletLimit mocks base method.
+func (m *MockAdminRepository) SetWalletLimit(ctx context.Context, walletID uuid.UUID, limit models.SpendingLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletLimit", ctx, walletID, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWalletLimit indicates an expected call of SetWalletLimit.
+func (mr *MockAdminRepositoryMockRecorder) SetWalletLimit(ctx, walletID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletLimit", reflect.TypeOf((*MockAdminRepository)(nil).SetWalletLimit), ctx, walletID, limit)
+}
+
+// SetWalletStatus mocks base method.
+func (m *MockAdminRepository) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status, reason string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletStatus", ctx, walletID, status, reason)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletStatus indicates an expected call of SetWalletStatus.
+func (mr *MockAdminRepositoryMockRecorder) SetWalletStatus(ctx, walletID, status, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletStatus", reflect.TypeOf((*MockAdminRepository)(nil).SetWalletStatus), ctx, walletID, status, reason)
+}
+
+// SetWalletTier mocks base method.
+func (m *MockAdminRepository) SetWalletTier(ctx context.Context, walletID uuid.UUID, tier string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletTier", ctx, walletID, tier)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletTier indicates an expected call of SetWalletTier.
+func (mr *MockAdminRepositoryMockRecorder) SetWalletTier(ctx, walletID, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletTier", reflect.TypeOf((*MockAdminRepository)(nil).SetWalletTier), ctx, walletID, tier)
+}
+
+// TakeBalanceSnapshots mocks base method.
+func (m *MockAdminRepository) TakeBalanceSnapshots(ctx context.Context, asOf time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeBalanceSnapshots", ctx, asOf)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeBalanceSnapshots indicates an expected call of TakeBalanceSnapshots.
+func (mr *MockAdminRepositoryMockRecorder) TakeBalanceSnapshots(ctx, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeBalanceSnapshots", reflect.TypeOf((*MockAdminRepository)(nil).TakeBalanceSnapshots), ctx, asOf)
+}
+
+// VerifyJournalChain mocks base method.
+func (m *MockAdminRepository) VerifyJournalChain(ctx context.Context, walletID *uuid.UUID) (models.ChainVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyJournalChain", ctx, walletID)
+	ret0, _ := ret[0].(models.ChainVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyJournalChain indicates an expected call of VerifyJournalChain.
+func (mr *MockAdminRepositoryMockRecorder) VerifyJournalChain(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyJournalChain", reflect.TypeOf((*MockAdminRepository)(nil).VerifyJournalChain), ctx, walletID)
+}
+
+// MockWalletRepository is a mock of WalletRepository interface.
+type MockWalletRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletRepositoryMockRecorder
+}
+
+// MockWalletRepositoryMockRecorder is the mock recorder for MockWalletRepository.
+type MockWalletRepositoryMockRecorder struct {
+	mock *MockWalletRepository
+}
+
+// NewMockWalletRepository creates a new mock instance.
+func NewMockWalletRepository(ctrl *gomock.Controller) *MockWalletRepository {
+	mock := &MockWalletRepository{ctrl: ctrl}
+	mock.recorder = &MockWalletRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWalletRepository) EXPECT() *MockWalletRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CancelScheduledOperation mocks base method.
+func (m *MockWalletRepository) CancelScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledOperation indicates an expected call of CancelScheduledOperation.
+func (mr *MockWalletRepositoryMockRecorder) CancelScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledOperation", reflect.TypeOf((*MockWalletRepository)(nil).CancelScheduledOperation), ctx, id)
+}
+
+// CaptureHold mocks base method.
+func (m *MockWalletRepository) CaptureHold(ctx context.Context, holdID uuid.UUID, amount *decimal.Decimal) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, holdID, amount)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockWalletRepositoryMockRecorder) CaptureHold(ctx, holdID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockWalletRepository)(nil).CaptureHold), ctx, holdID, amount)
+}
+
+// CreateHold mocks base method.
+func (m *MockWalletRepository) CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, expiresAt time.Time, opts ...models.OperationOption) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount, expiresAt}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateHold", varargs...)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockWalletRepositoryMockRecorder) CreateHold(ctx, walletID, amount, expiresAt interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount, expiresAt}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockWalletRepository)(nil).CreateHold), varargs...)
+}
+
+// CreateQuote mocks base method.
+func (m *MockWalletRepository) CreateQuote(ctx context.Context, quote models.ConversionQuote) (models.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateQuote", ctx, quote)
+	ret0, _ := ret[0].(models.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateQuote indicates an expected call of CreateQuote.
+func (mr *MockWalletRepositoryMockRecorder) CreateQuote(ctx, quote interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateQuote", reflect.TypeOf((*MockWalletRepository)(nil).CreateQuote), ctx, quote)
+}
+
+// CreateScheduledOperation mocks base method.
+func (m *MockWalletRepository) CreateScheduledOperation(ctx context.Context, op models.ScheduledOperation) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledOperation", ctx, op)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledOperation indicates an expected call of CreateScheduledOperation.
+func (mr *MockWalletRepositoryMockRecorder) CreateScheduledOperation(ctx, op interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledOperation", reflect.TypeOf((*MockWalletRepository)(nil).CreateScheduledOperation), ctx, op)
+}
+
+// CreateWallet mocks base method.
+func (m *MockWalletRepository) CreateWallet(ctx context.Context, wallet models.Wallet) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, wallet)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockWalletRepositoryMockRecorder) CreateWallet(ctx, wallet interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockWalletRepository)(nil).CreateWallet), ctx, wallet)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWalletRepository) CreateWebhook(ctx context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, sub)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWalletRepositoryMockRecorder) CreateWebhook(ctx, sub interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWalletRepository)(nil).CreateWebhook), ctx, sub)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWalletRepository) DeleteWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWalletRepositoryMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWalletRepository)(nil).DeleteWebhook), ctx, id)
+}
+
+// ExecuteConversion mocks base method.
+func (m *MockWalletRepository) ExecuteConversion(ctx context.Context, quoteID uuid.UUID, opts ...models.OperationOption) (models.ConversionResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, quoteID}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecuteConversion", varargs...)
+	ret0, _ := ret[0].(models.ConversionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteConversion indicates an expected call of ExecuteConversion.
+func (mr *MockWalletRepositoryMockRecorder) ExecuteConversion(ctx, quoteID interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, quoteID}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteConversion", reflect.TypeOf((*MockWalletRepository)(nil).ExecuteConversion), varargs...)
+}
+
+// ExpireHolds mocks base method.
+func (m *MockWalletRepository) ExpireHolds(ctx context.Context) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExpireHolds", ctx)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExpireHolds indicates an expected call of ExpireHolds.
+func (mr *MockWalletRepositoryMockRecorder) ExpireHolds(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExpireHolds", reflect.TypeOf((*MockWalletRepository)(nil).ExpireHolds), ctx)
+}
+
+// GetBalance mocks base method.
+func (m *MockWalletRepository) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, walletID)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockWalletRepositoryMockRecorder) GetBalance(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletRepository)(nil).GetBalance), ctx, walletID)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockWalletRepository) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (models.BalanceAt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, walletID, at)
+	ret0, _ := ret[0].(models.BalanceAt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockWalletRepositoryMockRecorder) GetBalanceAt(ctx, walletID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockWalletRepository)(nil).GetBalanceAt), ctx, walletID, at)
+}
+
+// GetHold mocks base method.
+func (m *MockWalletRepository) GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, holdID)
+	ret0, _ := ret[0].(models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockWalletRepositoryMockRecorder) GetHold(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockWalletRepository)(nil).GetHold), ctx, holdID)
+}
+
+// GetOverdraftLimitChanges mocks base method.
+func (m *MockWalletRepository) GetOverdraftLimitChanges(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftLimitChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftLimitChanges", ctx, walletID)
+	ret0, _ := ret[0].([]models.OverdraftLimitChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftLimitChanges indicates an expected call of GetOverdraftLimitChanges.
+func (mr *MockWalletRepositoryMockRecorder) GetOverdraftLimitChanges(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftLimitChanges", reflect.TypeOf((*MockWalletRepository)(nil).GetOverdraftLimitChanges), ctx, walletID)
+}
+
+// GetQuote mocks base method.
+func (m *MockWalletRepository) GetQuote(ctx context.Context, quoteID uuid.UUID) (models.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuote", ctx, quoteID)
+	ret0, _ := ret[0].(models.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuote indicates an expected call of GetQuote.
+func (mr *MockWalletRepositoryMockRecorder) GetQuote(ctx, quoteID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuote", reflect.TypeOf((*MockWalletRepository)(nil).GetQuote), ctx, quoteID)
+}
+
+// GetScheduledOperation mocks base method.
+func (m *MockWalletRepository) GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledOperation indicates an expected call of GetScheduledOperation.
+func (mr *MockWalletRepositoryMockRecorder) GetScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledOperation", reflect.TypeOf((*MockWalletRepository)(nil).GetScheduledOperation), ctx, id)
+}
+
+// GetScheduledRuns mocks base method.
+func (m *MockWalletRepository) GetScheduledRuns(ctx context.Context, id uuid.UUID) ([]models.ScheduledRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledRuns", ctx, id)
+	ret0, _ := ret[0].([]models.ScheduledRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledRuns indicates an expected call of GetScheduledRuns.
+func (mr *MockWalletRepositoryMockRecorder) GetScheduledRuns(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledRuns", reflect.TypeOf((*MockWalletRepository)(nil).GetScheduledRuns), ctx, id)
+}
+
+// GetStreamSnapshot mocks base method.
+func (m *MockWalletRepository) GetStreamSnapshot(ctx context.Context, walletID uuid.UUID) (models.StreamSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetStreamSnapshot", ctx, walletID)
+	ret0, _ := ret[0].(models.StreamSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetStreamSnapshot indicates an expected call of GetStreamSnapshot.
+func (mr *MockWalletRepositoryMockRecorder) GetStreamSnapshot(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetStreamSnapshot", reflect.TypeOf((*MockWalletRepository)(nil).GetStreamSnapshot), ctx, walletID)
+}
+
+// GetTrialBalance mocks base method.
+func (m *MockWalletRepository) GetTrialBalance(ctx context.Context) (models.TrialBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrialBalance", ctx)
+	ret0, _ := ret[0].(models.TrialBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrialBalance indicates an expected call of GetTrialBalance.
+func (mr *MockWalletRepositoryMockRecorder) GetTrialBalance(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrialBalance", reflect.TypeOf((*MockWalletRepository)(nil).GetTrialBalance), ctx)
+}
+
+// GetWallet mocks base method.
+func (m *MockWalletRepository) GetWallet(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, walletID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockWalletRepositoryMockRecorder) GetWallet(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletRepository)(nil).GetWallet), ctx, walletID)
+}
+
+// GetWalletLimits mocks base method.
+func (m *MockWalletRepository) GetWalletLimits(ctx context.Context, walletID uuid.UUID) ([]models.SpendingLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLimits", ctx, walletID)
+	ret0, _ := ret[0].([]models.SpendingLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLimits indicates an expected call of GetWalletLimits.
+func (mr *MockWalletRepositoryMockRecorder) GetWalletLimits(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLimits", reflect.TypeOf((*MockWalletRepository)(nil).GetWalletLimits), ctx, walletID)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWalletRepository) GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWalletRepositoryMockRecorder) GetWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWalletRepository)(nil).GetWebhook), ctx, id)
+}
+
+// ListFeeSchedules mocks base method.
+func (m *MockWalletRepository) ListFeeSchedules(ctx context.Context) ([]models.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeeSchedules", ctx)
+	ret0, _ := ret[0].([]models.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeeSchedules indicates an expected call of ListFeeSchedules.
+func (mr *MockWalletRepositoryMockRecorder) ListFeeSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeeSchedules", reflect.TypeOf((*MockWalletRepository)(nil).ListFeeSchedules), ctx)
+}
+
+// ListScheduledOperations mocks base method.
+func (m *MockWalletRepository) ListScheduledOperations(ctx context.Context, walletID uuid.UUID) ([]models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledOperations", ctx, walletID)
+	ret0, _ := ret[0].([]models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledOperations indicates an expected call of ListScheduledOperations.
+func (mr *MockWalletRepositoryMockRecorder) ListScheduledOperations(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledOperations", reflect.TypeOf((*MockWalletRepository)(nil).ListScheduledOperations), ctx, walletID)
+}
+
+// ListTransactions mocks base method.
+func (m *MockWalletRepository) ListTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) ([]models.Transaction, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListTransactions", ctx, walletID, filter)
+	ret0, _ := ret[0].([]models.Transaction)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListTransactions indicates an expected call of ListTransactions.
+func (mr *MockWalletRepositoryMockRecorder) ListTransactions(ctx, walletID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListTransactions", reflect.TypeOf((*MockWalletRepository)(nil).ListTransactions), ctx, walletID, filter)
+}
+
+// ListWalletEvents mocks base method.
+func (m *MockWalletRepository) ListWalletEvents(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWalletEvents", ctx, walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWalletEvents indicates an expected call of ListWalletEvents.
+func (mr *MockWalletRepositoryMockRecorder) ListWalletEvents(ctx, walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWalletEvents", reflect.TypeOf((*MockWalletRepository)(nil).ListWalletEvents), ctx, walletID, afterID, limit)
+}
+
+// ListWallets mocks base method.
+func (m *MockWalletRepository) ListWallets(ctx context.Context, filter models.WalletFilter) ([]models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", ctx, filter)
+	ret0, _ := ret[0].([]models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockWalletRepositoryMockRecorder) ListWallets(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockWalletRepository)(nil).ListWallets), ctx, filter)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockWalletRepository) ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, webhookID, status, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockWalletRepositoryMockRecorder) ListWebhookDeliveries(ctx, webhookID, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockWalletRepository)(nil).ListWebhookDeliveries), ctx, webhookID, status, limit)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockWalletRepository) ListWebhooks(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, ownerID)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockWalletRepositoryMockRecorder) ListWebhooks(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockWalletRepository)(nil).ListWebhooks), ctx, ownerID)
+}
+
+// LookupIdempotentResponse mocks base method.
+func (m *MockWalletRepository) LookupIdempotentResponse(ctx context.Context, idem *models.Idempotency) (bool, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "LookupIdempotentResponse", ctx, idem)
+	ret0, _ := ret[0].(bool)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// LookupIdempotentResponse indicates an expected call of LookupIdempotentResponse.
+func (mr *MockWalletRepositoryMockRecorder) LookupIdempotentResponse(ctx, idem interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "LookupIdempotentResponse", reflect.TypeOf((*MockWalletRepository)(nil).LookupIdempotentResponse), ctx, idem)
+}
+
+// QuoteFee mocks base method.
+func (m *MockWalletRepository) QuoteFee(ctx context.Context, opType, currency string, amount decimal.Decimal) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteFee", ctx, opType, currency, amount)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteFee indicates an expected call of QuoteFee.
+func (mr *MockWalletRepositoryMockRecorder) QuoteFee(ctx, opType, currency, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteFee", reflect.TypeOf((*MockWalletRepository)(nil).QuoteFee), ctx, opType, currency, amount)
+}
+
+// Reconcile mocks base method.
+func (m *MockWalletRepository) Reconcile(ctx context.Context) (models.ReconciliationReport, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Reconcile", ctx)
+	ret0, _ := ret[0].(models.ReconciliationReport)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Reconcile indicates an expected call of Reconcile.
+func (mr *MockWalletRepositoryMockRecorder) Reconcile(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Reconcile", reflect.TypeOf((*MockWalletRepository)(nil).Reconcile), ctx)
+}
+
+// RetryWebhookDelivery mocks base method.
+func (m *MockWalletRepository) RetryWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryWebhookDelivery", ctx, webhookID, deliveryID)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryWebhookDelivery indicates an expected call of RetryWebhookDelivery.
+func (mr *MockWalletRepositoryMockRecorder) RetryWebhookDelivery(ctx, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryWebhookDelivery", reflect.TypeOf((*MockWalletRepository)(nil).RetryWebhookDelivery), ctx, webhookID, deliveryID)
+}
+
+// ReverseTransaction mocks base method.
+func (m *MockWalletRepository) ReverseTransaction(ctx context.Context, transactionID int64, amount *decimal.Decimal, allowNegative bool, opts ...models.OperationOption) (models.ReversalResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, transactionID, amount, allowNegative}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReverseTransaction", varargs...)
+	ret0, _ := ret[0].(models.ReversalResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransaction indicates an expected call of ReverseTransaction.
+func (mr *MockWalletRepositoryMockRecorder) ReverseTransaction(ctx, transactionID, amount, allowNegative interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, transactionID, amount, allowNegative}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransaction", reflect.TypeOf((*MockWalletRepository)(nil).ReverseTransaction), varargs...)
+}
+
+// SetFeeSchedule mocks base method.
+func (m *MockWalletRepository) SetFeeSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFeeSchedule", ctx, schedule)
+	ret0, _ := ret[0].(models.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFeeSchedule indicates an expected call of SetFeeSchedule.
+func (mr *MockWalletRepositoryMockRecorder) SetFeeSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeeSchedule", reflect.TypeOf((*MockWalletRepository)(nil).SetFeeSchedule), ctx, schedule)
+}
+
+// SetLimitTier mocks base method.
+func (m *MockWalletRepository) SetLimitTier(ctx context.Context, tier, currency string, limit models.SpendingLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLimitTier", ctx, tier, currency, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLimitTier indicates an expected call of SetLimitTier.
+func (mr *MockWalletRepositoryMockRecorder) SetLimitTier(ctx, tier, currency, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimitTier", reflect.TypeOf((*MockWalletRepository)(nil).SetLimitTier), ctx, tier, currency, limit)
+}
+
+// SetOverdraftLimit mocks base method.
+func (m *MockWalletRepository) SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit decimal.Decimal, reason string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverdraftLimit", ctx, walletID, limit, reason)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOverdraftLimit indicates an expected call of SetOverdraftLimit.
+func (mr *MockWalletRepositoryMockRecorder) SetOverdraftLimit(ctx, walletID, limit, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverdraftLimit", reflect.TypeOf((*MockWalletRepository)(nil).SetOverdraftLimit), ctx, walletID, limit, reason)
+}
+
+// SetWalletLimit mocks base method.
+func (m *MockWalletRepository) SetWalletLimit(ctx context.Context, walletID uuid.UUID, limit models.SpendingLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletLimit", ctx, walletID, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWalletLimit indicates an expected call of SetWalletLimit.
+func (mr *MockWalletRepositoryMockRecorder) SetWalletLimit(ctx, walletID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletLimit", reflect.TypeOf((*MockWalletRepository)(nil).SetWalletLimit), ctx, walletID, limit)
+}
+
+// SetWalletStatus mocks base method.
+func (m *MockWalletRepository) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status, reason string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletStatus", ctx, walletID, status, reason)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletStatus indicates an expected call of SetWalletStatus.
+func (mr *MockWalletRepositoryMockRecorder) SetWalletStatus(ctx, walletID, status, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletStatus", reflect.TypeOf((*MockWalletRepository)(nil).SetWalletStatus), ctx, walletID, status, reason)
+}
+
+// SetWalletTier mocks base method.
+func (m *MockWalletRepository) SetWalletTier(ctx context.Context, walletID uuid.UUID, tier string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletTier", ctx, walletID, tier)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletTier indicates an expected call of SetWalletTier.
+func (mr *MockWalletRepositoryMockRecorder) SetWalletTier(ctx, walletID, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletTier", reflect.TypeOf((*MockWalletRepository)(nil).SetWalletTier), ctx, walletID, tier)
+}
+
+// StreamStatement mocks base method.
+func (m *MockWalletRepository) StreamStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "StreamStatement", ctx, walletID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// StreamStatement indicates an expected call of StreamStatement.
+func (mr *MockWalletRepositoryMockRecorder) StreamStatement(ctx, walletID, from, to, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "StreamStatement", reflect.TypeOf((*MockWalletRepository)(nil).StreamStatement), ctx, walletID, from, to, w)
+}
+
+// TakeBalanceSnapshots mocks base method.
+func (m *MockWalletRepository) TakeBalanceSnapshots(ctx context.Context, asOf time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "TakeBalanceSnapshots", ctx, asOf)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// TakeBalanceSnapshots indicates an expected call of TakeBalanceSnapshots.
+func (mr *MockWalletRepositoryMockRecorder) TakeBalanceSnapshots(ctx, asOf interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "TakeBalanceSnapshots", reflect.TypeOf((*MockWalletRepository)(nil).TakeBalanceSnapshots), ctx, asOf)
+}
+
+// Transfer mocks base method.
+func (m *MockWalletRepository) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.TransferResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, fromID, toID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Transfer", varargs...)
+	ret0, _ := ret[0].(models.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockWalletRepositoryMockRecorder) Transfer(ctx, fromID, toID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, fromID, toID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletRepository)(nil).Transfer), varargs...)
+}
+
+// UpdateBalance mocks base method.
+func (m *MockWalletRepository) UpdateBalance(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opType string, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount, opType}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "UpdateBalance", varargs...)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// UpdateBalance indicates an expected call of UpdateBalance.
+func (mr *MockWalletRepositoryMockRecorder) UpdateBalance(ctx, walletID, amount, opType interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount, opType}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockWalletRepository)(nil).UpdateBalance), varargs...)
+}
+
+// UpdateWallet mocks base method.
+func (m *MockWalletRepository) UpdateWallet(ctx context.Context, walletID uuid.UUID, update models.UpdateWalletRequest) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWallet", ctx, walletID, update)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWallet indicates an expected call of UpdateWallet.
+func (mr *MockWalletRepositoryMockRecorder) UpdateWallet(ctx, walletID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWallet", reflect.TypeOf((*MockWalletRepository)(nil).UpdateWallet), ctx, walletID, update)
+}
+
+// VerifyJournalChain mocks base method.
+func (m *MockWalletRepository) VerifyJournalChain(ctx context.Context, walletID *uuid.UUID) (models.ChainVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyJournalChain", ctx, walletID)
+	ret0, _ := ret[0].(models.ChainVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyJournalChain indicates an expected call of VerifyJournalChain.
+func (mr *MockWalletRepositoryMockRecorder) VerifyJournalChain(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyJournalChain", reflect.TypeOf((*MockWalletRepository)(nil).VerifyJournalChain), ctx, walletID)
+}
+
+// VoidHold mocks base method.
+func (m *MockWalletRepository) VoidHold(ctx context.Context, holdID uuid.UUID) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHold", ctx, holdID)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHold indicates an expected call of VoidHold.
+func (mr *MockWalletRepositoryMockRecorder) VoidHold(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHold", reflect.TypeOf((*MockWalletRepository)(nil).VoidHold), ctx, holdID)
+}
+
+// MockRateProvider is a mock of RateProvider interface.
+type MockRateProvider struct {
+	ctrl     *gomock.Controller
+	recorder *MockRateProviderMockRecorder
+}
+
+// MockRateProviderMockRecorder is the mock recorder for MockRateProvider.
+type MockRateProviderMockRecorder struct {
+	mock *MockRateProvider
+}
+
+// NewMockRateProvider creates a new mock instance.
+func NewMockRateProvider(ctrl *gomock.Controller) *MockRateProvider {
+	mock := &MockRateProvider{ctrl: ctrl}
+	mock.recorder = &MockRateProviderMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockRateProvider) EXPECT() *MockRateProviderMockRecorder {
+	return m.recorder
+}
+
+// Rate mocks base method.
+func (m *MockRateProvider) Rate(ctx context.Context, from, to string) (models.Rate, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Rate", ctx, from, to)
+	ret0, _ := ret[0].(models.Rate)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Rate indicates an expected call of Rate.
+func (mr *MockRateProviderMockRecorder) Rate(ctx, from, to interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateBalance", reflect.TypeOf((*MockWalletRepository)(nil).UpdateBalance), ctx, walletID, amount, opType)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Rate", reflect.TypeOf((*MockRateProvider)(nil).Rate), ctx, from, to)
 }
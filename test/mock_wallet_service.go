@@ -7,39 +7,60 @@ package test
 import (
 	context "context"
 	reflect "reflect"
+	models "test_wallet/internal/models"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	uuid "github.com/google/uuid"
 	decimal "github.com/shopspring/decimal"
 )
 
-// MockWalletService is a mock of WalletService interface.
-type MockWalletService struct {
+// MockWalletOperations is a mock of WalletOperations interface.
+type MockWalletOperations struct {
 	ctrl     *gomock.Controller
-	recorder *MockWalletServiceMockRecorder
+	recorder *MockWalletOperationsMockRecorder
 }
 
-// MockWalletServiceMockRecorder is the mock recorder for MockWalletService.
-type MockWalletServiceMockRecorder struct {
-	mock *MockWalletService
+// MockWalletOperationsMockRecorder is the mock recorder for MockWalletOperations.
+type MockWalletOperationsMockRecorder struct {
+	mock *MockWalletOperations
 }
 
-// NewMockWalletService creates a new mock instance.
-func NewMockWalletService(ctrl *gomock.Controller) *MockWalletService {
-	mock := &MockWalletService{ctrl: ctrl}
-	mock.recorder = &MockWalletServiceMockRecorder{mock}
+// NewMockWalletOperations creates a new mock instance.
+func NewMockWalletOperations(ctrl *gomock.Controller) *MockWalletOperations {
+	mock := &MockWalletOperations{ctrl: ctrl}
+	mock.recorder = &MockWalletOperationsMockRecorder{mock}
 	return mock
 }
 
 // EXPECT returns an object that allows the caller to indicate expected use.
-func (m *MockWalletService) EXPECT() *MockWalletServiceMockRecorder {
+func (m *MockWalletOperations) EXPECT() *MockWalletOperationsMockRecorder {
 	return m.recorder
 }
 
+// CreateWallet mocks base method.
+func (m *MockWalletOperations) CreateWallet(ctx context.Context, req models.CreateWalletRequest) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, req)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockWalletOperationsMockRecorder) CreateWallet(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockWalletOperations)(nil).CreateWallet), ctx, req)
+}
+
 // Deposit mocks base method.
-func (m *MockWalletService) Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal) (decimal.Decimal, bool, error) {
+func (m *MockWalletOperations) Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Deposit", ctx, walletID, amount)
+	varargs := []interface{}{ctx, walletID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Deposit", varargs...)
 	ret0, _ := ret[0].(decimal.Decimal)
 	ret1, _ := ret[1].(bool)
 	ret2, _ := ret[2].(error)
@@ -47,13 +68,28 @@ func (m *MockWalletService) Deposit(ctx context.Context, walletID uuid.UUID, amo
 }
 
 // Deposit indicates an expected call of Deposit.
-func (mr *MockWalletServiceMockRecorder) Deposit(ctx, walletID, amount interface{}) *gomock.Call {
+func (mr *MockWalletOperationsMockRecorder) Deposit(ctx, walletID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWalletOperations)(nil).Deposit), varargs...)
+}
+
+// ExportStatement mocks base method.
+func (m *MockWalletOperations) ExportStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportStatement", ctx, walletID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportStatement indicates an expected call of ExportStatement.
+func (mr *MockWalletOperationsMockRecorder) ExportStatement(ctx, walletID, from, to, w interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWalletService)(nil).Deposit), ctx, walletID, amount)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportStatement", reflect.TypeOf((*MockWalletOperations)(nil).ExportStatement), ctx, walletID, from, to, w)
 }
 
 // GetBalance mocks base method.
-func (m *MockWalletService) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
+func (m *MockWalletOperations) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
 	m.ctrl.T.Helper()
 	ret := m.ctrl.Call(m, "GetBalance", ctx, walletID)
 	ret0, _ := ret[0].(decimal.Decimal)
@@ -62,22 +98,1519 @@ func (m *MockWalletService) GetBalance(ctx context.Context, walletID uuid.UUID)
 }
 
 // GetBalance indicates an expected call of GetBalance.
-func (mr *MockWalletServiceMockRecorder) GetBalance(ctx, walletID interface{}) *gomock.Call {
+func (mr *MockWalletOperationsMockRecorder) GetBalance(ctx, walletID interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletService)(nil).GetBalance), ctx, walletID)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletOperations)(nil).GetBalance), ctx, walletID)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockWalletOperations) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (models.BalanceAt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, walletID, at)
+	ret0, _ := ret[0].(models.BalanceAt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockWalletOperationsMockRecorder) GetBalanceAt(ctx, walletID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockWalletOperations)(nil).GetBalanceAt), ctx, walletID, at)
+}
+
+// GetTransactions mocks base method.
+func (m *MockWalletOperations) GetTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) (models.TransactionPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactions", ctx, walletID, filter)
+	ret0, _ := ret[0].(models.TransactionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactions indicates an expected call of GetTransactions.
+func (mr *MockWalletOperationsMockRecorder) GetTransactions(ctx, walletID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockWalletOperations)(nil).GetTransactions), ctx, walletID, filter)
+}
+
+// GetWallet mocks base method.
+func (m *MockWalletOperations) GetWallet(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, walletID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockWalletOperationsMockRecorder) GetWallet(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletOperations)(nil).GetWallet), ctx, walletID)
+}
+
+// GetWalletLimits mocks base method.
+func (m *MockWalletOperations) GetWalletLimits(ctx context.Context, walletID uuid.UUID) ([]models.SpendingLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLimits", ctx, walletID)
+	ret0, _ := ret[0].([]models.SpendingLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLimits indicates an expected call of GetWalletLimits.
+func (mr *MockWalletOperationsMockRecorder) GetWalletLimits(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLimits", reflect.TypeOf((*MockWalletOperations)(nil).GetWalletLimits), ctx, walletID)
+}
+
+// ListWallets mocks base method.
+func (m *MockWalletOperations) ListWallets(ctx context.Context, filter models.WalletFilter) (models.WalletPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", ctx, filter)
+	ret0, _ := ret[0].(models.WalletPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockWalletOperationsMockRecorder) ListWallets(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockWalletOperations)(nil).ListWallets), ctx, filter)
+}
+
+// QuoteFee mocks base method.
+func (m *MockWalletOperations) QuoteFee(ctx context.Context, req models.FeeQuoteRequest) (models.FeeQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteFee", ctx, req)
+	ret0, _ := ret[0].(models.FeeQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteFee indicates an expected call of QuoteFee.
+func (mr *MockWalletOperationsMockRecorder) QuoteFee(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteFee", reflect.TypeOf((*MockWalletOperations)(nil).QuoteFee), ctx, req)
+}
+
+// Transfer mocks base method.
+func (m *MockWalletOperations) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.TransferResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, fromID, toID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Transfer", varargs...)
+	ret0, _ := ret[0].(models.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockWalletOperationsMockRecorder) Transfer(ctx, fromID, toID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, fromID, toID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletOperations)(nil).Transfer), varargs...)
+}
+
+// UpdateWallet mocks base method.
+func (m *MockWalletOperations) UpdateWallet(ctx context.Context, walletID uuid.UUID, update models.UpdateWalletRequest) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWallet", ctx, walletID, update)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWallet indicates an expected call of UpdateWallet.
+func (mr *MockWalletOperationsMockRecorder) UpdateWallet(ctx, walletID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWallet", reflect.TypeOf((*MockWalletOperations)(nil).UpdateWallet), ctx, walletID, update)
+}
+
+// Withdraw mocks base method.
+func (m *MockWalletOperations) Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Withdraw", varargs...)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Withdraw indicates an expected call of Withdraw.
+func (mr *MockWalletOperationsMockRecorder) Withdraw(ctx, walletID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWalletOperations)(nil).Withdraw), varargs...)
+}
+
+// MockHoldService is a mock of HoldService interface.
+type MockHoldService struct {
+	ctrl     *gomock.Controller
+	recorder *MockHoldServiceMockRecorder
+}
+
+// MockHoldServiceMockRecorder is the mock recorder for MockHoldService.
+type MockHoldServiceMockRecorder struct {
+	mock *MockHoldService
+}
+
+// NewMockHoldService creates a new mock instance.
+func NewMockHoldService(ctrl *gomock.Controller) *MockHoldService {
+	mock := &MockHoldService{ctrl: ctrl}
+	mock.recorder = &MockHoldServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockHoldService) EXPECT() *MockHoldServiceMockRecorder {
+	return m.recorder
+}
+
+// CaptureHold mocks base method.
+func (m *MockHoldService) CaptureHold(ctx context.Context, holdID uuid.UUID, amount *decimal.Decimal) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, holdID, amount)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockHoldServiceMockRecorder) CaptureHold(ctx, holdID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockHoldService)(nil).CaptureHold), ctx, holdID, amount)
+}
+
+// CreateHold mocks base method.
+func (m *MockHoldService) CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, ttl time.Duration, opts ...models.OperationOption) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount, ttl}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateHold", varargs...)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockHoldServiceMockRecorder) CreateHold(ctx, walletID, amount, ttl interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount, ttl}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockHoldService)(nil).CreateHold), varargs...)
+}
+
+// GetHold mocks base method.
+func (m *MockHoldService) GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, holdID)
+	ret0, _ := ret[0].(models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockHoldServiceMockRecorder) GetHold(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockHoldService)(nil).GetHold), ctx, holdID)
+}
+
+// VoidHold mocks base method.
+func (m *MockHoldService) VoidHold(ctx context.Context, holdID uuid.UUID) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHold", ctx, holdID)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHold indicates an expected call of VoidHold.
+func (mr *MockHoldServiceMockRecorder) VoidHold(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHold", reflect.TypeOf((*MockHoldService)(nil).VoidHold), ctx, holdID)
+}
+
+// MockConversionService is a mock of ConversionService interface.
+type MockConversionService struct {
+	ctrl     *gomock.Controller
+	recorder *MockConversionServiceMockRecorder
+}
+
+// MockConversionServiceMockRecorder is the mock recorder for MockConversionService.
+type MockConversionServiceMockRecorder struct {
+	mock *MockConversionService
+}
+
+// NewMockConversionService creates a new mock instance.
+func NewMockConversionService(ctrl *gomock.Controller) *MockConversionService {
+	mock := &MockConversionService{ctrl: ctrl}
+	mock.recorder = &MockConversionServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockConversionService) EXPECT() *MockConversionServiceMockRecorder {
+	return m.recorder
+}
+
+// Convert mocks base method.
+func (m *MockConversionService) Convert(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.ConversionResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, fromID, toID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Convert", varargs...)
+	ret0, _ := ret[0].(models.ConversionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert.
+func (mr *MockConversionServiceMockRecorder) Convert(ctx, fromID, toID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, fromID, toID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockConversionService)(nil).Convert), varargs...)
+}
+
+// ExecuteConversion mocks base method.
+func (m *MockConversionService) ExecuteConversion(ctx context.Context, quoteID uuid.UUID, opts ...models.OperationOption) (models.ConversionResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, quoteID}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecuteConversion", varargs...)
+	ret0, _ := ret[0].(models.ConversionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteConversion indicates an expected call of ExecuteConversion.
+func (mr *MockConversionServiceMockRecorder) ExecuteConversion(ctx, quoteID interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, quoteID}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteConversion", reflect.TypeOf((*MockConversionService)(nil).ExecuteConversion), varargs...)
+}
+
+// GetQuote mocks base method.
+func (m *MockConversionService) GetQuote(ctx context.Context, quoteID uuid.UUID) (models.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuote", ctx, quoteID)
+	ret0, _ := ret[0].(models.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuote indicates an expected call of GetQuote.
+func (mr *MockConversionServiceMockRecorder) GetQuote(ctx, quoteID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuote", reflect.TypeOf((*MockConversionService)(nil).GetQuote), ctx, quoteID)
+}
+
+// QuoteConversion mocks base method.
+func (m *MockConversionService) QuoteConversion(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal) (models.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteConversion", ctx, fromID, toID, amount)
+	ret0, _ := ret[0].(models.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteConversion indicates an expected call of QuoteConversion.
+func (mr *MockConversionServiceMockRecorder) QuoteConversion(ctx, fromID, toID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteConversion", reflect.TypeOf((*MockConversionService)(nil).QuoteConversion), ctx, fromID, toID, amount)
+}
+
+// MockScheduleService is a mock of ScheduleService interface.
+type MockScheduleService struct {
+	ctrl     *gomock.Controller
+	recorder *MockScheduleServiceMockRecorder
+}
+
+// MockScheduleServiceMockRecorder is the mock recorder for MockScheduleService.
+type MockScheduleServiceMockRecorder struct {
+	mock *MockScheduleService
+}
+
+// NewMockScheduleService creates a new mock instance.
+func NewMockScheduleService(ctrl *gomock.Controller) *MockScheduleService {
+	mock := &MockScheduleService{ctrl: ctrl}
+	mock.recorder = &MockScheduleServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockScheduleService) EXPECT() *MockScheduleServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelScheduledOperation mocks base method.
+func (m *MockScheduleService) CancelScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledOperation indicates an expected call of CancelScheduledOperation.
+func (mr *MockScheduleServiceMockRecorder) CancelScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledOperation", reflect.TypeOf((*MockScheduleService)(nil).CancelScheduledOperation), ctx, id)
+}
+
+// CreateScheduledOperation mocks base method.
+func (m *MockScheduleService) CreateScheduledOperation(ctx context.Context, req models.ScheduleRequest) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledOperation", ctx, req)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledOperation indicates an expected call of CreateScheduledOperation.
+func (mr *MockScheduleServiceMockRecorder) CreateScheduledOperation(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledOperation", reflect.TypeOf((*MockScheduleService)(nil).CreateScheduledOperation), ctx, req)
+}
+
+// GetScheduledOperation mocks base method.
+func (m *MockScheduleService) GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledOperation indicates an expected call of GetScheduledOperation.
+func (mr *MockScheduleServiceMockRecorder) GetScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledOperation", reflect.TypeOf((*MockScheduleService)(nil).GetScheduledOperation), ctx, id)
+}
+
+// GetScheduledRuns mocks base method.
+func (m *MockScheduleService) GetScheduledRuns(ctx context.Context, id uuid.UUID) ([]models.ScheduledRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledRuns", ctx, id)
+	ret0, _ := ret[0].([]models.ScheduledRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledRuns indicates an expected call of GetScheduledRuns.
+func (mr *MockScheduleServiceMockRecorder) GetScheduledRuns(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledRuns", reflect.TypeOf((*MockScheduleService)(nil).GetScheduledRuns), ctx, id)
+}
+
+// ListScheduledOperations mocks base method.
+func (m *MockScheduleService) ListScheduledOperations(ctx context.Context, walletID uuid.UUID) ([]models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledOperations", ctx, walletID)
+	ret0, _ := ret[0].([]models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledOperations indicates an expected call of ListScheduledOperations.
+func (mr *MockScheduleServiceMockRecorder) ListScheduledOperations(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledOperations", reflect.TypeOf((*MockScheduleService)(nil).ListScheduledOperations), ctx, walletID)
+}
+
+// MockWebhookService is a mock of WebhookService interface.
+type MockWebhookService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookServiceMockRecorder
+}
+
+// MockWebhookServiceMockRecorder is the mock recorder for MockWebhookService.
+type MockWebhookServiceMockRecorder struct {
+	mock *MockWebhookService
+}
+
+// NewMockWebhookService creates a new mock instance.
+func NewMockWebhookService(ctrl *gomock.Controller) *MockWebhookService {
+	mock := &MockWebhookService{ctrl: ctrl}
+	mock.recorder = &MockWebhookServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookService) EXPECT() *MockWebhookServiceMockRecorder {
+	return m.recorder
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWebhookService) CreateWebhook(ctx context.Context, req models.WebhookRequest) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, req)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWebhookServiceMockRecorder) CreateWebhook(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWebhookService)(nil).CreateWebhook), ctx, req)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWebhookService) DeleteWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWebhookServiceMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWebhookService)(nil).DeleteWebhook), ctx, id)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWebhookService) GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWebhookServiceMockRecorder) GetWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWebhookService)(nil).GetWebhook), ctx, id)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockWebhookService) ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, webhookID, status, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockWebhookServiceMockRecorder) ListWebhookDeliveries(ctx, webhookID, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockWebhookService)(nil).ListWebhookDeliveries), ctx, webhookID, status, limit)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockWebhookService) ListWebhooks(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, ownerID)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockWebhookServiceMockRecorder) ListWebhooks(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockWebhookService)(nil).ListWebhooks), ctx, ownerID)
+}
+
+// RetryWebhookDelivery mocks base method.
+func (m *MockWebhookService) RetryWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryWebhookDelivery", ctx, webhookID, deliveryID)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryWebhookDelivery indicates an expected call of RetryWebhookDelivery.
+func (mr *MockWebhookServiceMockRecorder) RetryWebhookDelivery(ctx, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryWebhookDelivery", reflect.TypeOf((*MockWebhookService)(nil).RetryWebhookDelivery), ctx, webhookID, deliveryID)
+}
+
+// MockEventService is a mock of EventService interface.
+type MockEventService struct {
+	ctrl     *gomock.Controller
+	recorder *MockEventServiceMockRecorder
+}
+
+// MockEventServiceMockRecorder is the mock recorder for MockEventService.
+type MockEventServiceMockRecorder struct {
+	mock *MockEventService
+}
+
+// NewMockEventService creates a new mock instance.
+func NewMockEventService(ctrl *gomock.Controller) *MockEventService {
+	mock := &MockEventService{ctrl: ctrl}
+	mock.recorder = &MockEventServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockEventService) EXPECT() *MockEventServiceMockRecorder {
+	return m.recorder
+}
+
+// GetEventStreamSnapshot mocks base method.
+func (m *MockEventService) GetEventStreamSnapshot(ctx context.Context, walletID uuid.UUID) (models.StreamSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventStreamSnapshot", ctx, walletID)
+	ret0, _ := ret[0].(models.StreamSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventStreamSnapshot indicates an expected call of GetEventStreamSnapshot.
+func (mr *MockEventServiceMockRecorder) GetEventStreamSnapshot(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventStreamSnapshot", reflect.TypeOf((*MockEventService)(nil).GetEventStreamSnapshot), ctx, walletID)
+}
+
+// GetWalletEvents mocks base method.
+func (m *MockEventService) GetWalletEvents(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletEvents", ctx, walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletEvents indicates an expected call of GetWalletEvents.
+func (mr *MockEventServiceMockRecorder) GetWalletEvents(ctx, walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletEvents", reflect.TypeOf((*MockEventService)(nil).GetWalletEvents), ctx, walletID, afterID, limit)
+}
+
+// MockAdminService is a mock of AdminService interface.
+type MockAdminService struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminServiceMockRecorder
+}
+
+// MockAdminServiceMockRecorder is the mock recorder for MockAdminService.
+type MockAdminServiceMockRecorder struct {
+	mock *MockAdminService
+}
+
+// NewMockAdminService creates a new mock instance.
+func NewMockAdminService(ctrl *gomock.Controller) *MockAdminService {
+	mock := &MockAdminService{ctrl: ctrl}
+	mock.recorder = &MockAdminServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdminService) EXPECT() *MockAdminServiceMockRecorder {
+	return m.recorder
+}
+
+// GetOverdraftLimitChanges mocks base method.
+func (m *MockAdminService) GetOverdraftLimitChanges(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftLimitChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftLimitChanges", ctx, walletID)
+	ret0, _ := ret[0].([]models.OverdraftLimitChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftLimitChanges indicates an expected call of GetOverdraftLimitChanges.
+func (mr *MockAdminServiceMockRecorder) GetOverdraftLimitChanges(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftLimitChanges", reflect.TypeOf((*MockAdminService)(nil).GetOverdraftLimitChanges), ctx, walletID)
+}
+
+// GetTrialBalance mocks base method.
+func (m *MockAdminService) GetTrialBalance(ctx context.Context) (models.TrialBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrialBalance", ctx)
+	ret0, _ := ret[0].(models.TrialBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrialBalance indicates an expected call of GetTrialBalance.
+func (mr *MockAdminServiceMockRecorder) GetTrialBalance(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrialBalance", reflect.TypeOf((*MockAdminService)(nil).GetTrialBalance), ctx)
+}
+
+// ListFeeSchedules mocks base method.
+func (m *MockAdminService) ListFeeSchedules(ctx context.Context) ([]models.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeeSchedules", ctx)
+	ret0, _ := ret[0].([]models.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeeSchedules indicates an expected call of ListFeeSchedules.
+func (mr *MockAdminServiceMockRecorder) ListFeeSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeeSchedules", reflect.TypeOf((*MockAdminService)(nil).ListFeeSchedules), ctx)
+}
+
+// ReverseTransaction mocks base method.
+func (m *MockAdminService) ReverseTransaction(ctx context.Context, transactionID int64, amount *decimal.Decimal, opts ...models.OperationOption) (models.ReversalResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, transactionID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReverseTransaction", varargs...)
+	ret0, _ := ret[0].(models.ReversalResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransaction indicates an expected call of ReverseTransaction.
+func (mr *MockAdminServiceMockRecorder) ReverseTransaction(ctx, transactionID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, transactionID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransaction", reflect.TypeOf((*MockAdminService)(nil).ReverseTransaction), varargs...)
+}
+
+// SetFeeSchedule mocks base method.
+func (m *MockAdminService) SetFeeSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFeeSchedule", ctx, schedule)
+	ret0, _ := ret[0].(models.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFeeSchedule indicates an expected call of SetFeeSchedule.
+func (mr *MockAdminServiceMockRecorder) SetFeeSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeeSchedule", reflect.TypeOf((*MockAdminService)(nil).SetFeeSchedule), ctx, schedule)
+}
+
+// SetLimitTier mocks base method.
+func (m *MockAdminService) SetLimitTier(ctx context.Context, tier, currency string, limit models.SpendingLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLimitTier", ctx, tier, currency, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLimitTier indicates an expected call of SetLimitTier.
+func (mr *MockAdminServiceMockRecorder) SetLimitTier(ctx, tier, currency, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimitTier", reflect.TypeOf((*MockAdminService)(nil).SetLimitTier), ctx, tier, currency, limit)
+}
+
+// SetOverdraftLimit mocks base method.
+func (m *MockAdminService) SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit decimal.Decimal, reason string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverdraftLimit", ctx, walletID, limit, reason)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOverdraftLimit indicates an expected call of SetOverdraftLimit.
+func (mr *MockAdminServiceMockRecorder) SetOverdraftLimit(ctx, walletID, limit, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverdraftLimit", reflect.TypeOf((*MockAdminService)(nil).SetOverdraftLimit), ctx, walletID, limit, reason)
+}
+
+// SetWalletLimit mocks base method.
+func (m *MockAdminService) SetWalletLimit(ctx context.Context, walletID uuid.UUID, limit models.SpendingLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletLimit", ctx, walletID, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWalletLimit indicates an expected call of SetWalletLimit.
+func (mr *MockAdminServiceMockRecorder) SetWalletLimit(ctx, walletID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletLimit", reflect.TypeOf((*MockAdminService)(nil).SetWalletLimit), ctx, walletID, limit)
+}
+
+// SetWalletStatus mocks base method.
+func (m *MockAdminService) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status, reason string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletStatus", ctx, walletID, status, reason)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletStatus indicates an expected call of SetWalletStatus.
+func (mr *MockAdminServiceMockRecorder) SetWalletStatus(ctx, walletID, status, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletStatus", reflect.TypeOf((*MockAdminService)(nil).SetWalletStatus), ctx, walletID, status, reason)
+}
+
+// SetWalletTier mocks base method.
+func (m *MockAdminService) SetWalletTier(ctx context.Context, walletID uuid.UUID, tier string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletTier", ctx, walletID, tier)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletTier indicates an expected call of SetWalletTier.
+func (mr *MockAdminServiceMockRecorder) SetWalletTier(ctx, walletID, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletTier", reflect.TypeOf((*MockAdminService)(nil).SetWalletTier), ctx, walletID, tier)
+}
+
+// VerifyJournalChain mocks base method.
+func (m *MockAdminService) VerifyJournalChain(ctx context.Context, walletID *uuid.UUID) (models.ChainVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyJournalChain", ctx, walletID)
+	ret0, _ := ret[0].(models.ChainVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyJournalChain indicates an expected call of VerifyJournalChain.
+func (mr *MockAdminServiceMockRecorder) VerifyJournalChain(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyJournalChain", reflect.TypeOf((*MockAdminService)(nil).VerifyJournalChain), ctx, walletID)
+}
+
+// MockWalletService is a mock of WalletService interface.
+type MockWalletService struct {
+	ctrl     *gomock.Controller
+	recorder *MockWalletServiceMockRecorder
+}
+
+// MockWalletServiceMockRecorder is the mock recorder for MockWalletService.
+type MockWalletServiceMockRecorder struct {
+	mock *MockWalletService
+}
+
+// NewMockWalletService creates a new mock instance.
+func NewMockWalletService(ctrl *gomock.Controller) *MockWalletService {
+	mock := &MockWalletService{ctrl: ctrl}
+	mock.recorder = &MockWalletServiceMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWalletService) EXPECT() *MockWalletServiceMockRecorder {
+	return m.recorder
+}
+
+// CancelScheduledOperation mocks base method.
+func (m *MockWalletService) CancelScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CancelScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CancelScheduledOperation indicates an expected call of CancelScheduledOperation.
+func (mr *MockWalletServiceMockRecorder) CancelScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CancelScheduledOperation", reflect.TypeOf((*MockWalletService)(nil).CancelScheduledOperation), ctx, id)
+}
+
+// CaptureHold mocks base method.
+func (m *MockWalletService) CaptureHold(ctx context.Context, holdID uuid.UUID, amount *decimal.Decimal) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CaptureHold", ctx, holdID, amount)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CaptureHold indicates an expected call of CaptureHold.
+func (mr *MockWalletServiceMockRecorder) CaptureHold(ctx, holdID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CaptureHold", reflect.TypeOf((*MockWalletService)(nil).CaptureHold), ctx, holdID, amount)
+}
+
+// Convert mocks base method.
+func (m *MockWalletService) Convert(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.ConversionResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, fromID, toID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Convert", varargs...)
+	ret0, _ := ret[0].(models.ConversionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Convert indicates an expected call of Convert.
+func (mr *MockWalletServiceMockRecorder) Convert(ctx, fromID, toID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, fromID, toID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Convert", reflect.TypeOf((*MockWalletService)(nil).Convert), varargs...)
+}
+
+// CreateHold mocks base method.
+func (m *MockWalletService) CreateHold(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, ttl time.Duration, opts ...models.OperationOption) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount, ttl}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "CreateHold", varargs...)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateHold indicates an expected call of CreateHold.
+func (mr *MockWalletServiceMockRecorder) CreateHold(ctx, walletID, amount, ttl interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount, ttl}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateHold", reflect.TypeOf((*MockWalletService)(nil).CreateHold), varargs...)
+}
+
+// CreateScheduledOperation mocks base method.
+func (m *MockWalletService) CreateScheduledOperation(ctx context.Context, req models.ScheduleRequest) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateScheduledOperation", ctx, req)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateScheduledOperation indicates an expected call of CreateScheduledOperation.
+func (mr *MockWalletServiceMockRecorder) CreateScheduledOperation(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateScheduledOperation", reflect.TypeOf((*MockWalletService)(nil).CreateScheduledOperation), ctx, req)
+}
+
+// CreateWallet mocks base method.
+func (m *MockWalletService) CreateWallet(ctx context.Context, req models.CreateWalletRequest) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWallet", ctx, req)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWallet indicates an expected call of CreateWallet.
+func (mr *MockWalletServiceMockRecorder) CreateWallet(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWallet", reflect.TypeOf((*MockWalletService)(nil).CreateWallet), ctx, req)
+}
+
+// CreateWebhook mocks base method.
+func (m *MockWalletService) CreateWebhook(ctx context.Context, req models.WebhookRequest) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhook", ctx, req)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhook indicates an expected call of CreateWebhook.
+func (mr *MockWalletServiceMockRecorder) CreateWebhook(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhook", reflect.TypeOf((*MockWalletService)(nil).CreateWebhook), ctx, req)
+}
+
+// DeleteWebhook mocks base method.
+func (m *MockWalletService) DeleteWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteWebhook", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteWebhook indicates an expected call of DeleteWebhook.
+func (mr *MockWalletServiceMockRecorder) DeleteWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteWebhook", reflect.TypeOf((*MockWalletService)(nil).DeleteWebhook), ctx, id)
+}
+
+// Deposit mocks base method.
+func (m *MockWalletService) Deposit(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, walletID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Deposit", varargs...)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(bool)
+	ret2, _ := ret[2].(error)
+	return ret0, ret1, ret2
+}
+
+// Deposit indicates an expected call of Deposit.
+func (mr *MockWalletServiceMockRecorder) Deposit(ctx, walletID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, walletID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Deposit", reflect.TypeOf((*MockWalletService)(nil).Deposit), varargs...)
+}
+
+// ExecuteConversion mocks base method.
+func (m *MockWalletService) ExecuteConversion(ctx context.Context, quoteID uuid.UUID, opts ...models.OperationOption) (models.ConversionResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, quoteID}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ExecuteConversion", varargs...)
+	ret0, _ := ret[0].(models.ConversionResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ExecuteConversion indicates an expected call of ExecuteConversion.
+func (mr *MockWalletServiceMockRecorder) ExecuteConversion(ctx, quoteID interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, quoteID}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExecuteConversion", reflect.TypeOf((*MockWalletService)(nil).ExecuteConversion), varargs...)
+}
+
+// ExportStatement mocks base method.
+func (m *MockWalletService) ExportStatement(ctx context.Context, walletID uuid.UUID, from, to time.Time, w models.StatementWriter) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ExportStatement", ctx, walletID, from, to, w)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ExportStatement indicates an expected call of ExportStatement.
+func (mr *MockWalletServiceMockRecorder) ExportStatement(ctx, walletID, from, to, w interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ExportStatement", reflect.TypeOf((*MockWalletService)(nil).ExportStatement), ctx, walletID, from, to, w)
+}
+
+// GetBalance mocks base method.
+func (m *MockWalletService) GetBalance(ctx context.Context, walletID uuid.UUID) (decimal.Decimal, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalance", ctx, walletID)
+	ret0, _ := ret[0].(decimal.Decimal)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalance indicates an expected call of GetBalance.
+func (mr *MockWalletServiceMockRecorder) GetBalance(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalance", reflect.TypeOf((*MockWalletService)(nil).GetBalance), ctx, walletID)
+}
+
+// GetBalanceAt mocks base method.
+func (m *MockWalletService) GetBalanceAt(ctx context.Context, walletID uuid.UUID, at time.Time) (models.BalanceAt, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetBalanceAt", ctx, walletID, at)
+	ret0, _ := ret[0].(models.BalanceAt)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetBalanceAt indicates an expected call of GetBalanceAt.
+func (mr *MockWalletServiceMockRecorder) GetBalanceAt(ctx, walletID, at interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetBalanceAt", reflect.TypeOf((*MockWalletService)(nil).GetBalanceAt), ctx, walletID, at)
+}
+
+// GetEventStreamSnapshot mocks base method.
+func (m *MockWalletService) GetEventStreamSnapshot(ctx context.Context, walletID uuid.UUID) (models.StreamSnapshot, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetEventStreamSnapshot", ctx, walletID)
+	ret0, _ := ret[0].(models.StreamSnapshot)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetEventStreamSnapshot indicates an expected call of GetEventStreamSnapshot.
+func (mr *MockWalletServiceMockRecorder) GetEventStreamSnapshot(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetEventStreamSnapshot", reflect.TypeOf((*MockWalletService)(nil).GetEventStreamSnapshot), ctx, walletID)
+}
+
+// GetHold mocks base method.
+func (m *MockWalletService) GetHold(ctx context.Context, holdID uuid.UUID) (models.Hold, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetHold", ctx, holdID)
+	ret0, _ := ret[0].(models.Hold)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetHold indicates an expected call of GetHold.
+func (mr *MockWalletServiceMockRecorder) GetHold(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetHold", reflect.TypeOf((*MockWalletService)(nil).GetHold), ctx, holdID)
+}
+
+// GetOverdraftLimitChanges mocks base method.
+func (m *MockWalletService) GetOverdraftLimitChanges(ctx context.Context, walletID uuid.UUID) ([]models.OverdraftLimitChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetOverdraftLimitChanges", ctx, walletID)
+	ret0, _ := ret[0].([]models.OverdraftLimitChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetOverdraftLimitChanges indicates an expected call of GetOverdraftLimitChanges.
+func (mr *MockWalletServiceMockRecorder) GetOverdraftLimitChanges(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetOverdraftLimitChanges", reflect.TypeOf((*MockWalletService)(nil).GetOverdraftLimitChanges), ctx, walletID)
+}
+
+// GetQuote mocks base method.
+func (m *MockWalletService) GetQuote(ctx context.Context, quoteID uuid.UUID) (models.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetQuote", ctx, quoteID)
+	ret0, _ := ret[0].(models.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetQuote indicates an expected call of GetQuote.
+func (mr *MockWalletServiceMockRecorder) GetQuote(ctx, quoteID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetQuote", reflect.TypeOf((*MockWalletService)(nil).GetQuote), ctx, quoteID)
+}
+
+// GetScheduledOperation mocks base method.
+func (m *MockWalletService) GetScheduledOperation(ctx context.Context, id uuid.UUID) (models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledOperation", ctx, id)
+	ret0, _ := ret[0].(models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledOperation indicates an expected call of GetScheduledOperation.
+func (mr *MockWalletServiceMockRecorder) GetScheduledOperation(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledOperation", reflect.TypeOf((*MockWalletService)(nil).GetScheduledOperation), ctx, id)
+}
+
+// GetScheduledRuns mocks base method.
+func (m *MockWalletService) GetScheduledRuns(ctx context.Context, id uuid.UUID) ([]models.ScheduledRun, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetScheduledRuns", ctx, id)
+	ret0, _ := ret[0].([]models.ScheduledRun)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetScheduledRuns indicates an expected call of GetScheduledRuns.
+func (mr *MockWalletServiceMockRecorder) GetScheduledRuns(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetScheduledRuns", reflect.TypeOf((*MockWalletService)(nil).GetScheduledRuns), ctx, id)
+}
+
+// GetTransactions mocks base method.
+func (m *MockWalletService) GetTransactions(ctx context.Context, walletID uuid.UUID, filter models.TransactionFilter) (models.TransactionPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTransactions", ctx, walletID, filter)
+	ret0, _ := ret[0].(models.TransactionPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTransactions indicates an expected call of GetTransactions.
+func (mr *MockWalletServiceMockRecorder) GetTransactions(ctx, walletID, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTransactions", reflect.TypeOf((*MockWalletService)(nil).GetTransactions), ctx, walletID, filter)
+}
+
+// GetTrialBalance mocks base method.
+func (m *MockWalletService) GetTrialBalance(ctx context.Context) (models.TrialBalance, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetTrialBalance", ctx)
+	ret0, _ := ret[0].(models.TrialBalance)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetTrialBalance indicates an expected call of GetTrialBalance.
+func (mr *MockWalletServiceMockRecorder) GetTrialBalance(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetTrialBalance", reflect.TypeOf((*MockWalletService)(nil).GetTrialBalance), ctx)
+}
+
+// GetWallet mocks base method.
+func (m *MockWalletService) GetWallet(ctx context.Context, walletID uuid.UUID) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWallet", ctx, walletID)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWallet indicates an expected call of GetWallet.
+func (mr *MockWalletServiceMockRecorder) GetWallet(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWallet", reflect.TypeOf((*MockWalletService)(nil).GetWallet), ctx, walletID)
+}
+
+// GetWalletEvents mocks base method.
+func (m *MockWalletService) GetWalletEvents(ctx context.Context, walletID uuid.UUID, afterID int64, limit int) ([]models.Event, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletEvents", ctx, walletID, afterID, limit)
+	ret0, _ := ret[0].([]models.Event)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletEvents indicates an expected call of GetWalletEvents.
+func (mr *MockWalletServiceMockRecorder) GetWalletEvents(ctx, walletID, afterID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletEvents", reflect.TypeOf((*MockWalletService)(nil).GetWalletEvents), ctx, walletID, afterID, limit)
+}
+
+// GetWalletLimits mocks base method.
+func (m *MockWalletService) GetWalletLimits(ctx context.Context, walletID uuid.UUID) ([]models.SpendingLimit, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWalletLimits", ctx, walletID)
+	ret0, _ := ret[0].([]models.SpendingLimit)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWalletLimits indicates an expected call of GetWalletLimits.
+func (mr *MockWalletServiceMockRecorder) GetWalletLimits(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWalletLimits", reflect.TypeOf((*MockWalletService)(nil).GetWalletLimits), ctx, walletID)
+}
+
+// GetWebhook mocks base method.
+func (m *MockWalletService) GetWebhook(ctx context.Context, id uuid.UUID) (models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetWebhook", ctx, id)
+	ret0, _ := ret[0].(models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetWebhook indicates an expected call of GetWebhook.
+func (mr *MockWalletServiceMockRecorder) GetWebhook(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetWebhook", reflect.TypeOf((*MockWalletService)(nil).GetWebhook), ctx, id)
+}
+
+// ListFeeSchedules mocks base method.
+func (m *MockWalletService) ListFeeSchedules(ctx context.Context) ([]models.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListFeeSchedules", ctx)
+	ret0, _ := ret[0].([]models.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListFeeSchedules indicates an expected call of ListFeeSchedules.
+func (mr *MockWalletServiceMockRecorder) ListFeeSchedules(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListFeeSchedules", reflect.TypeOf((*MockWalletService)(nil).ListFeeSchedules), ctx)
+}
+
+// ListScheduledOperations mocks base method.
+func (m *MockWalletService) ListScheduledOperations(ctx context.Context, walletID uuid.UUID) ([]models.ScheduledOperation, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListScheduledOperations", ctx, walletID)
+	ret0, _ := ret[0].([]models.ScheduledOperation)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListScheduledOperations indicates an expected call of ListScheduledOperations.
+func (mr *MockWalletServiceMockRecorder) ListScheduledOperations(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListScheduledOperations", reflect.TypeOf((*MockWalletService)(nil).ListScheduledOperations), ctx, walletID)
+}
+
+// ListWallets mocks base method.
+func (m *MockWalletService) ListWallets(ctx context.Context, filter models.WalletFilter) (models.WalletPage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWallets", ctx, filter)
+	ret0, _ := ret[0].(models.WalletPage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWallets indicates an expected call of ListWallets.
+func (mr *MockWalletServiceMockRecorder) ListWallets(ctx, filter interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWallets", reflect.TypeOf((*MockWalletService)(nil).ListWallets), ctx, filter)
+}
+
+// ListWebhookDeliveries mocks base method.
+func (m *MockWalletService) ListWebhookDeliveries(ctx context.Context, webhookID uuid.UUID, status string, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhookDeliveries", ctx, webhookID, status, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhookDeliveries indicates an expected call of ListWebhookDeliveries.
+func (mr *MockWalletServiceMockRecorder) ListWebhookDeliveries(ctx, webhookID, status, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhookDeliveries", reflect.TypeOf((*MockWalletService)(nil).ListWebhookDeliveries), ctx, webhookID, status, limit)
+}
+
+// ListWebhooks mocks base method.
+func (m *MockWalletService) ListWebhooks(ctx context.Context, ownerID string) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ListWebhooks", ctx, ownerID)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ListWebhooks indicates an expected call of ListWebhooks.
+func (mr *MockWalletServiceMockRecorder) ListWebhooks(ctx, ownerID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ListWebhooks", reflect.TypeOf((*MockWalletService)(nil).ListWebhooks), ctx, ownerID)
+}
+
+// QuoteConversion mocks base method.
+func (m *MockWalletService) QuoteConversion(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal) (models.ConversionQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteConversion", ctx, fromID, toID, amount)
+	ret0, _ := ret[0].(models.ConversionQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteConversion indicates an expected call of QuoteConversion.
+func (mr *MockWalletServiceMockRecorder) QuoteConversion(ctx, fromID, toID, amount interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteConversion", reflect.TypeOf((*MockWalletService)(nil).QuoteConversion), ctx, fromID, toID, amount)
+}
+
+// QuoteFee mocks base method.
+func (m *MockWalletService) QuoteFee(ctx context.Context, req models.FeeQuoteRequest) (models.FeeQuote, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "QuoteFee", ctx, req)
+	ret0, _ := ret[0].(models.FeeQuote)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// QuoteFee indicates an expected call of QuoteFee.
+func (mr *MockWalletServiceMockRecorder) QuoteFee(ctx, req interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "QuoteFee", reflect.TypeOf((*MockWalletService)(nil).QuoteFee), ctx, req)
+}
+
+// RetryWebhookDelivery mocks base method.
+func (m *MockWalletService) RetryWebhookDelivery(ctx context.Context, webhookID uuid.UUID, deliveryID int64) (models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RetryWebhookDelivery", ctx, webhookID, deliveryID)
+	ret0, _ := ret[0].(models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RetryWebhookDelivery indicates an expected call of RetryWebhookDelivery.
+func (mr *MockWalletServiceMockRecorder) RetryWebhookDelivery(ctx, webhookID, deliveryID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RetryWebhookDelivery", reflect.TypeOf((*MockWalletService)(nil).RetryWebhookDelivery), ctx, webhookID, deliveryID)
+}
+
+// ReverseTransaction mocks base method.
+func (m *MockWalletService) ReverseTransaction(ctx context.Context, transactionID int64, amount *decimal.Decimal, opts ...models.OperationOption) (models.ReversalResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, transactionID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "ReverseTransaction", varargs...)
+	ret0, _ := ret[0].(models.ReversalResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReverseTransaction indicates an expected call of ReverseTransaction.
+func (mr *MockWalletServiceMockRecorder) ReverseTransaction(ctx, transactionID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, transactionID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReverseTransaction", reflect.TypeOf((*MockWalletService)(nil).ReverseTransaction), varargs...)
+}
+
+// SetFeeSchedule mocks base method.
+func (m *MockWalletService) SetFeeSchedule(ctx context.Context, schedule models.FeeSchedule) (models.FeeSchedule, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetFeeSchedule", ctx, schedule)
+	ret0, _ := ret[0].(models.FeeSchedule)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetFeeSchedule indicates an expected call of SetFeeSchedule.
+func (mr *MockWalletServiceMockRecorder) SetFeeSchedule(ctx, schedule interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetFeeSchedule", reflect.TypeOf((*MockWalletService)(nil).SetFeeSchedule), ctx, schedule)
+}
+
+// SetLimitTier mocks base method.
+func (m *MockWalletService) SetLimitTier(ctx context.Context, tier, currency string, limit models.SpendingLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetLimitTier", ctx, tier, currency, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetLimitTier indicates an expected call of SetLimitTier.
+func (mr *MockWalletServiceMockRecorder) SetLimitTier(ctx, tier, currency, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetLimitTier", reflect.TypeOf((*MockWalletService)(nil).SetLimitTier), ctx, tier, currency, limit)
+}
+
+// SetOverdraftLimit mocks base method.
+func (m *MockWalletService) SetOverdraftLimit(ctx context.Context, walletID uuid.UUID, limit decimal.Decimal, reason string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetOverdraftLimit", ctx, walletID, limit, reason)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetOverdraftLimit indicates an expected call of SetOverdraftLimit.
+func (mr *MockWalletServiceMockRecorder) SetOverdraftLimit(ctx, walletID, limit, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetOverdraftLimit", reflect.TypeOf((*MockWalletService)(nil).SetOverdraftLimit), ctx, walletID, limit, reason)
+}
+
+// SetWalletLimit mocks base method.
+func (m *MockWalletService) SetWalletLimit(ctx context.Context, walletID uuid.UUID, limit models.SpendingLimit) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletLimit", ctx, walletID, limit)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// SetWalletLimit indicates an expected call of SetWalletLimit.
+func (mr *MockWalletServiceMockRecorder) SetWalletLimit(ctx, walletID, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletLimit", reflect.TypeOf((*MockWalletService)(nil).SetWalletLimit), ctx, walletID, limit)
+}
+
+// SetWalletStatus mocks base method.
+func (m *MockWalletService) SetWalletStatus(ctx context.Context, walletID uuid.UUID, status, reason string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletStatus", ctx, walletID, status, reason)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletStatus indicates an expected call of SetWalletStatus.
+func (mr *MockWalletServiceMockRecorder) SetWalletStatus(ctx, walletID, status, reason interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletStatus", reflect.TypeOf((*MockWalletService)(nil).SetWalletStatus), ctx, walletID, status, reason)
+}
+
+// SetWalletTier mocks base method.
+func (m *MockWalletService) SetWalletTier(ctx context.Context, walletID uuid.UUID, tier string) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SetWalletTier", ctx, walletID, tier)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SetWalletTier indicates an expected call of SetWalletTier.
+func (mr *MockWalletServiceMockRecorder) SetWalletTier(ctx, walletID, tier interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SetWalletTier", reflect.TypeOf((*MockWalletService)(nil).SetWalletTier), ctx, walletID, tier)
+}
+
+// Transfer mocks base method.
+func (m *MockWalletService) Transfer(ctx context.Context, fromID, toID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (models.TransferResult, error) {
+	m.ctrl.T.Helper()
+	varargs := []interface{}{ctx, fromID, toID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Transfer", varargs...)
+	ret0, _ := ret[0].(models.TransferResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Transfer indicates an expected call of Transfer.
+func (mr *MockWalletServiceMockRecorder) Transfer(ctx, fromID, toID, amount interface{}, opts ...interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	varargs := append([]interface{}{ctx, fromID, toID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Transfer", reflect.TypeOf((*MockWalletService)(nil).Transfer), varargs...)
+}
+
+// UpdateWallet mocks base method.
+func (m *MockWalletService) UpdateWallet(ctx context.Context, walletID uuid.UUID, update models.UpdateWalletRequest) (models.Wallet, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateWallet", ctx, walletID, update)
+	ret0, _ := ret[0].(models.Wallet)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateWallet indicates an expected call of UpdateWallet.
+func (mr *MockWalletServiceMockRecorder) UpdateWallet(ctx, walletID, update interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateWallet", reflect.TypeOf((*MockWalletService)(nil).UpdateWallet), ctx, walletID, update)
+}
+
+// VerifyJournalChain mocks base method.
+func (m *MockWalletService) VerifyJournalChain(ctx context.Context, walletID *uuid.UUID) (models.ChainVerification, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VerifyJournalChain", ctx, walletID)
+	ret0, _ := ret[0].(models.ChainVerification)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VerifyJournalChain indicates an expected call of VerifyJournalChain.
+func (mr *MockWalletServiceMockRecorder) VerifyJournalChain(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VerifyJournalChain", reflect.TypeOf((*MockWalletService)(nil).VerifyJournalChain), ctx, walletID)
+}
+
+// VoidHold mocks base method.
+func (m *MockWalletService) VoidHold(ctx context.Context, holdID uuid.UUID) (models.HoldResult, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "VoidHold", ctx, holdID)
+	ret0, _ := ret[0].(models.HoldResult)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// VoidHold indicates an expected call of VoidHold.
+func (mr *MockWalletServiceMockRecorder) VoidHold(ctx, holdID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "VoidHold", reflect.TypeOf((*MockWalletService)(nil).VoidHold), ctx, holdID)
 }
 
 // Withdraw mocks base method.
-func (m *MockWalletService) Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal) (decimal.Decimal, error) {
+func (m *MockWalletService) Withdraw(ctx context.Context, walletID uuid.UUID, amount decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "Withdraw", ctx, walletID, amount)
+	varargs := []interface{}{ctx, walletID, amount}
+	for _, a := range opts {
+		varargs = append(varargs, a)
+	}
+	ret := m.ctrl.Call(m, "Withdraw", varargs...)
 	ret0, _ := ret[0].(decimal.Decimal)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // Withdraw indicates an expected call of Withdraw.
-func (mr *MockWalletServiceMockRecorder) Withdraw(ctx, walletID, amount interface{}) *gomock.Call {
+func (mr *MockWalletServiceMockRecorder) Withdraw(ctx, walletID, amount interface{}, opts ...interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWalletService)(nil).Withdraw), ctx, walletID, amount)
+	varargs := append([]interface{}{ctx, walletID, amount}, opts...)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Withdraw", reflect.TypeOf((*MockWalletService)(nil).Withdraw), varargs...)
 }
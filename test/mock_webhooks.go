@@ -0,0 +1,97 @@
+// Code generated by MockGen. DO NOT EDIT.
+// Source: webhooks.go
+
+// Package test is a generated GoMock package.
+package test
+
+import (
+	context "context"
+	reflect "reflect"
+	models "test_wallet/internal/models"
+	time "time"
+
+	gomock "github.com/golang/mock/gomock"
+	uuid "github.com/google/uuid"
+)
+
+// MockWebhookStore is a mock of Store interface.
+type MockWebhookStore struct {
+	ctrl     *gomock.Controller
+	recorder *MockWebhookStoreMockRecorder
+}
+
+// MockWebhookStoreMockRecorder is the mock recorder for MockWebhookStore.
+type MockWebhookStoreMockRecorder struct {
+	mock *MockWebhookStore
+}
+
+// NewMockWebhookStore creates a new mock instance.
+func NewMockWebhookStore(ctrl *gomock.Controller) *MockWebhookStore {
+	mock := &MockWebhookStore{ctrl: ctrl}
+	mock.recorder = &MockWebhookStoreMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockWebhookStore) EXPECT() *MockWebhookStoreMockRecorder {
+	return m.recorder
+}
+
+// ClaimWebhookDeliveries mocks base method.
+func (m *MockWebhookStore) ClaimWebhookDeliveries(ctx context.Context, now time.Time, lease time.Duration, limit int) ([]models.WebhookDelivery, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ClaimWebhookDeliveries", ctx, now, lease, limit)
+	ret0, _ := ret[0].([]models.WebhookDelivery)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ClaimWebhookDeliveries indicates an expected call of ClaimWebhookDeliveries.
+func (mr *MockWebhookStoreMockRecorder) ClaimWebhookDeliveries(ctx, now, lease, limit interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ClaimWebhookDeliveries", reflect.TypeOf((*MockWebhookStore)(nil).ClaimWebhookDeliveries), ctx, now, lease, limit)
+}
+
+// CreateWebhookDeliveries mocks base method.
+func (m *MockWebhookStore) CreateWebhookDeliveries(ctx context.Context, deliveries []models.WebhookDelivery) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateWebhookDeliveries", ctx, deliveries)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateWebhookDeliveries indicates an expected call of CreateWebhookDeliveries.
+func (mr *MockWebhookStoreMockRecorder) CreateWebhookDeliveries(ctx, deliveries interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateWebhookDeliveries", reflect.TypeOf((*MockWebhookStore)(nil).CreateWebhookDeliveries), ctx, deliveries)
+}
+
+// RecordWebhookAttempt mocks base method.
+func (m *MockWebhookStore) RecordWebhookAttempt(ctx context.Context, delivery models.WebhookDelivery) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RecordWebhookAttempt", ctx, delivery)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// RecordWebhookAttempt indicates an expected call of RecordWebhookAttempt.
+func (mr *MockWebhookStoreMockRecorder) RecordWebhookAttempt(ctx, delivery interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RecordWebhookAttempt", reflect.TypeOf((*MockWebhookStore)(nil).RecordWebhookAttempt), ctx, delivery)
+}
+
+// WebhookSubscriptionsForWallet mocks base method.
+func (m *MockWebhookStore) WebhookSubscriptionsForWallet(ctx context.Context, walletID uuid.UUID) ([]models.WebhookSubscription, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "WebhookSubscriptionsForWallet", ctx, walletID)
+	ret0, _ := ret[0].([]models.WebhookSubscription)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// WebhookSubscriptionsForWallet indicates an expected call of WebhookSubscriptionsForWallet.
+func (mr *MockWebhookStoreMockRecorder) WebhookSubscriptionsForWallet(ctx, walletID interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "WebhookSubscriptionsForWallet", reflect.TypeOf((*MockWebhookStore)(nil).WebhookSubscriptionsForWallet), ctx, walletID)
+}
@@ -0,0 +1,222 @@
+package test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"test_wallet/internal/handlers"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/gin-gonic/gin"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// assertConforms проверяет, что ответ w на запрос req к маршруту gin route описан в openapi.json.
+func assertConforms(t *testing.T, route string, req *http.Request, params map[string]string, w *httptest.ResponseRecorder) {
+	t.Helper()
+	specRoute, ok := handlers.SpecRoute(route, req.Method)
+	require.True(t, ok, "%s %s is not in the spec", req.Method, route)
+	err := openapi3filter.ValidateResponse(context.Background(), &openapi3filter.ResponseValidationInput{
+		RequestValidationInput: &openapi3filter.RequestValidationInput{Request: req, PathParams: params, Route: specRoute},
+		Status:                 w.Code,
+		Header:                 w.Header(),
+		Body:                   io.NopCloser(bytes.NewReader(w.Body.Bytes())),
+		Options:                &openapi3filter.Options{IncludeResponseStatus: true},
+	})
+	assert.NoError(t, err, "%s %s -> %d %s", req.Method, req.URL, w.Code, w.Body.String())
+}
+
+func TestOpenAPISpecServed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	handler := handlers.NewWalletHTTPHandler(NewMockWalletService(ctrl))
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	req, _ := http.NewRequest("GET", "/api/v1/openapi.json", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	doc, err := openapi3.NewLoader().LoadFromData(w.Body.Bytes())
+	require.NoError(t, err)
+	assert.NoError(t, doc.Validate(context.Background()))
+	assert.NotNil(t, doc.Paths.Value("/wallet").Post)
+	assert.NotNil(t, doc.Paths.Value("/wallets/{wallet_id}").Get)
+}
+
+func TestOpenAPIConformance_WalletOperation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+	walletID := uuid.New()
+
+	withFee := func(fee decimal.Decimal, balance decimal.Decimal, created bool) any {
+		return func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			var o models.OperationOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			*o.Fee = fee
+			return balance, created, nil
+		}
+	}
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(100), gomock.Any()).
+		DoAndReturn(withFee(decimal.RequireFromString("0.5"), decimal.RequireFromString("99.5"), true))
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(5), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			var o models.OperationOptions
+			for _, opt := range opts {
+				opt(&o)
+			}
+			o.Idempotency.Replayed = &models.IdempotentResponse{StatusCode: http.StatusOK, Body: []byte(`{"balance":"105"}`)}
+			return decimal.Zero, false, nil
+		})
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(7), gomock.Any()).
+		Return(decimal.Zero, false, repository.ErrWalletFrozen)
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(30), gomock.Any()).
+		Return(decimal.NewFromInt(70), nil)
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(500), gomock.Any()).
+		Return(decimal.NewFromInt(70), &repository.InsufficientFundsError{AvailableCredit: decimal.NewFromInt(70)})
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(60), gomock.Any()).
+		Return(decimal.NewFromInt(70), &repository.LimitExceededError{
+			Limit: models.LimitDailyCap, Max: decimal.NewFromInt(100), Used: decimal.NewFromInt(50),
+		})
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(1), gomock.Any()).
+		Return(decimal.Zero, repository.ErrWalletNotFound)
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(2), gomock.Any()).
+		Return(decimal.Zero, repository.ErrWalletClosed)
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(3), gomock.Any()).
+		Return(decimal.Zero, assert.AnError)
+
+	cases := []struct {
+		body   string
+		key    string
+		status int
+	}{
+		{`{"walletId":"%s","operationType":"DEPOSIT","amount":"100","currency":"RUB"}`, "", http.StatusCreated},
+		{`{"walletId":"%s","operationType":"DEPOSIT","amount":5}`, "dep-5", http.StatusOK},
+		{`{"walletId":"%s","operationType":"DEPOSIT","amount":"7"}`, "", http.StatusLocked},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"30"}`, "", http.StatusOK},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"500"}`, "", http.StatusConflict},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"60"}`, "", http.StatusUnprocessableEntity},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"1"}`, "", http.StatusNotFound},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"2"}`, "", http.StatusGone},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"3"}`, "", http.StatusServiceUnavailable},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"-3"}`, "", http.StatusBadRequest},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"3","currency":"XXX"}`, "", http.StatusBadRequest},
+		{`{"walletId":"%s","operationType":"WITHDRAW","amount":"3","requestId":"a"}`, "b", http.StatusBadRequest},
+	}
+	for _, tc := range cases {
+		body := strings.Replace(tc.body, "%s", walletID.String(), 1)
+		req, _ := http.NewRequest("POST", "/api/v1/wallet", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		if tc.key != "" {
+			req.Header.Set(handlers.IdempotencyKeyHeader, tc.key)
+		}
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, tc.status, w.Code, body)
+		assertConforms(t, "/api/v1/wallet", req, nil, w)
+	}
+}
+
+func TestOpenAPIConformance_GetBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	handler := handlers.NewWalletHTTPHandler(mockService)
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+
+	found, missing, broken := uuid.New(), uuid.New(), uuid.New()
+	mockService.EXPECT().GetWallet(gomock.Any(), found).Return(models.Wallet{
+		ID:        found,
+		Balance:   decimal.RequireFromString("12.34"),
+		Held:      decimal.NewFromInt(2),
+		Available: decimal.RequireFromString("10.34"),
+	}, nil)
+	mockService.EXPECT().GetWallet(gomock.Any(), missing).Return(models.Wallet{}, repository.ErrWalletNotFound)
+	mockService.EXPECT().GetWallet(gomock.Any(), broken).Return(models.Wallet{}, assert.AnError)
+
+	for id, status := range map[string]int{
+		found.String():   http.StatusOK,
+		missing.String(): http.StatusNotFound,
+		broken.String():  http.StatusServiceUnavailable,
+		"not-a-uuid":     http.StatusBadRequest,
+	} {
+		req, _ := http.NewRequest("GET", "/api/v1/wallets/"+id, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, status, w.Code, id)
+		assertConforms(t, "/api/v1/wallets/:wallet_id", req, map[string]string{"wallet_id": id}, w)
+	}
+}
+
+func TestOpenAPIRequestValidation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	// Запросы, не соответствующие спецификации, отклоняются до обработчика: сервис не вызывается
+	handler := handlers.NewWalletHTTPHandler(NewMockWalletService(ctrl))
+	r := gin.Default()
+	handler.RegisterRoutes(r)
+	walletID := uuid.New().String()
+
+	for _, body := range []string{
+		`{"walletId":"` + walletID + `","operationType":"DEPOSIT"}`,
+		`{"walletId":"` + walletID + `","operationType":"REFUND","amount":"1"}`,
+		`{"walletId":"` + walletID + `","operationType":"DEPOSIT","amount":"1e3"}`,
+		`{"walletId":"` + walletID + `","operationType":"DEPOSIT","amount":"1","currency":"RUBLES"}`,
+		`{"walletId":"` + walletID + `","operationType":"DEPOSIT","amount":"1","requestId":"` + strings.Repeat("k", 256) + `"}`,
+	} {
+		req, _ := http.NewRequest("POST", "/api/v1/wallet", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, body)
+		assert.Contains(t, w.Body.String(), `"error":"invalid request"`, body)
+		assert.Contains(t, w.Body.String(), `"details"`, body)
+		assertConforms(t, "/api/v1/wallet", req, nil, w)
+	}
+
+	req, _ := http.NewRequest("POST", "/api/v1/wallet",
+		strings.NewReader(`{"walletId":"`+walletID+`","operationType":"DEPOSIT","amount":"1"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(handlers.IdempotencyKeyHeader, strings.Repeat("k", 256))
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	req, _ = http.NewRequest("GET", "/api/v1/wallets/not-a-uuid", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"invalid wallet_id"}`, w.Body.String())
+}
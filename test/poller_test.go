@@ -0,0 +1,22 @@
+package test
+
+import (
+	"test_wallet/internal/poller"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestPollerBackoff(t *testing.T) {
+	for attempt, want := range map[int]time.Duration{
+		1: time.Second,
+		2: 2 * time.Second,
+		3: 4 * time.Second,
+		4: 8 * time.Second,
+		5: 10 * time.Second,
+		9: 10 * time.Second,
+	} {
+		assert.Equal(t, want, poller.Backoff(time.Second, 10*time.Second, attempt), "attempt %d", attempt)
+	}
+}
@@ -0,0 +1,80 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"test_wallet/internal/models"
+	"test_wallet/internal/rates"
+	"testing"
+	"time"
+
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStaticProvider(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{
+		"timestamp": "2026-10-01T00:00:00Z",
+		"rates": {"USD": {"RUB": "80"}}
+	}`), 0o600))
+
+	provider, err := rates.NewStaticProvider(path)
+	require.NoError(t, err)
+
+	rate, err := provider.Rate(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assert.True(t, rate.Value.Equal(decimal.NewFromInt(80)))
+	assert.Equal(t, time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC), rate.Timestamp.UTC())
+
+	inverse, err := provider.Rate(context.Background(), "RUB", "USD")
+	assert.NoError(t, err)
+	assert.True(t, inverse.Value.Equal(decimal.RequireFromString("0.0125")))
+
+	_, err = provider.Rate(context.Background(), "USD", "JPY")
+	assert.ErrorIs(t, err, models.ErrRateNotFound)
+}
+
+func TestStaticProvider_InvalidFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"rates": {"USD": {"RUB": "0"}}}`), 0o600))
+	_, err := rates.NewStaticProvider(path)
+	assert.Error(t, err)
+}
+
+func TestHTTPProvider(t *testing.T) {
+	rateAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	stub := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		from, to := r.URL.Query().Get("from"), r.URL.Query().Get("to")
+		switch {
+		case from == "USD" && to == "RUB":
+			_ = json.NewEncoder(w).Encode(models.Rate{
+				From: from, To: to, Value: decimal.RequireFromString("92.5"), Timestamp: rateAt,
+			})
+		case from == "EUR":
+			w.WriteHeader(http.StatusInternalServerError)
+		default:
+			w.WriteHeader(http.StatusNotFound)
+		}
+	}))
+	defer stub.Close()
+
+	provider := rates.NewHTTPProvider(stub.URL+"/rates", stub.Client())
+
+	rate, err := provider.Rate(context.Background(), "USD", "RUB")
+	assert.NoError(t, err)
+	assert.True(t, rate.Value.Equal(decimal.RequireFromString("92.5")))
+	assert.True(t, rate.Timestamp.Equal(rateAt))
+
+	_, err = provider.Rate(context.Background(), "USD", "JPY")
+	assert.ErrorIs(t, err, models.ErrRateNotFound)
+
+	_, err = provider.Rate(context.Background(), "EUR", "RUB")
+	assert.Error(t, err)
+	assert.NotErrorIs(t, err, models.ErrRateNotFound)
+}
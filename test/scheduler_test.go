@@ -0,0 +1,237 @@
+package test
+
+import (
+	"context"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+	"test_wallet/internal/scheduler"
+	"test_wallet/internal/service"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func utc(s string) time.Time {
+	t, err := time.Parse(time.RFC3339, s)
+	if err != nil {
+		panic(err)
+	}
+	return t
+}
+
+func TestParseCron_Next(t *testing.T) {
+	cases := []struct{ expr, after, next string }{
+		{"* * * * *", "2024-03-01T10:00:30Z", "2024-03-01T10:01:00Z"},
+		{"*/15 * * * *", "2024-03-01T10:01:00Z", "2024-03-01T10:15:00Z"},
+		{"0 9 * * *", "2024-03-01T09:00:00Z", "2024-03-02T09:00:00Z"},
+		{"30 8 1 * *", "2024-01-31T12:00:00Z", "2024-02-01T08:30:00Z"},
+		{"0 0 * * 1-5", "2024-03-01T12:00:00Z", "2024-03-04T00:00:00Z"}, // пятница → понедельник
+		{"0 12 29 2 *", "2024-03-01T00:00:00Z", "2028-02-29T12:00:00Z"},
+		{"0 0 13 * 5", "2024-03-01T12:00:00Z", "2024-03-08T00:00:00Z"}, // день месяца ИЛИ пятница
+		{"0 6,18 * * *", "2024-03-01T07:00:00Z", "2024-03-01T18:00:00Z"},
+	}
+	for _, c := range cases {
+		cron, err := scheduler.ParseCron(c.expr)
+		assert.NoError(t, err, c.expr)
+		assert.Equal(t, utc(c.next), cron.Next(utc(c.after)), c.expr)
+	}
+}
+
+func TestParseCron_Invalid(t *testing.T) {
+	for _, expr := range []string{"", "* * * *", "60 * * * *", "* 24 * * *", "* * 0 * *", "*/0 * * * *", "5-1 * * * *", "a * * * *"} {
+		_, err := scheduler.ParseCron(expr)
+		assert.ErrorIs(t, err, models.ErrInvalidSchedule, expr)
+	}
+	cron, err := scheduler.ParseCron("0 0 30 2 *")
+	assert.NoError(t, err)
+	assert.True(t, cron.Next(utc("2024-01-01T00:00:00Z")).IsZero())
+}
+
+func newTestScheduler(ctrl *gomock.Controller, now time.Time) (*scheduler.Scheduler, *MockStore, *MockExecutor) {
+	store := NewMockStore(ctrl)
+	executor := NewMockExecutor(ctrl)
+	s := scheduler.New(store, executor, testLogger,
+		scheduler.WithRetryDelay(time.Minute),
+		scheduler.WithClock(func() time.Time { return now }),
+	)
+	return s, store, executor
+}
+
+func TestScheduler_RecurringSuccess(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := utc("2024-03-01T09:00:05Z")
+	s, store, executor := newTestScheduler(ctrl, now)
+
+	cron := "0 9 * * *"
+	currency := "RUB"
+	op := models.ScheduledOperation{
+		ID:           uuid.New(),
+		Operation:    "DEPOSIT",
+		WalletID:     uuid.New(),
+		Amount:       decimal.NewFromInt(100),
+		Currency:     &currency,
+		Cron:         &cron,
+		Status:       models.ScheduleActive,
+		OccurrenceAt: utc("2024-03-01T09:00:00Z"),
+		NextRunAt:    utc("2024-03-01T09:00:00Z"),
+		MaxAttempts:  3,
+	}
+	unlocked := false
+	store.EXPECT().DueScheduledOperations(gomock.Any(), now, scheduler.DefaultBatchSize).Return([]models.ScheduledOperation{op}, nil)
+	store.EXPECT().LockScheduledOperation(gomock.Any(), op.ID).Return(func() { unlocked = true }, true, nil)
+	store.EXPECT().GetScheduledOperation(gomock.Any(), op.ID).Return(op, nil)
+	executor.EXPECT().
+		Deposit(gomock.Any(), op.WalletID, op.Amount, gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			o := models.NewOperationOptions(opts...)
+			assert.Equal(t, op.IdempotencyKey(op.OccurrenceAt), o.Idempotency.Key)
+			assert.Equal(t, "RUB", o.Currency)
+			return decimal.NewFromInt(100), false, nil
+		})
+	store.EXPECT().
+		RecordScheduledRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, updated models.ScheduledOperation, run models.ScheduledRun) error {
+			assert.Equal(t, models.RunSucceeded, run.Status)
+			assert.Equal(t, op.OccurrenceAt, run.OccurrenceAt)
+			assert.Equal(t, models.ScheduleActive, updated.Status)
+			assert.Equal(t, utc("2024-03-02T09:00:00Z"), updated.OccurrenceAt)
+			assert.Equal(t, updated.OccurrenceAt, updated.NextRunAt)
+			return nil
+		})
+
+	n, err := s.RunDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+	assert.True(t, unlocked)
+}
+
+func TestScheduler_RetryThenFail(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := utc("2024-03-01T12:00:00Z")
+	s, store, executor := newTestScheduler(ctrl, now)
+
+	to := uuid.New()
+	op := models.ScheduledOperation{
+		ID:           uuid.New(),
+		Operation:    "TRANSFER",
+		WalletID:     uuid.New(),
+		ToWalletID:   &to,
+		Amount:       decimal.NewFromInt(50),
+		Status:       models.ScheduleActive,
+		OccurrenceAt: utc("2024-03-01T11:00:00Z"),
+		NextRunAt:    utc("2024-03-01T11:59:00Z"),
+		Attempts:     1,
+		MaxAttempts:  3,
+	}
+	store.EXPECT().DueScheduledOperations(gomock.Any(), now, gomock.Any()).Return([]models.ScheduledOperation{op}, nil).Times(2)
+	store.EXPECT().LockScheduledOperation(gomock.Any(), op.ID).Return(func() {}, true, nil).Times(2)
+	executor.EXPECT().
+		Transfer(gomock.Any(), op.WalletID, to, op.Amount, gomock.Any()).
+		Return(models.TransferResult{}, repository.ErrInsufficientFunds).
+		Times(2)
+
+	// Вторая попытка из трёх: повтор через 2 минуты
+	store.EXPECT().GetScheduledOperation(gomock.Any(), op.ID).Return(op, nil)
+	store.EXPECT().
+		RecordScheduledRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, updated models.ScheduledOperation, run models.ScheduledRun) error {
+			assert.Equal(t, models.RunFailed, run.Status)
+			assert.Equal(t, 2, run.Attempt)
+			assert.Equal(t, models.ScheduleActive, updated.Status)
+			assert.Equal(t, 2, updated.Attempts)
+			assert.Equal(t, now.Add(2*time.Minute), updated.NextRunAt)
+			assert.Equal(t, op.OccurrenceAt, updated.OccurrenceAt)
+			return nil
+		})
+	_, err := s.RunDue(context.Background())
+	assert.NoError(t, err)
+
+	// Третья попытка исчерпывает лимит: разовая операция помечается FAILED
+	op.Attempts = 2
+	store.EXPECT().GetScheduledOperation(gomock.Any(), op.ID).Return(op, nil)
+	store.EXPECT().
+		RecordScheduledRun(gomock.Any(), gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, updated models.ScheduledOperation, run models.ScheduledRun) error {
+			assert.Equal(t, 3, run.Attempt)
+			assert.Equal(t, models.ScheduleFailed, updated.Status)
+			assert.Equal(t, repository.ErrInsufficientFunds.Error(), *updated.LastError)
+			return nil
+		})
+	_, err = s.RunDue(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestScheduler_SkipsLockedAndStale(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	now := utc("2024-03-01T12:00:00Z")
+	s, store, _ := newTestScheduler(ctrl, now)
+
+	locked := models.ScheduledOperation{ID: uuid.New()}
+	stale := models.ScheduledOperation{ID: uuid.New()}
+	store.EXPECT().DueScheduledOperations(gomock.Any(), now, gomock.Any()).
+		Return([]models.ScheduledOperation{locked, stale}, nil)
+	// Срабатывание исполняет другая реплика
+	store.EXPECT().LockScheduledOperation(gomock.Any(), locked.ID).Return(nil, false, nil)
+	// Пока ждали, другая реплика уже исполнила срабатывание и перенесла следующее
+	store.EXPECT().LockScheduledOperation(gomock.Any(), stale.ID).Return(func() {}, true, nil)
+	stale.Status = models.ScheduleActive
+	stale.NextRunAt = now.Add(time.Hour)
+	store.EXPECT().GetScheduledOperation(gomock.Any(), stale.ID).Return(stale, nil)
+
+	n, err := s.RunDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+}
+
+func TestCreateScheduledOperation_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	walletID := uuid.New()
+	runAt := time.Now().Add(time.Hour)
+
+	cases := []struct {
+		req models.ScheduleRequest
+		err error
+	}{
+		{models.ScheduleRequest{Operation: "DEPOSIT", WalletID: walletID, Amount: decimal.Zero, RunAt: &runAt}, repository.ErrInvalidAmount},
+		{models.ScheduleRequest{Operation: "TRANSFER", WalletID: walletID, Amount: decimal.NewFromInt(1), RunAt: &runAt}, models.ErrInvalidSchedule},
+		{models.ScheduleRequest{Operation: "TRANSFER", WalletID: walletID, ToWalletID: &walletID, Amount: decimal.NewFromInt(1), RunAt: &runAt}, repository.ErrSameWallet},
+		{models.ScheduleRequest{Operation: "DEPOSIT", WalletID: walletID, Amount: decimal.NewFromInt(1)}, models.ErrInvalidSchedule},
+		{models.ScheduleRequest{Operation: "DEPOSIT", WalletID: walletID, Amount: decimal.NewFromInt(1), Cron: "bad"}, models.ErrInvalidSchedule},
+	}
+	for _, c := range cases {
+		_, err := svc.CreateScheduledOperation(context.Background(), c.req)
+		assert.ErrorIs(t, err, c.err)
+	}
+
+	mockRepo.EXPECT().GetWallet(gomock.Any(), walletID).Return(models.Wallet{ID: walletID, Currency: "RUB"}, nil)
+	mockRepo.EXPECT().
+		CreateScheduledOperation(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, op models.ScheduledOperation) (models.ScheduledOperation, error) {
+			assert.Equal(t, "0 9 * * 1", *op.Cron)
+			assert.Equal(t, time.Monday, op.OccurrenceAt.Weekday())
+			assert.Equal(t, 9, op.OccurrenceAt.Hour())
+			assert.True(t, op.OccurrenceAt.After(runAt))
+			assert.Equal(t, models.DefaultScheduleMaxAttempts, op.MaxAttempts)
+			return op, nil
+		})
+	_, err := svc.CreateScheduledOperation(context.Background(), models.ScheduleRequest{
+		Operation: "WITHDRAW", WalletID: walletID, Amount: decimal.NewFromInt(10), Cron: "0 9 * * 1", RunAt: &runAt,
+	})
+	assert.NoError(t, err)
+
+	mockRepo.EXPECT().GetWallet(gomock.Any(), walletID).Return(models.Wallet{ID: walletID, Currency: "RUB"}, nil)
+	_, err = svc.CreateScheduledOperation(context.Background(), models.ScheduleRequest{
+		Operation: "DEPOSIT", WalletID: walletID, Amount: decimal.RequireFromString("1.001"), RunAt: &runAt,
+	})
+	assert.ErrorIs(t, err, models.ErrInvalidAmountScale)
+}
@@ -4,9 +4,11 @@ import (
 	"context"
 	"io"
 	"log/slog"
+	"test_wallet/internal/models"
 	"test_wallet/internal/repository"
 	"test_wallet/internal/service"
 	"testing"
+	"time"
 
 	"github.com/golang/mock/gomock"
 	"github.com/google/uuid"
@@ -231,3 +233,527 @@ func TestGetBalance_OtherError(t *testing.T) {
 	assert.ErrorIs(t, err, someError)
 	assert.True(t, balance.IsZero())
 }
+
+func TestGetTransactions_NextCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	walletID := uuid.New()
+	now := time.Now().UTC()
+	rows := []models.Transaction{
+		{ID: 3, WalletID: walletID, Type: "DEPOSIT", Amount: decimal.NewFromInt(30), CreatedAt: now},
+		{ID: 2, WalletID: walletID, Type: "DEPOSIT", Amount: decimal.NewFromInt(20), CreatedAt: now.Add(-time.Second)},
+		{ID: 1, WalletID: walletID, Type: "DEPOSIT", Amount: decimal.NewFromInt(10), CreatedAt: now.Add(-2 * time.Second)},
+	}
+
+	// Сервис запрашивает limit+1 записей, чтобы определить наличие следующей страницы
+	mockRepo.EXPECT().
+		ListTransactions(gomock.Any(), walletID, models.TransactionFilter{Limit: 3}).
+		Return(rows, nil)
+
+	page, err := svc.GetTransactions(context.Background(), walletID, models.TransactionFilter{Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Transactions, 2)
+	cursor, err := models.DecodeTransactionCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, int64(2), cursor.ID)
+	assert.True(t, cursor.CreatedAt.Equal(rows[1].CreatedAt))
+}
+
+func TestGetTransactions_LastPage(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	walletID := uuid.New()
+	mockRepo.EXPECT().
+		ListTransactions(gomock.Any(), walletID, models.TransactionFilter{Limit: models.DefaultTransactionsLimit + 1}).
+		Return(nil, nil)
+
+	page, err := svc.GetTransactions(context.Background(), walletID, models.TransactionFilter{})
+	assert.NoError(t, err)
+	assert.Empty(t, page.Transactions)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestGetTransactions_WalletNotFound(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	walletID := uuid.New()
+	mockRepo.EXPECT().
+		ListTransactions(gomock.Any(), walletID, gomock.Any()).
+		Return(nil, repository.ErrWalletNotFound)
+
+	_, err := svc.GetTransactions(context.Background(), walletID, models.TransactionFilter{})
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+func TestTransfer_Success(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	fromID, toID := uuid.New(), uuid.New()
+	amount := decimal.NewFromInt(30)
+	expected := models.TransferResult{
+		ID:           uuid.New(),
+		FromWalletID: fromID,
+		ToWalletID:   toID,
+		Amount:       amount,
+		FromBalance:  decimal.NewFromInt(70),
+		ToBalance:    decimal.NewFromInt(30),
+	}
+	mockRepo.EXPECT().
+		Transfer(gomock.Any(), fromID, toID, amount).
+		Return(expected, nil)
+
+	result, err := svc.Transfer(context.Background(), fromID, toID, amount)
+	assert.NoError(t, err)
+	assert.Equal(t, expected, result)
+}
+
+func TestTransfer_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	walletID := uuid.New()
+	_, err := svc.Transfer(context.Background(), walletID, walletID, decimal.NewFromInt(10))
+	assert.ErrorIs(t, err, repository.ErrSameWallet)
+
+	_, err = svc.Transfer(context.Background(), walletID, uuid.New(), decimal.Zero)
+	assert.ErrorIs(t, err, repository.ErrInvalidAmount)
+}
+
+func TestTransfer_Retry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	fromID, toID := uuid.New(), uuid.New()
+	amount := decimal.NewFromInt(10)
+	retryErr := &pgconn.PgError{Code: "40001", Message: "serialization failure"}
+	gomock.InOrder(
+		mockRepo.EXPECT().
+			Transfer(gomock.Any(), fromID, toID, amount).
+			Return(models.TransferResult{}, retryErr),
+		mockRepo.EXPECT().
+			Transfer(gomock.Any(), fromID, toID, amount).
+			Return(models.TransferResult{FromBalance: decimal.NewFromInt(90)}, nil),
+	)
+
+	result, err := svc.Transfer(context.Background(), fromID, toID, amount)
+	assert.NoError(t, err)
+	assert.True(t, result.FromBalance.Equal(decimal.NewFromInt(90)))
+}
+
+func TestOperations_RetryExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	walletID, toID := uuid.New(), uuid.New()
+	amount := decimal.NewFromInt(10)
+	retryErr := &pgconn.PgError{Code: "40001", Message: "serialization failure"}
+	mockRepo.EXPECT().
+		UpdateBalance(gomock.Any(), walletID, amount, "DEPOSIT").
+		Return(decimal.Zero, false, retryErr).Times(3)
+	mockRepo.EXPECT().
+		UpdateBalance(gomock.Any(), walletID, amount.Neg(), "WITHDRAW").
+		Return(decimal.Zero, false, retryErr).Times(3)
+	mockRepo.EXPECT().
+		Transfer(gomock.Any(), walletID, toID, amount).
+		Return(models.TransferResult{}, retryErr).Times(3)
+
+	_, _, err := svc.Deposit(context.Background(), walletID, amount)
+	assert.ErrorIs(t, err, retryErr)
+	_, err = svc.Withdraw(context.Background(), walletID, amount)
+	assert.ErrorIs(t, err, retryErr)
+	_, err = svc.Transfer(context.Background(), walletID, toID, amount)
+	assert.ErrorIs(t, err, retryErr)
+
+	// Отменённый контекст прерывает повторы
+	ctx, cancel := context.WithCancel(context.Background())
+	mockRepo.EXPECT().
+		UpdateBalance(gomock.Any(), walletID, amount, "DEPOSIT").
+		DoAndReturn(func(context.Context, uuid.UUID, decimal.Decimal, string, ...models.OperationOption) (decimal.Decimal, bool, error) {
+			cancel()
+			return decimal.Zero, false, retryErr
+		})
+	_, _, err = svc.Deposit(ctx, walletID, amount)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestGetTrialBalance_OutOfBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	mockRepo.EXPECT().
+		GetTrialBalance(gomock.Any()).
+		Return(models.TrialBalance{Totals: map[string]decimal.Decimal{"RUB": decimal.NewFromInt(5)}, UnbalancedEntries: []int64{7}}, nil)
+
+	tb, err := svc.GetTrialBalance(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, tb.Balanced)
+	assert.Equal(t, []int64{7}, tb.UnbalancedEntries)
+}
+
+func TestCreateHold_DefaultTTL(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	walletID := uuid.New()
+	amount := decimal.NewFromInt(10)
+	before := time.Now()
+	mockRepo.EXPECT().
+		CreateHold(gomock.Any(), walletID, amount, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, expiresAt time.Time, _ ...models.OperationOption) (models.HoldResult, error) {
+			assert.WithinDuration(t, before.Add(service.DefaultHoldTTL), expiresAt, time.Second)
+			return models.HoldResult{Hold: models.Hold{WalletID: walletID, Amount: amount, Status: models.HoldActive}}, nil
+		})
+
+	result, err := svc.CreateHold(context.Background(), walletID, amount, 0)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HoldActive, result.Hold.Status)
+
+	_, err = svc.CreateHold(context.Background(), walletID, decimal.Zero, time.Minute)
+	assert.ErrorIs(t, err, repository.ErrInvalidAmount)
+}
+
+func TestCaptureHold_Retry(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	holdID := uuid.New()
+	retryErr := &pgconn.PgError{Code: "40P01", Message: "deadlock detected"}
+	gomock.InOrder(
+		mockRepo.EXPECT().CaptureHold(gomock.Any(), holdID, nil).Return(models.HoldResult{}, retryErr),
+		mockRepo.EXPECT().CaptureHold(gomock.Any(), holdID, nil).
+			Return(models.HoldResult{Hold: models.Hold{ID: holdID, Status: models.HoldCaptured}}, nil),
+	)
+
+	result, err := svc.CaptureHold(context.Background(), holdID, nil)
+	assert.NoError(t, err)
+	assert.Equal(t, models.HoldCaptured, result.Hold.Status)
+}
+
+func TestQuoteConversion(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	mockRates := NewMockRateProvider(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger,
+		service.WithRateProvider(mockRates), service.WithQuoteTTL(30*time.Second))
+
+	fromID, toID := uuid.New(), uuid.New()
+	rateAt := time.Date(2026, 10, 1, 0, 0, 0, 0, time.UTC)
+	mockRepo.EXPECT().GetWallet(gomock.Any(), fromID).Return(models.Wallet{ID: fromID, Currency: "USD"}, nil)
+	mockRepo.EXPECT().GetWallet(gomock.Any(), toID).Return(models.Wallet{ID: toID, Currency: "JPY"}, nil)
+	mockRates.EXPECT().
+		Rate(gomock.Any(), "USD", "JPY").
+		Return(models.Rate{From: "USD", To: "JPY", Value: decimal.RequireFromString("149.8"), Timestamp: rateAt}, nil)
+	mockRepo.EXPECT().
+		CreateQuote(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, q models.ConversionQuote) (models.ConversionQuote, error) {
+			return q, nil
+		})
+
+	before := time.Now()
+	quote, err := svc.QuoteConversion(context.Background(), fromID, toID, decimal.RequireFromString("10.01"))
+	assert.NoError(t, err)
+	// 10.01 * 149.8 = 1499.498 — округляется вниз до целых иен
+	assert.True(t, quote.ToAmount.Equal(decimal.NewFromInt(1499)))
+	assert.Equal(t, "USD", quote.FromCurrency)
+	assert.Equal(t, "JPY", quote.ToCurrency)
+	assert.Equal(t, rateAt, quote.RateTimestamp)
+	assert.WithinDuration(t, before.Add(30*time.Second), quote.ExpiresAt, time.Second)
+}
+
+func TestQuoteConversion_Rejected(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	mockRates := NewMockRateProvider(ctrl)
+	fromID, toID := uuid.New(), uuid.New()
+
+	_, err := service.NewWalletService(mockRepo, testLogger).
+		QuoteConversion(context.Background(), fromID, toID, decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, models.ErrRatesUnavailable)
+
+	svc := service.NewWalletService(mockRepo, testLogger, service.WithRateProvider(mockRates))
+	mockRepo.EXPECT().GetWallet(gomock.Any(), fromID).Return(models.Wallet{ID: fromID, Currency: "RUB"}, nil)
+	mockRepo.EXPECT().GetWallet(gomock.Any(), toID).Return(models.Wallet{ID: toID, Currency: "RUB"}, nil)
+	_, err = svc.QuoteConversion(context.Background(), fromID, toID, decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, models.ErrSameCurrency)
+}
+
+func TestReverseTransaction_Policy(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	amount := decimal.NewFromInt(40)
+	mockRepo.EXPECT().
+		ReverseTransaction(gomock.Any(), int64(7), &amount, false).
+		Return(models.ReversalResult{}, repository.ErrInsufficientFunds)
+	_, err := service.NewWalletService(mockRepo, testLogger).
+		ReverseTransaction(context.Background(), 7, &amount)
+	assert.ErrorIs(t, err, repository.ErrInsufficientFunds)
+
+	mockRepo.EXPECT().
+		ReverseTransaction(gomock.Any(), int64(7), &amount, true).
+		Return(models.ReversalResult{Balance: decimal.NewFromInt(-10)}, nil)
+	svc := service.NewWalletService(mockRepo, testLogger, service.WithReversalPolicy(models.ReversalPolicyAllowNegative))
+	result, err := svc.ReverseTransaction(context.Background(), 7, &amount)
+	assert.NoError(t, err)
+	assert.True(t, result.Balance.Equal(decimal.NewFromInt(-10)))
+}
+
+func TestCreateWallet_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+
+	_, err := svc.CreateWallet(context.Background(), models.CreateWalletRequest{Currency: "XXX"})
+	assert.ErrorIs(t, err, models.ErrUnsupportedCurrency)
+	_, err = svc.CreateWallet(context.Background(), models.CreateWalletRequest{Labels: map[string]string{"": "x"}})
+	assert.ErrorIs(t, err, models.ErrInvalidLabels)
+
+	mockRepo.EXPECT().
+		CreateWallet(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, wallet models.Wallet) (models.Wallet, error) {
+			assert.NotEqual(t, uuid.Nil, wallet.ID)
+			assert.Equal(t, "user-1", wallet.OwnerID)
+			return wallet, nil
+		})
+	wallet, err := svc.CreateWallet(context.Background(), models.CreateWalletRequest{OwnerID: "user-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "user-1", wallet.OwnerID)
+}
+
+func TestListWallets_NextCursor(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	now := time.Now()
+	wallets := []models.Wallet{
+		{ID: uuid.New(), CreatedAt: now},
+		{ID: uuid.New(), CreatedAt: now.Add(-time.Second)},
+		{ID: uuid.New(), CreatedAt: now.Add(-2 * time.Second)},
+	}
+	mockRepo.EXPECT().
+		ListWallets(gomock.Any(), models.WalletFilter{OwnerID: "user-1", Limit: 3}).
+		Return(wallets, nil)
+
+	page, err := service.NewWalletService(mockRepo, testLogger).
+		ListWallets(context.Background(), models.WalletFilter{OwnerID: "user-1", Limit: 2})
+	assert.NoError(t, err)
+	assert.Len(t, page.Wallets, 2)
+	cursor, err := models.DecodeWalletCursor(page.NextCursor)
+	assert.NoError(t, err)
+	assert.Equal(t, wallets[1].ID, cursor.ID)
+	assert.True(t, cursor.CreatedAt.Equal(wallets[1].CreatedAt))
+}
+
+func TestSetLimitTier_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	ctx := context.Background()
+
+	negative, fine := decimal.NewFromInt(-1), decimal.NewFromFloat(10.001)
+	maxOps := 5
+	assert.ErrorIs(t, svc.SetLimitTier(ctx, "kyc1", "RUB", models.SpendingLimit{Operation: "WITHDRAW", DailyCap: &negative}),
+		models.ErrInvalidLimit)
+	assert.ErrorIs(t, svc.SetLimitTier(ctx, "kyc1", "RUB", models.SpendingLimit{Operation: "WITHDRAW", MaxOperations: &maxOps}),
+		models.ErrInvalidLimit)
+	assert.ErrorIs(t, svc.SetLimitTier(ctx, "kyc1", "RUB", models.SpendingLimit{Operation: "WITHDRAW", MaxSingleAmount: &fine}),
+		models.ErrInvalidAmountScale)
+	assert.ErrorIs(t, svc.SetLimitTier(ctx, "kyc1", "XXX", models.SpendingLimit{Operation: "WITHDRAW"}),
+		models.ErrUnsupportedCurrency)
+
+	limit := decimal.NewFromInt(1000)
+	valid := models.SpendingLimit{Operation: "DEPOSIT", MonthlyCap: &limit}
+	mockRepo.EXPECT().SetLimitTier(gomock.Any(), "kyc1", "RUB", valid).Return(nil)
+	assert.NoError(t, svc.SetLimitTier(ctx, "kyc1", "RUB", valid))
+}
+
+func TestWithdraw_LimitExceeded(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	walletID := uuid.New()
+	limitErr := &repository.LimitExceededError{Limit: models.LimitDailyCap, Max: decimal.NewFromInt(100), Used: decimal.NewFromInt(90)}
+	mockRepo.EXPECT().
+		UpdateBalance(gomock.Any(), walletID, decimal.NewFromInt(-20), "WITHDRAW").
+		Return(decimal.Zero, false, limitErr)
+
+	_, err := service.NewWalletService(mockRepo, testLogger).Withdraw(context.Background(), walletID, decimal.NewFromInt(20))
+	assert.ErrorIs(t, err, repository.ErrLimitExceeded)
+	assert.Equal(t, limitErr, err)
+}
+
+func TestGetBalanceAt(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	walletID := uuid.New()
+
+	_, err := svc.GetBalanceAt(context.Background(), walletID, time.Now().Add(time.Hour))
+	assert.ErrorIs(t, err, models.ErrTimestampInFuture)
+
+	at := time.Date(2024, 3, 1, 0, 0, 0, 0, time.FixedZone("MSK", 3*60*60))
+	mockRepo.EXPECT().
+		GetBalanceAt(gomock.Any(), walletID, at.UTC()).
+		Return(models.BalanceAt{WalletID: walletID, Balance: decimal.NewFromInt(42), At: at.UTC()}, nil)
+	balance, err := svc.GetBalanceAt(context.Background(), walletID, at)
+	assert.NoError(t, err)
+	assert.True(t, balance.Balance.Equal(decimal.NewFromInt(42)))
+}
+
+func TestSnapshotBalances_EndOfDay(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	mockRepo.EXPECT().
+		TakeBalanceSnapshots(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, asOf time.Time) (int64, error) {
+			assert.Equal(t, time.UTC, asOf.Location())
+			assert.Equal(t, asOf, asOf.Truncate(24*time.Hour))
+			assert.True(t, time.Since(asOf) >= service.SnapshotLag)
+			assert.True(t, time.Since(asOf) < 24*time.Hour+service.SnapshotLag)
+			return 3, nil
+		})
+	service.NewWalletService(mockRepo, testLogger).SnapshotBalances(context.Background())
+}
+
+func TestReconcile_Drift(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	walletID := uuid.New()
+
+	mockRepo.EXPECT().Reconcile(gomock.Any()).Return(models.ReconciliationReport{
+		OK:     false,
+		Issues: []models.ReconciliationIssue{{Check: models.CheckBalanceDrift, WalletID: &walletID}},
+	}, nil)
+	report, err := svc.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.False(t, report.OK)
+	assert.Len(t, report.Issues, 1)
+
+	mockRepo.EXPECT().Reconcile(gomock.Any()).Return(models.ReconciliationReport{}, context.DeadlineExceeded)
+	_, err = svc.Reconcile(context.Background())
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+}
+
+func TestVerifyJournalChain(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	walletID := uuid.New()
+	txID := int64(42)
+
+	brk := &models.ChainBreak{WalletID: walletID, TransactionID: &txID, Reason: models.ChainHashMismatch}
+	mockRepo.EXPECT().VerifyJournalChain(gomock.Any(), &walletID).
+		Return(models.ChainVerification{WalletID: &walletID, RowsChecked: 3, FirstBreak: brk}, nil)
+	result, err := svc.VerifyJournalChain(context.Background(), &walletID)
+	assert.NoError(t, err)
+	assert.False(t, result.OK)
+	assert.Equal(t, brk, result.FirstBreak)
+
+	mockRepo.EXPECT().VerifyJournalChain(gomock.Any(), gomock.Nil()).
+		Return(models.ChainVerification{}, repository.ErrWalletNotFound)
+	_, err = svc.VerifyJournalChain(context.Background(), nil)
+	assert.ErrorIs(t, err, repository.ErrWalletNotFound)
+}
+
+func TestConvert_ReplaysBeforeQuoting(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+
+	mockRepo := NewMockWalletRepository(ctrl)
+	mockRates := NewMockRateProvider(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger, service.WithRateProvider(mockRates))
+	fromID, toID := uuid.New(), uuid.New()
+
+	// Повтор получает сохранённый ответ: курс не запрашивается, котировка не создаётся
+	idem := &models.Idempotency{Key: "convert-1", RequestHash: "hash-1"}
+	mockRepo.EXPECT().
+		LookupIdempotentResponse(gomock.Any(), idem).
+		DoAndReturn(func(_ context.Context, idem *models.Idempotency) (bool, error) {
+			idem.Replayed = &models.IdempotentResponse{StatusCode: 200, Body: []byte(`{}`)}
+			return true, nil
+		})
+	_, err := svc.Convert(context.Background(), fromID, toID, decimal.NewFromInt(10), models.WithIdempotency(idem))
+	assert.NoError(t, err)
+	assert.NotNil(t, idem.Replayed)
+
+	other := &models.Idempotency{Key: "convert-1", RequestHash: "hash-2"}
+	mockRepo.EXPECT().
+		LookupIdempotentResponse(gomock.Any(), other).
+		Return(false, repository.ErrIdempotencyKeyUsed)
+	_, err = svc.Convert(context.Background(), fromID, toID, decimal.NewFromInt(10), models.WithIdempotency(other))
+	assert.ErrorIs(t, err, repository.ErrIdempotencyKeyUsed)
+
+	// Новый ключ: котировка создаётся и исполняется с тем же ключом
+	fresh := &models.Idempotency{Key: "convert-2", RequestHash: "hash-3"}
+	quoteID := uuid.New()
+	gomock.InOrder(
+		mockRepo.EXPECT().LookupIdempotentResponse(gomock.Any(), fresh).Return(false, nil),
+		mockRepo.EXPECT().GetWallet(gomock.Any(), fromID).Return(models.Wallet{ID: fromID, Currency: "USD"}, nil),
+		mockRepo.EXPECT().GetWallet(gomock.Any(), toID).Return(models.Wallet{ID: toID, Currency: "RUB"}, nil),
+		mockRates.EXPECT().
+			Rate(gomock.Any(), "USD", "RUB").
+			Return(models.Rate{From: "USD", To: "RUB", Value: decimal.RequireFromString("92.5"), Timestamp: time.Now()}, nil),
+		mockRepo.EXPECT().
+			CreateQuote(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, q models.ConversionQuote) (models.ConversionQuote, error) {
+				q.ID = quoteID
+				return q, nil
+			}),
+		mockRepo.EXPECT().
+			ExecuteConversion(gomock.Any(), quoteID, gomock.Any()).
+			Return(models.ConversionResult{FromBalance: decimal.NewFromInt(90)}, nil),
+	)
+	result, err := svc.Convert(context.Background(), fromID, toID, decimal.NewFromInt(10), models.WithIdempotency(fresh))
+	assert.NoError(t, err)
+	assert.True(t, result.FromBalance.Equal(decimal.NewFromInt(90)))
+}
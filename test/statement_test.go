@@ -0,0 +1,113 @@
+package test
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"strings"
+	"test_wallet/internal/models"
+	"test_wallet/internal/service"
+	"test_wallet/internal/statement"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+// writeTestStatement выводит выписку: остаток 100, пополнение 50, комиссия 1.5.
+func writeTestStatement(t *testing.T, w models.StatementWriter, walletID uuid.UUID) {
+	from := time.Date(2024, 3, 1, 0, 0, 0, 0, time.UTC)
+	s := models.Statement{
+		WalletID:       walletID,
+		Currency:       "RUB",
+		From:           from,
+		To:             from.AddDate(0, 1, 0),
+		OpeningBalance: decimal.NewFromInt(100),
+	}
+	assert.NoError(t, w.WriteHeader(s))
+	balance := s.OpeningBalance
+	for i, tx := range []models.Transaction{
+		{ID: 7, WalletID: walletID, Type: "DEPOSIT", Amount: decimal.NewFromInt(50), CreatedAt: from.Add(time.Hour)},
+		{ID: 8, WalletID: walletID, Type: "FEE", Amount: decimal.RequireFromString("-1.5"), CreatedAt: from.Add(time.Hour)},
+	} {
+		balance = balance.Add(tx.Amount)
+		assert.NoError(t, w.WriteLine(models.StatementLine{Transaction: tx, RunningBalance: balance}), i)
+	}
+	s.ClosingBalance = balance
+	assert.NoError(t, w.WriteFooter(s))
+}
+
+func TestStatement_CSV(t *testing.T) {
+	var buf bytes.Buffer
+	walletID := uuid.New()
+	writeTestStatement(t, statement.NewCSVWriter(&buf), walletID)
+	assert.Equal(t, strings.Join([]string{
+		"record,date,id,type,amount,balance,currency",
+		"opening,2024-03-01T00:00:00Z,,,,100,RUB",
+		"transaction,2024-03-01T01:00:00Z,7,DEPOSIT,50,150,",
+		"transaction,2024-03-01T01:00:00Z,8,FEE,-1.5,148.5,",
+		"closing,2024-04-01T00:00:00Z,,,,148.5,RUB",
+	}, "\n")+"\n", buf.String())
+}
+
+func TestStatement_NDJSON(t *testing.T) {
+	var buf bytes.Buffer
+	walletID := uuid.New()
+	writeTestStatement(t, statement.NewNDJSONWriter(&buf), walletID)
+
+	var records []map[string]any
+	scanner := bufio.NewScanner(&buf)
+	for scanner.Scan() {
+		var record map[string]any
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &record))
+		records = append(records, record)
+	}
+	if assert.Len(t, records, 4) {
+		assert.Equal(t, "opening", records[0]["record"])
+		assert.Equal(t, "100", records[0]["balance"])
+		assert.Equal(t, "transaction", records[1]["record"])
+		assert.Equal(t, "DEPOSIT", records[1]["operationType"])
+		assert.Equal(t, "150", records[1]["runningBalance"])
+		assert.Equal(t, "closing", records[3]["record"])
+		assert.Equal(t, "148.5", records[3]["balance"])
+	}
+}
+
+func TestStatement_OFX(t *testing.T) {
+	var buf bytes.Buffer
+	walletID := uuid.New()
+	writeTestStatement(t, statement.NewOFXWriter(&buf), walletID)
+	out := buf.String()
+	assert.True(t, strings.HasPrefix(out, `<?xml version="1.0"`))
+	assert.Contains(t, out, "<ACCTID>"+walletID.String()+"</ACCTID>")
+	assert.Contains(t, out, "<DTSTART>20240301000000.000[0:GMT]</DTSTART>")
+	assert.Contains(t, out, "<TRNTYPE>DEP</TRNTYPE><DTPOSTED>20240301010000.000[0:GMT]</DTPOSTED><TRNAMT>50</TRNAMT><FITID>7</FITID>")
+	assert.Contains(t, out, "<TRNTYPE>FEE</TRNTYPE>")
+	assert.Contains(t, out, "<LEDGERBAL><BALAMT>148.5</BALAMT>")
+	assert.Contains(t, out, "<VALUE>100</VALUE>")
+	assert.True(t, strings.HasSuffix(out, "</OFX>\n"))
+}
+
+func TestStatement_Lookup(t *testing.T) {
+	for _, name := range []string{models.StatementCSV, models.StatementNDJSON, models.StatementOFX} {
+		_, err := statement.Lookup(name)
+		assert.NoError(t, err)
+	}
+	_, err := statement.Lookup("pdf")
+	assert.Error(t, err)
+}
+
+func TestExportStatement_InvalidRange(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	now := time.Now()
+
+	err := svc.ExportStatement(context.Background(), uuid.New(), now, now, statement.NewCSVWriter(&bytes.Buffer{}))
+	assert.ErrorIs(t, err, models.ErrInvalidStatementRange)
+}
@@ -0,0 +1,128 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"test_wallet/internal/stream"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeListener отдаёт уведомления из канала notifications, пока не отменят ctx.
+type fakeListener struct {
+	notifications chan uuid.UUID
+	listening     chan struct{}
+}
+
+func (l *fakeListener) ListenWalletEvents(
+	ctx context.Context,
+	onListen func(),
+	notify func(uuid.UUID),
+) error {
+	onListen()
+	l.listening <- struct{}{}
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case walletID := <-l.notifications:
+			notify(walletID)
+		}
+	}
+}
+
+func received(ch <-chan struct{}) bool {
+	select {
+	case _, ok := <-ch:
+		return ok
+	case <-time.After(time.Second):
+		return false
+	}
+}
+
+func TestHub(t *testing.T) {
+	listener := &fakeListener{notifications: make(chan uuid.UUID), listening: make(chan struct{}, 1)}
+	hub := stream.NewHub(listener, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	walletID, otherID := uuid.New(), uuid.New()
+	wake, unsubscribe := hub.Subscribe(walletID)
+	other, _ := hub.Subscribe(otherID)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(done)
+	}()
+	<-listener.listening
+
+	// После подписки на LISTEN будятся все: уведомления до неё могли потеряться
+	assert.True(t, received(wake))
+	assert.True(t, received(other))
+
+	// Несколько уведомлений подряд склеиваются в один сигнал
+	// (уведомление для незнакомого кошелька гарантирует, что предыдущие уже обработаны)
+	listener.notifications <- walletID
+	listener.notifications <- walletID
+	listener.notifications <- uuid.New()
+	assert.True(t, received(wake))
+	select {
+	case <-wake:
+		t.Fatal("signals must coalesce")
+	case <-other:
+		t.Fatal("other wallet must not be woken")
+	default:
+	}
+
+	unsubscribe()
+	listener.notifications <- walletID
+	listener.notifications <- uuid.New()
+	select {
+	case <-wake:
+		t.Fatal("unsubscribed channel must not be woken")
+	default:
+	}
+
+	// Остановка Hub закрывает каналы оставшихся подписчиков
+	cancel()
+	<-done
+	_, ok := <-other
+	assert.False(t, ok)
+	closed, _ := hub.Subscribe(walletID)
+	_, ok = <-closed
+	assert.False(t, ok)
+}
+
+// failingListener падает до подписки; Run должен переподключаться, а не завершаться.
+type failingListener struct {
+	calls chan struct{}
+}
+
+func (l *failingListener) ListenWalletEvents(context.Context, func(), func(uuid.UUID)) error {
+	l.calls <- struct{}{}
+	return errors.New("connection refused")
+}
+
+func TestHub_Reconnect(t *testing.T) {
+	listener := &failingListener{calls: make(chan struct{}, 1)}
+	hub := stream.NewHub(listener, slog.New(slog.NewTextHandler(io.Discard, nil)))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	go func() {
+		hub.Run(ctx)
+		close(done)
+	}()
+	<-listener.calls
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(time.Second):
+		t.Fatal("Run must stop while waiting to reconnect")
+	}
+}
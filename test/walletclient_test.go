@@ -0,0 +1,333 @@
+package test
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"test_wallet/internal/auth"
+	"test_wallet/internal/handlers"
+	"test_wallet/internal/models"
+	"test_wallet/internal/repository"
+	"test_wallet/pkg/walletclient"
+
+	"github.com/gin-gonic/gin"
+	"github.com/golang-jwt/jwt/v5"
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newWalletClient поднимает httptest-сервер с настоящим WalletHTTPHandler и возвращает клиент к нему.
+func newWalletClient(t *testing.T, service handlers.WalletService, opts ...walletclient.Option) *walletclient.Client {
+	r := gin.New()
+	handlers.NewWalletHTTPHandler(service).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	t.Cleanup(server.Close)
+	opts = append([]walletclient.Option{walletclient.WithRetryDelay(time.Millisecond)}, opts...)
+	return walletclient.New(server.URL, opts...)
+}
+
+// operationOptions применяет опции операции, переданные сервису обработчиком.
+func operationOptions(opts []models.OperationOption) models.OperationOptions {
+	var o models.OperationOptions
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return o
+}
+
+func TestWalletClientDeposit(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	client := newWalletClient(t, mockService)
+	walletID := uuid.New()
+
+	var key string
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.RequireFromString("100.5"), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			o := operationOptions(opts)
+			require.NotNil(t, o.Idempotency)
+			key = o.Idempotency.Key
+			assert.Equal(t, "USD", o.Currency)
+			*o.Fee = decimal.RequireFromString("0.50")
+			return decimal.NewFromInt(100), true, nil
+		})
+
+	result, err := client.Deposit(context.Background(), walletID, decimal.RequireFromString("100.5"),
+		walletclient.WithCurrency("USD"))
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(100).Equal(result.Balance))
+	assert.True(t, decimal.RequireFromString("0.5").Equal(result.Fee))
+	assert.True(t, result.Created)
+	assert.False(t, result.Replayed)
+	assert.NotEmpty(t, key, "client must send an idempotency key")
+	assert.Equal(t, key, result.IdempotencyKey)
+}
+
+func TestWalletClientDeposit_Replayed(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	client := newWalletClient(t, mockService)
+	walletID := uuid.New()
+
+	mockService.EXPECT().
+		Deposit(gomock.Any(), walletID, decimal.NewFromInt(5), gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, bool, error) {
+			o := operationOptions(opts)
+			assert.Equal(t, "order-42", o.Idempotency.Key)
+			o.Idempotency.Replayed = &models.IdempotentResponse{StatusCode: 201, Body: []byte(`{"balance":"5"}`)}
+			return decimal.Zero, false, nil
+		})
+
+	result, err := client.Deposit(context.Background(), walletID, decimal.NewFromInt(5),
+		walletclient.WithIdempotencyKey("order-42"))
+	require.NoError(t, err)
+	assert.True(t, result.Replayed)
+	assert.True(t, result.Created)
+	assert.True(t, decimal.NewFromInt(5).Equal(result.Balance))
+	assert.Equal(t, "order-42", result.IdempotencyKey)
+}
+
+func TestWalletClientWithdraw_RetriesUnavailableWithSameKey(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	client := newWalletClient(t, mockService)
+	walletID := uuid.New()
+
+	var keys []string
+	record := func(opts []models.OperationOption) {
+		keys = append(keys, operationOptions(opts).Idempotency.Key)
+	}
+	gomock.InOrder(
+		mockService.EXPECT().
+			Withdraw(gomock.Any(), walletID, decimal.NewFromInt(30), gomock.Any()).
+			Times(2).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, error) {
+				record(opts)
+				return decimal.Zero, errors.New("connection refused")
+			}),
+		mockService.EXPECT().
+			Withdraw(gomock.Any(), walletID, decimal.NewFromInt(30), gomock.Any()).
+			DoAndReturn(func(_ context.Context, _ uuid.UUID, _ decimal.Decimal, opts ...models.OperationOption) (decimal.Decimal, error) {
+				record(opts)
+				return decimal.NewFromInt(70), nil
+			}),
+	)
+
+	result, err := client.Withdraw(context.Background(), walletID, decimal.NewFromInt(30))
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(70).Equal(result.Balance))
+	require.Len(t, keys, 3)
+	assert.Equal(t, keys[0], keys[1])
+	assert.Equal(t, keys[0], keys[2])
+}
+
+func TestWalletClientWithdraw_RetriesExhausted(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	client := newWalletClient(t, mockService, walletclient.WithMaxAttempts(3))
+	walletID := uuid.New()
+
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(30), gomock.Any()).
+		Times(3).
+		Return(decimal.Zero, errors.New("connection refused"))
+
+	_, err := client.Withdraw(context.Background(), walletID, decimal.NewFromInt(30))
+	assert.ErrorIs(t, err, walletclient.ErrUnavailable)
+	var apiErr *walletclient.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 503, apiErr.StatusCode)
+}
+
+func TestWalletClientWithdraw_Errors(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	client := newWalletClient(t, mockService)
+	ctx := context.Background()
+	walletID := uuid.New()
+
+	// Ошибки, кроме 503, не повторяются: каждый вызов сервиса ожидается один раз
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(500), gomock.Any()).
+		Return(decimal.NewFromInt(70), &repository.InsufficientFundsError{WalletID: walletID, AvailableCredit: decimal.NewFromInt(70)})
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(60), gomock.Any()).
+		Return(decimal.NewFromInt(70), &repository.LimitExceededError{
+			Limit: models.LimitDailyCap, Max: decimal.NewFromInt(100), Used: decimal.NewFromInt(50),
+		})
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(1), gomock.Any()).
+		Return(decimal.Zero, repository.ErrWalletNotFound)
+	mockService.EXPECT().
+		Withdraw(gomock.Any(), walletID, decimal.NewFromInt(2), gomock.Any()).
+		Return(decimal.Zero, repository.ErrWalletFrozen)
+
+	_, err := client.Withdraw(ctx, walletID, decimal.NewFromInt(500))
+	assert.ErrorIs(t, err, walletclient.ErrInsufficientFunds)
+	var apiErr *walletclient.APIError
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, 409, apiErr.StatusCode)
+	require.NotNil(t, apiErr.AvailableCredit)
+	assert.True(t, decimal.NewFromInt(70).Equal(*apiErr.AvailableCredit))
+
+	_, err = client.Withdraw(ctx, walletID, decimal.NewFromInt(60))
+	assert.ErrorIs(t, err, walletclient.ErrLimitExceeded)
+	require.ErrorAs(t, err, &apiErr)
+	assert.Equal(t, models.LimitDailyCap, apiErr.Limit)
+	assert.True(t, decimal.NewFromInt(50).Equal(*apiErr.Used))
+
+	_, err = client.Withdraw(ctx, walletID, decimal.NewFromInt(1))
+	assert.ErrorIs(t, err, walletclient.ErrWalletNotFound)
+
+	_, err = client.Withdraw(ctx, walletID, decimal.NewFromInt(2))
+	assert.ErrorIs(t, err, walletclient.ErrWalletFrozen)
+
+	// Запрос отклоняется проверкой по спецификации, до сервиса
+	_, err = client.Withdraw(ctx, walletID, decimal.NewFromInt(3), walletclient.WithCurrency("RUBLES"))
+	assert.ErrorIs(t, err, walletclient.ErrInvalidRequest)
+	require.ErrorAs(t, err, &apiErr)
+	assert.NotEmpty(t, apiErr.Details)
+}
+
+func TestWalletClientGetBalance(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	client := newWalletClient(t, mockService)
+	ctx := context.Background()
+	found, missing := uuid.New(), uuid.New()
+
+	mockService.EXPECT().GetWallet(gomock.Any(), found).
+		Return(models.Wallet{ID: found, Balance: decimal.RequireFromString("12.34")}, nil)
+	mockService.EXPECT().GetWallet(gomock.Any(), missing).Return(models.Wallet{}, repository.ErrWalletNotFound)
+
+	balance, err := client.GetBalance(ctx, found)
+	require.NoError(t, err)
+	assert.True(t, decimal.RequireFromString("12.34").Equal(balance))
+
+	_, err = client.GetBalance(ctx, missing)
+	assert.ErrorIs(t, err, walletclient.ErrWalletNotFound)
+	assert.NotErrorIs(t, err, walletclient.ErrInsufficientFunds)
+}
+
+func TestWalletClientGetBalance_Deadline(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	client := newWalletClient(t, mockService, walletclient.WithTimeout(50*time.Millisecond))
+	walletID := uuid.New()
+
+	// Сервис отвечает дольше срока вызова: клиент прерывает запрос и не повторяет его
+	mockService.EXPECT().
+		GetWallet(gomock.Any(), walletID).
+		DoAndReturn(func(ctx context.Context, _ uuid.UUID) (models.Wallet, error) {
+			<-ctx.Done()
+			return models.Wallet{}, ctx.Err()
+		})
+
+	start := time.Now()
+	_, err := client.GetBalance(context.Background(), walletID)
+	assert.ErrorIs(t, err, context.DeadlineExceeded)
+	assert.Less(t, time.Since(start), 5*time.Second)
+
+	// Дедлайн контекста вызова имеет приоритет над WithTimeout
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = client.GetBalance(ctx, walletID)
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestWalletClientListTransactions(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	client := newWalletClient(t, mockService)
+	walletID := uuid.New()
+	createdAt := time.Date(2026, 10, 1, 12, 0, 0, 0, time.UTC)
+	from := createdAt.Add(-24 * time.Hour)
+	minAmount := decimal.NewFromInt(10)
+	cursor := models.TransactionCursor{CreatedAt: createdAt, ID: 7}
+
+	mockService.EXPECT().
+		GetTransactions(gomock.Any(), walletID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, filter models.TransactionFilter) (models.TransactionPage, error) {
+			assert.Equal(t, "WITHDRAW", filter.OperationType)
+			require.NotNil(t, filter.MinAmount)
+			assert.True(t, minAmount.Equal(*filter.MinAmount))
+			require.NotNil(t, filter.From)
+			assert.True(t, from.Equal(*filter.From))
+			assert.Nil(t, filter.To)
+			assert.Equal(t, 1, filter.Limit)
+			return models.TransactionPage{
+				Transactions: []models.Transaction{{
+					ID:           7,
+					WalletID:     walletID,
+					Type:         "WITHDRAW",
+					Amount:       decimal.NewFromInt(-25),
+					BalanceAfter: decimal.NewFromInt(75),
+					CreatedAt:    createdAt,
+					Hash:         "abc",
+				}},
+				NextCursor: cursor.Encode(),
+			}, nil
+		})
+	mockService.EXPECT().
+		GetTransactions(gomock.Any(), walletID, gomock.Any()).
+		DoAndReturn(func(_ context.Context, _ uuid.UUID, filter models.TransactionFilter) (models.TransactionPage, error) {
+			require.NotNil(t, filter.Cursor)
+			assert.Equal(t, cursor, *filter.Cursor)
+			return models.TransactionPage{Transactions: []models.Transaction{}}, nil
+		})
+
+	filter := walletclient.TransactionFilter{OperationType: "WITHDRAW", MinAmount: &minAmount, From: &from, Limit: 1}
+	page, err := client.ListTransactions(context.Background(), walletID, filter)
+	require.NoError(t, err)
+	require.Len(t, page.Transactions, 1)
+	tx := page.Transactions[0]
+	assert.Equal(t, int64(7), tx.ID)
+	assert.Equal(t, walletID, tx.WalletID)
+	assert.Equal(t, "WITHDRAW", tx.OperationType)
+	assert.True(t, decimal.NewFromInt(-25).Equal(tx.Amount))
+	assert.True(t, createdAt.Equal(tx.CreatedAt))
+	require.NotEmpty(t, page.NextCursor)
+
+	filter.Cursor = page.NextCursor
+	page, err = client.ListTransactions(context.Background(), walletID, filter)
+	require.NoError(t, err)
+	assert.Empty(t, page.Transactions)
+	assert.Empty(t, page.NextCursor)
+}
+
+func TestWalletClientAuthentication(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockService := NewMockWalletService(ctrl)
+	r := gin.New()
+	authenticator := auth.NewJWTAuthenticator(auth.KeySet{auth.HMACKey(testJWTSecret)})
+	handlers.NewWalletHTTPHandler(mockService, handlers.WithAuthenticator(authenticator)).RegisterRoutes(r)
+	server := httptest.NewServer(r)
+	defer server.Close()
+	walletID := uuid.New()
+	token := signToken(t, jwt.SigningMethodHS256, testJWTSecret, "", validClaims("user-1"))
+
+	mockService.EXPECT().GetWallet(gomock.Any(), walletID).Return(models.Wallet{ID: walletID, Balance: decimal.NewFromInt(10)}, nil)
+
+	balance, err := walletclient.New(server.URL, walletclient.WithBearerToken(token)).GetBalance(context.Background(), walletID)
+	require.NoError(t, err)
+	assert.True(t, decimal.NewFromInt(10).Equal(balance))
+
+	_, err = walletclient.New(server.URL).GetBalance(context.Background(), walletID)
+	assert.ErrorIs(t, err, walletclient.ErrUnauthorized)
+}
@@ -0,0 +1,332 @@
+package test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"net/netip"
+	"strings"
+	"sync/atomic"
+	"test_wallet/internal/auth"
+	"test_wallet/internal/models"
+	"test_wallet/internal/service"
+	"test_wallet/internal/webhooks"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/google/uuid"
+	"github.com/shopspring/decimal"
+	"github.com/stretchr/testify/assert"
+)
+
+func balanceEvent(t *testing.T, walletID uuid.UUID, op string, before, after int64) models.Event {
+	data, err := json.Marshal(models.BalanceChanged{
+		Operation:     op,
+		Amount:        decimal.NewFromInt(after - before),
+		BalanceBefore: decimal.NewFromInt(before),
+		BalanceAfter:  decimal.NewFromInt(after),
+	})
+	assert.NoError(t, err)
+	return models.Event{EventID: uuid.New(), Type: models.EventBalanceChanged, WalletID: walletID, Data: data}
+}
+
+func TestWebhookMatch(t *testing.T) {
+	walletID := uuid.New()
+	threshold := decimal.NewFromInt(100)
+	sub := models.WebhookSubscription{
+		Events:              []string{models.WebhookWithdraw, models.WebhookLowBalance, models.WebhookWalletFrozen},
+		LowBalanceThreshold: &threshold,
+	}
+	frozen, _ := json.Marshal(models.StatusChanged{OldStatus: models.WalletActive, Status: models.WalletFrozen})
+	unfrozen, _ := json.Marshal(models.StatusChanged{OldStatus: models.WalletFrozen, Status: models.WalletActive})
+
+	cases := []struct {
+		name  string
+		event models.Event
+		kinds []string
+	}{
+		{"deposit not subscribed", balanceEvent(t, walletID, "DEPOSIT", 50, 150), nil},
+		{"withdraw above threshold", balanceEvent(t, walletID, "WITHDRAW", 300, 200), []string{models.WebhookWithdraw}},
+		{"withdraw crosses threshold", balanceEvent(t, walletID, "WITHDRAW", 100, 99),
+			[]string{models.WebhookWithdraw, models.WebhookLowBalance}},
+		{"already below threshold", balanceEvent(t, walletID, "WITHDRAW", 90, 80), []string{models.WebhookWithdraw}},
+		{"frozen", models.Event{Type: models.EventStatusChanged, Data: frozen}, []string{models.WebhookWalletFrozen}},
+		{"unfrozen", models.Event{Type: models.EventStatusChanged, Data: unfrozen}, nil},
+	}
+	for _, c := range cases {
+		kinds, err := webhooks.Match(sub, c.event)
+		assert.NoError(t, err, c.name)
+		assert.Equal(t, c.kinds, kinds, c.name)
+	}
+}
+
+func TestWebhookPublisher(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := NewMockWebhookStore(ctrl)
+	walletID := uuid.New()
+	deposits := models.WebhookSubscription{ID: uuid.New(), Events: []string{models.WebhookDeposit}}
+	withdrawals := models.WebhookSubscription{ID: uuid.New(), Events: []string{models.WebhookWithdraw}}
+	event := balanceEvent(t, walletID, "DEPOSIT", 0, 100)
+	event.CreatedAt = utc("2024-03-01T12:00:00Z")
+
+	store.EXPECT().WebhookSubscriptionsForWallet(gomock.Any(), walletID).
+		Return([]models.WebhookSubscription{deposits, withdrawals}, nil)
+	store.EXPECT().CreateWebhookDeliveries(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, deliveries []models.WebhookDelivery) (int64, error) {
+			assert.Len(t, deliveries, 1)
+			assert.Equal(t, deposits.ID, deliveries[0].SubscriptionID)
+			assert.Equal(t, event.EventID, deliveries[0].EventID)
+			assert.Equal(t, models.WebhookDeposit, deliveries[0].Event)
+			var payload models.WebhookPayload
+			assert.NoError(t, json.Unmarshal(deliveries[0].Payload, &payload))
+			assert.Equal(t, models.WebhookDeposit, payload.Event)
+			assert.Equal(t, walletID, payload.WalletID)
+			assert.Equal(t, event.CreatedAt, payload.OccurredAt)
+			assert.JSONEq(t, string(event.Data), string(payload.Data))
+			return 1, nil
+		})
+	assert.NoError(t, webhooks.NewPublisher(store).Publish(context.Background(), event))
+
+	// Нет подписок — нет доставок
+	store.EXPECT().WebhookSubscriptionsForWallet(gomock.Any(), walletID).Return(nil, nil)
+	assert.NoError(t, webhooks.NewPublisher(store).Publish(context.Background(), event))
+}
+
+func TestDispatcher_SignedDelivery(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := NewMockWebhookStore(ctrl)
+	now := utc("2024-03-01T12:00:00Z")
+	payload := []byte(`{"event":"deposit"}`)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		assert.Equal(t, payload, body)
+		assert.Equal(t, webhooks.Sign("whsec_test", now, body), r.Header.Get(webhooks.SignatureHeader))
+		assert.True(t, strings.HasPrefix(r.Header.Get(webhooks.SignatureHeader), "t=1709294400,v1="))
+		assert.Equal(t, models.WebhookDeposit, r.Header.Get(webhooks.EventHeader))
+		assert.Equal(t, "7", r.Header.Get(webhooks.DeliveryHeader))
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	defer srv.Close()
+
+	delivery := models.WebhookDelivery{
+		ID: 7, Event: models.WebhookDeposit, Payload: payload, Status: models.DeliveryPending,
+		URL: srv.URL, Secret: "whsec_test",
+	}
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), now, gomock.Any(), webhooks.DefaultBatchSize).
+		Return([]models.WebhookDelivery{delivery}, nil)
+	store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, d models.WebhookDelivery) error {
+			assert.Equal(t, models.DeliveryDelivered, d.Status)
+			assert.Equal(t, 1, d.Attempts)
+			assert.Equal(t, http.StatusNoContent, *d.LastStatusCode)
+			assert.Equal(t, now, *d.DeliveredAt)
+			return nil
+		})
+
+	d := webhooks.NewDispatcher(store, testLogger,
+		webhooks.WithClock(func() time.Time { return now }),
+		webhooks.WithHTTPClient(srv.Client()),
+	)
+	n, err := d.DispatchDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 1, n)
+}
+
+func TestDispatcher_RetryThenDead(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := NewMockWebhookStore(ctrl)
+	now := utc("2024-03-01T12:00:00Z")
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusBadGateway)
+	}))
+	defer srv.Close()
+	d := webhooks.NewDispatcher(store, testLogger,
+		webhooks.WithClock(func() time.Time { return now }),
+		webhooks.WithHTTPClient(srv.Client()),
+		webhooks.WithRetryDelay(time.Minute),
+		webhooks.WithMaxAttempts(3),
+	)
+	delivery := models.WebhookDelivery{ID: 1, Payload: []byte(`{}`), Status: models.DeliveryPending, Attempts: 1, URL: srv.URL}
+
+	// Вторая попытка из трёх: повтор через 2 минуты
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), now, gomock.Any(), gomock.Any()).
+		Return([]models.WebhookDelivery{delivery}, nil)
+	store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, d models.WebhookDelivery) error {
+			assert.Equal(t, models.DeliveryPending, d.Status)
+			assert.Equal(t, 2, d.Attempts)
+			assert.Equal(t, now.Add(2*time.Minute), d.NextAttemptAt)
+			assert.Equal(t, http.StatusBadGateway, *d.LastStatusCode)
+			assert.Equal(t, "unexpected status 502", *d.LastError)
+			return nil
+		})
+	n, err := d.DispatchDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+
+	// Третья попытка исчерпывает лимит
+	delivery.Attempts = 2
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), now, gomock.Any(), gomock.Any()).
+		Return([]models.WebhookDelivery{delivery}, nil)
+	store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, d models.WebhookDelivery) error {
+			assert.Equal(t, models.DeliveryDead, d.Status)
+			assert.Equal(t, 3, d.Attempts)
+			return nil
+		})
+	_, err = d.DispatchDue(context.Background())
+	assert.NoError(t, err)
+}
+
+func TestWebhookAddresses(t *testing.T) {
+	for addr, public := range map[string]bool{
+		"93.184.216.34":   true,
+		"2606:4700::1111": true,
+		"127.0.0.1":       false,
+		"::1":             false,
+		"0.0.0.0":         false,
+		"10.1.2.3":        false,
+		"172.16.0.1":      false,
+		"192.168.1.1":     false,
+		"100.64.0.1":      false,
+		"169.254.169.254": false,
+		"fe80::1":         false,
+		"fd00::1":         false,
+		"::ffff:10.0.0.1": false,
+	} {
+		assert.Equal(t, public, webhooks.IsPublicAddr(netip.MustParseAddr(addr)), addr)
+	}
+}
+
+func TestDispatcher_RefusesInternalAddresses(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	store := NewMockWebhookStore(ctrl)
+	var called atomic.Bool
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called.Store(true)
+	}))
+	defer srv.Close()
+
+	// Клиент по умолчанию не соединяется с loopback, даже если имя разрешилось в него
+	store.EXPECT().ClaimWebhookDeliveries(gomock.Any(), gomock.Any(), gomock.Any(), gomock.Any()).
+		Return([]models.WebhookDelivery{{ID: 1, Payload: []byte(`{}`), Status: models.DeliveryPending, URL: srv.URL}}, nil)
+	store.EXPECT().RecordWebhookAttempt(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, d models.WebhookDelivery) error {
+			assert.Equal(t, models.DeliveryPending, d.Status)
+			assert.Contains(t, *d.LastError, webhooks.ErrForbiddenAddress.Error())
+			return nil
+		})
+	n, err := webhooks.NewDispatcher(store, testLogger).DispatchDue(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 0, n)
+	assert.False(t, called.Load())
+
+	// Редиректы не выполняются: ответ 3xx считается неудачной доставкой
+	redirect := httptest.NewServer(http.RedirectHandler(srv.URL, http.StatusFound))
+	defer redirect.Close()
+	client := webhooks.NewHTTPClient(time.Second)
+	client.Transport = redirect.Client().Transport
+	resp, err := client.Post(redirect.URL, "application/json", strings.NewReader(`{}`))
+	if assert.NoError(t, err) {
+		resp.Body.Close()
+		assert.Equal(t, http.StatusFound, resp.StatusCode)
+	}
+	assert.False(t, called.Load())
+}
+
+func TestCreateWebhook_Validation(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	threshold := decimal.NewFromInt(10)
+
+	invalid := []models.WebhookRequest{
+		{OwnerID: "acme", URL: "ftp://example.com/hook", Events: []string{models.WebhookDeposit}},
+		{OwnerID: "acme", URL: "/hook", Events: []string{models.WebhookDeposit}},
+		{OwnerID: "acme", URL: "http://example.com/hook", Events: []string{models.WebhookDeposit}},
+		{OwnerID: "acme", URL: "https://localhost/hook", Events: []string{models.WebhookDeposit}},
+		{OwnerID: "acme", URL: "https://10.0.0.5/hook", Events: []string{models.WebhookDeposit}},
+		{OwnerID: "acme", URL: "https://169.254.169.254/latest/meta-data", Events: []string{models.WebhookDeposit}},
+		{OwnerID: "acme", URL: "https://[::1]:8443/hook", Events: []string{models.WebhookDeposit}},
+		{OwnerID: "acme", URL: "https://example.com/hook", Events: []string{models.WebhookLowBalance}},
+		{OwnerID: "acme", URL: "https://example.com/hook", Events: []string{models.WebhookDeposit}, LowBalanceThreshold: &threshold},
+	}
+	for _, req := range invalid {
+		_, err := svc.CreateWebhook(context.Background(), req)
+		assert.ErrorIs(t, err, models.ErrInvalidWebhook, "%+v", req)
+	}
+
+	mockRepo.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+			assert.Equal(t, []string{models.WebhookDeposit, models.WebhookLowBalance}, sub.Events)
+			assert.True(t, strings.HasPrefix(sub.Secret, "whsec_"))
+			assert.Len(t, sub.Secret, len("whsec_")+64)
+			return sub, nil
+		})
+	sub, err := svc.CreateWebhook(context.Background(), models.WebhookRequest{
+		OwnerID:             "acme",
+		URL:                 "https://example.com/hook",
+		Events:              []string{models.WebhookDeposit, models.WebhookLowBalance, models.WebhookDeposit},
+		LowBalanceThreshold: &threshold,
+	})
+	assert.NoError(t, err)
+	assert.NotEmpty(t, sub.Secret)
+
+	_, err = svc.ListWebhookDeliveries(context.Background(), uuid.New(), "LOST", 0)
+	assert.ErrorIs(t, err, models.ErrInvalidWebhook)
+}
+
+func TestWebhookOwnership(t *testing.T) {
+	ctrl := gomock.NewController(t)
+	defer ctrl.Finish()
+	mockRepo := NewMockWalletRepository(ctrl)
+	svc := service.NewWalletService(mockRepo, testLogger)
+	ctx := auth.NewContext(context.Background(), models.Principal{Subject: "acme"})
+	ownWallet, otherWallet := uuid.New(), uuid.New()
+	mockRepo.EXPECT().GetWallet(gomock.Any(), ownWallet).Return(models.Wallet{ID: ownWallet, OwnerID: "acme"}, nil).AnyTimes()
+	mockRepo.EXPECT().GetWallet(gomock.Any(), otherWallet).Return(models.Wallet{ID: otherWallet, OwnerID: "globex"}, nil).AnyTimes()
+
+	// Владелец подписки — вызывающий, а не ownerId из запроса
+	mockRepo.EXPECT().CreateWebhook(gomock.Any(), gomock.Any()).
+		DoAndReturn(func(_ context.Context, sub models.WebhookSubscription) (models.WebhookSubscription, error) {
+			assert.Equal(t, "acme", sub.OwnerID)
+			return sub, nil
+		})
+	_, err := svc.CreateWebhook(ctx, models.WebhookRequest{
+		WalletID: &ownWallet, URL: "https://example.com/hook", Events: []string{models.WebhookDeposit},
+	})
+	assert.NoError(t, err)
+	_, err = svc.CreateWebhook(ctx, models.WebhookRequest{
+		OwnerID: "globex", URL: "https://example.com/hook", Events: []string{models.WebhookDeposit},
+	})
+	assert.ErrorIs(t, err, models.ErrWebhookAccessDenied)
+	_, err = svc.CreateWebhook(ctx, models.WebhookRequest{
+		WalletID: &otherWallet, URL: "https://example.com/hook", Events: []string{models.WebhookDeposit},
+	})
+	assert.ErrorIs(t, err, models.ErrWalletAccessDenied)
+
+	mockRepo.EXPECT().ListWebhooks(gomock.Any(), "acme").Return(nil, nil)
+	_, err = svc.ListWebhooks(ctx, "")
+	assert.NoError(t, err)
+	_, err = svc.ListWebhooks(ctx, "globex")
+	assert.ErrorIs(t, err, models.ErrWebhookAccessDenied)
+
+	foreign := models.WebhookSubscription{ID: uuid.New(), OwnerID: "globex"}
+	mockRepo.EXPECT().GetWebhook(gomock.Any(), foreign.ID).Return(foreign, nil).Times(4)
+	_, err = svc.GetWebhook(ctx, foreign.ID)
+	assert.ErrorIs(t, err, models.ErrWebhookAccessDenied)
+	_, err = svc.DeleteWebhook(ctx, foreign.ID)
+	assert.ErrorIs(t, err, models.ErrWebhookAccessDenied)
+	_, err = svc.ListWebhookDeliveries(ctx, foreign.ID, "", 0)
+	assert.ErrorIs(t, err, models.ErrWebhookAccessDenied)
+	_, err = svc.RetryWebhookDelivery(ctx, foreign.ID, 1)
+	assert.ErrorIs(t, err, models.ErrWebhookAccessDenied)
+}